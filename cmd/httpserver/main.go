@@ -2,6 +2,8 @@ package main
 
 import (
 	"flag"
+	"log"
+	_ "time/tzdata"
 
 	"github.com/SergeyChupin/wallets-api/internal/app/httpserver"
 )
@@ -14,5 +16,14 @@ func init() {
 
 func main() {
 	flag.Parse()
-	httpserver.Run(configPath)
+	switch flag.Arg(0) {
+	case "":
+		httpserver.Run(configPath)
+	case "migrate":
+		httpserver.Migrate(configPath, flag.Args()[1:])
+	case "backfill-entries":
+		httpserver.BackfillEntries(configPath, flag.Args()[1:])
+	default:
+		log.Fatalf("unknown command: %s", flag.Arg(0))
+	}
 }
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"os"
+	_ "time/tzdata"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/walletctl"
+)
+
+func main() {
+	os.Exit(walletctl.Run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -1,25 +1,58 @@
 package api
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1"
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/SergeyChupin/wallets-api/internal/events"
+	"github.com/SergeyChupin/wallets-api/internal/health"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/metrics"
+	"github.com/SergeyChupin/wallets-api/internal/ratelimit"
 	"github.com/SergeyChupin/wallets-api/internal/service"
 	"github.com/go-openapi/runtime/middleware"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type handler struct {
-	logger *log.Logger
+	logger *logging.Logger
 	router *mux.Router
 }
 
-func NewHandler(logger *log.Logger, walletService service.WalletService) *handler {
+// Dependencies are the services and the configs the routes of the handler
+// are served with.
+type Dependencies struct {
+	WalletService service.WalletService
+	OwnerService  service.OwnerService
+	// DepositImportService serves the deposit imports, nil disables them.
+	DepositImportService service.DepositImportService
+	// WebhookService serves the webhooks, nil disables them.
+	WebhookService  service.WebhookService
+	ApiKeyService   service.ApiKeyService
+	AuditService    service.AuditService
+	TokenVerifier   auth.TokenVerifier
+	Broker          events.Broker
+	ExportConfig    v1.ExportConfig
+	EventsConfig    events.Config
+	AuthConfig      auth.Config
+	RateLimitConfig ratelimit.Config
+	PoolStats       func() []database.PoolStats
+	// MetricsRegistry is the registry the metrics are served from, nil
+	// disables them.
+	MetricsRegistry *prometheus.Registry
+	MetricsConfig   metrics.Config
+	HealthChecker   health.Checker
+}
+
+func NewHandler(logger *logging.Logger, dependencies Dependencies) *handler {
 	handler := &handler{
 		logger: logger,
 	}
-	handler.initRoutes(walletService)
+	handler.initRoutes(dependencies)
 	return handler
 }
 
@@ -27,12 +60,43 @@ func (handler *handler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	handler.router.ServeHTTP(rw, req)
 }
 
-func (handler *handler) initRoutes(walletService service.WalletService) {
+func (handler *handler) initRoutes(dependencies Dependencies) {
 	router := mux.NewRouter()
+	router.Use(v1.RequestId, v1.Trace, v1.AccessLog(handler.logger))
+	if dependencies.MetricsRegistry != nil {
+		httpMetrics := metrics.NewHttpMetrics(dependencies.MetricsRegistry)
+		router.Use(v1.Metrics(httpMetrics))
+		v1.CountUnmatched(router, httpMetrics)
+		router.Handle(dependencies.MetricsConfig.Path, promhttp.HandlerFor(dependencies.MetricsRegistry, promhttp.HandlerOpts{})).Methods(http.MethodGet)
+	}
+	v1.NewHealthApi(handler.logger, router, dependencies.HealthChecker)
 
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
+	apiRouter := router.PathPrefix(v1.PathPrefix).Subrouter()
+	var rateLimiter ratelimit.Limiter
+	if dependencies.RateLimitConfig.Enabled {
+		rateLimiter = ratelimit.NewLimiter(dependencies.RateLimitConfig)
+		apiRouter.Use(v1.LimitIpRate(rateLimiter, dependencies.RateLimitConfig))
+	}
+	apiRouter.Use(v1.Authenticate(handler.logger, dependencies.ApiKeyService, dependencies.TokenVerifier, dependencies.AuthConfig))
+	if rateLimiter != nil {
+		apiRouter.Use(v1.LimitApiKeyRate(rateLimiter))
+	}
+	apiRouter.Use(v1.Audit(handler.logger, dependencies.AuditService, dependencies.RateLimitConfig.TrustForwardedFor))
+	apiRouter.Use(v1.ReadConsistency)
 
-	v1.NewWalletsApi(handler.logger, apiRouter, walletService)
+	v1.NewWalletsApi(handler.logger, apiRouter, dependencies.WalletService, dependencies.ExportConfig)
+	v1.NewWalletGrantsApi(handler.logger, apiRouter, dependencies.WalletService)
+	v1.NewOwnersApi(handler.logger, apiRouter, dependencies.OwnerService)
+	if dependencies.DepositImportService != nil {
+		v1.NewImportsApi(handler.logger, apiRouter, dependencies.DepositImportService)
+	}
+	if dependencies.WebhookService != nil {
+		v1.NewWebhooksApi(handler.logger, apiRouter, dependencies.WebhookService)
+	}
+	v1.NewEventsApi(handler.logger, apiRouter, dependencies.WalletService, dependencies.Broker, dependencies.EventsConfig)
+	v1.NewAdminApi(handler.logger, apiRouter, dependencies.PoolStats)
+	v1.NewApiKeysApi(handler.logger, apiRouter, dependencies.ApiKeyService)
+	v1.NewAuditLogApi(handler.logger, apiRouter, dependencies.AuditService)
 
 	redocOpts := middleware.RedocOpts{SpecURL: "/api.yaml"}
 	redocHandler := middleware.Redoc(redocOpts, nil)
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/gorilla/mux"
+)
+
+type adminApi struct {
+	logger    *logging.Logger
+	poolStats func() []database.PoolStats
+}
+
+func NewAdminApi(logger *logging.Logger, router *mux.Router, poolStats func() []database.PoolStats) {
+	adminApi := &adminApi{
+		logger:    logger,
+		poolStats: poolStats,
+	}
+	router.HandleFunc("/admin/pools", adminApi.GetPools).Methods(http.MethodGet)
+}
+
+// swagger:route GET /admin/pools AdminAPI getPools
+// Return statistics of the database connection pools
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: poolsStatsResponse
+//  500: errorResponse
+func (adminApi *adminApi) GetPools(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	pools := adminApi.poolStats()
+	var respData dto.PoolsStatsResponse = make([]*dto.PoolStatsResponse, 0, len(pools))
+	for _, pool := range pools {
+		respData = append(respData, &dto.PoolStatsResponse{
+			Name:           pool.Name,
+			MaxOpen:        pool.MaxOpen,
+			Open:           pool.Open,
+			InUse:          pool.InUse,
+			Idle:           pool.Idle,
+			WaitCount:      pool.WaitCount,
+			WaitDurationMs: pool.WaitDuration.Milliseconds(),
+		})
+	}
+	if err := respData.ToJson(rw); err != nil {
+		adminApi.logger.Error(req.Context(), "adminApi - GetPools - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,43 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPools(t *testing.T) {
+	// given
+	router := mux.NewRouter()
+	NewAdminApi(logger, router, func() []database.PoolStats {
+		return []database.PoolStats{{
+			Name:         "postgres",
+			MaxOpen:      20,
+			Open:         5,
+			InUse:        3,
+			Idle:         2,
+			WaitCount:    7,
+			WaitDuration: 1500 * time.Millisecond,
+		}}
+	})
+	req, err := http.NewRequest("GET", "/admin/pools", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t,
+		`[{"name":"postgres","max_open":20,"open":5,"in_use":3,"idle":2,"wait_count":7,"wait_duration_ms":1500}]`,
+		recorder.Body.String(),
+	)
+}
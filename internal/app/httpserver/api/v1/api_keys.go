@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type apiKeysApi struct {
+	logger        *logging.Logger
+	apiKeyService service.ApiKeyService
+}
+
+func NewApiKeysApi(logger *logging.Logger, router *mux.Router, apiKeyService service.ApiKeyService) {
+	apiKeysApi := &apiKeysApi{
+		logger:        logger,
+		apiKeyService: apiKeyService,
+	}
+	router.HandleFunc("/admin/api-keys", apiKeysApi.CreateApiKey).Methods(http.MethodPost)
+	router.HandleFunc("/admin/api-keys", apiKeysApi.GetApiKeys).Methods(http.MethodGet)
+	router.HandleFunc("/admin/api-keys/{id}/rotate", apiKeysApi.RotateApiKey).Methods(http.MethodPost)
+	router.HandleFunc("/admin/api-keys/{id}", apiKeysApi.RevokeApiKey).Methods(http.MethodDelete)
+}
+
+// swagger:route POST /admin/api-keys AdminAPI createApiKey
+// Issue an API key, the response contains the key which is not shown again
+//
+// consumes:
+//	- application/json
+// produces:
+// 	- application/json
+//
+// responses:
+//	201: apiKeyResponse
+//  400: errorResponse
+//  500: errorResponse
+func (apiKeysApi *apiKeysApi) CreateApiKey(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	var reqData dto.CreateApiKeyRequest
+	if err := reqData.FromJson(req.Body); err != nil {
+		apiKeysApi.logger.Warn(req.Context(), "apiKeysApi - CreateApiKey - reqData.FromJson", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := reqData.Validate(); err != nil {
+		apiKeysApi.logger.Warn(req.Context(), "apiKeysApi - CreateApiKey - reqData.Validate", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	scopes := make([]model.Scope, 0, len(reqData.Scopes))
+	for _, value := range reqData.Scopes {
+		scope, err := model.ScopeFromString(value)
+		if err != nil {
+			apiKeysApi.logger.Warn(req.Context(), "apiKeysApi - CreateApiKey - model.ScopeFromString", logging.Err(err))
+			writeError(rw, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		scopes = append(scopes, scope)
+	}
+	apiKey, key, err := apiKeysApi.apiKeyService.IssueApiKey(req.Context(), reqData.Name, scopes)
+	if err != nil {
+		apiKeysApi.logger.Error(req.Context(), "apiKeysApi - CreateApiKey - apiKeysApi.apiKeyService.IssueApiKey", logging.Err(err))
+		writeError(rw, "unable to issue api key", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusCreated)
+	respData := toApiKeyResponse(apiKey)
+	respData.Key = key
+	if err = respData.ToJson(rw); err != nil {
+		apiKeysApi.logger.Error(req.Context(), "apiKeysApi - CreateApiKey - respData.ToJson", logging.Err(err))
+		return
+	}
+}
+
+// swagger:route GET /admin/api-keys AdminAPI getApiKeys
+// Return a list of API keys, revoked ones included
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: apiKeysResponse
+//  500: errorResponse
+func (apiKeysApi *apiKeysApi) GetApiKeys(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	apiKeys, err := apiKeysApi.apiKeyService.GetApiKeys(req.Context())
+	if err != nil {
+		apiKeysApi.logger.Error(req.Context(), "apiKeysApi - GetApiKeys - apiKeysApi.apiKeyService.GetApiKeys", logging.Err(err))
+		writeError(rw, "unable to get api keys", http.StatusInternalServerError)
+		return
+	}
+	var respData dto.ApiKeysResponse = make([]*dto.ApiKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		respData = append(respData, toApiKeyResponse(apiKey))
+	}
+	if err = respData.ToJson(rw); err != nil {
+		apiKeysApi.logger.Error(req.Context(), "apiKeysApi - GetApiKeys - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route POST /admin/api-keys/{id}/rotate AdminAPI rotateApiKey
+// Replace an API key, the previous key stops working right away
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: apiKeyResponse
+//  404: errorResponse
+//  500: errorResponse
+func (apiKeysApi *apiKeysApi) RotateApiKey(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(req)["id"]
+	apiKey, key, err := apiKeysApi.apiKeyService.RotateApiKey(req.Context(), id)
+	if err != nil {
+		apiKeysApi.logger.Log(req.Context(), errorLevel(err), "apiKeysApi - RotateApiKey - apiKeysApi.apiKeyService.RotateApiKey", logging.Err(err))
+		if errors.Is(err, model.ErrApiKeyNotFound) {
+			writeError(rw, "api key not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to rotate api key", http.StatusInternalServerError)
+		return
+	}
+	respData := toApiKeyResponse(apiKey)
+	respData.Key = key
+	if err = respData.ToJson(rw); err != nil {
+		apiKeysApi.logger.Error(req.Context(), "apiKeysApi - RotateApiKey - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route DELETE /admin/api-keys/{id} AdminAPI revokeApiKey
+// Revoke an API key, the transactions it created keep referring to it
+//
+// responses:
+//	204:
+//  404: errorResponse
+//  500: errorResponse
+func (apiKeysApi *apiKeysApi) RevokeApiKey(rw http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if err := apiKeysApi.apiKeyService.RevokeApiKey(req.Context(), id); err != nil {
+		apiKeysApi.logger.Log(req.Context(), errorLevel(err), "apiKeysApi - RevokeApiKey - apiKeysApi.apiKeyService.RevokeApiKey", logging.Err(err))
+		if errors.Is(err, model.ErrApiKeyNotFound) {
+			writeError(rw, "api key not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to revoke api key", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func toApiKeyResponse(apiKey *model.ApiKey) *dto.ApiKeyResponse {
+	respData := &dto.ApiKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		Scopes:    make([]string, 0, len(apiKey.Scopes)),
+		CreatedAt: apiKey.CreatedAt,
+		RotatedAt: apiKey.RotatedAt,
+		RevokedAt: apiKey.RevokedAt,
+	}
+	for _, scope := range apiKey.Scopes {
+		respData.Scopes = append(respData.Scopes, scope.String())
+	}
+	return respData
+}
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateApiKey(t *testing.T) {
+	// given
+	apiKeyService := new(apiKeyServiceMock)
+	router := mux.NewRouter()
+	NewApiKeysApi(logger, router, apiKeyService)
+	req, err := http.NewRequest("POST", "/admin/api-keys", bytes.NewBufferString(`{"name":"checkout","scopes":["wallets:read","transfers:create"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	scopes := []model.Scope{model.WalletsReadScope, model.TransfersCreateScope}
+	apiKeyService.On("IssueApiKey", "checkout", scopes).Return(
+		&model.ApiKey{ID: "3001", Name: "checkout", Prefix: "wk_abcdefgh", Scopes: scopes, CreatedAt: createdAt},
+		"wk_abcdefghsecret",
+		nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	var respData dto.ApiKeyResponse
+	if err = json.NewDecoder(recorder.Body).Decode(&respData); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dto.ApiKeyResponse{
+		ID:        "3001",
+		Name:      "checkout",
+		Prefix:    "wk_abcdefgh",
+		Key:       "wk_abcdefghsecret",
+		Scopes:    []string{"wallets:read", "transfers:create"},
+		CreatedAt: createdAt,
+	}, respData)
+
+	apiKeyService.AssertExpectations(t)
+}
+
+func TestCreateApiKeyInvalidScope(t *testing.T) {
+	// given
+	apiKeyService := new(apiKeyServiceMock)
+	router := mux.NewRouter()
+	NewApiKeysApi(logger, router, apiKeyService)
+	req, err := http.NewRequest("POST", "/admin/api-keys", bytes.NewBufferString(`{"name":"checkout","scopes":["wallets:delete"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	apiKeyService.AssertNumberOfCalls(t, "IssueApiKey", 0)
+}
+
+func TestRevokeApiKeyNotFound(t *testing.T) {
+	// given
+	apiKeyService := new(apiKeyServiceMock)
+	router := mux.NewRouter()
+	NewApiKeysApi(logger, router, apiKeyService)
+	req, err := http.NewRequest("DELETE", "/admin/api-keys/3001", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	apiKeyService.On("RevokeApiKey", "3001").Return(model.ErrApiKeyNotFound)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	apiKeyService.AssertExpectations(t)
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// auditActions maps the method and path template of the state-changing routes
+// to the action they are audited as. Routes not listed are not audited.
+var auditActions = map[string]string{
+	http.MethodPost + " /wallets":                                      "wallet.create",
+	http.MethodPost + " /wallets/{id}/deposit":                         "wallet.deposit",
+	http.MethodPost + " /wallets/{id}/transfer":                        "wallet.transfer",
+	http.MethodPut + " /wallets/{id}/grants/{subject}":                 "wallet.grant",
+	http.MethodDelete + " /wallets/{id}/grants/{subject}":              "wallet.revoke",
+	http.MethodPost + " /imports/deposits":                             "deposits.import",
+	http.MethodPost + " /owners":                                       "owner.create",
+	http.MethodPut + " /owners/{id}":                                   "owner.update",
+	http.MethodDelete + " /owners/{id}":                                "owner.delete",
+	http.MethodPost + " /owners/{id}/wallets":                          "owner.wallet.create",
+	http.MethodPost + " /webhooks":                                     "webhook.create",
+	http.MethodDelete + " /webhooks/{id}":                              "webhook.delete",
+	http.MethodPost + " /webhooks/{id}/replay":                         "webhook.replay",
+	http.MethodPost + " /webhooks/{id}/deliveries/{deliveryId}/replay": "webhook.delivery.replay",
+	http.MethodPost + " /admin/api-keys":                               "api_key.create",
+	http.MethodPost + " /admin/api-keys/{id}/rotate":                   "api_key.rotate",
+	http.MethodDelete + " /admin/api-keys/{id}":                        "api_key.revoke",
+}
+
+// maxAuditedPayloadSize bounds the request bodies read to be hashed, it is the
+// largest body the API accepts.
+const maxAuditedPayloadSize = maxDepositImportSize
+
+// Audit records who made each state-changing call of auditActions, from which
+// address and whether it succeeded. It runs after Authenticate. The entry is
+// passed to the handler through the context: creating a wallet, a deposit and
+// a transfer record it along with the operation if it succeeds. Any other call,
+// and a failed one, is recorded once the handler returns, a call is failed if
+// the response status is 400 or above. It runs after RequestId.
+func Audit(logger *logging.Logger, auditService service.AuditService, trustForwardedFor bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			name := routeName(req)
+			action, ok := auditActions[name]
+			if !ok {
+				next.ServeHTTP(rw, req)
+				return
+			}
+			entry := &model.AuditEntry{
+				Action:    action,
+				ClientIp:  clientIp(req, trustForwardedFor),
+				RequestId: logging.RequestIdFromContext(req.Context()),
+			}
+			if principal := auth.PrincipalFromContext(req.Context()); principal != nil {
+				entry.Actor = principal.Subject
+			}
+			if _, template, _ := cutString(name, " "); strings.HasPrefix(template, "/wallets/{id}") {
+				entry.WalletId = getWalletId(req)
+			}
+			recorder := &statusRecorder{ResponseWriter: rw, statusCode: http.StatusOK}
+			payload, err := io.ReadAll(http.MaxBytesReader(rw, req.Body, maxAuditedPayloadSize))
+			hash := sha256.Sum256(payload)
+			entry.PayloadHash = hex.EncodeToString(hash[:])
+			if err != nil {
+				logger.Warn(req.Context(), "Audit - io.ReadAll", logging.Err(err))
+				writeError(recorder, "invalid request body", http.StatusBadRequest)
+			} else {
+				req.Body = io.NopCloser(bytes.NewReader(payload))
+				next.ServeHTTP(recorder, req.WithContext(audit.WithEntry(req.Context(), entry)))
+			}
+			if entry.ID != "" {
+				return
+			}
+			entry.Outcome = model.AuditSuccess
+			if recorder.statusCode >= http.StatusBadRequest {
+				entry.Outcome = model.AuditFailure
+			}
+			// The call is recorded even if the client is gone.
+			if err = auditService.RecordAuditEntry(context.Background(), entry); err != nil {
+				logger.Error(req.Context(), "Audit - auditService.RecordAuditEntry", logging.Err(err))
+			}
+		})
+	}
+}
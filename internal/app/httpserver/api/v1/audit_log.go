@@ -0,0 +1,124 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type auditLogApi struct {
+	logger       *logging.Logger
+	auditService service.AuditService
+}
+
+func NewAuditLogApi(logger *logging.Logger, router *mux.Router, auditService service.AuditService) {
+	auditLogApi := &auditLogApi{
+		logger:       logger,
+		auditService: auditService,
+	}
+	router.HandleFunc("/admin/audit-log", auditLogApi.GetAuditLog).Methods(http.MethodGet)
+}
+
+// swagger:route GET /admin/audit-log AdminAPI getAuditLog
+// Return the audit log entries matching the filter, newest first
+//
+// produces:
+// 	- application/json
+// 	- text/csv
+//
+// responses:
+//	200: auditEntriesResponse
+//  400: errorResponse
+//  406: errorResponse
+//  500: errorResponse
+func (auditLogApi *auditLogApi) GetAuditLog(rw http.ResponseWriter, req *http.Request) {
+	contentType := negotiateContentType(req.Header.Get("Accept"), []string{contentTypeJson, contentTypeCsv})
+	if contentType == "" {
+		auditLogApi.logger.Warn(req.Context(), "auditLogApi - GetAuditLog - invalid header 'Accept'")
+		writeError(rw, "invalid header 'Accept'", http.StatusNotAcceptable)
+		return
+	}
+	limit, offset, err := getPagination(req)
+	if err != nil {
+		auditLogApi.logger.Warn(req.Context(), "auditLogApi - GetAuditLog - getPagination", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := getAuditFilter(req)
+	if err != nil {
+		auditLogApi.logger.Warn(req.Context(), "auditLogApi - GetAuditLog - getAuditFilter", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entries, err := auditLogApi.auditService.GetAuditEntries(req.Context(), limit, offset, filter)
+	if err != nil {
+		auditLogApi.logger.Error(req.Context(), "auditLogApi - GetAuditLog - auditLogApi.auditService.GetAuditEntries", logging.Err(err))
+		writeError(rw, "unable to get audit log", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", contentType)
+	var respData dto.AuditEntriesResponse = make([]*dto.AuditEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		respData = append(respData, &dto.AuditEntryResponse{
+			ID:          entry.ID,
+			Actor:       entry.Actor,
+			Action:      entry.Action,
+			WalletId:    entry.WalletId,
+			PayloadHash: entry.PayloadHash,
+			Outcome:     entry.Outcome.String(),
+			ClientIp:    entry.ClientIp,
+			RequestId:   entry.RequestId,
+			CreatedAt:   entry.CreatedAt,
+		})
+	}
+	if contentType == contentTypeCsv {
+		rw.Header().Set("Content-Disposition", `attachment; filename="audit-log.csv"`)
+		if err = respData.ToCsv(rw); err != nil {
+			auditLogApi.logger.Error(req.Context(), "auditLogApi - GetAuditLog - respData.ToCsv", logging.Err(err))
+		}
+		return
+	}
+	if err = respData.ToJson(rw); err != nil {
+		auditLogApi.logger.Error(req.Context(), "auditLogApi - GetAuditLog - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func getAuditFilter(req *http.Request) (model.AuditFilter, error) {
+	query := req.URL.Query()
+	filter := model.AuditFilter{
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		WalletId:  query.Get("wallet_id"),
+		RequestId: query.Get("request_id"),
+	}
+	if outcome := query.Get("outcome"); outcome != "" {
+		var err error
+		if filter.Outcome, err = model.AuditOutcomeFromString(outcome); err != nil {
+			return filter, errors.New("invalid query parameter outcome")
+		}
+	}
+	if createdAtGte := query.Get("created_at.gte"); createdAtGte != "" {
+		var err error
+		if filter.CreatedAtGte, err = time.Parse(time.RFC3339Nano, createdAtGte); err != nil {
+			return filter, errors.New("invalid query parameter created_at.gte")
+		}
+	}
+	if createdAtLte := query.Get("created_at.lte"); createdAtLte != "" {
+		var err error
+		if filter.CreatedAtLte, err = time.Parse(time.RFC3339Nano, createdAtLte); err != nil {
+			return filter, errors.New("invalid query parameter created_at.lte")
+		}
+	}
+	if !filter.CreatedAtLte.IsZero() && !filter.CreatedAtGte.Before(filter.CreatedAtLte) {
+		return filter, errors.New("invalid time range created_at")
+	}
+	return filter, nil
+}
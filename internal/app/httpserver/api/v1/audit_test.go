@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type auditServiceMock struct {
+	mock.Mock
+}
+
+func (auditService *auditServiceMock) RecordAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	args := auditService.Called(*entry)
+	return args.Error(0)
+}
+
+func (auditService *auditServiceMock) GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	args := auditService.Called(limit, offset, filter)
+	return args.Get(0).([]*model.AuditEntry), args.Error(1)
+}
+
+// newAuditedRouter returns a router auditing the calls made by alice to handler.
+func newAuditedRouter(auditService *auditServiceMock, handler http.HandlerFunc) *mux.Router {
+	router := mux.NewRouter()
+	router.Use(RequestId)
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			principal := &model.Principal{Subject: "alice"}
+			next.ServeHTTP(rw, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+		})
+	})
+	router.Use(Audit(logger, auditService, false))
+	router.HandleFunc("/wallets/{id}/transfer", handler).Methods(http.MethodPost)
+	router.HandleFunc("/wallets/{id}/transactions", handler).Methods(http.MethodGet)
+	return router
+}
+
+func TestAuditFailedCall(t *testing.T) {
+	// given
+	auditService := new(auditServiceMock)
+	router := newAuditedRouter(auditService, func(rw http.ResponseWriter, req *http.Request) {
+		writeError(rw, "insufficient funds", http.StatusConflict)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/wallets/1001/transfer", bytes.NewBufferString(`{}`))
+	req.Header.Set(requestIdHeader, "request-1")
+	recorder := httptest.NewRecorder()
+	auditService.On("RecordAuditEntry", model.AuditEntry{
+		Actor:       "alice",
+		Action:      "wallet.transfer",
+		WalletId:    "1001",
+		PayloadHash: "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+		Outcome:     model.AuditFailure,
+		ClientIp:    "192.0.2.1",
+		RequestId:   "request-1",
+	}).Return(nil)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	auditService.AssertExpectations(t)
+}
+
+func TestAuditRecordedWithOperation(t *testing.T) {
+	// given
+	auditService := new(auditServiceMock)
+	router := newAuditedRouter(auditService, func(rw http.ResponseWriter, req *http.Request) {
+		audit.EntryFromContext(req.Context()).ID = "4001"
+		rw.WriteHeader(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, "/wallets/1001/transfer", bytes.NewBufferString(`{}`))
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	auditService.AssertNotCalled(t, "RecordAuditEntry", mock.Anything)
+}
+
+func TestAuditSkipsReadOnlyCall(t *testing.T) {
+	// given
+	auditService := new(auditServiceMock)
+	var entry *model.AuditEntry
+	router := newAuditedRouter(auditService, func(rw http.ResponseWriter, req *http.Request) {
+		entry = audit.EntryFromContext(req.Context())
+		rw.WriteHeader(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/wallets/1001/transactions", nil)
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Nil(t, entry)
+	auditService.AssertNotCalled(t, "RecordAuditEntry", mock.Anything)
+}
+
+func TestGetAuditLogCsv(t *testing.T) {
+	// given
+	auditService := new(auditServiceMock)
+	router := mux.NewRouter()
+	NewAuditLogApi(logger, router, auditService)
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit-log?actor=alice&outcome=failure&limit=10", nil)
+	req.Header.Set("Accept", "text/csv")
+	recorder := httptest.NewRecorder()
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	auditService.On("GetAuditEntries", 10, -1, model.AuditFilter{Actor: "alice", Outcome: model.AuditFailure}).Return(
+		[]*model.AuditEntry{{
+			ID:          "4001",
+			Actor:       "alice",
+			Action:      "wallet.transfer",
+			WalletId:    "1001",
+			PayloadHash: "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a",
+			Outcome:     model.AuditFailure,
+			ClientIp:    "192.0.2.1",
+			CreatedAt:   createdAt,
+		}},
+		nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "Id,Actor,Action,WalletId,PayloadHash,Outcome,ClientIp,RequestId,CreatedAt\n"+
+		"4001,alice,wallet.transfer,1001,44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a,failure,192.0.2.1,,2022-01-01T00:00:00Z\n",
+		recorder.Body.String())
+	auditService.AssertExpectations(t)
+}
+
+func TestGetAuditLogInvalidOutcome(t *testing.T) {
+	// given
+	auditService := new(auditServiceMock)
+	router := mux.NewRouter()
+	NewAuditLogApi(logger, router, auditService)
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit-log?outcome=maybe", nil)
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	auditService.AssertNotCalled(t, "GetAuditEntries", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+// PathPrefix is the path the v1 API is served under.
+const PathPrefix = "/api/v1"
+
+// routeScopes maps the method and path template of a route to the scope it
+// requires. Routes not listed, webhooks, owners and admin ones among them,
+// require the admin scope.
+var routeScopes = map[string]model.Scope{
+	http.MethodPost + " /wallets":                         model.WalletsWriteScope,
+	http.MethodPost + " /wallets/{id}/deposit":            model.WalletsWriteScope,
+	http.MethodPost + " /wallets/{id}/transfer":           model.TransfersCreateScope,
+	http.MethodGet + " /wallets/{id}/transactions":        model.WalletsReadScope,
+	http.MethodGet + " /wallets/{id}/events":              model.WalletsReadScope,
+	http.MethodGet + " /wallets/{id}/grants":              model.WalletsReadScope,
+	http.MethodPut + " /wallets/{id}/grants/{subject}":    model.WalletsWriteScope,
+	http.MethodDelete + " /wallets/{id}/grants/{subject}": model.WalletsWriteScope,
+	http.MethodPost + " /imports/deposits":                model.WalletsWriteScope,
+	http.MethodGet + " /imports/deposits/{id}":            model.WalletsReadScope,
+}
+
+// Authenticate identifies the request by the bearer token of the authorization
+// header, or by the key of the API key header, and checks that the principal
+// is granted the scope of the route. The principal is available to the
+// handlers through auth.PrincipalFromContext. Bearer tokens are accepted when
+// tokenVerifier is not nil. With authentication disabled every request is made
+// as auth.Anonymous.
+func Authenticate(
+	logger *logging.Logger, apiKeyService service.ApiKeyService, tokenVerifier auth.TokenVerifier, config auth.Config,
+) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			principal := auth.Anonymous
+			if config.Enabled {
+				var err error
+				if principal, err = authenticate(req, apiKeyService, tokenVerifier); err != nil {
+					switch {
+					case errors.Is(err, errMissingCredentials):
+						writeError(rw, fmt.Sprintf("missing header '%s' or '%s'", auth.AuthorizationHeader, auth.ApiKeyHeader), http.StatusUnauthorized)
+					case errors.Is(err, model.ErrApiKeyNotFound):
+						writeError(rw, "invalid api key", http.StatusUnauthorized)
+					case errors.Is(err, model.ErrInvalidToken):
+						logger.Warn(req.Context(), "Authenticate - authenticate", logging.Err(err))
+						writeError(rw, "invalid bearer token", http.StatusUnauthorized)
+					default:
+						logger.Error(req.Context(), "Authenticate - authenticate", logging.Err(err))
+						writeError(rw, "unable to authenticate", http.StatusInternalServerError)
+					}
+					return
+				}
+			}
+			if scope := routeScope(req); !principal.HasScope(scope) {
+				writeError(rw, fmt.Sprintf("principal is not granted scope '%s'", scope), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(rw, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+		})
+	}
+}
+
+var errMissingCredentials = errors.New("missing credentials")
+
+func authenticate(req *http.Request, apiKeyService service.ApiKeyService, tokenVerifier auth.TokenVerifier) (*model.Principal, error) {
+	if token, ok := auth.BearerToken(req.Header.Get(auth.AuthorizationHeader)); ok && tokenVerifier != nil {
+		return tokenVerifier.Verify(req.Context(), token)
+	}
+	key := req.Header.Get(auth.ApiKeyHeader)
+	if key == "" {
+		return nil, errMissingCredentials
+	}
+	apiKey, err := apiKeyService.Authenticate(req.Context(), key)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey.Principal(), nil
+}
+
+// routeScope returns the scope required by the route of the request.
+func routeScope(req *http.Request) model.Scope {
+	if scope, ok := routeScopes[routeName(req)]; ok {
+		return scope
+	}
+	return model.AdminScope
+}
+
+// routeName returns the method and the path template below PathPrefix of the
+// route of the request, empty if the request matches no route.
+func routeName(req *http.Request) string {
+	route := mux.CurrentRoute(req)
+	if route == nil {
+		return ""
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+	return req.Method + " " + strings.TrimPrefix(template, PathPrefix)
+}
@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type apiKeyServiceMock struct {
+	mock.Mock
+}
+
+func (apiKeyService *apiKeyServiceMock) IssueApiKey(ctx context.Context, name string, scopes []model.Scope) (*model.ApiKey, string, error) {
+	args := apiKeyService.Called(name, scopes)
+	return args.Get(0).(*model.ApiKey), args.String(1), args.Error(2)
+}
+
+func (apiKeyService *apiKeyServiceMock) GetApiKeys(ctx context.Context) ([]*model.ApiKey, error) {
+	args := apiKeyService.Called()
+	return args.Get(0).([]*model.ApiKey), args.Error(1)
+}
+
+func (apiKeyService *apiKeyServiceMock) RotateApiKey(ctx context.Context, id string) (*model.ApiKey, string, error) {
+	args := apiKeyService.Called(id)
+	return args.Get(0).(*model.ApiKey), args.String(1), args.Error(2)
+}
+
+func (apiKeyService *apiKeyServiceMock) RevokeApiKey(ctx context.Context, id string) error {
+	args := apiKeyService.Called(id)
+	return args.Error(0)
+}
+
+func (apiKeyService *apiKeyServiceMock) Authenticate(ctx context.Context, key string) (*model.ApiKey, error) {
+	args := apiKeyService.Called(key)
+	return args.Get(0).(*model.ApiKey), args.Error(1)
+}
+
+type tokenVerifierMock struct {
+	mock.Mock
+}
+
+func (tokenVerifier *tokenVerifierMock) Verify(ctx context.Context, token string) (*model.Principal, error) {
+	args := tokenVerifier.Called(token)
+	return args.Get(0).(*model.Principal), args.Error(1)
+}
+
+func TestAuthenticate(t *testing.T) {
+	reader := &model.ApiKey{ID: "3001", Scopes: []model.Scope{model.WalletsReadScope}}
+	admin := &model.ApiKey{ID: "3002", Scopes: []model.Scope{model.AdminScope}}
+	user := &model.Principal{Subject: "user-1", Scopes: []model.Scope{model.TransfersCreateScope}}
+	tests := []struct {
+		name              string
+		method            string
+		path              string
+		key               string
+		authorization     string
+		disabled          bool
+		expectedCode      int
+		expectedPrincipal *model.Principal
+	}{
+		{name: "missing key", method: "GET", path: "/api/v1/wallets/1001/transactions", expectedCode: http.StatusUnauthorized},
+		{name: "invalid key", method: "GET", path: "/api/v1/wallets/1001/transactions", key: "wk_invalid", expectedCode: http.StatusUnauthorized},
+		{name: "granted scope", method: "GET", path: "/api/v1/wallets/1001/transactions", key: "wk_reader", expectedCode: http.StatusOK, expectedPrincipal: reader.Principal()},
+		{name: "missing scope", method: "POST", path: "/api/v1/wallets/1001/transfer", key: "wk_reader", expectedCode: http.StatusForbidden},
+		{name: "unlisted route", method: "GET", path: "/api/v1/webhooks", key: "wk_reader", expectedCode: http.StatusForbidden},
+		{name: "admin", method: "POST", path: "/api/v1/wallets/1001/transfer", key: "wk_admin", expectedCode: http.StatusOK, expectedPrincipal: admin.Principal()},
+		{name: "bearer token", method: "POST", path: "/api/v1/wallets/1001/transfer", authorization: "Bearer user-token", expectedCode: http.StatusOK, expectedPrincipal: user},
+		{name: "invalid bearer token", method: "POST", path: "/api/v1/wallets/1001/transfer", authorization: "Bearer invalid", expectedCode: http.StatusUnauthorized},
+		{name: "bearer token missing scope", method: "GET", path: "/api/v1/webhooks", authorization: "bearer user-token", expectedCode: http.StatusForbidden},
+		{name: "disabled", method: "GET", path: "/api/v1/webhooks", disabled: true, expectedCode: http.StatusOK, expectedPrincipal: auth.Anonymous},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			apiKeyService := new(apiKeyServiceMock)
+			apiKeyService.On("Authenticate", "wk_invalid").Return((*model.ApiKey)(nil), model.ErrApiKeyNotFound)
+			apiKeyService.On("Authenticate", "wk_reader").Return(reader, nil)
+			apiKeyService.On("Authenticate", "wk_admin").Return(admin, nil)
+			tokenVerifier := new(tokenVerifierMock)
+			tokenVerifier.On("Verify", "invalid").Return((*model.Principal)(nil), model.ErrInvalidToken)
+			tokenVerifier.On("Verify", "user-token").Return(user, nil)
+			var principal *model.Principal
+			handler := func(rw http.ResponseWriter, req *http.Request) {
+				principal = auth.PrincipalFromContext(req.Context())
+			}
+			router := mux.NewRouter()
+			apiRouter := router.PathPrefix(PathPrefix).Subrouter()
+			apiRouter.Use(Authenticate(logger, apiKeyService, tokenVerifier, auth.Config{Enabled: !test.disabled}))
+			apiRouter.HandleFunc("/wallets/{id}/transactions", handler).Methods(http.MethodGet)
+			apiRouter.HandleFunc("/wallets/{id}/transfer", handler).Methods(http.MethodPost)
+			apiRouter.HandleFunc("/webhooks", handler).Methods(http.MethodGet)
+			req, err := http.NewRequest(test.method, test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.key != "" {
+				req.Header.Set(auth.ApiKeyHeader, test.key)
+			}
+			if test.authorization != "" {
+				req.Header.Set(auth.AuthorizationHeader, test.authorization)
+			}
+			recorder := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(recorder, req)
+
+			// then
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			assert.Equal(t, test.expectedPrincipal, principal)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package v1
+
+import "github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+
+type ExportConfig struct {
+	CsvProfiles map[string]dto.CsvProfile `yaml:"csv-profiles"`
+}
+
+func NewExportConfig() ExportConfig {
+	return ExportConfig{
+		CsvProfiles: map[string]dto.CsvProfile{},
+	}
+}
@@ -0,0 +1,27 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SergeyChupin/wallets-api/internal/database"
+)
+
+const eventualReadConsistency = "eventual"
+
+// ReadConsistency reads the primary for requests with the strong read
+// consistency header, so a client sees its own writes despite replica lag.
+func ReadConsistency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch consistency := strings.ToLower(req.Header.Get(database.ReadConsistencyHeader)); consistency {
+		case "", eventualReadConsistency:
+		case database.StrongReadConsistency:
+			req = req.WithContext(database.WithPrimaryReads(req.Context()))
+		default:
+			writeError(rw, fmt.Sprintf("invalid header '%s'", database.ReadConsistencyHeader), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(rw, req)
+	})
+}
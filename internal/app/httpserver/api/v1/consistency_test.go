@@ -0,0 +1,48 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadConsistency(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		expectedCode int
+		primaryReads bool
+	}{
+		{name: "default", expectedCode: http.StatusOK},
+		{name: "eventual", header: "eventual", expectedCode: http.StatusOK},
+		{name: "strong", header: "Strong", expectedCode: http.StatusOK, primaryReads: true},
+		{name: "invalid", header: "linearizable", expectedCode: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			var primaryReads bool
+			handler := ReadConsistency(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				primaryReads = database.PrimaryReads(req.Context())
+			}))
+			req, err := http.NewRequest("GET", "/wallets/id/transactions", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.header != "" {
+				req.Header.Set(database.ReadConsistencyHeader, test.header)
+			}
+			recorder := httptest.NewRecorder()
+
+			// when
+			handler.ServeHTTP(recorder, req)
+
+			// then
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			assert.Equal(t, test.primaryReads, primaryReads)
+		})
+	}
+}
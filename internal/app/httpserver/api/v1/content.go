@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	contentTypeJson    = "application/json"
+	contentTypeCsv     = "text/csv"
+	contentTypeCamt053 = "application/xml"
+	contentTypeOfx     = "application/x-ofx"
+)
+
+type acceptedMediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// negotiateContentType picks the supported content type that best matches
+// the Accept header. The first supported type is the default for an empty
+// header or a wildcard. An empty string means nothing acceptable is supported.
+func negotiateContentType(accept string, supported []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return supported[0]
+	}
+	var mediaRanges []acceptedMediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaRange := acceptedMediaRange{
+			mediaType: strings.ToLower(strings.TrimSpace(params[0])),
+			quality:   1,
+		}
+		for _, param := range params[1:] {
+			name, value, found := cutString(strings.TrimSpace(param), "=")
+			if !found || strings.ToLower(name) != "q" {
+				continue
+			}
+			quality, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				quality = 0
+			}
+			mediaRange.quality = quality
+		}
+		if mediaRange.mediaType != "" && mediaRange.quality > 0 {
+			mediaRanges = append(mediaRanges, mediaRange)
+		}
+	}
+	sort.SliceStable(mediaRanges, func(i, j int) bool {
+		return mediaRanges[i].quality > mediaRanges[j].quality
+	})
+	for _, mediaRange := range mediaRanges {
+		for _, contentType := range supported {
+			if matchMediaRange(mediaRange.mediaType, contentType) {
+				return contentType
+			}
+		}
+	}
+	return ""
+}
+
+func matchMediaRange(mediaRange string, contentType string) bool {
+	if mediaRange == "*/*" || mediaRange == contentType {
+		return true
+	}
+	if strings.HasSuffix(mediaRange, "/*") {
+		return strings.HasPrefix(contentType, strings.TrimSuffix(mediaRange, "*"))
+	}
+	return false
+}
+
+func cutString(s string, sep string) (before string, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -6,6 +6,20 @@
 //	BasePath: /api/v1
 //	Version: 1.0.0
 //
+//	Security:
+//	- api_key:
+//	- bearer:
+//
+//	SecurityDefinitions:
+//	api_key:
+//	  type: apiKey
+//	  name: X-API-Key
+//	  in: header
+//	bearer:
+//	  type: apiKey
+//	  name: Authorization
+//	  in: header
+//
 // swagger:meta
 package v1
 
@@ -51,7 +65,7 @@ type transferResponse struct {
 	Body dto.TransferResponse `json:"body"`
 }
 
-// swagger:parameters deposit transfer getTransactions
+// swagger:parameters deposit transfer getTransactions getEvents
 type walletID struct {
 	// in: path
 	ID string `json:"id"`
@@ -69,6 +83,279 @@ type getTransactions struct {
 	ProcessedAtGte time.Time `json:"processed_at.gte"`
 	// in: query
 	ProcessedAtLte time.Time `json:"processed_at.lte"`
+	// Saved CSV export profile
+	// in: query
+	CsvProfile string `json:"csv.profile"`
+	// Comma separated CSV columns: Id, OperationType, Amount, SenderWalletId, SenderWalletBalance,
+	// SenderWalletMe, RecipientWalletId, RecipientWalletBalance, RecipientWalletMe, Balance, ProcessedAt, Reference, ApiKeyId
+	// in: query
+	CsvColumns string `json:"csv.columns"`
+	// Comma separated CSV header overrides as column:header pairs, e.g. ProcessedAt:Date,Amount:Sum
+	// in: query
+	CsvHeaders string `json:"csv.headers"`
+	// CSV delimiter: a single character or one of comma, semicolon, tab, pipe
+	// in: query
+	CsvDelimiter string `json:"csv.delimiter"`
+	// CSV representation of empty values
+	// in: query
+	CsvNull string `json:"csv.null"`
+	// CSV timestamp format: rfc3339, rfc3339nano, datetime, date, unix or a Go time layout
+	// in: query
+	CsvTimeFormat string `json:"csv.time_format"`
+	// CSV timestamp IANA timezone
+	// in: query
+	CsvTimezone string `json:"csv.timezone"`
+	// CSV amounts units: minor or major
+	// in: query
+	CsvAmounts string `json:"csv.amounts"`
+	// CSV decimal separator of amounts in major units
+	// in: query
+	CsvDecimalSeparator string `json:"csv.decimal_separator"`
+}
+
+// swagger:parameters getTransactions
+type readConsistency struct {
+	// Read consistency: eventual (default) reads may be served by a lagging replica, strong reads are served by the primary
+	// in: header
+	ReadConsistency string `json:"X-Read-Consistency"`
+}
+
+// swagger:parameters getEvents
+type getEvents struct {
+	// ID of the last received event to resume the stream after
+	// in: header
+	LastEventID string `json:"Last-Event-ID"`
+}
+
+// swagger:response eventsResponse
+type eventsResponse struct {
+	// Stream of transaction and balance events
+	// in: body
+	Body string
+}
+
+// swagger:parameters importDeposits
+type importDepositsRequest struct {
+	// Validate and report rows without applying them
+	// in: query
+	DryRun bool `json:"dry_run"`
+	// Apply valid rows atomically or in chunks: atomic, chunked
+	// in: query
+	Mode string `json:"mode"`
+	// CSV file with wallet_id, amount and reference columns
+	// in: formData
+	// swagger:file
+	File interface{} `json:"file"`
+}
+
+// swagger:parameters getDepositImport
+type depositImportID struct {
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:response depositImportResponse
+type depositImportResponse struct {
+	// in: body
+	Body dto.DepositImportResponse `json:"body"`
+}
+
+// swagger:parameters createWebhook
+type createWebhookRequest struct {
+	// in: body
+	Body dto.CreateWebhookRequest `json:"body"`
+}
+
+// swagger:parameters deleteWebhook getWebhookDeliveries replayWebhook
+type webhookID struct {
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:parameters getWebhookDeliveries
+type getWebhookDeliveries struct {
+	// in: query
+	Limit int `json:"limit"`
+	// in: query
+	Offset int `json:"offset"`
+	// Delivery status: pending, delivered, dead
+	// in: query
+	Status string `json:"status"`
+}
+
+// swagger:parameters replayWebhookDelivery
+type webhookDeliveryID struct {
+	// in: path
+	ID string `json:"id"`
+	// in: path
+	DeliveryId string `json:"deliveryId"`
+}
+
+// swagger:response webhookResponse
+type webhookResponse struct {
+	// in: body
+	Body dto.WebhookResponse `json:"body"`
+}
+
+// swagger:response webhooksResponse
+type webhooksResponse struct {
+	// in: body
+	Body []dto.WebhookResponse
+}
+
+// swagger:response webhookDeliveriesResponse
+type webhookDeliveriesResponse struct {
+	// in: body
+	Body []dto.WebhookDeliveryResponse
+}
+
+// swagger:response replayWebhookResponse
+type replayWebhookResponse struct {
+	// in: body
+	Body dto.ReplayWebhookResponse `json:"body"`
+}
+
+// swagger:parameters createApiKey
+type createApiKeyRequest struct {
+	// in: body
+	Body dto.CreateApiKeyRequest `json:"body"`
+}
+
+// swagger:parameters rotateApiKey revokeApiKey
+type apiKeyID struct {
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:response apiKeyResponse
+type apiKeyResponse struct {
+	// in: body
+	Body dto.ApiKeyResponse `json:"body"`
+}
+
+// swagger:response apiKeysResponse
+type apiKeysResponse struct {
+	// in: body
+	Body []dto.ApiKeyResponse
+}
+
+// swagger:parameters getAuditLog
+type getAuditLog struct {
+	// in: query
+	Limit int `json:"limit"`
+	// in: query
+	Offset int `json:"offset"`
+	// in: query
+	Actor string `json:"actor"`
+	// in: query
+	Action string `json:"action"`
+	// in: query
+	WalletId string `json:"wallet_id"`
+	// in: query
+	Outcome string `json:"outcome"`
+	// in: query
+	RequestId string `json:"request_id"`
+	// in: query
+	CreatedAtGte time.Time `json:"created_at.gte"`
+	// in: query
+	CreatedAtLte time.Time `json:"created_at.lte"`
+}
+
+// swagger:response auditEntriesResponse
+type auditEntriesResponse struct {
+	// in: body
+	Body []dto.AuditEntryResponse
+}
+
+// swagger:parameters getWalletGrants
+type walletGrantsID struct {
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:parameters grantWalletAccess revokeWalletAccess
+type walletGrantID struct {
+	// in: path
+	ID string `json:"id"`
+	// Subject of the principal, the subject claim of a bearer token or apikey:<id>
+	// in: path
+	Subject string `json:"subject"`
+}
+
+// swagger:parameters grantWalletAccess
+type walletGrantRequest struct {
+	// in: body
+	Body dto.WalletGrantRequest `json:"body"`
+}
+
+// swagger:response walletGrantsResponse
+type walletGrantsResponse struct {
+	// in: body
+	Body []dto.WalletGrantResponse
+}
+
+// swagger:parameters createOwner updateOwner
+type ownerRequest struct {
+	// in: body
+	Body dto.OwnerRequest `json:"body"`
+}
+
+// swagger:parameters getOwner updateOwner deleteOwner createOwnerWallet getOwnerWallets getOwnerTransactions
+type ownerID struct {
+	// in: path
+	ID string `json:"id"`
+}
+
+// swagger:parameters getOwners
+type getOwners struct {
+	// in: query
+	Limit int `json:"limit"`
+	// in: query
+	Offset int `json:"offset"`
+}
+
+// swagger:parameters createOwnerWallet
+type createOwnerWalletRequest struct {
+	// in: body
+	Body dto.CreateWalletRequest `json:"body"`
+}
+
+// swagger:parameters getOwnerTransactions
+type getOwnerTransactions struct {
+	// in: query
+	Limit int `json:"limit"`
+	// in: query
+	Offset int `json:"offset"`
+	// in: query
+	OperationType string `json:"operation_type"`
+	// in: query
+	ProcessedAtGte time.Time `json:"processed_at.gte"`
+	// in: query
+	ProcessedAtLte time.Time `json:"processed_at.lte"`
+}
+
+// swagger:response ownerResponse
+type ownerResponse struct {
+	// in: body
+	Body dto.OwnerResponse `json:"body"`
+}
+
+// swagger:response ownersResponse
+type ownersResponse struct {
+	// in: body
+	Body []dto.OwnerResponse
+}
+
+// swagger:response walletsResponse
+type walletsResponse struct {
+	// in: body
+	Body []dto.WalletResponse
+}
+
+// swagger:response poolsStatsResponse
+type poolsStatsResponse struct {
+	// in: body
+	Body []dto.PoolStatsResponse
 }
 
 // swagger:response errorResponse
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// swagger:model
+type CreateApiKeyRequest struct {
+	Name   string   `json:"name" validate:"required,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=wallets:read wallets:write transfers:create admin"`
+}
+
+func (req *CreateApiKeyRequest) FromJson(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	return decoder.Decode(req)
+}
+
+func (req *CreateApiKeyRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+// swagger:model
+type ApiKeyResponse struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Prefix string `json:"prefix"`
+	// Key is returned once, when the key is issued or rotated.
+	Key       string     `json:"key,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	CreatedAt time.Time  `json:"created_at"`
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+func (resp *ApiKeyResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+type ApiKeysResponse []*ApiKeyResponse
+
+func (resp *ApiKeysResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
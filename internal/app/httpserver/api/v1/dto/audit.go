@@ -0,0 +1,58 @@
+package dto
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// swagger:model
+type AuditEntryResponse struct {
+	ID          string    `json:"id"`
+	Actor       string    `json:"actor"`
+	Action      string    `json:"action"`
+	WalletId    string    `json:"wallet_id,omitempty"`
+	PayloadHash string    `json:"payload_hash"`
+	Outcome     string    `json:"outcome"`
+	ClientIp    string    `json:"client_ip"`
+	RequestId   string    `json:"request_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type AuditEntriesResponse []*AuditEntryResponse
+
+func (resp *AuditEntriesResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+var auditCsvColumns = []string{
+	"Id", "Actor", "Action", "WalletId", "PayloadHash", "Outcome", "ClientIp", "RequestId", "CreatedAt",
+}
+
+// ToCsv writes the entries with a header, empty fields are written empty and
+// times in RFC 3339 in UTC.
+func (resp *AuditEntriesResponse) ToCsv(writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(auditCsvColumns); err != nil {
+		return err
+	}
+	for _, entry := range *resp {
+		if err := csvWriter.Write([]string{
+			entry.ID,
+			entry.Actor,
+			entry.Action,
+			entry.WalletId,
+			entry.PayloadHash,
+			entry.Outcome,
+			entry.ClientIp,
+			entry.RequestId,
+			entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+		}); err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,256 @@
+package dto
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"
+
+// The lengths of the camt.053 text types the statement fields are restricted to.
+const (
+	camt053Max34Text = 34
+	camt053Max35Text = 35
+	camt053Max70Text = 70
+)
+
+type camt053Document struct {
+	XMLName xml.Name             `xml:"Document"`
+	Xmlns   string               `xml:"xmlns,attr"`
+	Stmt    camt053BkToCstmrStmt `xml:"BkToCstmrStmt"`
+}
+
+type camt053BkToCstmrStmt struct {
+	GrpHdr camt053GrpHdr `xml:"GrpHdr"`
+	Stmt   camt053Stmt   `xml:"Stmt"`
+}
+
+type camt053GrpHdr struct {
+	MsgId   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+type camt053Stmt struct {
+	Id        string           `xml:"Id"`
+	CreDtTm   string           `xml:"CreDtTm"`
+	FrToDt    camt053FrToDt    `xml:"FrToDt"`
+	Acct      camt053Acct      `xml:"Acct"`
+	Bal       []camt053Bal     `xml:"Bal"`
+	TxsSummry camt053TxsSummry `xml:"TxsSummry"`
+	Ntry      []camt053Ntry    `xml:"Ntry"`
+}
+
+type camt053FrToDt struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+type camt053Acct struct {
+	Id  camt053AcctId `xml:"Id"`
+	Ccy string        `xml:"Ccy"`
+	Nm  string        `xml:"Nm,omitempty"`
+}
+
+type camt053AcctId struct {
+	Othr camt053Othr `xml:"Othr"`
+}
+
+type camt053Othr struct {
+	Id string `xml:"Id"`
+}
+
+type camt053Amt struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+type camt053Bal struct {
+	Tp        camt053BalTp `xml:"Tp"`
+	Amt       camt053Amt   `xml:"Amt"`
+	CdtDbtInd string       `xml:"CdtDbtInd"`
+	Dt        camt053DtTm  `xml:"Dt"`
+}
+
+type camt053BalTp struct {
+	CdOrPrtry camt053Cd `xml:"CdOrPrtry"`
+}
+
+type camt053Cd struct {
+	Cd string `xml:"Cd"`
+}
+
+type camt053DtTm struct {
+	DtTm string `xml:"DtTm"`
+}
+
+type camt053TxsSummry struct {
+	TtlNtries    camt053TtlNtries  `xml:"TtlNtries"`
+	TtlCdtNtries camt053NbOfNtries `xml:"TtlCdtNtries"`
+	TtlDbtNtries camt053NbOfNtries `xml:"TtlDbtNtries"`
+}
+
+type camt053TtlNtries struct {
+	NbOfNtries int `xml:"NbOfNtries"`
+}
+
+type camt053NbOfNtries struct {
+	NbOfNtries int    `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type camt053Ntry struct {
+	NtryRef     string          `xml:"NtryRef"`
+	Amt         camt053Amt      `xml:"Amt"`
+	CdtDbtInd   string          `xml:"CdtDbtInd"`
+	Sts         string          `xml:"Sts"`
+	BookgDt     camt053DtTm     `xml:"BookgDt"`
+	ValDt       camt053DtTm     `xml:"ValDt"`
+	AcctSvcrRef string          `xml:"AcctSvcrRef"`
+	BkTxCd      camt053BkTxCd   `xml:"BkTxCd"`
+	NtryDtls    camt053NtryDtls `xml:"NtryDtls"`
+}
+
+type camt053BkTxCd struct {
+	Prtry camt053Prtry `xml:"Prtry"`
+}
+
+type camt053Prtry struct {
+	Cd   string `xml:"Cd"`
+	Issr string `xml:"Issr"`
+}
+
+type camt053NtryDtls struct {
+	TxDtls camt053TxDtls `xml:"TxDtls"`
+}
+
+type camt053TxDtls struct {
+	Refs      camt053Refs       `xml:"Refs"`
+	RltdPties *camt053RltdPties `xml:"RltdPties,omitempty"`
+}
+
+type camt053Refs struct {
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	EndToEndId  string `xml:"EndToEndId"`
+}
+
+type camt053RltdPties struct {
+	DbtrAcct *camt053CounterpartyAcct `xml:"DbtrAcct,omitempty"`
+	CdtrAcct *camt053CounterpartyAcct `xml:"CdtrAcct,omitempty"`
+}
+
+type camt053CounterpartyAcct struct {
+	Id camt053AcctId `xml:"Id"`
+}
+
+func formatCamt053Time(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func camt053CdtDbtInd(credit bool) string {
+	if credit {
+		return "CRDT"
+	}
+	return "DBIT"
+}
+
+func (resp *StatementResponse) newCamt053Bal(code string, balance uint64, at time.Time) camt053Bal {
+	return camt053Bal{
+		Tp:        camt053BalTp{CdOrPrtry: camt053Cd{Cd: code}},
+		Amt:       camt053Amt{Ccy: resp.Currency, Value: formatAmount(balance, resp.Currency, ".")},
+		CdtDbtInd: camt053CdtDbtInd(true),
+		Dt:        camt053DtTm{DtTm: formatCamt053Time(at)},
+	}
+}
+
+// ToCamt053 writes the statement as an ISO 20022 camt.053.001.02 document.
+func (resp *StatementResponse) ToCamt053(writer io.Writer) error {
+	if err := ValidateCurrency(resp.Currency); err != nil {
+		return err
+	}
+	credits, debits := resp.totals()
+	creditEntries := 0
+	for _, entry := range resp.Entries {
+		if entry.Credit {
+			creditEntries++
+		}
+	}
+	stmt := camt053Stmt{
+		Id:      resp.id(),
+		CreDtTm: formatCamt053Time(resp.CreatedAt),
+		FrToDt: camt053FrToDt{
+			FrDtTm: formatCamt053Time(resp.From),
+			ToDtTm: formatCamt053Time(resp.To),
+		},
+		Acct: camt053Acct{
+			Id:  camt053AcctId{Othr: camt053Othr{Id: shortenId(resp.WalletId, camt053Max34Text)}},
+			Ccy: resp.Currency,
+			Nm:  truncate(resp.WalletName, camt053Max70Text),
+		},
+		Bal: []camt053Bal{
+			resp.newCamt053Bal("OPBD", resp.OpeningBalance, resp.From),
+			resp.newCamt053Bal("CLBD", resp.ClosingBalance, resp.To),
+		},
+		TxsSummry: camt053TxsSummry{
+			TtlNtries: camt053TtlNtries{NbOfNtries: len(resp.Entries)},
+			TtlCdtNtries: camt053NbOfNtries{
+				NbOfNtries: creditEntries,
+				Sum:        formatAmount(credits, resp.Currency, "."),
+			},
+			TtlDbtNtries: camt053NbOfNtries{
+				NbOfNtries: len(resp.Entries) - creditEntries,
+				Sum:        formatAmount(debits, resp.Currency, "."),
+			},
+		},
+	}
+	for _, entry := range resp.Entries {
+		endToEndId := shortenId(entry.EndToEndId, camt053Max35Text)
+		if endToEndId == "" {
+			endToEndId = "NOTPROVIDED"
+		}
+		reference := shortenId(entry.Reference, camt053Max35Text)
+		ntry := camt053Ntry{
+			NtryRef:     reference,
+			Amt:         camt053Amt{Ccy: resp.Currency, Value: formatAmount(entry.Amount, resp.Currency, ".")},
+			CdtDbtInd:   camt053CdtDbtInd(entry.Credit),
+			Sts:         "BOOK",
+			BookgDt:     camt053DtTm{DtTm: formatCamt053Time(entry.ProcessedAt)},
+			ValDt:       camt053DtTm{DtTm: formatCamt053Time(entry.ProcessedAt)},
+			AcctSvcrRef: reference,
+			BkTxCd: camt053BkTxCd{
+				Prtry: camt053Prtry{Cd: entry.OperationType, Issr: "wallets-api"},
+			},
+			NtryDtls: camt053NtryDtls{
+				TxDtls: camt053TxDtls{
+					Refs: camt053Refs{AcctSvcrRef: reference, EndToEndId: endToEndId},
+				},
+			},
+		}
+		if entry.CounterpartyWalletId != "" {
+			counterpartyId := shortenId(entry.CounterpartyWalletId, camt053Max34Text)
+			counterparty := &camt053CounterpartyAcct{Id: camt053AcctId{Othr: camt053Othr{Id: counterpartyId}}}
+			if entry.Credit {
+				ntry.NtryDtls.TxDtls.RltdPties = &camt053RltdPties{DbtrAcct: counterparty}
+			} else {
+				ntry.NtryDtls.TxDtls.RltdPties = &camt053RltdPties{CdtrAcct: counterparty}
+			}
+		}
+		stmt.Ntry = append(stmt.Ntry, ntry)
+	}
+	document := camt053Document{
+		Xmlns: camt053Namespace,
+		Stmt: camt053BkToCstmrStmt{
+			GrpHdr: camt053GrpHdr{
+				MsgId:   resp.id(),
+				CreDtTm: formatCamt053Time(resp.CreatedAt),
+			},
+			Stmt: stmt,
+		},
+	}
+	if _, err := io.WriteString(writer, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
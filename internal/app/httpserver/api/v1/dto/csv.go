@@ -0,0 +1,261 @@
+package dto
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	AmountsMinorUnits = "minor"
+	AmountsMajorUnits = "major"
+)
+
+// CsvProfile describes a CSV export layout. Profiles are either saved in the
+// configuration or built from query parameters, empty fields are inherited.
+type CsvProfile struct {
+	Columns []string `yaml:"columns"`
+	// Headers renames the columns in the header row, columns not listed keep
+	// their names.
+	Headers          map[string]string `yaml:"headers"`
+	Delimiter        string            `yaml:"delimiter"`
+	Null             *string           `yaml:"null"`
+	TimeFormat       string            `yaml:"time-format"`
+	Timezone         string            `yaml:"timezone"`
+	Amounts          string            `yaml:"amounts"`
+	DecimalSeparator string            `yaml:"decimal-separator"`
+}
+
+// Merge returns a copy of the profile with fields overridden by non-empty fields of override,
+// headers are overridden one by one.
+func (profile CsvProfile) Merge(override CsvProfile) CsvProfile {
+	if len(override.Columns) > 0 {
+		profile.Columns = override.Columns
+	}
+	if len(override.Headers) > 0 {
+		headers := make(map[string]string, len(profile.Headers)+len(override.Headers))
+		for column, header := range profile.Headers {
+			headers[column] = header
+		}
+		for column, header := range override.Headers {
+			headers[column] = header
+		}
+		profile.Headers = headers
+	}
+	if override.Delimiter != "" {
+		profile.Delimiter = override.Delimiter
+	}
+	if override.Null != nil {
+		profile.Null = override.Null
+	}
+	if override.TimeFormat != "" {
+		profile.TimeFormat = override.TimeFormat
+	}
+	if override.Timezone != "" {
+		profile.Timezone = override.Timezone
+	}
+	if override.Amounts != "" {
+		profile.Amounts = override.Amounts
+	}
+	if override.DecimalSeparator != "" {
+		profile.DecimalSeparator = override.DecimalSeparator
+	}
+	return profile
+}
+
+// CsvOptions is a validated CSV export layout.
+type CsvOptions struct {
+	Columns []string
+	// Headers holds the header of each column, in the order of Columns.
+	Headers          []string
+	Delimiter        rune
+	Null             string
+	TimeFormat       string
+	Location         *time.Location
+	MajorUnits       bool
+	DecimalSeparator string
+	Currency         string
+}
+
+func DefaultCsvOptions() CsvOptions {
+	return CsvOptions{
+		Columns:          defaultCsvColumns,
+		Delimiter:        ',',
+		Null:             "NULL",
+		TimeFormat:       time.RFC3339Nano,
+		Location:         time.UTC,
+		DecimalSeparator: ".",
+	}
+}
+
+var timeFormats = map[string]string{
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"datetime":    "2006-01-02 15:04:05",
+	"date":        "2006-01-02",
+	"unix":        "unix",
+}
+
+var delimiters = map[string]rune{
+	"comma":     ',',
+	"semicolon": ';',
+	"tab":       '\t',
+	"pipe":      '|',
+}
+
+// Options validates the profile and converts it to CSV options. The currency
+// is used to convert amounts to major units.
+func (profile CsvProfile) Options(currency string) (CsvOptions, error) {
+	options := DefaultCsvOptions()
+	options.Currency = currency
+	if len(profile.Columns) > 0 {
+		for _, column := range profile.Columns {
+			if _, ok := csvColumns[column]; !ok {
+				return CsvOptions{}, fmt.Errorf("unknown csv column: %s", column)
+			}
+		}
+		options.Columns = profile.Columns
+	}
+	for column, header := range profile.Headers {
+		if _, ok := csvColumns[column]; !ok {
+			return CsvOptions{}, fmt.Errorf("unknown csv column: %s", column)
+		}
+		if header == "" {
+			return CsvOptions{}, fmt.Errorf("empty csv header: %s", column)
+		}
+	}
+	options.Headers = make([]string, 0, len(options.Columns))
+	for _, column := range options.Columns {
+		header, ok := profile.Headers[column]
+		if !ok {
+			header = column
+		}
+		options.Headers = append(options.Headers, header)
+	}
+	if profile.Delimiter != "" {
+		delimiter, ok := delimiters[profile.Delimiter]
+		if !ok {
+			if utf8.RuneCountInString(profile.Delimiter) != 1 {
+				return CsvOptions{}, fmt.Errorf("invalid csv delimiter: %s", profile.Delimiter)
+			}
+			delimiter, _ = utf8.DecodeRuneInString(profile.Delimiter)
+		}
+		if delimiter == '"' || delimiter == '\r' || delimiter == '\n' || delimiter == utf8.RuneError {
+			return CsvOptions{}, fmt.Errorf("invalid csv delimiter: %s", profile.Delimiter)
+		}
+		options.Delimiter = delimiter
+	}
+	if profile.Null != nil {
+		options.Null = *profile.Null
+	}
+	if profile.TimeFormat != "" {
+		timeFormat, ok := timeFormats[strings.ToLower(profile.TimeFormat)]
+		if !ok {
+			timeFormat = profile.TimeFormat
+		}
+		options.TimeFormat = timeFormat
+	}
+	if profile.Timezone != "" {
+		location, err := time.LoadLocation(profile.Timezone)
+		if err != nil {
+			return CsvOptions{}, fmt.Errorf("invalid csv timezone: %s", profile.Timezone)
+		}
+		options.Location = location
+	}
+	switch profile.Amounts {
+	case "", AmountsMinorUnits:
+	case AmountsMajorUnits:
+		options.MajorUnits = true
+	default:
+		return CsvOptions{}, fmt.Errorf("invalid csv amounts: %s", profile.Amounts)
+	}
+	if profile.DecimalSeparator != "" {
+		options.DecimalSeparator = profile.DecimalSeparator
+	}
+	if options.MajorUnits {
+		if err := ValidateCurrency(currency); err != nil {
+			return CsvOptions{}, err
+		}
+	}
+	if options.MajorUnits && string(options.Delimiter) == options.DecimalSeparator {
+		return CsvOptions{}, errors.New("csv decimal separator should be different than delimiter")
+	}
+	return options, nil
+}
+
+func (options *CsvOptions) formatAmount(amount uint64) string {
+	if !options.MajorUnits {
+		return strconv.FormatUint(amount, 10)
+	}
+	return formatAmount(amount, options.Currency, options.DecimalSeparator)
+}
+
+type csvColumn func(transaction *TransactionResponse, options *CsvOptions) (string, bool)
+
+var defaultCsvColumns = []string{
+	"OperationType", "Amount",
+	"SenderWalletId", "SenderWalletBalance", "SenderWalletMe",
+	"RecipientWalletId", "RecipientWalletBalance", "RecipientWalletMe",
+	"Balance", "ProcessedAt",
+}
+
+var csvColumns = map[string]csvColumn{
+	"Id": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return transaction.ID, transaction.ID != ""
+	},
+	"OperationType": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return transaction.OperationType, true
+	},
+	"Amount": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return options.formatAmount(transaction.Amount), true
+	},
+	"SenderWalletId": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		if transaction.SenderWalletId == nil {
+			return "", false
+		}
+		return *transaction.SenderWalletId, true
+	},
+	"SenderWalletBalance": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		if transaction.SenderWalletBalance == nil {
+			return "", false
+		}
+		return options.formatAmount(*transaction.SenderWalletBalance), true
+	},
+	"SenderWalletMe": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return strconv.FormatBool(transaction.SenderWalletMe), transaction.SenderWalletMe
+	},
+	"RecipientWalletId": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		if transaction.RecipientWalletId == nil {
+			return "", false
+		}
+		return *transaction.RecipientWalletId, true
+	},
+	"RecipientWalletBalance": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		if transaction.RecipientWalletBalance == nil {
+			return "", false
+		}
+		return options.formatAmount(*transaction.RecipientWalletBalance), true
+	},
+	"RecipientWalletMe": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return strconv.FormatBool(transaction.RecipientWalletMe), transaction.RecipientWalletMe
+	},
+	"Balance": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return options.formatAmount(transaction.Balance), true
+	},
+	"Reference": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return transaction.Reference, transaction.Reference != ""
+	},
+	"ApiKeyId": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		return transaction.ApiKeyId, transaction.ApiKeyId != ""
+	},
+	"ProcessedAt": func(transaction *TransactionResponse, options *CsvOptions) (string, bool) {
+		processedAt := transaction.ProcessedAt.In(options.Location)
+		if options.TimeFormat == "unix" {
+			return strconv.FormatInt(processedAt.Unix(), 10), true
+		}
+		return processedAt.Format(options.TimeFormat), true
+	},
+}
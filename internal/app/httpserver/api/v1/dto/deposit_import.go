@@ -0,0 +1,108 @@
+package dto
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var depositImportColumns = []string{"wallet_id", "amount", "reference"}
+
+type DepositImportRowRequest struct {
+	Line      int
+	WalletId  string
+	Amount    uint64
+	Reference string
+	Error     string
+}
+
+// DepositImportRequest is a CSV file with a header row naming the wallet_id,
+// amount and reference columns, in any order.
+type DepositImportRequest struct {
+	Rows []*DepositImportRowRequest
+}
+
+// FromCsv reads the rows of the file. Rows that can't be parsed are kept with
+// an error, an error is returned only if the file itself is malformed.
+func (req *DepositImportRequest) FromCsv(reader io.Reader) error {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	header, err := csvReader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("empty csv file")
+		}
+		return err
+	}
+	indexes := make(map[string]int, len(depositImportColumns))
+	for i, column := range header {
+		indexes[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, column := range depositImportColumns {
+		if _, ok := indexes[column]; !ok {
+			return fmt.Errorf("missing csv column: %s", column)
+		}
+	}
+	for {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				req.Rows = append(req.Rows, &DepositImportRowRequest{Line: parseErr.StartLine, Error: parseErr.Err.Error()})
+				continue
+			}
+			return err
+		}
+		line, _ := csvReader.FieldPos(0)
+		row := &DepositImportRowRequest{Line: line}
+		if len(record) != len(header) {
+			row.Error = fmt.Sprintf("expected %d fields, got %d", len(header), len(record))
+			req.Rows = append(req.Rows, row)
+			continue
+		}
+		row.WalletId = strings.TrimSpace(record[indexes["wallet_id"]])
+		row.Reference = strings.TrimSpace(record[indexes["reference"]])
+		amount := strings.TrimSpace(record[indexes["amount"]])
+		if row.Amount, err = strconv.ParseUint(amount, 10, 64); err != nil {
+			row.Error = "invalid amount"
+		}
+		req.Rows = append(req.Rows, row)
+	}
+	return nil
+}
+
+type DepositImportRowResponse struct {
+	Line          int    `json:"line"`
+	WalletId      string `json:"wallet_id"`
+	Amount        uint64 `json:"amount"`
+	Reference     string `json:"reference"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+	TransactionId string `json:"transaction_id,omitempty"`
+}
+
+// swagger:model
+type DepositImportResponse struct {
+	ID         string                      `json:"id"`
+	Status     string                      `json:"status"`
+	DryRun     bool                        `json:"dry_run"`
+	Mode       string                      `json:"mode"`
+	CreatedAt  time.Time                   `json:"created_at"`
+	FinishedAt *time.Time                  `json:"finished_at,omitempty"`
+	Summary    map[string]int              `json:"summary"`
+	Rows       []*DepositImportRowResponse `json:"rows"`
+}
+
+func (resp *DepositImportResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
@@ -0,0 +1,31 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	TransactionEvent = "transaction"
+	BalanceEvent     = "balance"
+)
+
+// swagger:model
+type BalanceEventResponse struct {
+	WalletId      string    `json:"wallet_id"`
+	Balance       uint64    `json:"balance"`
+	TransactionId string    `json:"transaction_id"`
+	ProcessedAt   time.Time `json:"processed_at"`
+}
+
+// WriteServerSentEvent writes data as a single line JSON server-sent event.
+func WriteServerSentEvent(writer io.Writer, id string, event string, data interface{}) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "id: %s\nevent: %s\ndata: %s\n\n", id, event, body)
+	return err
+}
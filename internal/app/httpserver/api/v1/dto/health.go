@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"encoding/json"
+	"io"
+)
+
+const (
+	StatusOk       = "ok"
+	StatusFailed   = "failed"
+	StatusNotReady = "not ready"
+	StatusDraining = "draining"
+)
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	// Checks maps the name of each readiness check to ok or failed.
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+func (resp *HealthResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+type VersionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+func (resp *VersionResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
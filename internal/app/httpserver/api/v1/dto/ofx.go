@@ -0,0 +1,184 @@
+package dto
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+const ofxHeader = `<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>` + "\n"
+
+// ofxBankId identifies the service as the bank of the accounts, BANKID is at
+// most 9 characters.
+const ofxBankId = "WALLETS"
+
+// The lengths of the OFX elements the statement fields are restricted to.
+const (
+	ofxAcctIdLength = 22
+	ofxNameLength   = 32
+	ofxRefNumLength = 32
+)
+
+type ofxDocument struct {
+	XMLName        xml.Name          `xml:"OFX"`
+	SignonMsgsRsV1 ofxSignonMsgsRsV1 `xml:"SIGNONMSGSRSV1"`
+	BankMsgsRsV1   ofxBankMsgsRsV1   `xml:"BANKMSGSRSV1"`
+}
+
+type ofxStatus struct {
+	Code     int    `xml:"CODE"`
+	Severity string `xml:"SEVERITY"`
+}
+
+type ofxSignonMsgsRsV1 struct {
+	SonRs ofxSonRs `xml:"SONRS"`
+}
+
+type ofxSonRs struct {
+	Status   ofxStatus `xml:"STATUS"`
+	DtServer string    `xml:"DTSERVER"`
+	Language string    `xml:"LANGUAGE"`
+}
+
+type ofxBankMsgsRsV1 struct {
+	StmtTrnRs ofxStmtTrnRs `xml:"STMTTRNRS"`
+}
+
+type ofxStmtTrnRs struct {
+	TrnUid string    `xml:"TRNUID"`
+	Status ofxStatus `xml:"STATUS"`
+	StmtRs ofxStmtRs `xml:"STMTRS"`
+}
+
+type ofxStmtRs struct {
+	CurDef       string          `xml:"CURDEF"`
+	BankAcctFrom ofxBankAcctFrom `xml:"BANKACCTFROM"`
+	BankTranList ofxBankTranList `xml:"BANKTRANLIST"`
+	LedgerBal    ofxBal          `xml:"LEDGERBAL"`
+	BalList      ofxBalList      `xml:"BALLIST"`
+}
+
+type ofxBankAcctFrom struct {
+	BankId   string `xml:"BANKID"`
+	AcctId   string `xml:"ACCTID"`
+	AcctType string `xml:"ACCTTYPE"`
+}
+
+type ofxBankTranList struct {
+	DtStart string       `xml:"DTSTART"`
+	DtEnd   string       `xml:"DTEND"`
+	StmtTrn []ofxStmtTrn `xml:"STMTTRN"`
+}
+
+type ofxStmtTrn struct {
+	TrnType  string `xml:"TRNTYPE"`
+	DtPosted string `xml:"DTPOSTED"`
+	TrnAmt   string `xml:"TRNAMT"`
+	FitId    string `xml:"FITID"`
+	RefNum   string `xml:"REFNUM"`
+	Name     string `xml:"NAME,omitempty"`
+	Memo     string `xml:"MEMO"`
+}
+
+type ofxBal struct {
+	BalAmt string `xml:"BALAMT"`
+	DtAsOf string `xml:"DTASOF"`
+}
+
+type ofxBalList struct {
+	Bal []ofxNamedBal `xml:"BAL"`
+}
+
+type ofxNamedBal struct {
+	Name    string `xml:"NAME"`
+	Desc    string `xml:"DESC"`
+	BalType string `xml:"BALTYPE"`
+	Value   string `xml:"VALUE"`
+	DtAsOf  string `xml:"DTASOF"`
+}
+
+func formatOfxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:UTC]"
+}
+
+// ToOfx writes the statement as an OFX 2.1.1 bank statement response.
+func (resp *StatementResponse) ToOfx(writer io.Writer) error {
+	if err := ValidateCurrency(resp.Currency); err != nil {
+		return err
+	}
+	tranList := ofxBankTranList{
+		DtStart: formatOfxTime(resp.From),
+		DtEnd:   formatOfxTime(resp.To),
+	}
+	for _, entry := range resp.Entries {
+		trn := ofxStmtTrn{
+			TrnType:  "CREDIT",
+			DtPosted: formatOfxTime(entry.ProcessedAt),
+			TrnAmt:   formatAmount(entry.Amount, resp.Currency, "."),
+			FitId:    entry.Reference,
+			RefNum:   shortenId(entry.Reference, ofxRefNumLength),
+			Name:     shortenId(entry.CounterpartyWalletId, ofxNameLength),
+			Memo:     entry.OperationType,
+		}
+		if !entry.Credit {
+			trn.TrnType = "DEBIT"
+			trn.TrnAmt = "-" + trn.TrnAmt
+		}
+		if entry.OperationType == "transfer" {
+			trn.TrnType = "XFER"
+		}
+		tranList.StmtTrn = append(tranList.StmtTrn, trn)
+	}
+	document := ofxDocument{
+		SignonMsgsRsV1: ofxSignonMsgsRsV1{
+			SonRs: ofxSonRs{
+				Status:   ofxStatus{Code: 0, Severity: "INFO"},
+				DtServer: formatOfxTime(resp.CreatedAt),
+				Language: "ENG",
+			},
+		},
+		BankMsgsRsV1: ofxBankMsgsRsV1{
+			StmtTrnRs: ofxStmtTrnRs{
+				TrnUid: resp.id(),
+				Status: ofxStatus{Code: 0, Severity: "INFO"},
+				StmtRs: ofxStmtRs{
+					CurDef: resp.Currency,
+					BankAcctFrom: ofxBankAcctFrom{
+						BankId:   ofxBankId,
+						AcctId:   shortenId(resp.WalletId, ofxAcctIdLength),
+						AcctType: "CHECKING",
+					},
+					BankTranList: tranList,
+					LedgerBal: ofxBal{
+						BalAmt: formatAmount(resp.ClosingBalance, resp.Currency, "."),
+						DtAsOf: formatOfxTime(resp.To),
+					},
+					BalList: ofxBalList{
+						Bal: []ofxNamedBal{
+							{
+								Name:    "OPENING",
+								Desc:    "Opening balance",
+								BalType: "DOLLAR",
+								Value:   formatAmount(resp.OpeningBalance, resp.Currency, "."),
+								DtAsOf:  formatOfxTime(resp.From),
+							},
+							{
+								Name:    "CLOSING",
+								Desc:    "Closing balance",
+								BalType: "DOLLAR",
+								Value:   formatAmount(resp.ClosingBalance, resp.Currency, "."),
+								DtAsOf:  formatOfxTime(resp.To),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if _, err := io.WriteString(writer, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>`+"\n"+ofxHeader); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+	return encoder.Encode(document)
+}
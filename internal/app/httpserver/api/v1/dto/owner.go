@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// swagger:model
+type OwnerRequest struct {
+	// ExternalID is the identifier of the customer in the system of the client.
+	ExternalID string `json:"external_id,omitempty" validate:"max=255"`
+	// Subject is the subject of the API key or token of the customer, it owns
+	// the wallets of the owner and cannot be changed once set.
+	Subject string `json:"subject,omitempty" validate:"max=255"`
+	Name    string `json:"name" validate:"required,max=255"`
+	Email   string `json:"email,omitempty" validate:"omitempty,email"`
+	Phone   string `json:"phone,omitempty" validate:"omitempty,e164"`
+	KycTier int    `json:"kyc_tier" validate:"min=0,max=3"`
+}
+
+func (req *OwnerRequest) FromJson(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	return decoder.Decode(req)
+}
+
+func (req *OwnerRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+// swagger:model
+type OwnerResponse struct {
+	ID         string    `json:"id"`
+	ExternalID string    `json:"external_id,omitempty"`
+	Subject    string    `json:"subject,omitempty"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email,omitempty"`
+	Phone      string    `json:"phone,omitempty"`
+	KycTier    int       `json:"kyc_tier"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (resp *OwnerResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+type OwnersResponse []*OwnerResponse
+
+func (resp *OwnersResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+// swagger:model
+type WalletResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	Balance  uint64 `json:"balance"`
+	Frozen   bool   `json:"frozen,omitempty"`
+}
+
+type WalletsResponse []*WalletResponse
+
+func (resp *WalletsResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
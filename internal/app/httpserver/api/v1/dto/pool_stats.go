@@ -0,0 +1,24 @@
+package dto
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// swagger:model
+type PoolStatsResponse struct {
+	Name           string `json:"name"`
+	MaxOpen        int    `json:"max_open"`
+	Open           int    `json:"open"`
+	InUse          int    `json:"in_use"`
+	Idle           int    `json:"idle"`
+	WaitCount      int64  `json:"wait_count"`
+	WaitDurationMs int64  `json:"wait_duration_ms"`
+}
+
+type PoolsStatsResponse []*PoolStatsResponse
+
+func (resp *PoolsStatsResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
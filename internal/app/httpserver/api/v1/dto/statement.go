@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// StatementEntry is a single booked entry of a wallet statement.
+type StatementEntry struct {
+	Reference            string
+	EndToEndId           string
+	OperationType        string
+	Amount               uint64
+	Credit               bool
+	CounterpartyWalletId string
+	Balance              uint64
+	ProcessedAt          time.Time
+}
+
+// StatementResponse is a bank statement of a single wallet for a period,
+// serialized to the bank statement formats used by accounting software.
+// Entries are kept in chronological order.
+type StatementResponse struct {
+	WalletId       string
+	WalletName     string
+	Currency       string
+	CreatedAt      time.Time
+	From           time.Time
+	To             time.Time
+	OpeningBalance uint64
+	ClosingBalance uint64
+	Entries        []*StatementEntry
+}
+
+// statementIdLength fits the identifiers of a statement into both camt.053
+// (Max35Text) and OFX (36 characters).
+const statementIdLength = 35
+
+// id identifies the statement of the wallet created at the time.
+func (resp *StatementResponse) id() string {
+	return shortenId(resp.WalletId+"-"+resp.CreatedAt.Format("20060102150405"), statementIdLength)
+}
+
+// shortenId fits the identifier into the length of a statement field: an
+// identifier too long is written without hyphens, a UUID becomes 32 characters,
+// and if that is still too long, as the prefix of its SHA-256 hash.
+func shortenId(id string, maxLength int) string {
+	if len(id) <= maxLength {
+		return id
+	}
+	if compact := strings.ReplaceAll(id, "-", ""); len(compact) <= maxLength {
+		return compact
+	}
+	hash := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(hash[:])[:maxLength]
+}
+
+// truncate cuts the text to at most maxLength characters.
+func truncate(text string, maxLength int) string {
+	runes := []rune(text)
+	if len(runes) <= maxLength {
+		return text
+	}
+	return string(runes[:maxLength])
+}
+
+func (resp *StatementResponse) totals() (credits uint64, debits uint64) {
+	for _, entry := range resp.Entries {
+		if entry.Credit {
+			credits += entry.Amount
+		} else {
+			debits += entry.Amount
+		}
+	}
+	return
+}
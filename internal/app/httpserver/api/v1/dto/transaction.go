@@ -4,12 +4,12 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"io"
-	"strconv"
 	"time"
 )
 
 // swagger:model
 type TransactionResponse struct {
+	ID                     string    `json:"id"`
 	OperationType          string    `json:"operation_type"`
 	Amount                 uint64    `json:"amount"`
 	SenderWalletId         *string   `json:"sender_wallet_id,omitempty"`
@@ -20,6 +20,8 @@ type TransactionResponse struct {
 	RecipientWalletMe      bool      `json:"recipient_wallet_me,omitempty"`
 	Balance                uint64    `json:"balance"`
 	ProcessedAt            time.Time `json:"processed_at"`
+	Reference              string    `json:"reference,omitempty"`
+	ApiKeyId               string    `json:"api_key_id,omitempty"`
 }
 
 type TransactionsResponse []*TransactionResponse
@@ -30,56 +32,32 @@ func (resp *TransactionsResponse) ToJson(writer io.Writer) error {
 }
 
 func (resp *TransactionsResponse) ToCsv(writer io.Writer) error {
+	return resp.ToCsvWithOptions(writer, DefaultCsvOptions())
+}
+
+func (resp *TransactionsResponse) ToCsvWithOptions(writer io.Writer, options CsvOptions) error {
 	csvWriter := csv.NewWriter(writer)
-	header := []string{
-		"OperationType", "Amount",
-		"SenderWalletId", "SenderWalletBalance", "SenderWalletMe",
-		"RecipientWalletId", "RecipientWalletBalance", "RecipientWalletMe",
-		"Balance", "ProcessedAt",
+	csvWriter.Comma = options.Delimiter
+	headers := options.Headers
+	if headers == nil {
+		headers = options.Columns
 	}
-	if err := csvWriter.Write(header); err != nil {
+	if err := csvWriter.Write(headers); err != nil {
 		return err
 	}
 	for _, transactionResponse := range *resp {
-		var record []string
-		record = append(record, transactionResponse.OperationType)
-		record = append(record, strconv.FormatUint(transactionResponse.Amount, 10))
-		if transactionResponse.SenderWalletId != nil {
-			record = append(record, *transactionResponse.SenderWalletId)
-		} else {
-			record = append(record, "NULL")
-		}
-		if transactionResponse.SenderWalletBalance != nil {
-			record = append(record, strconv.FormatUint(*transactionResponse.SenderWalletBalance, 10))
-		} else {
-			record = append(record, "NULL")
-		}
-		if transactionResponse.SenderWalletMe {
-			record = append(record, strconv.FormatBool(transactionResponse.SenderWalletMe))
-		} else {
-			record = append(record, "NULL")
-		}
-		if transactionResponse.RecipientWalletId != nil {
-			record = append(record, *transactionResponse.RecipientWalletId)
-		} else {
-			record = append(record, "NULL")
-		}
-		if transactionResponse.RecipientWalletBalance != nil {
-			record = append(record, strconv.FormatUint(*transactionResponse.RecipientWalletBalance, 10))
-		} else {
-			record = append(record, "NULL")
-		}
-		if transactionResponse.RecipientWalletMe {
-			record = append(record, strconv.FormatBool(transactionResponse.RecipientWalletMe))
-		} else {
-			record = append(record, "NULL")
+		record := make([]string, 0, len(options.Columns))
+		for _, column := range options.Columns {
+			value, ok := csvColumns[column](transactionResponse, &options)
+			if !ok {
+				value = options.Null
+			}
+			record = append(record, value)
 		}
-		record = append(record, strconv.FormatUint(transactionResponse.Balance, 10))
-		record = append(record, transactionResponse.ProcessedAt.Format(time.RFC3339Nano))
 		if err := csvWriter.Write(record); err != nil {
 			return err
 		}
 	}
 	csvWriter.Flush()
-	return nil
+	return csvWriter.Error()
 }
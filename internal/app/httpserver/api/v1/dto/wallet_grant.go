@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// swagger:model
+type WalletGrantRequest struct {
+	Role string `json:"role" validate:"required,oneof=viewer spender owner"`
+}
+
+func (req *WalletGrantRequest) FromJson(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	return decoder.Decode(req)
+}
+
+func (req *WalletGrantRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+// swagger:model
+type WalletGrantResponse struct {
+	Subject   string    `json:"subject"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type WalletGrantsResponse []*WalletGrantResponse
+
+func (resp *WalletGrantsResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
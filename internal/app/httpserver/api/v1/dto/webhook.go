@@ -0,0 +1,78 @@
+package dto
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// swagger:model
+type CreateWebhookRequest struct {
+	Url        string   `json:"url" validate:"required,url,startswith=http"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16"`
+	EventTypes []string `json:"event_types" validate:"required,min=1,dive,oneof=transaction.deposit transaction.transfer"`
+}
+
+func (req *CreateWebhookRequest) FromJson(reader io.Reader) error {
+	decoder := json.NewDecoder(reader)
+	return decoder.Decode(req)
+}
+
+func (req *CreateWebhookRequest) Validate() error {
+	validate := validator.New()
+	return validate.Struct(req)
+}
+
+// swagger:model
+type WebhookResponse struct {
+	ID         string    `json:"id"`
+	Url        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (resp *WebhookResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+type WebhooksResponse []*WebhookResponse
+
+func (resp *WebhooksResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+// swagger:model
+type WebhookDeliveryResponse struct {
+	ID            string          `json:"id"`
+	EventId       string          `json:"event_id"`
+	EventType     string          `json:"event_type"`
+	Event         json.RawMessage `json:"event"`
+	Status        string          `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt *time.Time      `json:"next_attempt_at,omitempty"`
+	LastError     string          `json:"last_error,omitempty"`
+	DeliveredAt   *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type WebhookDeliveriesResponse []*WebhookDeliveryResponse
+
+func (resp *WebhookDeliveriesResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
+
+// swagger:model
+type ReplayWebhookResponse struct {
+	Replayed int `json:"replayed"`
+}
+
+func (resp *ReplayWebhookResponse) ToJson(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	return encoder.Encode(resp)
+}
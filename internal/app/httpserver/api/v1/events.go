@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/SergeyChupin/wallets-api/internal/events"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+const contentTypeEventStream = "text/event-stream"
+
+type eventsApi struct {
+	logger        *logging.Logger
+	walletService service.WalletService
+	broker        events.Broker
+	config        events.Config
+}
+
+func NewEventsApi(logger *logging.Logger, router *mux.Router, walletService service.WalletService, broker events.Broker, config events.Config) {
+	eventsApi := &eventsApi{
+		logger:        logger,
+		walletService: walletService,
+		broker:        broker,
+		config:        config,
+	}
+	router.HandleFunc("/wallets/{id}/events", eventsApi.GetEvents).Methods(http.MethodGet)
+}
+
+// swagger:route GET /wallets/{id}/events WalletsAPI getEvents
+// Stream transaction and balance events of the wallet as they commit.
+// The stream resumes after the transaction given in the Last-Event-ID header
+//
+// produces:
+// 	- text/event-stream
+//
+// responses:
+//	200: eventsResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (eventsApi *eventsApi) GetEvents(rw http.ResponseWriter, req *http.Request) {
+	id := getWalletId(req)
+	// Replayed events must not lag behind the published ones.
+	ctx := database.WithPrimaryReads(req.Context())
+	if _, err := eventsApi.walletService.GetWallet(ctx, id); err != nil {
+		eventsApi.logger.Log(req.Context(), errorLevel(err), "eventsApi - GetEvents - eventsApi.walletService.GetWallet", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, model.ErrWalletNotFound) {
+			writeError(rw, "wallet not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to get events", http.StatusInternalServerError)
+		return
+	}
+	flusher, ok := rw.(http.Flusher)
+	if !ok {
+		eventsApi.logger.Error(req.Context(), "eventsApi - GetEvents - streaming is not supported")
+		writeError(rw, "unable to get events", http.StatusInternalServerError)
+		return
+	}
+
+	// Subscribe before reading the missed transactions, so nothing committed
+	// in between is lost. Transactions seen twice are skipped.
+	transactions, unsubscribe := eventsApi.broker.Subscribe(id)
+	defer unsubscribe()
+	var missed []*model.Transaction
+	if lastEventId := req.Header.Get("Last-Event-ID"); lastEventId != "" {
+		var err error
+		if missed, err = eventsApi.walletService.GetTransactionsAfter(ctx, id, lastEventId); err != nil {
+			eventsApi.logger.Error(req.Context(), "eventsApi - GetEvents - eventsApi.walletService.GetTransactionsAfter", logging.Err(err))
+			writeError(rw, "unable to get events", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	// The stream outlives the write timeout of the server.
+	if deadliner, ok := rw.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		_ = deadliner.SetWriteDeadline(time.Time{})
+	}
+	rw.Header().Set("Content-Type", contentTypeEventStream)
+	rw.Header().Set("Cache-Control", "no-cache")
+	rw.Header().Set("Connection", "keep-alive")
+	rw.Header().Set("X-Accel-Buffering", "no")
+	rw.WriteHeader(http.StatusOK)
+
+	sent := make(map[string]struct{}, len(missed))
+	for _, transaction := range missed {
+		if err := eventsApi.writeTransaction(rw, id, transaction); err != nil {
+			eventsApi.logger.Error(req.Context(), "eventsApi - GetEvents - eventsApi.writeTransaction", logging.Err(err))
+			return
+		}
+		sent[transaction.ID] = struct{}{}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsApi.config.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case transaction, ok := <-transactions:
+			if !ok {
+				return
+			}
+			if _, ok = sent[transaction.ID]; ok {
+				delete(sent, transaction.ID)
+				continue
+			}
+			if err := eventsApi.writeTransaction(rw, id, transaction); err != nil {
+				eventsApi.logger.Error(req.Context(), "eventsApi - GetEvents - eventsApi.writeTransaction", logging.Err(err))
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(rw, ": heartbeat\n\n"); err != nil {
+				eventsApi.logger.Error(req.Context(), "eventsApi - GetEvents - fmt.Fprint", logging.Err(err))
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeTransaction writes the transaction event followed by the balance event
+// of the wallet, both identified by the transaction for resuming.
+func (eventsApi *eventsApi) writeTransaction(rw http.ResponseWriter, walletId string, transaction *model.Transaction) error {
+	respTransaction := toTransactionResponse(transaction, walletId)
+	if err := dto.WriteServerSentEvent(rw, transaction.ID, dto.TransactionEvent, respTransaction); err != nil {
+		return fmt.Errorf("dto.WriteServerSentEvent: %w", err)
+	}
+	respBalance := dto.BalanceEventResponse{
+		WalletId:      walletId,
+		Balance:       respTransaction.Balance,
+		TransactionId: transaction.ID,
+		ProcessedAt:   transaction.ProcessedAt,
+	}
+	if err := dto.WriteServerSentEvent(rw, transaction.ID, dto.BalanceEvent, respBalance); err != nil {
+		return fmt.Errorf("dto.WriteServerSentEvent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/events"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+type brokerStub struct {
+	transactions chan *model.Transaction
+}
+
+func (broker *brokerStub) Subscribe(walletId string) (<-chan *model.Transaction, func()) {
+	return broker.transactions, func() {}
+}
+
+func TestGetEventsResumesFromLastEventId(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	broker := &brokerStub{transactions: make(chan *model.Transaction, 2)}
+
+	router := mux.NewRouter()
+	NewEventsApi(logger, router, walletService, broker, events.NewConfig())
+	req, err := http.NewRequest("GET", "/wallets/2001/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Last-Event-ID", "1000")
+	recorder := httptest.NewRecorder()
+
+	processedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	missed := &model.Transaction{
+		ID:              "1001",
+		Amount:          10000,
+		ProcessedAt:     processedAt,
+		RecipientWallet: model.Wallet{ID: "2001", Balance: 10000},
+		OperationType:   model.Deposit,
+	}
+	live := &model.Transaction{
+		ID:              "1002",
+		Amount:          4000,
+		ProcessedAt:     processedAt.Add(time.Second),
+		SenderWallet:    &model.Wallet{ID: "2001", Balance: 6000},
+		RecipientWallet: model.Wallet{ID: "2002", Balance: 4000},
+		OperationType:   model.Transfer,
+	}
+	broker.transactions <- missed
+	broker.transactions <- live
+	close(broker.transactions)
+	walletService.On("GetWallet", "2001").Return(&model.Wallet{ID: "2001"}, nil)
+	walletService.On("GetTransactionsAfter", "2001", "1000").Return([]*model.Transaction{missed}, nil)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		"id: 1001",
+		"event: transaction",
+		`data: {"id":"1001","operation_type":"deposit","amount":10000,"balance":10000,"processed_at":"2022-01-01T00:00:00Z"}`,
+		"",
+		"id: 1001",
+		"event: balance",
+		`data: {"wallet_id":"2001","balance":10000,"transaction_id":"1001","processed_at":"2022-01-01T00:00:00Z"}`,
+		"",
+		"id: 1002",
+		"event: transaction",
+		`data: {"id":"1002","operation_type":"transfer","amount":4000,"sender_wallet_me":true,"recipient_wallet_id":"2002","recipient_wallet_balance":4000,"balance":6000,"processed_at":"2022-01-01T00:00:01Z"}`,
+		"",
+		"id: 1002",
+		"event: balance",
+		`data: {"wallet_id":"2001","balance":6000,"transaction_id":"1002","processed_at":"2022-01-01T00:00:01Z"}`,
+		"",
+		"",
+	}, "\n"), recorder.Body.String())
+
+	walletService.AssertExpectations(t)
+}
+
+func TestGetEventsWalletNotFound(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	broker := &brokerStub{}
+
+	router := mux.NewRouter()
+	NewEventsApi(logger, router, walletService, broker, events.NewConfig())
+	req, err := http.NewRequest("GET", "/wallets/2001/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	walletService.On("GetWallet", "2001").Return((*model.Wallet)(nil), model.ErrWalletNotFound)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	walletService.AssertExpectations(t)
+}
@@ -0,0 +1,89 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/buildinfo"
+	"github.com/SergeyChupin/wallets-api/internal/health"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/gorilla/mux"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+	VersionPath   = "/version"
+)
+
+type healthApi struct {
+	logger  *logging.Logger
+	checker health.Checker
+}
+
+// NewHealthApi registers the probes of the orchestrator and the build info,
+// they are served without authentication.
+func NewHealthApi(logger *logging.Logger, router *mux.Router, checker health.Checker) {
+	healthApi := &healthApi{
+		logger:  logger,
+		checker: checker,
+	}
+	router.HandleFunc(LivenessPath, healthApi.GetLiveness).Methods(http.MethodGet)
+	router.HandleFunc(ReadinessPath, healthApi.GetReadiness).Methods(http.MethodGet)
+	router.HandleFunc(VersionPath, healthApi.GetVersion).Methods(http.MethodGet)
+}
+
+// GetLiveness reports the process alive as long as it serves requests, it does
+// not check the dependencies, so that their outage does not restart it.
+func (healthApi *healthApi) GetLiveness(rw http.ResponseWriter, req *http.Request) {
+	healthApi.writeHealth(rw, req, http.StatusOK, &dto.HealthResponse{Status: dto.StatusOk})
+}
+
+// GetReadiness reports the server ready when every check passes, and not ready
+// once it drains on shutdown. The errors of the checks are logged rather than
+// returned, as the probes are not authenticated.
+func (healthApi *healthApi) GetReadiness(rw http.ResponseWriter, req *http.Request) {
+	results, err := healthApi.checker.Ready(req.Context())
+	if errors.Is(err, health.ErrDraining) {
+		healthApi.writeHealth(rw, req, http.StatusServiceUnavailable, &dto.HealthResponse{Status: dto.StatusDraining})
+		return
+	}
+	respData := &dto.HealthResponse{Status: dto.StatusOk, Checks: make(map[string]string, len(results))}
+	statusCode := http.StatusOK
+	for _, result := range results {
+		if result.Err != nil {
+			healthApi.logger.Warn(req.Context(), "healthApi - GetReadiness - check failed", logging.String("check", result.Name), logging.Err(result.Err))
+			respData.Checks[result.Name] = dto.StatusFailed
+			respData.Status = dto.StatusNotReady
+			statusCode = http.StatusServiceUnavailable
+			continue
+		}
+		respData.Checks[result.Name] = dto.StatusOk
+	}
+	healthApi.writeHealth(rw, req, statusCode, respData)
+}
+
+func (healthApi *healthApi) GetVersion(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	respData := &dto.VersionResponse{
+		Version:   buildinfo.Version,
+		Commit:    buildinfo.Commit,
+		BuildTime: buildinfo.BuildTime,
+		GoVersion: buildinfo.GoVersion(),
+	}
+	if err := respData.ToJson(rw); err != nil {
+		healthApi.logger.Error(req.Context(), "healthApi - GetVersion - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+func (healthApi *healthApi) writeHealth(rw http.ResponseWriter, req *http.Request, statusCode int, respData *dto.HealthResponse) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(statusCode)
+	if err := respData.ToJson(rw); err != nil {
+		healthApi.logger.Error(req.Context(), "healthApi - writeHealth - respData.ToJson", logging.Err(err))
+	}
+}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/health"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLiveness(t *testing.T) {
+	// given
+	router := mux.NewRouter()
+	NewHealthApi(logger, router, health.NewChecker(health.NewConfig()))
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
+
+func TestGetReadiness(t *testing.T) {
+	tests := []struct {
+		name               string
+		schemaErr          error
+		drain              bool
+		expectedStatusCode int
+		expectedBody       string
+	}{
+		{
+			name:               "ready",
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"status":"ok","checks":{"postgres":"ok","schema":"ok"}}`,
+		},
+		{
+			name:               "check failed",
+			schemaErr:          errors.New("database schema is outdated"),
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"status":"not ready","checks":{"postgres":"ok","schema":"failed"}}`,
+		},
+		{
+			name:               "draining",
+			drain:              true,
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedBody:       `{"status":"draining"}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			checker := health.NewChecker(health.NewConfig()).
+				AddCheck("postgres", func(ctx context.Context) error {
+					return nil
+				}).
+				AddCheck("schema", func(ctx context.Context) error {
+					return test.schemaErr
+				})
+			if test.drain {
+				checker.Drain()
+			}
+			router := mux.NewRouter()
+			NewHealthApi(logger, router, checker)
+			recorder := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			// then
+			assert.Equal(t, test.expectedStatusCode, recorder.Code)
+			assert.JSONEq(t, test.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestGetVersion(t *testing.T) {
+	// given
+	router := mux.NewRouter()
+	NewHealthApi(logger, router, health.NewChecker(health.NewConfig()))
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t,
+		`{"version":"dev","commit":"unknown","build_time":"unknown","go_version":"`+runtime.Version()+`"}`,
+		recorder.Body.String(),
+	)
+}
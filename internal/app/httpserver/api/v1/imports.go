@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxDepositImportSize = 32 << 20
+
+	depositImportModeAtomic  = "atomic"
+	depositImportModeChunked = "chunked"
+)
+
+type importsApi struct {
+	logger               *logging.Logger
+	depositImportService service.DepositImportService
+}
+
+func NewImportsApi(logger *logging.Logger, router *mux.Router, depositImportService service.DepositImportService) {
+	importsApi := &importsApi{
+		logger:               logger,
+		depositImportService: depositImportService,
+	}
+	router.HandleFunc("/imports/deposits", importsApi.ImportDeposits).Methods(http.MethodPost)
+	router.HandleFunc("/imports/deposits/{id}", importsApi.GetDepositImport).Methods(http.MethodGet)
+}
+
+// swagger:route POST /imports/deposits ImportsAPI importDeposits
+// Import deposits from a CSV file with wallet_id, amount and reference columns
+//
+// consumes:
+//	- text/csv
+//	- multipart/form-data
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: depositImportResponse
+//	202: depositImportResponse
+//  400: errorResponse
+//  500: errorResponse
+func (importsApi *importsApi) ImportDeposits(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	dryRun := false
+	if value := req.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			importsApi.logger.Warn(req.Context(), "importsApi - ImportDeposits - strconv.ParseBool", logging.Err(err))
+			writeError(rw, "invalid query parameter dry_run", http.StatusBadRequest)
+			return
+		}
+	}
+	mode := req.URL.Query().Get("mode")
+	if mode == "" {
+		mode = depositImportModeChunked
+	}
+	if mode != depositImportModeAtomic && mode != depositImportModeChunked {
+		importsApi.logger.Warn(req.Context(), "importsApi - ImportDeposits - invalid query parameter mode")
+		writeError(rw, "invalid query parameter mode", http.StatusBadRequest)
+		return
+	}
+	req.Body = http.MaxBytesReader(rw, req.Body, maxDepositImportSize)
+	file, err := getImportFile(req)
+	if err != nil {
+		importsApi.logger.Warn(req.Context(), "importsApi - ImportDeposits - getImportFile", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	var reqData dto.DepositImportRequest
+	if err = reqData.FromCsv(file); err != nil {
+		importsApi.logger.Warn(req.Context(), "importsApi - ImportDeposits - reqData.FromCsv", logging.Err(err))
+		writeError(rw, "invalid csv file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	rows := make([]*model.DepositImportRow, 0, len(reqData.Rows))
+	for _, reqRow := range reqData.Rows {
+		row := &model.DepositImportRow{
+			Line:      reqRow.Line,
+			WalletId:  reqRow.WalletId,
+			Amount:    reqRow.Amount,
+			Reference: reqRow.Reference,
+		}
+		if reqRow.Error != "" {
+			row.Status = model.DepositImportRowInvalid
+			row.Error = reqRow.Error
+		}
+		rows = append(rows, row)
+	}
+	depositImport, err := importsApi.depositImportService.ImportDeposits(
+		req.Context(), rows, dryRun, mode == depositImportModeAtomic,
+	)
+	if err != nil {
+		importsApi.logger.Error(req.Context(), "importsApi - ImportDeposits - importsApi.depositImportService.ImportDeposits", logging.Err(err))
+		writeError(rw, "unable to import deposits", http.StatusInternalServerError)
+		return
+	}
+	respData := toDepositImportResponse(depositImport)
+	if !dryRun {
+		rw.Header().Set("Location", req.URL.Path+"/"+depositImport.ID)
+		rw.WriteHeader(http.StatusAccepted)
+	}
+	if err = respData.ToJson(rw); err != nil {
+		importsApi.logger.Error(req.Context(), "importsApi - ImportDeposits - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route GET /imports/deposits/{id} ImportsAPI getDepositImport
+// Return a deposit import with the outcome of every row
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: depositImportResponse
+//  404: errorResponse
+//  500: errorResponse
+func (importsApi *importsApi) GetDepositImport(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(req)["id"]
+	depositImport, err := importsApi.depositImportService.GetDepositImport(req.Context(), id)
+	if err != nil {
+		importsApi.logger.Log(req.Context(), errorLevel(err), "importsApi - GetDepositImport - importsApi.depositImportService.GetDepositImport", logging.Err(err))
+		if errors.Is(err, model.ErrDepositImportNotFound) {
+			writeError(rw, "deposit import not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to get deposit import", http.StatusInternalServerError)
+		return
+	}
+	respData := toDepositImportResponse(depositImport)
+	if err = respData.ToJson(rw); err != nil {
+		importsApi.logger.Error(req.Context(), "importsApi - GetDepositImport - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getImportFile returns the uploaded file of a multipart form, or the request
+// body itself for any other content type.
+func getImportFile(req *http.Request) (io.Reader, error) {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" {
+		return req.Body, nil
+	}
+	file, _, err := req.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func toDepositImportResponse(depositImport *model.DepositImport) *dto.DepositImportResponse {
+	respData := &dto.DepositImportResponse{
+		ID:         depositImport.ID,
+		Status:     depositImport.Status.String(),
+		DryRun:     depositImport.DryRun,
+		Mode:       depositImportModeChunked,
+		CreatedAt:  depositImport.CreatedAt,
+		FinishedAt: depositImport.FinishedAt,
+		Summary:    map[string]int{"total": len(depositImport.Rows)},
+		Rows:       make([]*dto.DepositImportRowResponse, 0, len(depositImport.Rows)),
+	}
+	if depositImport.Atomic {
+		respData.Mode = depositImportModeAtomic
+	}
+	for _, row := range depositImport.Rows {
+		respData.Summary[row.Status.String()]++
+		respData.Rows = append(respData.Rows, &dto.DepositImportRowResponse{
+			Line:          row.Line,
+			WalletId:      row.WalletId,
+			Amount:        row.Amount,
+			Reference:     row.Reference,
+			Status:        row.Status.String(),
+			Error:         row.Error,
+			TransactionId: row.TransactionId,
+		})
+	}
+	return respData
+}
@@ -0,0 +1,182 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type depositImportServiceMock struct {
+	mock.Mock
+}
+
+func (depositImportService *depositImportServiceMock) ImportDeposits(ctx context.Context, rows []*model.DepositImportRow, dryRun bool, atomic bool) (*model.DepositImport, error) {
+	args := depositImportService.Called(rows, dryRun, atomic)
+	return args.Get(0).(*model.DepositImport), args.Error(1)
+}
+
+func (depositImportService *depositImportServiceMock) GetDepositImport(ctx context.Context, id string) (*model.DepositImport, error) {
+	args := depositImportService.Called(id)
+	return args.Get(0).(*model.DepositImport), args.Error(1)
+}
+
+func (depositImportService *depositImportServiceMock) Start() {
+	depositImportService.Called()
+}
+
+func (depositImportService *depositImportServiceMock) Shutdown() {
+	depositImportService.Called()
+}
+
+func TestImportDepositsDryRun(t *testing.T) {
+	// given
+	depositImportService := new(depositImportServiceMock)
+
+	reqBody := strings.Join([]string{
+		"reference,wallet_id,amount",
+		"ref-1,6c1f7a3e-0f4e-4a53-9a3f-2f1d5f0a3b11,10000",
+		"ref-2,6c1f7a3e-0f4e-4a53-9a3f-2f1d5f0a3b11,ten",
+	}, "\n")
+	router := mux.NewRouter()
+	NewImportsApi(logger, router, depositImportService)
+	req, err := http.NewRequest("POST", "/imports/deposits?dry_run=true&mode=atomic", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "text/csv")
+	recorder := httptest.NewRecorder()
+
+	depositImportService.On(
+		"ImportDeposits",
+		[]*model.DepositImportRow{
+			{
+				Line:      2,
+				WalletId:  "6c1f7a3e-0f4e-4a53-9a3f-2f1d5f0a3b11",
+				Amount:    10000,
+				Reference: "ref-1",
+			},
+			{
+				Line:      3,
+				WalletId:  "6c1f7a3e-0f4e-4a53-9a3f-2f1d5f0a3b11",
+				Reference: "ref-2",
+				Status:    model.DepositImportRowInvalid,
+				Error:     "invalid amount",
+			},
+		},
+		true,
+		true,
+	).Return(
+		&model.DepositImport{
+			ID:        "2001",
+			Status:    model.DepositImportCompleted,
+			DryRun:    true,
+			Atomic:    true,
+			CreatedAt: time.Now().UTC(),
+			Rows: []*model.DepositImportRow{
+				{
+					Line:      2,
+					WalletId:  "6c1f7a3e-0f4e-4a53-9a3f-2f1d5f0a3b11",
+					Amount:    10000,
+					Reference: "ref-1",
+					Status:    model.DepositImportRowValid,
+				},
+				{
+					Line:      3,
+					WalletId:  "6c1f7a3e-0f4e-4a53-9a3f-2f1d5f0a3b11",
+					Reference: "ref-2",
+					Status:    model.DepositImportRowInvalid,
+					Error:     "invalid amount",
+				},
+			},
+		}, nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	respBody := new(dto.DepositImportResponse)
+	if err := json.Unmarshal(recorder.Body.Bytes(), respBody); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "2001", respBody.ID)
+	assert.Equal(t, "completed", respBody.Status)
+	assert.Equal(t, "atomic", respBody.Mode)
+	assert.Equal(t, map[string]int{"total": 2, "valid": 1, "invalid": 1}, respBody.Summary)
+	assert.Equal(t, 3, respBody.Rows[1].Line)
+	assert.Equal(t, "invalid amount", respBody.Rows[1].Error)
+
+	depositImportService.AssertNumberOfCalls(t, "ImportDeposits", 1)
+	depositImportService.AssertExpectations(t)
+}
+
+func TestImportDepositsMissingColumn(t *testing.T) {
+	// given
+	depositImportService := new(depositImportServiceMock)
+
+	router := mux.NewRouter()
+	NewImportsApi(logger, router, depositImportService)
+	req, err := http.NewRequest("POST", "/imports/deposits", bytes.NewBufferString("wallet_id,amount\n1001,100\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+	respBody := new(dto.ErrorResponse)
+	if err := json.Unmarshal(recorder.Body.Bytes(), respBody); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "invalid csv file: missing csv column: reference", respBody.Message)
+
+	depositImportService.AssertNumberOfCalls(t, "ImportDeposits", 0)
+	depositImportService.AssertExpectations(t)
+}
+
+func TestGetDepositImportNotFound(t *testing.T) {
+	// given
+	depositImportService := new(depositImportServiceMock)
+
+	router := mux.NewRouter()
+	NewImportsApi(logger, router, depositImportService)
+	req, err := http.NewRequest("GET", "/imports/deposits/2001", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	depositImportService.On("GetDepositImport", "2001").Return(
+		(*model.DepositImport)(nil), model.ErrDepositImportNotFound,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+
+	depositImportService.AssertNumberOfCalls(t, "GetDepositImport", 1)
+	depositImportService.AssertExpectations(t)
+}
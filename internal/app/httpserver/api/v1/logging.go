@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+)
+
+const requestIdHeader = "X-Request-ID"
+
+// RequestId keeps the X-Request-ID header of the request, or generates one if
+// it is missing or invalid, and returns it in the response. The id is passed
+// down through the context, every line logged while serving the request has it.
+func RequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requestId := req.Header.Get(requestIdHeader)
+		if !logging.ValidRequestId(requestId) {
+			requestId = logging.NewRequestId()
+		}
+		rw.Header().Set(requestIdHeader, requestId)
+		next.ServeHTTP(rw, req.WithContext(logging.WithRequestId(req.Context(), requestId)))
+	})
+}
+
+// AccessLog logs a line for each request once it is served: the method, the
+// route template rather than the path, so that lines of a route are grouped,
+// the status, the latency and the size of the response body.
+func AccessLog(logger *logging.Logger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: rw, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, req)
+			logger.Info(
+				req.Context(), "access",
+				logging.String("method", req.Method),
+				logging.String("route", routeTemplate(req)),
+				logging.Int("status", recorder.statusCode),
+				logging.Duration("latency", time.Since(start)),
+				logging.Int64("bytes", recorder.size),
+			)
+		})
+	}
+}
+
+// routeTemplate returns the path template of the route matched by the request,
+// or an empty string if no route matched.
+func routeTemplate(req *http.Request) string {
+	route := ""
+	if currentRoute := mux.CurrentRoute(req); currentRoute != nil {
+		route, _ = currentRoute.GetPathTemplate()
+	}
+	return route
+}
+
+// requestErrors are the model errors caused by the request rather than by the
+// server, they are logged as warnings.
+var requestErrors = []error{
+	model.ErrWalletNotFound,
+	model.ErrWalletAlreadyExists,
+	model.ErrInsufficientFunds,
+	model.ErrWalletFrozen,
+	model.ErrSameWallet,
+	model.ErrInvalidWalletShards,
+	model.ErrShardsNotSupported,
+	model.ErrDepositImportNotFound,
+	model.ErrWebhookNotFound,
+	model.ErrDeliveryNotFound,
+	model.ErrApiKeyNotFound,
+	model.ErrInvalidScope,
+	model.ErrInvalidToken,
+	model.ErrWalletAccessDenied,
+	model.ErrWalletGrantNotFound,
+	model.ErrInvalidWalletRole,
+	model.ErrOwnerNotFound,
+	model.ErrOwnerAlreadyExists,
+	model.ErrOwnerHasWallets,
+}
+
+// errorLevel returns the level to log the error of a service call at.
+func errorLevel(err error) logging.Level {
+	for _, requestErr := range requestErrors {
+		if errors.Is(err, requestErr) {
+			return logging.LevelWarn
+		}
+	}
+	return logging.LevelError
+}
+
+// statusRecorder keeps the status and the size of the response.
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	size       int64
+}
+
+func (recorder *statusRecorder) WriteHeader(statusCode int) {
+	recorder.statusCode = statusCode
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (recorder *statusRecorder) Write(p []byte) (int, error) {
+	n, err := recorder.ResponseWriter.Write(p)
+	recorder.size += int64(n)
+	return n, err
+}
+
+// SetWriteDeadline lets streaming handlers extend the write deadline of the
+// underlying writer.
+func (recorder *statusRecorder) SetWriteDeadline(deadline time.Time) error {
+	if deadliner, ok := recorder.ResponseWriter.(interface{ SetWriteDeadline(time.Time) error }); ok {
+		return deadliner.SetWriteDeadline(deadline)
+	}
+	return http.ErrNotSupported
+}
+
+// Flush lets streaming handlers flush through the recorder.
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
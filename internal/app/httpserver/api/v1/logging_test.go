@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIdPropagated(t *testing.T) {
+	// given
+	var requestId string
+	router := mux.NewRouter()
+	router.Use(RequestId)
+	router.HandleFunc("/wallets", func(rw http.ResponseWriter, req *http.Request) {
+		requestId = logging.RequestIdFromContext(req.Context())
+	}).Methods(http.MethodPost)
+	req := httptest.NewRequest(http.MethodPost, "/wallets", nil)
+	req.Header.Set(requestIdHeader, "request-1")
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, "request-1", requestId)
+	assert.Equal(t, "request-1", recorder.Header().Get(requestIdHeader))
+}
+
+func TestRequestIdGenerated(t *testing.T) {
+	// given
+	var requestId string
+	router := mux.NewRouter()
+	router.Use(RequestId)
+	router.HandleFunc("/wallets", func(rw http.ResponseWriter, req *http.Request) {
+		requestId = logging.RequestIdFromContext(req.Context())
+	}).Methods(http.MethodPost)
+	req := httptest.NewRequest(http.MethodPost, "/wallets", nil)
+	req.Header.Set(requestIdHeader, "not a request id")
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Len(t, requestId, 32)
+	assert.Equal(t, requestId, recorder.Header().Get(requestIdHeader))
+}
+
+func TestAccessLog(t *testing.T) {
+	// given
+	var buf bytes.Buffer
+	accessLogger, err := logging.New(&buf, logging.Config{Level: "info", Format: logging.JsonFormat})
+	assert.NoError(t, err)
+	router := mux.NewRouter()
+	router.Use(RequestId, AccessLog(accessLogger))
+	router.HandleFunc("/api/v1/wallets/{id}/transactions", func(rw http.ResponseWriter, req *http.Request) {
+		writeError(rw, "wallet not found", http.StatusNotFound)
+	}).Methods(http.MethodGet)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/wallets/1001/transactions", nil)
+	req.Header.Set(requestIdHeader, "request-1")
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	var line map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "access", line["msg"])
+	assert.Equal(t, "request-1", line["request_id"])
+	assert.Equal(t, http.MethodGet, line["method"])
+	assert.Equal(t, "/api/v1/wallets/{id}/transactions", line["route"])
+	assert.Equal(t, float64(http.StatusNotFound), line["status"])
+	assert.Equal(t, float64(recorder.Body.Len()), line["bytes"])
+	assert.Contains(t, line, "latency")
+}
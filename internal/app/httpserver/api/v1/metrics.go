@@ -0,0 +1,40 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/metrics"
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute labels the requests that matched no route or no method of
+// the route, their paths are not used as labels so that scanners cannot add
+// series.
+const unmatchedRoute = "unmatched"
+
+// Metrics counts the requests served and their latency by method, route
+// template and status.
+func Metrics(httpMetrics *metrics.HttpMetrics) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: rw, statusCode: http.StatusOK}
+			next.ServeHTTP(recorder, req)
+			route := routeTemplate(req)
+			if route == "" {
+				route = unmatchedRoute
+			}
+			httpMetrics.ObserveRequest(req.Method, route, recorder.statusCode, time.Since(start))
+		})
+	}
+}
+
+// CountUnmatched counts the requests the router answers with 404 or 405 too,
+// its middlewares only run for the requests that matched a route.
+func CountUnmatched(router *mux.Router, httpMetrics *metrics.HttpMetrics) {
+	router.NotFoundHandler = Metrics(httpMetrics)(http.NotFoundHandler())
+	router.MethodNotAllowedHandler = Metrics(httpMetrics)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+}
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/metrics"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	// given
+	registry := prometheus.NewRegistry()
+	router := mux.NewRouter()
+	router.Use(Metrics(metrics.NewHttpMetrics(registry)))
+	router.HandleFunc("/api/v1/wallets/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		writeError(rw, "wallet not found", http.StatusNotFound)
+	}).Methods(http.MethodGet)
+
+	// when
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/wallets/1001", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/wallets/1002", nil))
+
+	// then
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP http_requests_total HTTP requests served.
+# TYPE http_requests_total counter
+http_requests_total{method="GET",route="/api/v1/wallets/{id}",status="404"} 2
+`), "http_requests_total"))
+}
+
+func TestMetricsUnmatched(t *testing.T) {
+	// given
+	registry := prometheus.NewRegistry()
+	httpMetrics := metrics.NewHttpMetrics(registry)
+	router := mux.NewRouter()
+	router.Use(Metrics(httpMetrics))
+	CountUnmatched(router, httpMetrics)
+	router.HandleFunc("/api/v1/wallets/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodGet)
+	notFound, methodNotAllowed := httptest.NewRecorder(), httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(notFound, httptest.NewRequest(http.MethodGet, "/wp-login.php", nil))
+	router.ServeHTTP(methodNotAllowed, httptest.NewRequest(http.MethodDelete, "/api/v1/wallets/1001", nil))
+
+	// then
+	assert.Equal(t, http.StatusNotFound, notFound.Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, methodNotAllowed.Code)
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP http_requests_total HTTP requests served.
+# TYPE http_requests_total counter
+http_requests_total{method="DELETE",route="unmatched",status="405"} 1
+http_requests_total{method="GET",route="unmatched",status="404"} 1
+`), "http_requests_total"))
+}
@@ -0,0 +1,406 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type ownersApi struct {
+	logger       *logging.Logger
+	ownerService service.OwnerService
+}
+
+func NewOwnersApi(logger *logging.Logger, router *mux.Router, ownerService service.OwnerService) {
+	ownersApi := &ownersApi{
+		logger:       logger,
+		ownerService: ownerService,
+	}
+	router.HandleFunc("/owners", ownersApi.CreateOwner).Methods(http.MethodPost)
+	router.HandleFunc("/owners", ownersApi.GetOwners).Methods(http.MethodGet)
+	router.HandleFunc("/owners/{id}", ownersApi.GetOwner).Methods(http.MethodGet)
+	router.HandleFunc("/owners/{id}", ownersApi.UpdateOwner).Methods(http.MethodPut)
+	router.HandleFunc("/owners/{id}", ownersApi.DeleteOwner).Methods(http.MethodDelete)
+	router.HandleFunc("/owners/{id}/wallets", ownersApi.CreateOwnerWallet).Methods(http.MethodPost)
+	router.HandleFunc("/owners/{id}/wallets", ownersApi.GetOwnerWallets).Methods(http.MethodGet)
+	router.HandleFunc("/owners/{id}/transactions", ownersApi.GetOwnerTransactions).Methods(http.MethodGet)
+}
+
+// swagger:route POST /owners OwnersAPI createOwner
+// Create an owner, the customer holding wallets
+//
+// consumes:
+//	- application/json
+// produces:
+// 	- application/json
+//
+// responses:
+//	201: ownerResponse
+//  400: errorResponse
+//  409: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) CreateOwner(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	var reqData dto.OwnerRequest
+	if err := reqData.FromJson(req.Body); err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - CreateOwner - reqData.FromJson", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := reqData.Validate(); err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - CreateOwner - reqData.Validate", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	owner, err := ownersApi.ownerService.CreateOwner(req.Context(), toOwner(reqData))
+	if err != nil {
+		ownersApi.logger.Log(req.Context(), errorLevel(err), "ownersApi - CreateOwner - ownersApi.ownerService.CreateOwner", logging.Err(err))
+		if errors.Is(err, model.ErrOwnerAlreadyExists) {
+			writeError(rw, "owner already exists", http.StatusConflict)
+			return
+		}
+		writeError(rw, "unable to create owner", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusCreated)
+	respData := toOwnerResponse(owner)
+	if err = respData.ToJson(rw); err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - CreateOwner - respData.ToJson", logging.Err(err))
+		return
+	}
+}
+
+// swagger:route GET /owners OwnersAPI getOwners
+// Return a list of owners, the oldest first
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: ownersResponse
+//  400: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) GetOwners(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	limit, offset, err := getPagination(req)
+	if err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - GetOwners - getPagination", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	owners, err := ownersApi.ownerService.GetOwners(req.Context(), limit, offset)
+	if err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwners - ownersApi.ownerService.GetOwners", logging.Err(err))
+		writeError(rw, "unable to get owners", http.StatusInternalServerError)
+		return
+	}
+	var respData dto.OwnersResponse = make([]*dto.OwnerResponse, 0, len(owners))
+	for _, owner := range owners {
+		respData = append(respData, toOwnerResponse(owner))
+	}
+	if err = respData.ToJson(rw); err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwners - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route GET /owners/{id} OwnersAPI getOwner
+// Return an owner
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: ownerResponse
+//  404: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) GetOwner(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	owner, err := ownersApi.ownerService.GetOwner(req.Context(), getOwnerId(req))
+	if err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwner - ownersApi.ownerService.GetOwner", logging.Err(err))
+		ownersApi.writeOwnerError(rw, err, "unable to get owner")
+		return
+	}
+	respData := toOwnerResponse(owner)
+	if err = respData.ToJson(rw); err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwner - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route PUT /owners/{id} OwnersAPI updateOwner
+// Replace the details of an owner
+//
+// consumes:
+//	- application/json
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: ownerResponse
+//  400: errorResponse
+//  404: errorResponse
+//  409: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) UpdateOwner(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	var reqData dto.OwnerRequest
+	if err := reqData.FromJson(req.Body); err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - UpdateOwner - reqData.FromJson", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := reqData.Validate(); err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - UpdateOwner - reqData.Validate", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	owner := toOwner(reqData)
+	owner.ID = getOwnerId(req)
+	updated, err := ownersApi.ownerService.UpdateOwner(req.Context(), owner)
+	if err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - UpdateOwner - ownersApi.ownerService.UpdateOwner", logging.Err(err))
+		ownersApi.writeOwnerError(rw, err, "unable to update owner")
+		return
+	}
+	respData := toOwnerResponse(updated)
+	if err = respData.ToJson(rw); err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - UpdateOwner - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route DELETE /owners/{id} OwnersAPI deleteOwner
+// Delete an owner, an owner holding wallets cannot be deleted
+//
+// responses:
+//	204:
+//  404: errorResponse
+//  409: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) DeleteOwner(rw http.ResponseWriter, req *http.Request) {
+	if err := ownersApi.ownerService.DeleteOwner(req.Context(), getOwnerId(req)); err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - DeleteOwner - ownersApi.ownerService.DeleteOwner", logging.Err(err))
+		ownersApi.writeOwnerError(rw, err, "unable to delete owner")
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// swagger:route POST /owners/{id}/wallets OwnersAPI createOwnerWallet
+// Create a wallet held by the owner, an owner holds one wallet per currency
+//
+// consumes:
+//	- application/json
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: createWalletResponse
+//  400: errorResponse
+//  404: errorResponse
+//  409: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) CreateOwnerWallet(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	var reqData dto.CreateWalletRequest
+	if err := reqData.FromJson(req.Body); err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - CreateOwnerWallet - reqData.FromJson", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := reqData.Validate(); err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - CreateOwnerWallet - reqData.Validate", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	id, err := ownersApi.ownerService.CreateOwnerWallet(
+		req.Context(),
+		getOwnerId(req),
+		model.Wallet{
+			Name:     reqData.Name,
+			Currency: reqData.Currency,
+		},
+	)
+	if err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - CreateOwnerWallet - ownersApi.ownerService.CreateOwnerWallet", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAlreadyExists) {
+			writeError(rw, "wallet already exists", http.StatusConflict)
+			return
+		}
+		ownersApi.writeOwnerError(rw, err, "unable to create wallet")
+		return
+	}
+	respData := dto.CreateWalletResponse{ID: id}
+	if err = respData.ToJson(rw); err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - CreateOwnerWallet - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route GET /owners/{id}/wallets OwnersAPI getOwnerWallets
+// Return the wallets held by the owner
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: walletsResponse
+//  404: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) GetOwnerWallets(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	wallets, err := ownersApi.ownerService.GetOwnerWallets(req.Context(), getOwnerId(req))
+	if err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwnerWallets - ownersApi.ownerService.GetOwnerWallets", logging.Err(err))
+		ownersApi.writeOwnerError(rw, err, "unable to get wallets")
+		return
+	}
+	var respData dto.WalletsResponse = make([]*dto.WalletResponse, 0, len(wallets))
+	for _, wallet := range wallets {
+		respData = append(respData, &dto.WalletResponse{
+			ID:       wallet.ID,
+			Name:     wallet.Name,
+			Currency: wallet.Currency,
+			Balance:  wallet.Balance,
+			Frozen:   wallet.Frozen,
+		})
+	}
+	if err = respData.ToJson(rw); err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwnerWallets - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route GET /owners/{id}/transactions OwnersAPI getOwnerTransactions
+// Return the transactions of all wallets of the owner, the newest first
+//
+// produces:
+// 	- application/json
+//	- text/csv
+//
+// responses:
+//	200: transactionsResponse
+//  400: errorResponse
+//  404: errorResponse
+//  406: errorResponse
+//  500: errorResponse
+func (ownersApi *ownersApi) GetOwnerTransactions(rw http.ResponseWriter, req *http.Request) {
+	contentType := negotiateContentType(req.Header.Get("Accept"), []string{contentTypeJson, contentTypeCsv})
+	if contentType == "" {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - GetOwnerTransactions - invalid header 'Accept'")
+		writeError(rw, "invalid header 'Accept'", http.StatusNotAcceptable)
+		return
+	}
+	id := getOwnerId(req)
+	limit, offset, err := getPagination(req)
+	if err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - GetOwnerTransactions - getPagination", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := getTransactionFilter(req)
+	if err != nil {
+		ownersApi.logger.Warn(req.Context(), "ownersApi - GetOwnerTransactions - getTransactionFilter", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	wallets, err := ownersApi.ownerService.GetOwnerWallets(req.Context(), id)
+	if err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwnerTransactions - ownersApi.ownerService.GetOwnerWallets", logging.Err(err))
+		ownersApi.writeOwnerError(rw, err, "unable to get transactions")
+		return
+	}
+	transactions, err := ownersApi.ownerService.GetOwnerTransactions(req.Context(), id, limit, offset, filter)
+	if err != nil {
+		ownersApi.logger.Error(req.Context(), "ownersApi - GetOwnerTransactions - ownersApi.ownerService.GetOwnerTransactions", logging.Err(err))
+		ownersApi.writeOwnerError(rw, err, "unable to get transactions")
+		return
+	}
+	ownerWallets := make(map[string]bool, len(wallets))
+	for _, wallet := range wallets {
+		ownerWallets[wallet.ID] = true
+	}
+	rw.Header().Set("Content-Type", contentType)
+	var respData dto.TransactionsResponse = make([]*dto.TransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		// A transfer is presented from the side of the wallet of the owner,
+		// the sending one if both wallets are held by the owner.
+		walletId := transaction.RecipientWallet.ID
+		if transaction.SenderWallet != nil && ownerWallets[transaction.SenderWallet.ID] {
+			walletId = transaction.SenderWallet.ID
+		}
+		respData = append(respData, toTransactionResponse(transaction, walletId))
+	}
+	if contentType == contentTypeJson {
+		if err = respData.ToJson(rw); err != nil {
+			ownersApi.logger.Error(req.Context(), "ownersApi - GetOwnerTransactions - respData.ToJson", logging.Err(err))
+			writeError(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if contentType == contentTypeCsv {
+		if err = respData.ToCsv(rw); err != nil {
+			ownersApi.logger.Error(req.Context(), "ownersApi - GetOwnerTransactions - respData.ToCsv", logging.Err(err))
+			writeError(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// writeOwnerError writes the error of an owner request, message describes
+// unexpected errors.
+func (ownersApi *ownersApi) writeOwnerError(rw http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, model.ErrOwnerNotFound):
+		writeError(rw, "owner not found", http.StatusNotFound)
+	case errors.Is(err, model.ErrOwnerAlreadyExists):
+		writeError(rw, "owner already exists", http.StatusConflict)
+	case errors.Is(err, model.ErrOwnerHasWallets):
+		writeError(rw, "owner holds wallets", http.StatusConflict)
+	case errors.Is(err, model.ErrOwnerSubjectChanged):
+		writeError(rw, "owner subject cannot be changed", http.StatusConflict)
+	default:
+		writeError(rw, message, http.StatusInternalServerError)
+	}
+}
+
+func getOwnerId(req *http.Request) string {
+	return mux.Vars(req)["id"]
+}
+
+func toOwner(reqData dto.OwnerRequest) model.Owner {
+	return model.Owner{
+		ExternalID: reqData.ExternalID,
+		Subject:    reqData.Subject,
+		Name:       reqData.Name,
+		Email:      reqData.Email,
+		Phone:      reqData.Phone,
+		KycTier:    reqData.KycTier,
+	}
+}
+
+func toOwnerResponse(owner *model.Owner) *dto.OwnerResponse {
+	return &dto.OwnerResponse{
+		ID:         owner.ID,
+		ExternalID: owner.ExternalID,
+		Subject:    owner.Subject,
+		Name:       owner.Name,
+		Email:      owner.Email,
+		Phone:      owner.Phone,
+		KycTier:    owner.KycTier,
+		CreatedAt:  owner.CreatedAt,
+		UpdatedAt:  owner.UpdatedAt,
+	}
+}
@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ownerServiceMock struct {
+	mock.Mock
+}
+
+func (ownerService *ownerServiceMock) CreateOwner(ctx context.Context, owner model.Owner) (*model.Owner, error) {
+	args := ownerService.Called(owner)
+	return args.Get(0).(*model.Owner), args.Error(1)
+}
+
+func (ownerService *ownerServiceMock) GetOwner(ctx context.Context, id string) (*model.Owner, error) {
+	args := ownerService.Called(id)
+	return args.Get(0).(*model.Owner), args.Error(1)
+}
+
+func (ownerService *ownerServiceMock) GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, error) {
+	args := ownerService.Called(limit, offset)
+	return args.Get(0).([]*model.Owner), args.Error(1)
+}
+
+func (ownerService *ownerServiceMock) UpdateOwner(ctx context.Context, owner model.Owner) (*model.Owner, error) {
+	args := ownerService.Called(owner)
+	return args.Get(0).(*model.Owner), args.Error(1)
+}
+
+func (ownerService *ownerServiceMock) DeleteOwner(ctx context.Context, id string) error {
+	args := ownerService.Called(id)
+	return args.Error(0)
+}
+
+func (ownerService *ownerServiceMock) CreateOwnerWallet(ctx context.Context, ownerId string, wallet model.Wallet) (string, error) {
+	args := ownerService.Called(ownerId, wallet)
+	return args.String(0), args.Error(1)
+}
+
+func (ownerService *ownerServiceMock) GetOwnerWallets(ctx context.Context, ownerId string) ([]*model.Wallet, error) {
+	args := ownerService.Called(ownerId)
+	return args.Get(0).([]*model.Wallet), args.Error(1)
+}
+
+func (ownerService *ownerServiceMock) GetOwnerTransactions(
+	ctx context.Context, ownerId string, limit int, offset int, filter model.TransactionFilter,
+) ([]*model.Transaction, error) {
+	args := ownerService.Called(ownerId, limit, offset, filter)
+	return args.Get(0).([]*model.Transaction), args.Error(1)
+}
+
+func TestCreateOwner(t *testing.T) {
+	// given
+	ownerService := new(ownerServiceMock)
+	router := mux.NewRouter()
+	NewOwnersApi(logger, router, ownerService)
+	req, err := http.NewRequest("POST", "/owners", bytes.NewBufferString(`{"external_id":"customer-1","name":"Alice","email":"alice@example.com","kyc_tier":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	owner := model.Owner{ExternalID: "customer-1", Name: "Alice", Email: "alice@example.com", KycTier: 1}
+	created := owner
+	created.ID, created.CreatedAt, created.UpdatedAt = "4001", createdAt, createdAt
+	ownerService.On("CreateOwner", owner).Return(&created, nil)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	var respData dto.OwnerResponse
+	if err = json.NewDecoder(recorder.Body).Decode(&respData); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dto.OwnerResponse{
+		ID:         "4001",
+		ExternalID: "customer-1",
+		Name:       "Alice",
+		Email:      "alice@example.com",
+		KycTier:    1,
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}, respData)
+
+	ownerService.AssertExpectations(t)
+}
+
+func TestCreateOwnerInvalidKycTier(t *testing.T) {
+	// given
+	ownerService := new(ownerServiceMock)
+	router := mux.NewRouter()
+	NewOwnersApi(logger, router, ownerService)
+	req, err := http.NewRequest("POST", "/owners", bytes.NewBufferString(`{"name":"Alice","kyc_tier":4}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	ownerService.AssertNotCalled(t, "CreateOwner")
+}
+
+func TestDeleteOwnerHoldingWallets(t *testing.T) {
+	// given
+	ownerService := new(ownerServiceMock)
+	router := mux.NewRouter()
+	NewOwnersApi(logger, router, ownerService)
+	req, err := http.NewRequest("DELETE", "/owners/4001", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	ownerService.On("DeleteOwner", "4001").Return(model.ErrOwnerHasWallets)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+	ownerService.AssertExpectations(t)
+}
+
+func TestGetOwnerTransactions(t *testing.T) {
+	// given
+	ownerService := new(ownerServiceMock)
+	router := mux.NewRouter()
+	NewOwnersApi(logger, router, ownerService)
+	req, err := http.NewRequest("GET", "/owners/4001/transactions?limit=10&operation_type=transfer", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	processedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	ownerService.On("GetOwnerWallets", "4001").Return([]*model.Wallet{{ID: "1001"}, {ID: "1002"}}, nil)
+	ownerService.On("GetOwnerTransactions", "4001", 10, -1, model.TransactionFilter{OperationType: model.Transfer}).Return(
+		[]*model.Transaction{
+			{
+				ID:              "2002",
+				OperationType:   model.Transfer,
+				Amount:          30,
+				SenderWallet:    &model.Wallet{ID: "1003", Balance: 70},
+				RecipientWallet: model.Wallet{ID: "1002", Balance: 30},
+				ProcessedAt:     processedAt.Add(time.Minute),
+			},
+			{
+				ID:              "2001",
+				OperationType:   model.Transfer,
+				Amount:          20,
+				SenderWallet:    &model.Wallet{ID: "1001", Balance: 80},
+				RecipientWallet: model.Wallet{ID: "1002", Balance: 20},
+				ProcessedAt:     processedAt,
+			},
+		},
+		nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var respData dto.TransactionsResponse
+	if err = json.NewDecoder(recorder.Body).Decode(&respData); err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, respData, 2) {
+		assert.True(t, respData[0].RecipientWalletMe)
+		assert.Equal(t, uint64(30), respData[0].Balance)
+		assert.True(t, respData[1].SenderWalletMe)
+		assert.Equal(t, uint64(80), respData[1].Balance)
+	}
+
+	ownerService.AssertExpectations(t)
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/ratelimit"
+	"github.com/gorilla/mux"
+)
+
+// LimitIpRate rejects the requests of a client address over the limit of the
+// route group with 429. It runs before Authenticate, so that requests with
+// invalid credentials are limited too.
+func LimitIpRate(limiter ratelimit.Limiter, config ratelimit.Config) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if ok, retryAfter := limiter.AllowIp(routeName(req), clientIp(req, config.TrustForwardedFor)); !ok {
+				writeRateLimited(rw, retryAfter)
+				return
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// LimitApiKeyRate rejects the requests of a principal over the limit of the
+// route group with 429. It runs after Authenticate, requests made as
+// auth.Anonymous with authentication disabled are not limited.
+func LimitApiKeyRate(limiter ratelimit.Limiter) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			principal := auth.PrincipalFromContext(req.Context())
+			if principal != nil && principal != auth.Anonymous {
+				if ok, retryAfter := limiter.AllowApiKey(routeName(req), principal.Subject); !ok {
+					writeRateLimited(rw, retryAfter)
+					return
+				}
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// writeRateLimited rejects the request, Retry-After is rounded up to whole seconds.
+func writeRateLimited(rw http.ResponseWriter, retryAfter time.Duration) {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	rw.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeError(rw, "rate limit exceeded", http.StatusTooManyRequests)
+}
+
+// clientIp returns the address of the client, the last address of the
+// X-Forwarded-For header if the proxy in front of the server is trusted.
+func clientIp(req *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if values := req.Header.Values("X-Forwarded-For"); len(values) > 0 {
+			addresses := strings.Split(values[len(values)-1], ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
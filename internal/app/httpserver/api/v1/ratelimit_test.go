@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/ratelimit"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitApiKeyRate(t *testing.T) {
+	// given
+	config := ratelimit.Config{
+		Enabled: true,
+		Groups: map[string]ratelimit.GroupConfig{
+			"transfers": {
+				Routes: []string{"POST /wallets/{id}/transfer"},
+				ApiKey: ratelimit.Limit{Rate: 0.5, Burst: 1},
+			},
+		},
+	}
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			principal := &model.Principal{Subject: req.Header.Get("X-Subject")}
+			next.ServeHTTP(rw, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+		})
+	})
+	router.Use(LimitApiKeyRate(ratelimit.NewLimiter(config)))
+	router.HandleFunc("/wallets/{id}/transfer", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}).Methods(http.MethodPost)
+	request := func(subject string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/wallets/1001/transfer", nil)
+		req.Header.Set("X-Subject", subject)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	// when
+	first := request("apikey:1")
+	second := request("apikey:1")
+	other := request("apikey:2")
+
+	// then
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	assert.Equal(t, "2", second.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, other.Code)
+}
+
+func TestClientIp(t *testing.T) {
+	tests := []struct {
+		name              string
+		forwardedFor      []string
+		trustForwardedFor bool
+		expected          string
+	}{
+		{name: "remote address", forwardedFor: []string{"10.0.0.1"}, expected: "192.0.2.1"},
+		{name: "trusted proxy", forwardedFor: []string{"10.0.0.1, 10.0.0.2", "10.0.0.3"}, trustForwardedFor: true, expected: "10.0.0.3"},
+		{name: "trusted proxy without header", trustForwardedFor: true, expected: "192.0.2.1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			req := httptest.NewRequest(http.MethodGet, "/wallets", nil)
+			for _, value := range test.forwardedFor {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+
+			// when
+			ip := clientIp(req, test.trustForwardedFor)
+
+			// then
+			assert.Equal(t, test.expected, ip)
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1")
+
+// Trace starts a server span for each request, named after the method and the
+// route template. The span continues the trace of the W3C traceparent header
+// of the request, if it has one.
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+		route := routeTemplate(req)
+		ctx, span := tracer.Start(
+			ctx, req.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, req)...),
+		)
+		defer span.End()
+		recorder := &statusRecorder{ResponseWriter: rw, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, req.WithContext(ctx))
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(recorder.statusCode)...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(recorder.statusCode, trace.SpanKindServer))
+	})
+}
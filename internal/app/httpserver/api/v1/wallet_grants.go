@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type walletGrantsApi struct {
+	logger        *logging.Logger
+	walletService service.WalletService
+}
+
+func NewWalletGrantsApi(logger *logging.Logger, router *mux.Router, walletService service.WalletService) {
+	walletGrantsApi := &walletGrantsApi{
+		logger:        logger,
+		walletService: walletService,
+	}
+	router.HandleFunc("/wallets/{id}/grants", walletGrantsApi.GetWalletGrants).Methods(http.MethodGet)
+	router.HandleFunc("/wallets/{id}/grants/{subject}", walletGrantsApi.GrantWalletAccess).Methods(http.MethodPut)
+	router.HandleFunc("/wallets/{id}/grants/{subject}", walletGrantsApi.RevokeWalletAccess).Methods(http.MethodDelete)
+}
+
+// swagger:route GET /wallets/{id}/grants WalletsAPI getWalletGrants
+// Return the roles granted on the wallet besides its owner, only the owner can list them
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: walletGrantsResponse
+//  403: errorResponse
+//  500: errorResponse
+func (walletGrantsApi *walletGrantsApi) GetWalletGrants(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	id := getWalletId(req)
+	grants, err := walletGrantsApi.walletService.GetWalletGrants(req.Context(), id)
+	if err != nil {
+		walletGrantsApi.logger.Log(req.Context(), errorLevel(err), "walletGrantsApi - GetWalletGrants - walletGrantsApi.walletService.GetWalletGrants", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
+		writeError(rw, "unable to get wallet grants", http.StatusInternalServerError)
+		return
+	}
+	var respData dto.WalletGrantsResponse = make([]*dto.WalletGrantResponse, 0, len(grants))
+	for _, grant := range grants {
+		respData = append(respData, &dto.WalletGrantResponse{
+			Subject:   grant.Subject,
+			Role:      grant.Role.String(),
+			CreatedAt: grant.CreatedAt,
+		})
+	}
+	if err = respData.ToJson(rw); err != nil {
+		walletGrantsApi.logger.Error(req.Context(), "walletGrantsApi - GetWalletGrants - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route PUT /wallets/{id}/grants/{subject} WalletsAPI grantWalletAccess
+// Grant a role on the wallet to the subject of a principal, replacing the role granted before
+//
+// consumes:
+//	- application/json
+//
+// responses:
+//	204:
+//  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (walletGrantsApi *walletGrantsApi) GrantWalletAccess(rw http.ResponseWriter, req *http.Request) {
+	id := getWalletId(req)
+	subject := mux.Vars(req)["subject"]
+	var reqData dto.WalletGrantRequest
+	if err := reqData.FromJson(req.Body); err != nil {
+		walletGrantsApi.logger.Warn(req.Context(), "walletGrantsApi - GrantWalletAccess - reqData.FromJson", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := reqData.Validate(); err != nil {
+		walletGrantsApi.logger.Warn(req.Context(), "walletGrantsApi - GrantWalletAccess - reqData.Validate", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	role, err := model.WalletRoleFromString(reqData.Role)
+	if err != nil {
+		walletGrantsApi.logger.Warn(req.Context(), "walletGrantsApi - GrantWalletAccess - model.WalletRoleFromString", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err = walletGrantsApi.walletService.GrantWalletAccess(req.Context(), id, subject, role); err != nil {
+		walletGrantsApi.logger.Log(req.Context(), errorLevel(err), "walletGrantsApi - GrantWalletAccess - walletGrantsApi.walletService.GrantWalletAccess", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, model.ErrWalletNotFound) {
+			writeError(rw, "wallet not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to grant wallet access", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// swagger:route DELETE /wallets/{id}/grants/{subject} WalletsAPI revokeWalletAccess
+// Revoke the role granted on the wallet to the subject
+//
+// responses:
+//	204:
+//  403: errorResponse
+//  404: errorResponse
+//  500: errorResponse
+func (walletGrantsApi *walletGrantsApi) RevokeWalletAccess(rw http.ResponseWriter, req *http.Request) {
+	id := getWalletId(req)
+	subject := mux.Vars(req)["subject"]
+	if err := walletGrantsApi.walletService.RevokeWalletAccess(req.Context(), id, subject); err != nil {
+		walletGrantsApi.logger.Log(req.Context(), errorLevel(err), "walletGrantsApi - RevokeWalletAccess - walletGrantsApi.walletService.RevokeWalletAccess", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, model.ErrWalletGrantNotFound) {
+			writeError(rw, "wallet grant not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to revoke wallet access", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
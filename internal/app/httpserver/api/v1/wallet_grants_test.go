@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrantWalletAccess(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	router := mux.NewRouter()
+	NewWalletGrantsApi(logger, router, walletService)
+	req, err := http.NewRequest("PUT", "/wallets/1001/grants/user-2", bytes.NewBufferString(`{"role":"spender"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	walletService.On("GrantWalletAccess", "1001", "user-2", model.SpenderRole).Return(nil)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	walletService.AssertExpectations(t)
+}
+
+func TestGrantWalletAccessInvalidRole(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	router := mux.NewRouter()
+	NewWalletGrantsApi(logger, router, walletService)
+	req, err := http.NewRequest("PUT", "/wallets/1001/grants/user-2", bytes.NewBufferString(`{"role":"admin"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	walletService.AssertNotCalled(t, "GrantWalletAccess")
+}
+
+func TestRevokeWalletAccessDenied(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	router := mux.NewRouter()
+	NewWalletGrantsApi(logger, router, walletService)
+	req, err := http.NewRequest("DELETE", "/wallets/1001/grants/user-2", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	walletService.On("RevokeWalletAccess", "1001", "user-2").Return(model.ErrWalletAccessDenied)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	walletService.AssertExpectations(t)
+}
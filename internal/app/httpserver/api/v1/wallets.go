@@ -1,27 +1,33 @@
 package v1
 
 import (
+	"context"
 	"errors"
-	"log"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
 	"github.com/SergeyChupin/wallets-api/internal/model"
 	"github.com/SergeyChupin/wallets-api/internal/service"
 	"github.com/gorilla/mux"
 )
 
 type walletsApi struct {
-	logger        *log.Logger
+	logger        *logging.Logger
 	walletService service.WalletService
+	exportConfig  ExportConfig
 }
 
-func NewWalletsApi(logger *log.Logger, router *mux.Router, walletService service.WalletService) {
+func NewWalletsApi(logger *logging.Logger, router *mux.Router, walletService service.WalletService, exportConfig ExportConfig) {
 	walletsApi := &walletsApi{
 		logger:        logger,
 		walletService: walletService,
+		exportConfig:  exportConfig,
 	}
 	router.HandleFunc("/wallets", walletsApi.CreateWallet).Methods(http.MethodPost)
 	router.HandleFunc("/wallets/{id}/deposit", walletsApi.Deposit).Methods(http.MethodPost)
@@ -40,35 +46,41 @@ func NewWalletsApi(logger *log.Logger, router *mux.Router, walletService service
 // responses:
 //	200: createWalletResponse
 //  400: errorResponse
+//  409: errorResponse
 //  500: errorResponse
 func (walletsApi *walletsApi) CreateWallet(rw http.ResponseWriter, req *http.Request) {
 	rw.Header().Set("Content-Type", "application/json")
 	var reqData dto.CreateWalletRequest
 	err := reqData.FromJson(req.Body)
 	if err != nil {
-		walletsApi.logger.Println("walletsApi - CreateWallet - reqData.FromJson:", err)
+		walletsApi.logger.Warn(req.Context(), "walletsApi - CreateWallet - reqData.FromJson", logging.Err(err))
 		writeError(rw, "invalid request body", http.StatusBadRequest)
 		return
 	}
 	if err = reqData.Validate(); err != nil {
-		walletsApi.logger.Println("walletsApi - CreateWallet - reqData.Validate:", err)
+		walletsApi.logger.Warn(req.Context(), "walletsApi - CreateWallet - reqData.Validate", logging.Err(err))
 		writeError(rw, "invalid request body", http.StatusBadRequest)
 		return
 	}
 	id, err := walletsApi.walletService.CreateWallet(
+		req.Context(),
 		model.Wallet{
 			Name:     reqData.Name,
 			Currency: reqData.Currency,
 		},
 	)
 	if err != nil {
-		walletsApi.logger.Println("walletsApi - CreateWallet - walletsApi.walletService.CreateWallet:", err)
+		walletsApi.logger.Log(req.Context(), errorLevel(err), "walletsApi - CreateWallet - walletsApi.walletService.CreateWallet", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAlreadyExists) {
+			writeError(rw, "wallet already exists", http.StatusConflict)
+			return
+		}
 		writeError(rw, "unable to create wallet", http.StatusInternalServerError)
 		return
 	}
 	respData := dto.CreateWalletResponse{ID: id}
 	if err = respData.ToJson(rw); err != nil {
-		walletsApi.logger.Println("walletsApi - CreateWallet - respData.ToJson:", err)
+		walletsApi.logger.Error(req.Context(), "walletsApi - CreateWallet - respData.ToJson", logging.Err(err))
 		writeError(rw, "internal error", http.StatusInternalServerError)
 		return
 	}
@@ -85,32 +97,42 @@ func (walletsApi *walletsApi) CreateWallet(rw http.ResponseWriter, req *http.Req
 // responses:
 //	200: depositResponse
 //  400: errorResponse
+//  404: errorResponse
+//  409: errorResponse
 //  500: errorResponse
 func (walletsApi *walletsApi) Deposit(rw http.ResponseWriter, req *http.Request) {
 	rw.Header().Set("Content-Type", "application/json")
 	id := getWalletId(req)
 	var reqData dto.DepositRequest
 	if err := reqData.FromJson(req.Body); err != nil {
-		walletsApi.logger.Println("walletsApi - Deposit - reqData.FromJson:", err)
+		walletsApi.logger.Warn(req.Context(), "walletsApi - Deposit - reqData.FromJson", logging.Err(err))
 		writeError(rw, "invalid request body", http.StatusBadRequest)
 		return
 	}
 	if err := reqData.Validate(); err != nil {
-		walletsApi.logger.Println("walletsApi - Deposit - reqData.Validate:", err)
+		walletsApi.logger.Warn(req.Context(), "walletsApi - Deposit - reqData.Validate", logging.Err(err))
 		writeError(rw, "invalid request body", http.StatusBadRequest)
 		return
 	}
 	depositTransaction, err := walletsApi.walletService.Deposit(
-		id, reqData.Amount,
+		req.Context(), id, reqData.Amount,
 	)
 	if err != nil {
-		walletsApi.logger.Println("walletsApi - Deposit - walletsApi.walletService.Deposit:", err)
+		walletsApi.logger.Log(req.Context(), errorLevel(err), "walletsApi - Deposit - walletsApi.walletService.Deposit", logging.Err(err))
+		if errors.Is(err, model.ErrWalletNotFound) {
+			writeError(rw, "wallet not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, model.ErrWalletFrozen) {
+			writeError(rw, "wallet is frozen", http.StatusConflict)
+			return
+		}
 		writeError(rw, "unable to deposit wallet", http.StatusInternalServerError)
 		return
 	}
 	respData := dto.DepositResponse{Balance: depositTransaction.RecipientWallet.Balance}
 	if err = respData.ToJson(rw); err != nil {
-		walletsApi.logger.Println("walletsApi - Deposit - respData.ToJson:", err)
+		walletsApi.logger.Error(req.Context(), "walletsApi - Deposit - respData.ToJson", logging.Err(err))
 		writeError(rw, "internal error", http.StatusInternalServerError)
 		return
 	}
@@ -127,26 +149,49 @@ func (walletsApi *walletsApi) Deposit(rw http.ResponseWriter, req *http.Request)
 // responses:
 //	200: transferResponse
 //  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
+//  409: errorResponse
 //  500: errorResponse
 func (walletsApi *walletsApi) Transfer(rw http.ResponseWriter, req *http.Request) {
 	rw.Header().Set("Content-Type", "application/json")
 	id := getWalletId(req)
 	var reqData dto.TransferRequest
 	if err := reqData.FromJson(req.Body); err != nil {
-		walletsApi.logger.Println("walletsApi - Transfer - reqData.FromJson:", err)
+		walletsApi.logger.Warn(req.Context(), "walletsApi - Transfer - reqData.FromJson", logging.Err(err))
 		writeError(rw, "invalid request body", http.StatusBadRequest)
 		return
 	}
 	if err := reqData.Validate(); err != nil {
-		walletsApi.logger.Println("walletsApi - Transfer - reqData.Validate:", err)
+		walletsApi.logger.Warn(req.Context(), "walletsApi - Transfer - reqData.Validate", logging.Err(err))
 		writeError(rw, "invalid request body", http.StatusBadRequest)
 		return
 	}
 	transferTransaction, err := walletsApi.walletService.Transfer(
-		reqData.SenderWalletId, id, reqData.Amount,
+		req.Context(), reqData.SenderWalletId, id, reqData.Amount,
 	)
 	if err != nil {
-		walletsApi.logger.Println("walletsApi - Transfer - walletsApi.walletService.Transfer:", err)
+		walletsApi.logger.Log(req.Context(), errorLevel(err), "walletsApi - Transfer - walletsApi.walletService.Transfer", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, model.ErrWalletNotFound) {
+			writeError(rw, "wallet not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, model.ErrInsufficientFunds) {
+			writeError(rw, "insufficient funds", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, model.ErrWalletFrozen) {
+			writeError(rw, "wallet is frozen", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, model.ErrSameWallet) {
+			writeError(rw, "sender wallet should be different than recipient wallet", http.StatusBadRequest)
+			return
+		}
 		writeError(rw, "unable to transfer money between wallets", http.StatusInternalServerError)
 		return
 	}
@@ -155,7 +200,7 @@ func (walletsApi *walletsApi) Transfer(rw http.ResponseWriter, req *http.Request
 		Balance:             transferTransaction.RecipientWallet.Balance,
 	}
 	if err = respData.ToJson(rw); err != nil {
-		walletsApi.logger.Println("walletsApi - Transfer - respData.ToJson:", err)
+		walletsApi.logger.Error(req.Context(), "walletsApi - Transfer - respData.ToJson", logging.Err(err))
 		writeError(rw, "internal error", http.StatusInternalServerError)
 		return
 	}
@@ -167,115 +212,310 @@ func (walletsApi *walletsApi) Transfer(rw http.ResponseWriter, req *http.Request
 // produces:
 // 	- application/json
 //	- text/csv
+//	- application/xml
+//	- application/x-ofx
 //
 // responses:
 //	200: transactionsResponse
 //  400: errorResponse
+//  403: errorResponse
+//  404: errorResponse
 //  406: errorResponse
 //  500: errorResponse
 func (walletsApi *walletsApi) GetTransactions(rw http.ResponseWriter, req *http.Request) {
-	contentType := req.Header.Get("Accept")
-	if contentType == "" || contentType == "*/*" {
-		contentType = "application/json"
-	}
-	if contentType != "application/json" && contentType != "text/csv" {
-		walletsApi.logger.Println("walletsApi - GetTransactions - invalid header 'Accept'")
+	contentType := negotiateContentType(
+		req.Header.Get("Accept"),
+		[]string{contentTypeJson, contentTypeCsv, contentTypeCamt053, contentTypeOfx},
+	)
+	if contentType == "" {
+		walletsApi.logger.Warn(req.Context(), "walletsApi - GetTransactions - invalid header 'Accept'")
 		writeError(rw, "invalid header 'Accept'", http.StatusNotAcceptable)
 		return
 	}
-	rw.Header().Set("Content-Type", contentType)
 	id := getWalletId(req)
 	limit, offset, err := getPagination(req)
 	if err != nil {
-		walletsApi.logger.Println("walletsApi - GetTransactions - getPagination:", err)
+		walletsApi.logger.Warn(req.Context(), "walletsApi - GetTransactions - getPagination", logging.Err(err))
 		writeError(rw, err.Error(), http.StatusBadRequest)
 		return
 	}
-	filter := model.TransactionFilter{
-		WalletId: id,
+	filter, err := getTransactionFilter(req)
+	if err != nil {
+		walletsApi.logger.Warn(req.Context(), "walletsApi - GetTransactions - getTransactionFilter", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
 	}
-	operationType := req.URL.Query().Get("operation_type")
-	if operationType != "" {
-		operationType, err := model.FromString(operationType)
+	filter.WalletId = id
+	csvOptions := dto.DefaultCsvOptions()
+	if contentType == contentTypeCsv {
+		csvProfile, err := walletsApi.getCsvProfile(req)
 		if err != nil {
-			walletsApi.logger.Println("walletsApi - GetTransactions - model.FromString:", err)
-			writeError(rw, "invalid query parameter operation_type", http.StatusBadRequest)
+			walletsApi.logger.Warn(req.Context(), "walletsApi - GetTransactions - walletsApi.getCsvProfile", logging.Err(err))
+			writeError(rw, err.Error(), http.StatusBadRequest)
 			return
 		}
-		filter.OperationType = operationType
-	}
-	processedAtGte := req.URL.Query().Get("processed_at.gte")
-	if processedAtGte != "" {
-		processedAtGte, err := time.Parse(time.RFC3339Nano, processedAtGte)
-		if err != nil {
-			walletsApi.logger.Println("walletsApi - GetTransactions - time.Parse:", err)
-			writeError(rw, "invalid query parameter processed_at.gte", http.StatusBadRequest)
+		var currency string
+		if csvProfile.Amounts == dto.AmountsMajorUnits {
+			wallet, err := walletsApi.walletService.GetWallet(req.Context(), id)
+			if err != nil {
+				walletsApi.logger.Log(req.Context(), errorLevel(err), "walletsApi - GetTransactions - walletsApi.walletService.GetWallet", logging.Err(err))
+				if errors.Is(err, model.ErrWalletAccessDenied) {
+					writeError(rw, "wallet access denied", http.StatusForbidden)
+					return
+				}
+				if errors.Is(err, model.ErrWalletNotFound) {
+					writeError(rw, "wallet not found", http.StatusNotFound)
+					return
+				}
+				writeError(rw, "unable to get transactions", http.StatusInternalServerError)
+				return
+			}
+			currency = wallet.Currency
+		}
+		if csvOptions, err = csvProfile.Options(currency); err != nil {
+			walletsApi.logger.Warn(req.Context(), "walletsApi - GetTransactions - csvProfile.Options", logging.Err(err))
+			writeError(rw, err.Error(), http.StatusBadRequest)
 			return
 		}
-		filter.ProcessedAtGte = processedAtGte
 	}
-	processedAtLte := req.URL.Query().Get("processed_at.lte")
-	if processedAtLte != "" {
-		processedAtLte, err := time.Parse(time.RFC3339Nano, processedAtLte)
-		if err != nil {
-			walletsApi.logger.Println("walletsApi - GetTransactions - time.Parse:", err)
-			writeError(rw, "invalid query parameter processed_at.lte", http.StatusBadRequest)
+	if contentType == contentTypeCamt053 || contentType == contentTypeOfx {
+		// A statement covers the whole period, so that its entries add up to
+		// the difference between its balances.
+		if limit != -1 || offset != -1 || filter.OperationType != model.UnknownOperation {
+			walletsApi.logger.Warn(req.Context(), "walletsApi - GetTransactions - statement of a part of the period")
+			writeError(rw, "limit, offset and operation_type are not supported for statements", http.StatusBadRequest)
 			return
 		}
-		filter.ProcessedAtLte = processedAtLte
-	}
-	if !filter.ProcessedAtLte.IsZero() && !filter.ProcessedAtGte.Before(filter.ProcessedAtLte) {
-		walletsApi.logger.Println("walletsApi - GetTransactions - invalid time range processed_at")
-		writeError(rw, "invalid time range processed_at", http.StatusBadRequest)
+		// Balances of the statement are derived from the wallet and its
+		// transactions, both have to be read from the same database.
+		walletsApi.writeStatement(database.WithPrimaryReads(req.Context()), rw, contentType, id, filter)
 		return
 	}
 	transactions, err := walletsApi.walletService.GetTransactions(
-		limit, offset, filter,
+		req.Context(), limit, offset, filter,
 	)
 	if err != nil {
-		walletsApi.logger.Println("walletsApi - GetTransactions - walletsApi.walletService.GetTransactions:", err)
+		walletsApi.logger.Log(req.Context(), errorLevel(err), "walletsApi - GetTransactions - walletsApi.walletService.GetTransactions", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
 		writeError(rw, "unable to get transactions", http.StatusInternalServerError)
 		return
 	}
+	rw.Header().Set("Content-Type", contentType)
 	var respData dto.TransactionsResponse = make([]*dto.TransactionResponse, 0, len(transactions))
 	for _, transaction := range transactions {
-		respItem := &dto.TransactionResponse{
+		respData = append(respData, toTransactionResponse(transaction, id))
+	}
+	if contentType == contentTypeJson {
+		if err = respData.ToJson(rw); err != nil {
+			walletsApi.logger.Error(req.Context(), "walletsApi - GetTransactions - respData.ToJson", logging.Err(err))
+			writeError(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+	if contentType == contentTypeCsv {
+		if err = respData.ToCsvWithOptions(rw, csvOptions); err != nil {
+			walletsApi.logger.Error(req.Context(), "walletsApi - GetTransactions - respData.ToCsvWithOptions", logging.Err(err))
+			writeError(rw, "internal error", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// writeStatement renders the transactions of the period as a bank statement with
+// opening and closing balances of the wallet.
+func (walletsApi *walletsApi) writeStatement(
+	ctx context.Context, rw http.ResponseWriter, contentType string, id string, filter model.TransactionFilter,
+) {
+	wallet, err := walletsApi.walletService.GetWallet(ctx, id)
+	if err != nil {
+		walletsApi.logger.Log(ctx, errorLevel(err), "walletsApi - writeStatement - walletsApi.walletService.GetWallet", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
+		if errors.Is(err, model.ErrWalletNotFound) {
+			writeError(rw, "wallet not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to get transactions", http.StatusInternalServerError)
+		return
+	}
+	if err = dto.ValidateCurrency(wallet.Currency); err != nil {
+		walletsApi.logger.Warn(ctx, "walletsApi - writeStatement - dto.ValidateCurrency", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusNotAcceptable)
+		return
+	}
+	respData := dto.StatementResponse{
+		WalletId:   wallet.ID,
+		WalletName: wallet.Name,
+		Currency:   wallet.Currency,
+		CreatedAt:  time.Now().UTC(),
+		From:       filter.ProcessedAtGte,
+		To:         filter.ProcessedAtLte,
+	}
+	if respData.To.IsZero() {
+		respData.To = respData.CreatedAt
+	}
+	transactions, err := walletsApi.walletService.GetTransactions(ctx, -1, -1, filter)
+	if err != nil {
+		walletsApi.logger.Log(ctx, errorLevel(err), "walletsApi - writeStatement - walletsApi.walletService.GetTransactions", logging.Err(err))
+		if errors.Is(err, model.ErrWalletAccessDenied) {
+			writeError(rw, "wallet access denied", http.StatusForbidden)
+			return
+		}
+		writeError(rw, "unable to get transactions", http.StatusInternalServerError)
+		return
+	}
+	for i := len(transactions) - 1; i >= 0; i-- {
+		transaction := transactions[i]
+		entry := &dto.StatementEntry{
+			Reference:     transaction.ID,
+			EndToEndId:    transaction.Reference,
 			OperationType: transaction.OperationType.String(),
 			Amount:        transaction.Amount,
+			Credit:        true,
 			Balance:       transaction.RecipientWallet.Balance,
 			ProcessedAt:   transaction.ProcessedAt,
 		}
 		if transaction.SenderWallet != nil {
 			if transaction.SenderWallet.ID == id {
-				respItem.Balance = transaction.SenderWallet.Balance
-				respItem.SenderWalletMe = true
-				respItem.RecipientWalletId = &transaction.RecipientWallet.ID
-				respItem.RecipientWalletBalance = &transaction.RecipientWallet.Balance
+				entry.Credit = false
+				entry.Balance = transaction.SenderWallet.Balance
+				entry.CounterpartyWalletId = transaction.RecipientWallet.ID
 			} else {
-				respItem.Balance = transaction.RecipientWallet.Balance
-				respItem.RecipientWalletMe = true
-				respItem.SenderWalletId = &transaction.SenderWallet.ID
-				respItem.SenderWalletBalance = &transaction.SenderWallet.Balance
+				entry.CounterpartyWalletId = transaction.SenderWallet.ID
 			}
 		}
-		respData = append(respData, respItem)
+		respData.Entries = append(respData.Entries, entry)
 	}
-	if contentType == "application/json" {
-		if err = respData.ToJson(rw); err != nil {
-			walletsApi.logger.Println("walletsApi - GetTransactions - respData.ToJson:", err)
+	if respData.From.IsZero() {
+		respData.From = respData.To
+		if len(respData.Entries) > 0 {
+			respData.From = respData.Entries[0].ProcessedAt
+		}
+	} else if respData.OpeningBalance, err = walletsApi.balanceAt(ctx, id, respData.From.Add(-time.Nanosecond)); err != nil {
+		walletsApi.logger.Error(ctx, "walletsApi - writeStatement - walletsApi.balanceAt", logging.Err(err))
+		writeError(rw, "unable to get transactions", http.StatusInternalServerError)
+		return
+	}
+	if respData.ClosingBalance, err = walletsApi.balanceAt(ctx, id, respData.To); err != nil {
+		walletsApi.logger.Error(ctx, "walletsApi - writeStatement - walletsApi.balanceAt", logging.Err(err))
+		writeError(rw, "unable to get transactions", http.StatusInternalServerError)
+		return
+	}
+	rw.Header().Set("Content-Type", contentType)
+	if contentType == contentTypeCamt053 {
+		if err = respData.ToCamt053(rw); err != nil {
+			walletsApi.logger.Error(ctx, "walletsApi - writeStatement - respData.ToCamt053", logging.Err(err))
 			writeError(rw, "internal error", http.StatusInternalServerError)
 			return
 		}
 	}
-	if contentType == "text/csv" {
-		if err = respData.ToCsv(rw); err != nil {
-			walletsApi.logger.Println("walletsApi - GetTransactions - respData.ToCsv:", err)
+	if contentType == contentTypeOfx {
+		if err = respData.ToOfx(rw); err != nil {
+			walletsApi.logger.Error(ctx, "walletsApi - writeStatement - respData.ToOfx", logging.Err(err))
 			writeError(rw, "internal error", http.StatusInternalServerError)
 			return
 		}
 	}
 }
 
+// balanceAt returns the balance of the wallet after the last transaction
+// processed at or before the time, 0 if there is none.
+func (walletsApi *walletsApi) balanceAt(ctx context.Context, id string, at time.Time) (uint64, error) {
+	previous, err := walletsApi.walletService.GetTransactions(
+		ctx, 1, 0, model.TransactionFilter{WalletId: id, ProcessedAtLte: at},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("walletsApi - balanceAt - walletsApi.walletService.GetTransactions: %w", err)
+	}
+	if len(previous) == 0 {
+		return 0, nil
+	}
+	if previous[0].SenderWallet != nil && previous[0].SenderWallet.ID == id {
+		return previous[0].SenderWallet.Balance, nil
+	}
+	return previous[0].RecipientWallet.Balance, nil
+}
+
+// toTransactionResponse presents the transaction from the side of the wallet.
+func toTransactionResponse(transaction *model.Transaction, walletId string) *dto.TransactionResponse {
+	respItem := &dto.TransactionResponse{
+		ID:            transaction.ID,
+		OperationType: transaction.OperationType.String(),
+		Amount:        transaction.Amount,
+		Balance:       transaction.RecipientWallet.Balance,
+		ProcessedAt:   transaction.ProcessedAt,
+		Reference:     transaction.Reference,
+		ApiKeyId:      transaction.ApiKeyId,
+	}
+	if transaction.SenderWallet != nil {
+		if transaction.SenderWallet.ID == walletId {
+			respItem.Balance = transaction.SenderWallet.Balance
+			respItem.SenderWalletMe = true
+			respItem.RecipientWalletId = &transaction.RecipientWallet.ID
+			respItem.RecipientWalletBalance = &transaction.RecipientWallet.Balance
+		} else {
+			respItem.Balance = transaction.RecipientWallet.Balance
+			respItem.RecipientWalletMe = true
+			respItem.SenderWalletId = &transaction.SenderWallet.ID
+			respItem.SenderWalletBalance = &transaction.SenderWallet.Balance
+		}
+	}
+	return respItem
+}
+
+// getCsvProfile resolves the CSV export layout of the request: a saved profile
+// selected by csv.profile, overridden by the individual csv.* query parameters.
+func (walletsApi *walletsApi) getCsvProfile(req *http.Request) (dto.CsvProfile, error) {
+	query := req.URL.Query()
+	var profile dto.CsvProfile
+	if name := query.Get("csv.profile"); name != "" {
+		savedProfile, ok := walletsApi.exportConfig.CsvProfiles[name]
+		if !ok {
+			return dto.CsvProfile{}, errors.New("unknown csv profile: " + name)
+		}
+		profile = savedProfile
+	}
+	override := dto.CsvProfile{
+		Delimiter:        query.Get("csv.delimiter"),
+		TimeFormat:       query.Get("csv.time_format"),
+		Timezone:         query.Get("csv.timezone"),
+		Amounts:          query.Get("csv.amounts"),
+		DecimalSeparator: query.Get("csv.decimal_separator"),
+	}
+	if columns := query.Get("csv.columns"); columns != "" {
+		override.Columns = strings.Split(columns, ",")
+	}
+	if headers := query.Get("csv.headers"); headers != "" {
+		override.Headers = make(map[string]string)
+		for _, columnHeader := range strings.Split(headers, ",") {
+			column, header, ok := cutColumnHeader(columnHeader)
+			if !ok {
+				return dto.CsvProfile{}, errors.New("invalid csv header: " + columnHeader)
+			}
+			override.Headers[column] = header
+		}
+	}
+	if _, ok := query["csv.null"]; ok {
+		null := query.Get("csv.null")
+		override.Null = &null
+	}
+	return profile.Merge(override), nil
+}
+
+// cutColumnHeader splits a column:header pair of the csv.headers query parameter.
+func cutColumnHeader(columnHeader string) (column string, header string, ok bool) {
+	i := strings.IndexByte(columnHeader, ':')
+	if i < 0 {
+		return "", "", false
+	}
+	return columnHeader[:i], columnHeader[i+1:], true
+}
+
 func writeError(rw http.ResponseWriter, message string, statusCode int) {
 	rw.Header().Set("Content-Type", "application/json")
 	rw.WriteHeader(statusCode)
@@ -289,6 +529,35 @@ func getWalletId(req *http.Request) string {
 	return vars["id"]
 }
 
+// getTransactionFilter reads the operation type and the processed_at time range
+// of the query, the wallet is left to the caller.
+func getTransactionFilter(req *http.Request) (model.TransactionFilter, error) {
+	var filter model.TransactionFilter
+	query := req.URL.Query()
+	if operationType := query.Get("operation_type"); operationType != "" {
+		var err error
+		if filter.OperationType, err = model.FromString(operationType); err != nil {
+			return filter, errors.New("invalid query parameter operation_type")
+		}
+	}
+	if processedAtGte := query.Get("processed_at.gte"); processedAtGte != "" {
+		var err error
+		if filter.ProcessedAtGte, err = time.Parse(time.RFC3339Nano, processedAtGte); err != nil {
+			return filter, errors.New("invalid query parameter processed_at.gte")
+		}
+	}
+	if processedAtLte := query.Get("processed_at.lte"); processedAtLte != "" {
+		var err error
+		if filter.ProcessedAtLte, err = time.Parse(time.RFC3339Nano, processedAtLte); err != nil {
+			return filter, errors.New("invalid query parameter processed_at.lte")
+		}
+	}
+	if !filter.ProcessedAtLte.IsZero() && !filter.ProcessedAtGte.Before(filter.ProcessedAtLte) {
+		return filter, errors.New("invalid time range processed_at")
+	}
+	return filter, nil
+}
+
 func getPagination(req *http.Request) (pageLimit int, pageOffset int, err error) {
 	pageLimit, pageOffset = -1, -1
 	limit := req.URL.Query().Get("limit")
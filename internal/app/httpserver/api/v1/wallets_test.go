@@ -2,8 +2,9 @@ package v1
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"log"
+	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -11,40 +12,87 @@ import (
 	"time"
 
 	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
 	"github.com/SergeyChupin/wallets-api/internal/model"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 var (
-	logger = log.New(os.Stdout, "wallets-api-testing ", log.LstdFlags)
+	logger, _ = logging.New(os.Stdout, logging.Config{Level: logging.LevelDebug.String(), Format: logging.TextFormat})
 )
 
 type walletServiceMock struct {
 	mock.Mock
 }
 
-func (walletService *walletServiceMock) CreateWallet(wallet model.Wallet) (string, error) {
+func (walletService *walletServiceMock) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
 	args := walletService.Called(wallet)
 	return args.String(0), args.Error(1)
 }
 
-func (walletService *walletServiceMock) Deposit(recipientWalletId string, amount uint64) (*model.Transaction, error) {
+func (walletService *walletServiceMock) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	args := walletService.Called(id)
+	return args.Get(0).(*model.Wallet), args.Error(1)
+}
+
+func (walletService *walletServiceMock) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
 	args := walletService.Called(recipientWalletId, amount)
 	return args.Get(0).(*model.Transaction), args.Error(1)
 }
 
-func (walletService *walletServiceMock) Transfer(senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+func (walletService *walletServiceMock) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
 	args := walletService.Called(senderWalletId, recipientWalletId, amount)
 	return args.Get(0).(*model.Transaction), args.Error(1)
 }
 
-func (walletService *walletServiceMock) GetTransactions(limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+func (walletService *walletServiceMock) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
 	args := walletService.Called(limit, offset, filter)
 	return args.Get(0).([]*model.Transaction), args.Error(1)
 }
 
+func (walletService *walletServiceMock) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	args := walletService.Called(walletId, transactionId)
+	return args.Get(0).([]*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) FreezeWallet(ctx context.Context, id string) error {
+	args := walletService.Called(id)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) UnfreezeWallet(ctx context.Context, id string) error {
+	args := walletService.Called(id)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) ShardWallet(ctx context.Context, id string, shards int) error {
+	args := walletService.Called(id, shards)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	args := walletService.Called()
+	return args.Get(0).([]*model.WalletReconciliation), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	args := walletService.Called(walletId)
+	return args.Get(0).([]*model.WalletGrant), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GrantWalletAccess(ctx context.Context, walletId string, subject string, role model.WalletRole) error {
+	args := walletService.Called(walletId, subject, role)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	args := walletService.Called(walletId, subject)
+	return args.Error(0)
+}
+
 func TestCreateWallet(t *testing.T) {
 	// given
 	walletService := new(walletServiceMock)
@@ -58,7 +106,7 @@ func TestCreateWallet(t *testing.T) {
 		t.Fatal(err)
 	}
 	router := mux.NewRouter()
-	NewWalletsApi(logger, router, walletService)
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
 	req, err := http.NewRequest("POST", "/wallets", reqBodyBuf)
 	if err != nil {
 		t.Fatal(err)
@@ -100,7 +148,7 @@ func TestCreateWalletCurrencyValidation(t *testing.T) {
 		t.Fatal(err)
 	}
 	router := mux.NewRouter()
-	NewWalletsApi(logger, router, walletService)
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
 	req, err := http.NewRequest("POST", "/wallets", reqBodyBuf)
 	if err != nil {
 		t.Fatal(err)
@@ -136,7 +184,7 @@ func TestDeposit(t *testing.T) {
 		t.Fatal(err)
 	}
 	router := mux.NewRouter()
-	NewWalletsApi(logger, router, walletService)
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
 	req, err := http.NewRequest("POST", "/wallets/1001/deposit", reqBodyBuf)
 	if err != nil {
 		t.Fatal(err)
@@ -187,7 +235,7 @@ func TestTransfer(t *testing.T) {
 		t.Fatal(err)
 	}
 	router := mux.NewRouter()
-	NewWalletsApi(logger, router, walletService)
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
 	req, err := http.NewRequest("POST", "/wallets/1001/transfer", reqBodyBuf)
 	if err != nil {
 		t.Fatal(err)
@@ -230,12 +278,33 @@ func TestTransfer(t *testing.T) {
 	walletService.AssertExpectations(t)
 }
 
+func TestTransferAccessDenied(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	req, err := http.NewRequest("POST", "/wallets/1001/transfer", bytes.NewBufferString(`{"amount":100,"sender_wallet_id":"1002"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	walletService.On("Transfer", "1002", "1001", uint64(100)).Return((*model.Transaction)(nil), model.ErrWalletAccessDenied)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	walletService.AssertExpectations(t)
+}
+
 func TestGetDepositTransactionsJson(t *testing.T) {
 	// given
 	walletService := new(walletServiceMock)
 
 	router := mux.NewRouter()
-	NewWalletsApi(logger, router, walletService)
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
 	req, err := http.NewRequest(
 		"GET",
 		"/wallets/1001/transactions?limit=10&offset=0&operation_type=deposit&processed_at.gte=2022-01-10T00:00:00Z&processed_at.lte=2022-01-10T23:59:59Z",
@@ -306,7 +375,7 @@ func TestGetTransferTransactionsJson(t *testing.T) {
 	walletService := new(walletServiceMock)
 
 	router := mux.NewRouter()
-	NewWalletsApi(logger, router, walletService)
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
 	req, err := http.NewRequest(
 		"GET",
 		"/wallets/1001/transactions?limit=10&offset=0&operation_type=transfer&processed_at.gte=2022-01-10T00:00:00Z&processed_at.lte=2022-01-10T23:59:59Z",
@@ -375,3 +444,644 @@ func TestGetTransferTransactionsJson(t *testing.T) {
 	walletService.AssertNumberOfCalls(t, "GetTransactions", 1)
 	walletService.AssertExpectations(t)
 }
+
+func TestGetTransactionsCamt053(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	req, err := http.NewRequest(
+		"GET",
+		"/wallets/1001/transactions?processed_at.gte=2022-01-10T00:00:00Z&processed_at.lte=2022-01-10T23:59:59Z",
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+	processedAtGte, err := time.Parse(time.RFC3339Nano, "2022-01-10T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	processedAtLte, err := time.Parse(time.RFC3339Nano, "2022-01-10T23:59:59Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletService.On(
+		"GetTransactions", -1, -1, model.TransactionFilter{
+			WalletId:       "1001",
+			ProcessedAtGte: processedAtGte,
+			ProcessedAtLte: processedAtLte,
+		},
+	).Return(
+		[]*model.Transaction{
+			{
+				ID:          "tx-2",
+				Amount:      2500,
+				ProcessedAt: processedAtGte.Add(time.Hour * 2),
+				SenderWallet: &model.Wallet{
+					ID:      "1001",
+					Balance: 7500,
+				},
+				RecipientWallet: model.Wallet{
+					ID:      "1002",
+					Balance: 2500,
+				},
+				OperationType: model.Transfer,
+			},
+			{
+				ID:          "tx-1",
+				Amount:      10000,
+				ProcessedAt: processedAtGte.Add(time.Hour),
+				RecipientWallet: model.Wallet{
+					ID:      "1001",
+					Balance: 10000,
+				},
+				OperationType: model.Deposit,
+			},
+		}, nil,
+	)
+	walletService.On(
+		"GetTransactions", 1, 0, model.TransactionFilter{
+			WalletId:       "1001",
+			ProcessedAtLte: processedAtGte.Add(-time.Nanosecond),
+		},
+	).Return([]*model.Transaction{}, nil)
+	walletService.On(
+		"GetTransactions", 1, 0, model.TransactionFilter{
+			WalletId:       "1001",
+			ProcessedAtLte: processedAtLte,
+		},
+	).Return(
+		[]*model.Transaction{
+			{
+				ID:          "tx-2",
+				Amount:      2500,
+				ProcessedAt: processedAtGte.Add(time.Hour * 2),
+				SenderWallet: &model.Wallet{
+					ID:      "1001",
+					Balance: 7500,
+				},
+				RecipientWallet: model.Wallet{
+					ID:      "1002",
+					Balance: 2500,
+				},
+				OperationType: model.Transfer,
+			},
+		}, nil,
+	)
+	walletService.On("GetWallet", "1001").Return(
+		&model.Wallet{ID: "1001", Name: "wallet", Currency: "USD", Balance: 7500}, nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	assert.Equal(t, "application/xml", recorder.Header().Get("Content-Type"))
+	respBody := struct {
+		Stmt struct {
+			Bal []struct {
+				Code   string `xml:"Tp>CdOrPrtry>Cd"`
+				Amount string `xml:"Amt"`
+			} `xml:"Bal"`
+			Ntry []struct {
+				NtryRef   string `xml:"NtryRef"`
+				Amount    string `xml:"Amt"`
+				CdtDbtInd string `xml:"CdtDbtInd"`
+			} `xml:"Ntry"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}{}
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &respBody); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "OPBD", respBody.Stmt.Bal[0].Code)
+	assert.Equal(t, "0.00", respBody.Stmt.Bal[0].Amount)
+	assert.Equal(t, "CLBD", respBody.Stmt.Bal[1].Code)
+	assert.Equal(t, "75.00", respBody.Stmt.Bal[1].Amount)
+	assert.Equal(t, "tx-1", respBody.Stmt.Ntry[0].NtryRef)
+	assert.Equal(t, "100.00", respBody.Stmt.Ntry[0].Amount)
+	assert.Equal(t, "CRDT", respBody.Stmt.Ntry[0].CdtDbtInd)
+	assert.Equal(t, "tx-2", respBody.Stmt.Ntry[1].NtryRef)
+	assert.Equal(t, "25.00", respBody.Stmt.Ntry[1].Amount)
+	assert.Equal(t, "DBIT", respBody.Stmt.Ntry[1].CdtDbtInd)
+
+	walletService.AssertNumberOfCalls(t, "GetTransactions", 3)
+	walletService.AssertExpectations(t)
+}
+
+func TestGetTransactionsStatementIdLengths(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	walletId := "5b0f8f8e-3c1e-4a8d-9f55-2d1a7c9e4b61"
+	counterpartyId := "9d2c6a41-7f0b-4e3a-8c19-b64e52f0a7d3"
+	processedAtGte, err := time.Parse(time.RFC3339Nano, "2022-01-10T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	processedAtLte, err := time.Parse(time.RFC3339Nano, "2022-01-10T23:59:59Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	transfer := &model.Transaction{
+		ID:          "0e7b3d52-8a61-4f2c-b9d4-13c5e8a7f260",
+		Amount:      2500,
+		ProcessedAt: processedAtGte.Add(time.Hour),
+		SenderWallet: &model.Wallet{
+			ID:      walletId,
+			Balance: 7500,
+		},
+		RecipientWallet: model.Wallet{
+			ID:      counterpartyId,
+			Balance: 2500,
+		},
+		OperationType: model.Transfer,
+		Reference:     "invoice-2022-01-10-customer-0042-payment-of-january",
+	}
+	walletService.On(
+		"GetTransactions", -1, -1, model.TransactionFilter{
+			WalletId:       walletId,
+			ProcessedAtGte: processedAtGte,
+			ProcessedAtLte: processedAtLte,
+		},
+	).Return([]*model.Transaction{transfer}, nil)
+	walletService.On(
+		"GetTransactions", 1, 0, model.TransactionFilter{
+			WalletId:       walletId,
+			ProcessedAtLte: processedAtGte.Add(-time.Nanosecond),
+		},
+	).Return([]*model.Transaction{}, nil)
+	walletService.On(
+		"GetTransactions", 1, 0, model.TransactionFilter{
+			WalletId:       walletId,
+			ProcessedAtLte: processedAtLte,
+		},
+	).Return([]*model.Transaction{transfer}, nil)
+	walletService.On("GetWallet", walletId).Return(
+		&model.Wallet{ID: walletId, Name: "wallet", Currency: "USD", Balance: 7500}, nil,
+	)
+	serve := func(accept string) []byte {
+		req, err := http.NewRequest(
+			"GET",
+			"/wallets/"+walletId+"/transactions?processed_at.gte=2022-01-10T00:00:00Z&processed_at.lte=2022-01-10T23:59:59Z",
+			nil,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", accept)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		return recorder.Body.Bytes()
+	}
+
+	// when
+	camt053 := serve("application/xml")
+	ofx := serve("application/x-ofx")
+
+	// then
+	camt053Body := struct {
+		MsgId string `xml:"BkToCstmrStmt>GrpHdr>MsgId"`
+		Stmt  struct {
+			Id     string `xml:"Id"`
+			AcctId string `xml:"Acct>Id>Othr>Id"`
+			Ntry   []struct {
+				NtryRef     string `xml:"NtryRef"`
+				AcctSvcrRef string `xml:"AcctSvcrRef"`
+				RefsRef     string `xml:"NtryDtls>TxDtls>Refs>AcctSvcrRef"`
+				EndToEndId  string `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+				CdtrAcctId  string `xml:"NtryDtls>TxDtls>RltdPties>CdtrAcct>Id>Othr>Id"`
+			} `xml:"Ntry"`
+		} `xml:"BkToCstmrStmt>Stmt"`
+	}{}
+	require.NoError(t, xml.Unmarshal(camt053, &camt053Body))
+	assert.LessOrEqual(t, len(camt053Body.MsgId), 35)
+	assert.LessOrEqual(t, len(camt053Body.Stmt.Id), 35)
+	assert.Equal(t, "5b0f8f8e3c1e4a8d9f552d1a7c9e4b61", camt053Body.Stmt.AcctId)
+	require.Len(t, camt053Body.Stmt.Ntry, 1)
+	ntry := camt053Body.Stmt.Ntry[0]
+	assert.Equal(t, "0e7b3d528a614f2cb9d413c5e8a7f260", ntry.NtryRef)
+	assert.Equal(t, ntry.NtryRef, ntry.AcctSvcrRef)
+	assert.Equal(t, ntry.NtryRef, ntry.RefsRef)
+	assert.Len(t, ntry.EndToEndId, 35)
+	assert.Equal(t, "9d2c6a417f0b4e3a8c19b64e52f0a7d3", ntry.CdtrAcctId)
+	ofxBody := struct {
+		StmtTrnRs struct {
+			TrnUid string `xml:"TRNUID"`
+			BankId string `xml:"STMTRS>BANKACCTFROM>BANKID"`
+			AcctId string `xml:"STMTRS>BANKACCTFROM>ACCTID"`
+			Trn    []struct {
+				Name   string `xml:"NAME"`
+				RefNum string `xml:"REFNUM"`
+			} `xml:"STMTRS>BANKTRANLIST>STMTTRN"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS"`
+	}{}
+	require.NoError(t, xml.Unmarshal(ofx, &ofxBody))
+	assert.LessOrEqual(t, len(ofxBody.StmtTrnRs.TrnUid), 36)
+	assert.LessOrEqual(t, len(ofxBody.StmtTrnRs.BankId), 9)
+	assert.Len(t, ofxBody.StmtTrnRs.AcctId, 22)
+	require.Len(t, ofxBody.StmtTrnRs.Trn, 1)
+	assert.Equal(t, "9d2c6a417f0b4e3a8c19b64e52f0a7d3", ofxBody.StmtTrnRs.Trn[0].Name)
+	assert.Equal(t, "0e7b3d528a614f2cb9d413c5e8a7f260", ofxBody.StmtTrnRs.Trn[0].RefNum)
+
+	walletService.AssertExpectations(t)
+}
+
+func TestGetTransactionsOfxWithoutEntries(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	req, err := http.NewRequest(
+		"GET",
+		"/wallets/1001/transactions?processed_at.gte=2022-01-10T00:00:00Z&processed_at.lte=2022-01-10T23:59:59Z",
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/html;q=0.9, application/x-ofx")
+	recorder := httptest.NewRecorder()
+	processedAtGte, err := time.Parse(time.RFC3339Nano, "2022-01-10T00:00:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	processedAtLte, err := time.Parse(time.RFC3339Nano, "2022-01-10T23:59:59Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletService.On(
+		"GetTransactions", -1, -1, model.TransactionFilter{
+			WalletId:       "1001",
+			ProcessedAtGte: processedAtGte,
+			ProcessedAtLte: processedAtLte,
+		},
+	).Return([]*model.Transaction{}, nil)
+	previous := []*model.Transaction{
+		{
+			ID:          "tx-1",
+			Amount:      10000,
+			ProcessedAt: processedAtGte.Add(-time.Hour),
+			RecipientWallet: model.Wallet{
+				ID:      "1001",
+				Balance: 10000,
+			},
+			OperationType: model.Deposit,
+		},
+	}
+	walletService.On(
+		"GetTransactions", 1, 0, model.TransactionFilter{
+			WalletId:       "1001",
+			ProcessedAtLte: processedAtGte.Add(-time.Nanosecond),
+		},
+	).Return(previous, nil)
+	walletService.On(
+		"GetTransactions", 1, 0, model.TransactionFilter{
+			WalletId:       "1001",
+			ProcessedAtLte: processedAtLte,
+		},
+	).Return(previous, nil)
+	walletService.On("GetWallet", "1001").Return(
+		&model.Wallet{ID: "1001", Name: "wallet", Currency: "USD", Balance: 10000}, nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	assert.Equal(t, "application/x-ofx", recorder.Header().Get("Content-Type"))
+	respBody := struct {
+		StmtRs struct {
+			LedgerBal string   `xml:"LEDGERBAL>BALAMT"`
+			BalList   []string `xml:"BALLIST>BAL>VALUE"`
+			StmtTrn   []string `xml:"BANKTRANLIST>STMTTRN>FITID"`
+		} `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS"`
+	}{}
+	if err := xml.Unmarshal(recorder.Body.Bytes(), &respBody); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "100.00", respBody.StmtRs.LedgerBal)
+	assert.Equal(t, []string{"100.00", "100.00"}, respBody.StmtRs.BalList)
+	assert.Empty(t, respBody.StmtRs.StmtTrn)
+
+	walletService.AssertNumberOfCalls(t, "GetTransactions", 3)
+	walletService.AssertExpectations(t)
+}
+
+func TestGetTransactionsOfxCurrencyMinorUnits(t *testing.T) {
+	tests := []struct {
+		currency        string
+		expectedBalance string
+	}{
+		{currency: "JPY", expectedBalance: "10000"},
+		{currency: "KWD", expectedBalance: "10.000"},
+		{currency: "EUR", expectedBalance: "100.00"},
+	}
+	for _, test := range tests {
+		t.Run(test.currency, func(t *testing.T) {
+			// given
+			walletService := new(walletServiceMock)
+			router := mux.NewRouter()
+			NewWalletsApi(logger, router, walletService, NewExportConfig())
+			req := httptest.NewRequest("GET", "/wallets/1001/transactions?processed_at.lte=2022-01-10T23:59:59Z", nil)
+			req.Header.Set("Accept", "application/x-ofx")
+			recorder := httptest.NewRecorder()
+			processedAtLte, err := time.Parse(time.RFC3339Nano, "2022-01-10T23:59:59Z")
+			if err != nil {
+				t.Fatal(err)
+			}
+			deposit := &model.Transaction{
+				ID:              "tx-1",
+				Amount:          10000,
+				ProcessedAt:     processedAtLte.Add(-time.Hour),
+				RecipientWallet: model.Wallet{ID: "1001", Balance: 10000},
+				OperationType:   model.Deposit,
+			}
+			walletService.On(
+				"GetTransactions", -1, -1, model.TransactionFilter{WalletId: "1001", ProcessedAtLte: processedAtLte},
+			).Return([]*model.Transaction{deposit}, nil)
+			walletService.On(
+				"GetTransactions", 1, 0, model.TransactionFilter{WalletId: "1001", ProcessedAtLte: processedAtLte},
+			).Return([]*model.Transaction{deposit}, nil)
+			walletService.On("GetWallet", "1001").Return(
+				&model.Wallet{ID: "1001", Name: "wallet", Currency: test.currency, Balance: 10000}, nil,
+			)
+
+			// when
+			router.ServeHTTP(recorder, req)
+
+			// then
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			respBody := struct {
+				LedgerBal string `xml:"BANKMSGSRSV1>STMTTRNRS>STMTRS>LEDGERBAL>BALAMT"`
+			}{}
+			if err := xml.Unmarshal(recorder.Body.Bytes(), &respBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expectedBalance, respBody.LedgerBal)
+		})
+	}
+}
+
+func TestGetTransactionsStatementUnknownCurrency(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	req := httptest.NewRequest("GET", "/wallets/1001/transactions", nil)
+	req.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+	walletService.On("GetWallet", "1001").Return(
+		&model.Wallet{ID: "1001", Name: "wallet", Currency: "XAU", Balance: 10000}, nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+	walletService.AssertNumberOfCalls(t, "GetTransactions", 0)
+}
+
+func TestGetTransactionsStatementPagination(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	req := httptest.NewRequest("GET", "/wallets/1001/transactions?limit=10&offset=10", nil)
+	req.Header.Set("Accept", "application/xml")
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	walletService.AssertNumberOfCalls(t, "GetWallet", 0)
+	walletService.AssertNumberOfCalls(t, "GetTransactions", 0)
+}
+
+func TestGetTransactionsNotAcceptable(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	req, err := http.NewRequest("GET", "/wallets/1001/transactions", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/html, application/json;q=0")
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusNotAcceptable {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotAcceptable)
+	}
+
+	walletService.AssertNumberOfCalls(t, "GetTransactions", 0)
+	walletService.AssertExpectations(t)
+}
+
+func TestGetTransactionsCsvProfile(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+
+	null := ""
+	exportConfig := NewExportConfig()
+	exportConfig.CsvProfiles["excel-eu"] = dto.CsvProfile{
+		Delimiter:        "semicolon",
+		Null:             &null,
+		TimeFormat:       "02.01.2006 15:04",
+		Timezone:         "Europe/Berlin",
+		Amounts:          dto.AmountsMajorUnits,
+		DecimalSeparator: ",",
+	}
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, exportConfig)
+	req, err := http.NewRequest(
+		"GET",
+		"/wallets/1001/transactions?csv.profile=excel-eu&csv.columns=ProcessedAt,Amount,SenderWalletId,Balance",
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/csv")
+	recorder := httptest.NewRecorder()
+	processedAt, err := time.Parse(time.RFC3339Nano, "2022-01-10T10:30:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletService.On(
+		"GetTransactions", -1, -1, model.TransactionFilter{WalletId: "1001"},
+	).Return(
+		[]*model.Transaction{
+			{
+				Amount:      10050,
+				ProcessedAt: processedAt,
+				RecipientWallet: model.Wallet{
+					ID:      "1001",
+					Balance: 120005,
+				},
+				OperationType: model.Deposit,
+			},
+		}, nil,
+	)
+	walletService.On("GetWallet", "1001").Return(
+		&model.Wallet{ID: "1001", Name: "wallet", Currency: "USD", Balance: 120005}, nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	assert.Equal(
+		t,
+		"ProcessedAt;Amount;SenderWalletId;Balance\n10.01.2022 11:30;100,50;;1200,05\n",
+		recorder.Body.String(),
+	)
+
+	walletService.AssertExpectations(t)
+}
+
+func TestGetTransactionsCsvHeaders(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+
+	exportConfig := NewExportConfig()
+	exportConfig.CsvProfiles["accounting"] = dto.CsvProfile{
+		Columns: []string{"ProcessedAt", "OperationType", "Amount"},
+		Headers: map[string]string{"ProcessedAt": "Date", "OperationType": "Type"},
+	}
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, exportConfig)
+	req, err := http.NewRequest(
+		"GET",
+		"/wallets/1001/transactions?csv.profile=accounting&csv.headers=Amount:Sum,OperationType:Operation",
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/csv")
+	recorder := httptest.NewRecorder()
+	processedAt, err := time.Parse(time.RFC3339Nano, "2022-01-10T10:30:00Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletService.On(
+		"GetTransactions", -1, -1, model.TransactionFilter{WalletId: "1001"},
+	).Return(
+		[]*model.Transaction{
+			{
+				Amount:      10050,
+				ProcessedAt: processedAt,
+				RecipientWallet: model.Wallet{
+					ID:      "1001",
+					Balance: 10050,
+				},
+				OperationType: model.Deposit,
+			},
+		}, nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	assert.Equal(
+		t,
+		"Date,Operation,Sum\n2022-01-10T10:30:00Z,deposit,10050\n",
+		recorder.Body.String(),
+	)
+
+	walletService.AssertExpectations(t)
+}
+
+func TestGetTransactionsCsvInvalidHeader(t *testing.T) {
+	tests := []struct {
+		name            string
+		headers         string
+		expectedMessage string
+	}{
+		{name: "unknown column", headers: "Unknown:Sum", expectedMessage: "unknown csv column: Unknown"},
+		{name: "empty header", headers: "Amount:", expectedMessage: "empty csv header: Amount"},
+		{name: "missing separator", headers: "Amount", expectedMessage: "invalid csv header: Amount"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			walletService := new(walletServiceMock)
+			router := mux.NewRouter()
+			NewWalletsApi(logger, router, walletService, NewExportConfig())
+			req := httptest.NewRequest("GET", "/wallets/1001/transactions?csv.headers="+test.headers, nil)
+			req.Header.Set("Accept", "text/csv")
+			recorder := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(recorder, req)
+
+			// then
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			respBody := new(dto.ErrorResponse)
+			if err := json.Unmarshal(recorder.Body.Bytes(), respBody); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expectedMessage, respBody.Message)
+			walletService.AssertNumberOfCalls(t, "GetTransactions", 0)
+		})
+	}
+}
+
+func TestGetTransactionsCsvInvalidColumn(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+
+	router := mux.NewRouter()
+	NewWalletsApi(logger, router, walletService, NewExportConfig())
+	req, err := http.NewRequest("GET", "/wallets/1001/transactions?csv.columns=Amount,Unknown", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/csv")
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	if status := recorder.Code; status != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+	}
+	respBody := new(dto.ErrorResponse)
+	if err := json.Unmarshal(recorder.Body.Bytes(), respBody); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "unknown csv column: Unknown", respBody.Message)
+
+	walletService.AssertNumberOfCalls(t, "GetTransactions", 0)
+	walletService.AssertExpectations(t)
+}
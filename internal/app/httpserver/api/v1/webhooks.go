@@ -0,0 +1,251 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/gorilla/mux"
+)
+
+type webhooksApi struct {
+	logger         *logging.Logger
+	webhookService service.WebhookService
+}
+
+func NewWebhooksApi(logger *logging.Logger, router *mux.Router, webhookService service.WebhookService) {
+	webhooksApi := &webhooksApi{
+		logger:         logger,
+		webhookService: webhookService,
+	}
+	router.HandleFunc("/webhooks", webhooksApi.CreateWebhook).Methods(http.MethodPost)
+	router.HandleFunc("/webhooks", webhooksApi.GetWebhooks).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id}", webhooksApi.DeleteWebhook).Methods(http.MethodDelete)
+	router.HandleFunc("/webhooks/{id}/deliveries", webhooksApi.GetWebhookDeliveries).Methods(http.MethodGet)
+	router.HandleFunc("/webhooks/{id}/deliveries/{deliveryId}/replay", webhooksApi.ReplayWebhookDelivery).Methods(http.MethodPost)
+	router.HandleFunc("/webhooks/{id}/replay", webhooksApi.ReplayWebhook).Methods(http.MethodPost)
+}
+
+// swagger:route POST /webhooks WebhooksAPI createWebhook
+// Subscribe an URL to wallet events, the response contains the signing secret
+//
+// consumes:
+//	- application/json
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: webhookResponse
+//  400: errorResponse
+//  500: errorResponse
+func (webhooksApi *webhooksApi) CreateWebhook(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	var reqData dto.CreateWebhookRequest
+	if err := reqData.FromJson(req.Body); err != nil {
+		webhooksApi.logger.Warn(req.Context(), "webhooksApi - CreateWebhook - reqData.FromJson", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := reqData.Validate(); err != nil {
+		webhooksApi.logger.Warn(req.Context(), "webhooksApi - CreateWebhook - reqData.Validate", logging.Err(err))
+		writeError(rw, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	subscription := model.WebhookSubscription{
+		Url:    reqData.Url,
+		Secret: reqData.Secret,
+	}
+	for _, value := range reqData.EventTypes {
+		eventType, err := model.EventTypeFromString(value)
+		if err != nil {
+			webhooksApi.logger.Warn(req.Context(), "webhooksApi - CreateWebhook - model.EventTypeFromString", logging.Err(err))
+			writeError(rw, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		subscription.EventTypes = append(subscription.EventTypes, eventType)
+	}
+	createdSubscription, err := webhooksApi.webhookService.CreateSubscription(subscription)
+	if err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - CreateWebhook - webhooksApi.webhookService.CreateSubscription", logging.Err(err))
+		writeError(rw, "unable to create webhook", http.StatusInternalServerError)
+		return
+	}
+	respData := toWebhookResponse(createdSubscription)
+	respData.Secret = createdSubscription.Secret
+	if err = respData.ToJson(rw); err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - CreateWebhook - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route GET /webhooks WebhooksAPI getWebhooks
+// Return a list of webhook subscriptions
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: webhooksResponse
+//  500: errorResponse
+func (webhooksApi *webhooksApi) GetWebhooks(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	subscriptions, err := webhooksApi.webhookService.GetSubscriptions()
+	if err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - GetWebhooks - webhooksApi.webhookService.GetSubscriptions", logging.Err(err))
+		writeError(rw, "unable to get webhooks", http.StatusInternalServerError)
+		return
+	}
+	var respData dto.WebhooksResponse = make([]*dto.WebhookResponse, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		respData = append(respData, toWebhookResponse(subscription))
+	}
+	if err = respData.ToJson(rw); err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - GetWebhooks - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route DELETE /webhooks/{id} WebhooksAPI deleteWebhook
+// Unsubscribe a webhook, pending deliveries are kept for replay
+//
+// responses:
+//	204:
+//  404: errorResponse
+//  500: errorResponse
+func (webhooksApi *webhooksApi) DeleteWebhook(rw http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)["id"]
+	if err := webhooksApi.webhookService.DeleteSubscription(id); err != nil {
+		webhooksApi.logger.Log(req.Context(), errorLevel(err), "webhooksApi - DeleteWebhook - webhooksApi.webhookService.DeleteSubscription", logging.Err(err))
+		if errors.Is(err, model.ErrWebhookNotFound) {
+			writeError(rw, "webhook not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to delete webhook", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// swagger:route GET /webhooks/{id}/deliveries WebhooksAPI getWebhookDeliveries
+// Return a list of webhook deliveries, newest events first
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	200: webhookDeliveriesResponse
+//  400: errorResponse
+//  500: errorResponse
+func (webhooksApi *webhooksApi) GetWebhookDeliveries(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(req)["id"]
+	limit, offset, err := getPagination(req)
+	if err != nil {
+		webhooksApi.logger.Warn(req.Context(), "webhooksApi - GetWebhookDeliveries - getPagination", logging.Err(err))
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	status := model.UnknownWebhookDeliveryStatus
+	if value := req.URL.Query().Get("status"); value != "" {
+		if status, err = model.WebhookDeliveryStatusFromString(value); err != nil {
+			webhooksApi.logger.Warn(req.Context(), "webhooksApi - GetWebhookDeliveries - model.WebhookDeliveryStatusFromString", logging.Err(err))
+			writeError(rw, "invalid query parameter status", http.StatusBadRequest)
+			return
+		}
+	}
+	deliveries, err := webhooksApi.webhookService.GetDeliveries(id, status, limit, offset)
+	if err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - GetWebhookDeliveries - webhooksApi.webhookService.GetDeliveries", logging.Err(err))
+		writeError(rw, "unable to get webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	var respData dto.WebhookDeliveriesResponse = make([]*dto.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		respItem := &dto.WebhookDeliveryResponse{
+			ID:          delivery.ID,
+			EventId:     delivery.Event.ID,
+			EventType:   delivery.Event.Type.String(),
+			Event:       delivery.Event.Payload,
+			Status:      delivery.Status.String(),
+			Attempts:    delivery.Attempts,
+			LastError:   delivery.LastError,
+			DeliveredAt: delivery.DeliveredAt,
+			CreatedAt:   delivery.Event.CreatedAt,
+		}
+		if delivery.Status == model.WebhookDeliveryPending {
+			nextAttemptAt := delivery.NextAttemptAt
+			respItem.NextAttemptAt = &nextAttemptAt
+		}
+		respData = append(respData, respItem)
+	}
+	if err = respData.ToJson(rw); err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - GetWebhookDeliveries - respData.ToJson", logging.Err(err))
+		writeError(rw, "internal error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// swagger:route POST /webhooks/{id}/deliveries/{deliveryId}/replay WebhooksAPI replayWebhookDelivery
+// Deliver the event of a webhook delivery again
+//
+// responses:
+//	202:
+//  404: errorResponse
+//  500: errorResponse
+func (webhooksApi *webhooksApi) ReplayWebhookDelivery(rw http.ResponseWriter, req *http.Request) {
+	vars := mux.Vars(req)
+	if err := webhooksApi.webhookService.ReplayDelivery(vars["id"], vars["deliveryId"]); err != nil {
+		webhooksApi.logger.Log(req.Context(), errorLevel(err), "webhooksApi - ReplayWebhookDelivery - webhooksApi.webhookService.ReplayDelivery", logging.Err(err))
+		if errors.Is(err, model.ErrDeliveryNotFound) {
+			writeError(rw, "webhook delivery not found", http.StatusNotFound)
+			return
+		}
+		writeError(rw, "unable to replay webhook delivery", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusAccepted)
+}
+
+// swagger:route POST /webhooks/{id}/replay WebhooksAPI replayWebhook
+// Deliver all dead deliveries of a webhook again
+//
+// produces:
+// 	- application/json
+//
+// responses:
+//	202: replayWebhookResponse
+//  500: errorResponse
+func (webhooksApi *webhooksApi) ReplayWebhook(rw http.ResponseWriter, req *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+	id := mux.Vars(req)["id"]
+	replayed, err := webhooksApi.webhookService.ReplayDeadDeliveries(id)
+	if err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - ReplayWebhook - webhooksApi.webhookService.ReplayDeadDeliveries", logging.Err(err))
+		writeError(rw, "unable to replay webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+	rw.WriteHeader(http.StatusAccepted)
+	respData := dto.ReplayWebhookResponse{Replayed: replayed}
+	if err = respData.ToJson(rw); err != nil {
+		webhooksApi.logger.Error(req.Context(), "webhooksApi - ReplayWebhook - respData.ToJson", logging.Err(err))
+		return
+	}
+}
+
+func toWebhookResponse(subscription *model.WebhookSubscription) *dto.WebhookResponse {
+	respData := &dto.WebhookResponse{
+		ID:         subscription.ID,
+		Url:        subscription.Url,
+		EventTypes: make([]string, 0, len(subscription.EventTypes)),
+		CreatedAt:  subscription.CreatedAt,
+	}
+	for _, eventType := range subscription.EventTypes {
+		respData.EventTypes = append(respData.EventTypes, eventType.String())
+	}
+	return respData
+}
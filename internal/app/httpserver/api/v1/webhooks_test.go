@@ -0,0 +1,150 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type webhookServiceMock struct {
+	mock.Mock
+}
+
+func (webhookService *webhookServiceMock) CreateSubscription(subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	args := webhookService.Called(subscription)
+	return args.Get(0).(*model.WebhookSubscription), args.Error(1)
+}
+
+func (webhookService *webhookServiceMock) GetSubscriptions() ([]*model.WebhookSubscription, error) {
+	args := webhookService.Called()
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+func (webhookService *webhookServiceMock) DeleteSubscription(id string) error {
+	args := webhookService.Called(id)
+	return args.Error(0)
+}
+
+func (webhookService *webhookServiceMock) GetDeliveries(
+	subscriptionId string, status model.WebhookDeliveryStatus, limit int, offset int,
+) ([]*model.WebhookDelivery, error) {
+	args := webhookService.Called(subscriptionId, status, limit, offset)
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (webhookService *webhookServiceMock) ReplayDelivery(subscriptionId string, id string) error {
+	args := webhookService.Called(subscriptionId, id)
+	return args.Error(0)
+}
+
+func (webhookService *webhookServiceMock) ReplayDeadDeliveries(subscriptionId string) (int, error) {
+	args := webhookService.Called(subscriptionId)
+	return args.Int(0), args.Error(1)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	// given
+	webhookService := new(webhookServiceMock)
+
+	reqBody := `{"url":"https://example.com/hooks","event_types":["transaction.deposit"]}`
+	router := mux.NewRouter()
+	NewWebhooksApi(logger, router, webhookService)
+	req, err := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	webhookService.On(
+		"CreateSubscription",
+		model.WebhookSubscription{
+			Url:        "https://example.com/hooks",
+			EventTypes: []model.EventType{model.DepositEvent},
+		},
+	).Return(
+		&model.WebhookSubscription{
+			ID:         "1001",
+			Url:        "https://example.com/hooks",
+			Secret:     "generated-secret",
+			EventTypes: []model.EventType{model.DepositEvent},
+			Active:     true,
+			CreatedAt:  createdAt,
+		},
+		nil,
+	)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var respBody dto.WebhookResponse
+	if err = json.NewDecoder(recorder.Body).Decode(&respBody); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, dto.WebhookResponse{
+		ID:         "1001",
+		Url:        "https://example.com/hooks",
+		Secret:     "generated-secret",
+		EventTypes: []string{"transaction.deposit"},
+		CreatedAt:  createdAt,
+	}, respBody)
+
+	webhookService.AssertExpectations(t)
+}
+
+func TestCreateWebhookInvalidEventType(t *testing.T) {
+	// given
+	webhookService := new(webhookServiceMock)
+
+	reqBody := `{"url":"https://example.com/hooks","event_types":["wallet.created"]}`
+	router := mux.NewRouter()
+	NewWebhooksApi(logger, router, webhookService)
+	req, err := http.NewRequest("POST", "/webhooks", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	webhookService.AssertExpectations(t)
+}
+
+func TestReplayWebhookDeliveryNotFound(t *testing.T) {
+	// given
+	webhookService := new(webhookServiceMock)
+
+	router := mux.NewRouter()
+	NewWebhooksApi(logger, router, webhookService)
+	req, err := http.NewRequest("POST", "/webhooks/1001/deliveries/3001/replay", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorder := httptest.NewRecorder()
+
+	webhookService.On("ReplayDelivery", "1001", "3001").Return(model.ErrDeliveryNotFound)
+
+	// when
+	router.ServeHTTP(recorder, req)
+
+	// then
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	webhookService.AssertExpectations(t)
+}
@@ -1,50 +1,384 @@
 package httpserver
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"os"
 
 	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api"
+	apiv1 "github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1"
 	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/config"
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/rpc/v1"
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/consolidation"
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/SergeyChupin/wallets-api/internal/database/migrations"
 	"github.com/SergeyChupin/wallets-api/internal/database/postgres"
+	"github.com/SergeyChupin/wallets-api/internal/database/sqlite"
+	"github.com/SergeyChupin/wallets-api/internal/events"
+	"github.com/SergeyChupin/wallets-api/internal/grpcserver"
+	"github.com/SergeyChupin/wallets-api/internal/health"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/metrics"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/ratelimit"
 	"github.com/SergeyChupin/wallets-api/internal/repository"
 	"github.com/SergeyChupin/wallets-api/internal/server"
 	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/SergeyChupin/wallets-api/internal/tracing"
+	"github.com/SergeyChupin/wallets-api/internal/webhook"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
 )
 
 func Run(configPath string) {
-	logger := log.New(os.Stdout, "wallets-api ", log.LstdFlags)
+	cfg, logger := loadConfig(configPath)
 
-	configLoader := config.NewLoader(configPath)
-	cfg, err := configLoader.Load()
-	if err != nil {
-		logger.Fatal(err)
+	// Without a tracer provider the spans are not recorded, but the trace
+	// context of the requests is still propagated.
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	var tracerProvider *sdktrace.TracerProvider
+	if cfg.Tracing.Enabled {
+		var err error
+		tracerProvider, err = tracing.NewTracerProvider(cfg.Tracing)
+		if err != nil {
+			logger.Fatal("Run - tracing.NewTracerProvider", logging.Err(err))
+		}
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			logger.Warn(context.Background(), "Run - tracing", logging.Err(err))
+		}))
+		otel.SetTracerProvider(tracerProvider)
 	}
 
-	db, err := postgres.Open(logger, cfg.Postgres)
-	if err != nil {
-		logger.Fatal(err)
+	var walletRepository repository.WalletRepository
+	var apiKeyRepository repository.ApiKeyRepository
+	var ownerRepository repository.OwnerRepository
+	var auditRepository repository.AuditRepository
+	var transactionListener repository.TransactionListener
+	var depositImportService service.DepositImportService
+	var webhookService service.WebhookService
+	var webhookDispatcher webhook.Dispatcher
+	var consolidator consolidation.Consolidator
+	var poolStats []func() database.PoolStats
+	healthChecker := health.NewChecker(cfg.Health)
+	switch cfg.Storage {
+	case config.MemoryStorage:
+		// Deposit imports and webhooks are stored in Postgres only.
+		logger.Warn(context.Background(), "Using in-memory storage, data is lost on shutdown")
+		memoryAuditRepository := repository.NewMemoryAuditRepository()
+		memoryWalletRepository := repository.NewMemoryWalletRepository().WithAuditLog(memoryAuditRepository)
+		walletRepository = memoryWalletRepository
+		transactionListener = memoryWalletRepository
+		apiKeyRepository = repository.NewMemoryApiKeyRepository()
+		ownerRepository = repository.NewMemoryOwnerRepository()
+		auditRepository = memoryAuditRepository
+	case config.PostgresStorage:
+		db, dialect, err := openDatabase(logger, cfg)
+		if err != nil {
+			logger.Fatal("Run - openDatabase", logging.Err(err))
+		}
+		defer func() {
+			_ = db.Close()
+		}()
+		if err = prepareSchema(logger, db, dialect, cfg.Migrations); err != nil {
+			logger.Fatal("Run - prepareSchema", logging.Err(err))
+		}
+
+		healthChecker.
+			AddCheck("postgres", db.PingContext).
+			AddCheck("schema", migrations.NewMigrator(logger, db, dialect).Check)
+		poolStats = append(poolStats, func() database.PoolStats {
+			return database.SqlPoolStats("postgres", db)
+		})
+
+		var replicas repository.Replicas
+		var walletShardRepository repository.WalletShardRepository
+		if len(cfg.Postgres.ReplicaUrls) > 0 {
+			postgresReplicas, err := postgres.OpenReplicas(logger, cfg.Postgres)
+			if err != nil {
+				logger.Fatal("Run - postgres.OpenReplicas", logging.Err(err))
+			}
+			defer postgresReplicas.Close()
+			postgresReplicas.Start()
+			defer postgresReplicas.Stop()
+			for i, replica := range postgresReplicas.DBs() {
+				name, replica := fmt.Sprintf("postgres-replica-%d", i), replica
+				poolStats = append(poolStats, func() database.PoolStats {
+					return database.SqlPoolStats(name, replica)
+				})
+			}
+			replicas = postgresReplicas
+		}
+
+		switch cfg.Postgres.Pool {
+		case postgres.DatabaseSqlPool:
+			postgresWalletRepository := repository.NewWalletRepository(db)
+			if replicas != nil {
+				postgresWalletRepository.WithReplicas(replicas)
+			}
+			walletRepository = postgresWalletRepository
+			walletShardRepository = postgresWalletRepository
+		case postgres.PgxPool:
+			pool, err := postgres.OpenPool(logger, cfg.Postgres)
+			if err != nil {
+				logger.Fatal("Run - postgres.OpenPool", logging.Err(err))
+			}
+			defer pool.Close()
+			healthChecker.AddCheck("postgres-pgxpool", pool.Ping)
+			poolStats = append(poolStats, func() database.PoolStats {
+				return database.PgxPoolStats("postgres-pgxpool", pool)
+			})
+			pgxWalletRepository := repository.NewPgxWalletRepository(pool)
+			if replicas != nil {
+				pgxWalletRepository.WithReplicas(replicas)
+			}
+			walletRepository = pgxWalletRepository
+			walletShardRepository = pgxWalletRepository
+		default:
+			logger.Fatal("Run - unknown postgres pool", logging.String("pool", cfg.Postgres.Pool))
+		}
+		if cfg.Consolidation.Enabled {
+			consolidator = consolidation.NewConsolidator(logger, walletShardRepository, cfg.Consolidation)
+			consolidator.Start()
+		}
+		transactionListener = repository.NewTransactionListener(db)
+		apiKeyRepository = repository.NewApiKeyRepository(db)
+		ownerRepository = repository.NewOwnerRepository(db)
+		auditRepository = repository.NewAuditRepository(db)
+		depositImportRepository := repository.NewDepositImportRepository(db)
+		depositImportService = service.NewDepositImportService(logger, depositImportRepository, cfg.DepositImport)
+		depositImportService.Start()
+
+		webhookRepository := repository.NewWebhookRepository(db)
+		webhookService = service.NewWebhookService(webhookRepository)
+		if cfg.Webhook.Enabled {
+			webhookDispatcher = webhook.NewDispatcher(logger, webhookRepository, nil, cfg.Webhook)
+			webhookDispatcher.Start()
+		}
+	case config.SqliteStorage:
+		// Deposit imports and webhooks are stored in Postgres only, the events
+		// of transactions committed by other processes are not published.
+		db, dialect, err := openDatabase(logger, cfg)
+		if err != nil {
+			logger.Fatal("Run - openDatabase", logging.Err(err))
+		}
+		defer func() {
+			_ = db.Close()
+		}()
+		if err = prepareSchema(logger, db, dialect, cfg.Migrations); err != nil {
+			logger.Fatal("Run - prepareSchema", logging.Err(err))
+		}
+
+		healthChecker.
+			AddCheck("sqlite", db.PingContext).
+			AddCheck("schema", migrations.NewMigrator(logger, db, dialect).Check)
+		poolStats = append(poolStats, func() database.PoolStats {
+			return database.SqlPoolStats("sqlite", db)
+		})
+		sqliteWalletRepository := repository.NewSqliteWalletRepository(db)
+		walletRepository = sqliteWalletRepository
+		transactionListener = sqliteWalletRepository
+		apiKeyRepository = repository.NewSqliteApiKeyRepository(db)
+		ownerRepository = repository.NewSqliteOwnerRepository(db)
+		auditRepository = repository.NewSqliteAuditRepository(db)
+	default:
+		logger.Fatal("Run - unknown storage", logging.String("storage", cfg.Storage))
+	}
+	allPoolStats := func() []database.PoolStats {
+		stats := make([]database.PoolStats, 0, len(poolStats))
+		for _, poolStat := range poolStats {
+			stats = append(stats, poolStat())
+		}
+		return stats
+	}
+	var metricsRegistry *prometheus.Registry
+	var walletService service.WalletService = service.NewWalletService(walletRepository)
+	if cfg.Metrics.Enabled {
+		metricsRegistry = prometheus.NewRegistry()
+		metricsRegistry.MustRegister(
+			prometheus.NewGoCollector(),
+			prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+			metrics.NewPoolStatsCollector(allPoolStats),
+		)
+		walletMetrics := metrics.NewWalletMetrics(metricsRegistry)
+		walletService = service.NewInstrumentedWalletService(
+			service.NewWalletService(walletRepository).WithRetryObserver(walletMetrics.ObserveRetry),
+			service.NewWalletCurrencySource(walletRepository),
+			walletMetrics,
+		)
+	}
+	walletService = service.NewTracedWalletService(walletService)
+	ownerService := service.NewOwnerService(ownerRepository, walletRepository, walletService)
+	apiKeyService := service.NewApiKeyService(apiKeyRepository)
+	auditService := service.NewAuditService(auditRepository)
+	if cfg.Storage == config.MemoryStorage && cfg.Auth.Enabled {
+		// Nothing can issue the first key of an empty in-memory storage.
+		_, key, err := apiKeyService.IssueApiKey(context.Background(), "bootstrap", []model.Scope{model.AdminScope})
+		if err != nil {
+			logger.Fatal("Run - apiKeyService.IssueApiKey", logging.Err(err))
+		}
+		// The key is printed once outside the logger, log lines are shipped and
+		// retained where a secret does not belong.
+		logger.Info(context.Background(), "Issued admin API key for in-memory storage, the key is printed to stderr")
+		fmt.Fprintf(os.Stderr, "Admin API key for in-memory storage: %s\n", key)
+	}
+	var tokenVerifier auth.TokenVerifier
+	if cfg.Auth.Jwt.Jwks != "" {
+		jwtVerifier, err := auth.NewJwtVerifier(cfg.Auth.Jwt, nil)
+		if err != nil {
+			logger.Fatal("Run - auth.NewJwtVerifier", logging.Err(err))
+		}
+		tokenVerifier = jwtVerifier
 	}
-	defer func() {
-		_ = db.Close()
-	}()
 
-	walletRepository := repository.NewWalletRepository(db)
-	walletService := service.NewWalletService(walletRepository)
+	eventsBroker := events.NewBroker(logger, transactionListener, cfg.Events)
+	eventsBroker.Start()
 
-	handler := api.NewHandler(logger, walletService)
+	handler := api.NewHandler(logger, api.Dependencies{
+		WalletService:        walletService,
+		OwnerService:         ownerService,
+		DepositImportService: depositImportService,
+		WebhookService:       webhookService,
+		ApiKeyService:        apiKeyService,
+		AuditService:         auditService,
+		TokenVerifier:        tokenVerifier,
+		Broker:               eventsBroker,
+		ExportConfig:         cfg.Export,
+		EventsConfig:         cfg.Events,
+		AuthConfig:           cfg.Auth,
+		RateLimitConfig:      cfg.RateLimit,
+		PoolStats:            allPoolStats,
+		MetricsRegistry:      metricsRegistry,
+		MetricsConfig:        cfg.Metrics,
+		HealthChecker:        healthChecker,
+	})
 	srv := server.NewServer(logger, cfg.Server, handler)
+	srv.ExemptFromShedding(apiv1.LivenessPath, apiv1.ReadinessPath)
+	srv.RegisterOnDrain(healthChecker.Drain)
+	if cfg.Server.ShedOnPoolSaturation {
+		srv.ShedLoad(poolsSaturated(poolStats))
+	}
+	srv.RegisterOnShutdown(eventsBroker.Stop)
+
+	unaryInterceptors := []grpc.UnaryServerInterceptor{v1.UnaryRequestId, v1.UnaryTrace}
+	streamInterceptors := []grpc.StreamServerInterceptor{v1.StreamRequestId, v1.StreamTrace}
+	var grpcRateLimiter ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		// gRPC calls are limited by the buckets of their own.
+		grpcRateLimiter = ratelimit.NewLimiter(cfg.RateLimit)
+		unaryInterceptors = append(unaryInterceptors, v1.UnaryLimitIpRate(grpcRateLimiter))
+		streamInterceptors = append(streamInterceptors, v1.StreamLimitIpRate(grpcRateLimiter))
+	}
+	unaryInterceptors = append(unaryInterceptors, v1.UnaryAuthenticate(logger, apiKeyService, tokenVerifier, cfg.Auth))
+	streamInterceptors = append(streamInterceptors, v1.StreamAuthenticate(logger, apiKeyService, tokenVerifier, cfg.Auth))
+	if grpcRateLimiter != nil {
+		unaryInterceptors = append(unaryInterceptors, v1.UnaryLimitApiKeyRate(grpcRateLimiter))
+		streamInterceptors = append(streamInterceptors, v1.StreamLimitApiKeyRate(grpcRateLimiter))
+	}
+	unaryInterceptors = append(unaryInterceptors, v1.UnaryAudit(logger, auditService))
+	grpcSrv := grpcserver.NewServer(
+		logger, cfg.Grpc,
+		func(grpcServer *grpc.Server) {
+			v1.NewWalletsServer(logger, grpcServer, walletService)
+		},
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	if cfg.Grpc.ShedOnPoolSaturation {
+		grpcSrv.ShedLoad(poolsSaturated(poolStats))
+	}
 
 	go func() {
 		if err := srv.Start(); err != nil {
 			if err != http.ErrServerClosed {
-				logger.Fatal(err)
+				logger.Fatal("Run - srv.Start", logging.Err(err))
 			}
 		}
 	}()
 
+	if cfg.Grpc.Enabled {
+		go func() {
+			if err := grpcSrv.Start(); err != nil {
+				logger.Fatal("Run - grpcSrv.Start", logging.Err(err))
+			}
+		}()
+	}
+
 	if err := srv.GracefulShutdown(); err != nil {
-		logger.Fatal(err)
+		logger.Fatal("Run - srv.GracefulShutdown", logging.Err(err))
+	}
+	if cfg.Grpc.Enabled {
+		grpcSrv.GracefulShutdown()
+	}
+	if depositImportService != nil {
+		depositImportService.Shutdown()
+	}
+	if webhookDispatcher != nil {
+		webhookDispatcher.Stop()
+	}
+	if consolidator != nil {
+		consolidator.Stop()
+	}
+	if tracerProvider != nil {
+		if err := tracerProvider.Shutdown(context.Background()); err != nil {
+			logger.Error(context.Background(), "Run - tracerProvider.Shutdown", logging.Err(err))
+		}
+	}
+}
+
+// loadConfig loads the config and returns the logger it configures, errors
+// loading the config are logged by the default logger.
+func loadConfig(configPath string) (*config.Config, *logging.Logger) {
+	defaultLogger, _ := logging.New(os.Stdout, logging.NewConfig())
+	configLoader := config.NewLoader(configPath)
+	cfg, err := configLoader.Load()
+	if err != nil {
+		defaultLogger.Fatal("loadConfig - configLoader.Load", logging.Err(err))
+	}
+	logger, err := logging.New(os.Stdout, cfg.Log)
+	if err != nil {
+		defaultLogger.Fatal("loadConfig - logging.New", logging.Err(err))
+	}
+	return cfg, logger
+}
+
+// openDatabase opens the database of the configured storage.
+func openDatabase(logger *logging.Logger, cfg *config.Config) (*sql.DB, migrations.Dialect, error) {
+	switch cfg.Storage {
+	case config.PostgresStorage:
+		db, err := postgres.Open(logger, cfg.Postgres)
+		return db, migrations.Postgres, err
+	case config.SqliteStorage:
+		db, err := sqlite.Open(logger, cfg.Sqlite)
+		return db, migrations.Sqlite, err
+	}
+	return nil, migrations.Dialect{}, fmt.Errorf("storage %s has no database", cfg.Storage)
+}
+
+// prepareSchema migrates the schema if configured and checks that it is up to date.
+func prepareSchema(logger *logging.Logger, db *sql.DB, dialect migrations.Dialect, config migrations.Config) error {
+	migrator := migrations.NewMigrator(logger, db, dialect)
+	if config.RunOnStartup {
+		if err := migrator.Up(context.Background()); err != nil {
+			return err
+		}
+	}
+	return migrator.Check(context.Background())
+}
+
+// poolsSaturated tells whether every connection of one of the pools is in use.
+func poolsSaturated(poolStats []func() database.PoolStats) func() bool {
+	return func() bool {
+		for _, poolStat := range poolStats {
+			if poolStat().Saturated() {
+				return true
+			}
+		}
+		return false
 	}
 }
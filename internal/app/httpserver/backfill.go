@@ -0,0 +1,62 @@
+package httpserver
+
+import (
+	"context"
+	"flag"
+	"os"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/config"
+	"github.com/SergeyChupin/wallets-api/internal/database/migrations"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+// BackfillEntries records the wallet entries of the transactions made before the
+// wallet_entries table existed. It can be interrupted and run again, entries
+// already recorded are skipped.
+func BackfillEntries(configPath string, args []string) {
+	flagSet := flag.NewFlagSet("backfill-entries", flag.ExitOnError)
+	batchSize := flagSet.Int("batch-size", 1000, "number of transactions backfilled per statement")
+	after := flagSet.String("after", repository.FirstTransactionId, "id of the transaction to resume after")
+	_ = flagSet.Parse(args)
+	if *batchSize <= 0 || flagSet.NArg() > 0 {
+		flagSet.Usage()
+		os.Exit(2)
+	}
+
+	cfg, logger := loadConfig(configPath)
+	if cfg.Storage != config.PostgresStorage {
+		logger.Fatal("BackfillEntries - wallet entries are stored in Postgres only", logging.String("storage", cfg.Storage))
+	}
+
+	db, dialect, err := openDatabase(logger, cfg)
+	if err != nil {
+		logger.Fatal("BackfillEntries - openDatabase", logging.Err(err))
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	ctx := context.Background()
+	if err = migrations.NewMigrator(logger, db, dialect).Check(ctx); err != nil {
+		logger.Fatal("BackfillEntries - migrations.Migrator.Check", logging.Err(err))
+	}
+
+	walletRepository := repository.NewWalletRepository(db)
+	total := 0
+	for {
+		last, backfilled, err := walletRepository.BackfillWalletEntries(ctx, *after, *batchSize)
+		if err != nil {
+			logger.Fatal(
+				"BackfillEntries - walletRepository.BackfillWalletEntries, resume with -after",
+				logging.String("after", *after), logging.Err(err),
+			)
+		}
+		if backfilled == 0 {
+			break
+		}
+		total += backfilled
+		*after = last
+		logger.Info(ctx, "Backfilled transactions", logging.Int("total", total), logging.String("last", last))
+	}
+	logger.Info(ctx, "Backfilled wallet entries", logging.Int("total", total))
+}
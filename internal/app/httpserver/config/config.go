@@ -1,18 +1,68 @@
 package config
 
 import (
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1"
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/consolidation"
+	"github.com/SergeyChupin/wallets-api/internal/database/migrations"
 	"github.com/SergeyChupin/wallets-api/internal/database/postgres"
+	"github.com/SergeyChupin/wallets-api/internal/database/sqlite"
+	"github.com/SergeyChupin/wallets-api/internal/events"
+	"github.com/SergeyChupin/wallets-api/internal/grpcserver"
+	"github.com/SergeyChupin/wallets-api/internal/health"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/metrics"
+	"github.com/SergeyChupin/wallets-api/internal/ratelimit"
 	"github.com/SergeyChupin/wallets-api/internal/server"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	"github.com/SergeyChupin/wallets-api/internal/tracing"
+	"github.com/SergeyChupin/wallets-api/internal/webhook"
+)
+
+const (
+	PostgresStorage = "postgres"
+	MemoryStorage   = "memory"
+	SqliteStorage   = "sqlite"
 )
 
 type Config struct {
-	Server   server.Config   `yaml:"server"`
-	Postgres postgres.Config `yaml:"postgres"`
+	Storage       string                      `yaml:"storage" env:"STORAGE"`
+	Log           logging.Config              `yaml:"log"`
+	Metrics       metrics.Config              `yaml:"metrics"`
+	Tracing       tracing.Config              `yaml:"tracing"`
+	Server        server.Config               `yaml:"server"`
+	Health        health.Config               `yaml:"health"`
+	Grpc          grpcserver.Config           `yaml:"grpc"`
+	Postgres      postgres.Config             `yaml:"postgres"`
+	Sqlite        sqlite.Config               `yaml:"sqlite"`
+	Migrations    migrations.Config           `yaml:"migrations"`
+	Export        v1.ExportConfig             `yaml:"export"`
+	DepositImport service.DepositImportConfig `yaml:"deposit-import"`
+	Webhook       webhook.Config              `yaml:"webhook"`
+	Events        events.Config               `yaml:"events"`
+	Consolidation consolidation.Config        `yaml:"consolidation"`
+	Auth          auth.Config                 `yaml:"auth"`
+	RateLimit     ratelimit.Config            `yaml:"rate-limit"`
 }
 
 func NewConfig() *Config {
 	return &Config{
-		Server:   server.NewConfig(),
-		Postgres: postgres.NewConfig(),
+		Storage:       PostgresStorage,
+		Log:           logging.NewConfig(),
+		Metrics:       metrics.NewConfig(),
+		Tracing:       tracing.NewConfig(),
+		Server:        server.NewConfig(),
+		Health:        health.NewConfig(),
+		Grpc:          grpcserver.NewConfig(),
+		Postgres:      postgres.NewConfig(),
+		Sqlite:        sqlite.NewConfig(),
+		Migrations:    migrations.NewConfig(),
+		Export:        v1.NewExportConfig(),
+		DepositImport: service.NewDepositImportConfig(),
+		Webhook:       webhook.NewConfig(),
+		Events:        events.NewConfig(),
+		Consolidation: consolidation.NewConfig(),
+		Auth:          auth.NewConfig(),
+		RateLimit:     ratelimit.NewConfig(),
 	}
 }
@@ -0,0 +1,64 @@
+package httpserver
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/SergeyChupin/wallets-api/internal/database/migrations"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+)
+
+const migrateUsage = "usage: httpserver migrate up | down [steps] | version | force <version>"
+
+// Migrate runs the migrate subcommand given by args against the database of the config.
+func Migrate(configPath string, args []string) {
+	cfg, logger := loadConfig(configPath)
+	if len(args) == 0 {
+		logger.Fatal(migrateUsage)
+	}
+
+	db, dialect, err := openDatabase(logger, cfg)
+	if err != nil {
+		logger.Fatal("Migrate - openDatabase", logging.Err(err))
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	ctx := context.Background()
+	migrator := migrations.NewMigrator(logger, db, dialect)
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = migrator.Up(ctx)
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				logger.Fatal(migrateUsage)
+			}
+		}
+		err = migrator.Down(ctx, steps)
+	case args[0] == "force" && len(args) == 2:
+		var version int
+		if version, err = strconv.Atoi(args[1]); err != nil {
+			logger.Fatal(migrateUsage)
+		}
+		err = migrator.Force(ctx, version)
+	case args[0] == "version" && len(args) == 1:
+	default:
+		logger.Fatal(migrateUsage)
+	}
+	if err != nil {
+		logger.Fatal("Migrate - migrator."+args[0], logging.Err(err))
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		logger.Fatal("Migrate - migrator.Version", logging.Err(err))
+	}
+	expected, err := migrations.LatestVersion(dialect)
+	if err != nil {
+		logger.Fatal("Migrate - migrations.LatestVersion", logging.Err(err))
+	}
+	logger.Info(ctx, "Database schema version", logging.Int("version", version), logging.Int("expected", expected))
+}
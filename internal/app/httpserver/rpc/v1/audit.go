@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	walletv1 "github.com/SergeyChupin/wallets-api/pkg/api/wallet/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/proto"
+)
+
+// methodActions maps the full method name of the state-changing calls to the
+// action they are audited as, the same as the HTTP ones.
+var methodActions = map[string]string{
+	"/" + walletv1.WalletService_ServiceDesc.ServiceName + "/CreateWallet": "wallet.create",
+	"/" + walletv1.WalletService_ServiceDesc.ServiceName + "/Deposit":      "wallet.deposit",
+	"/" + walletv1.WalletService_ServiceDesc.ServiceName + "/Transfer":     "wallet.transfer",
+}
+
+// UnaryAudit is the unary call counterpart of the Audit HTTP middleware, the
+// payload hash is the one of the deterministic encoding of the request. It
+// runs after UnaryRequestId and UnaryAuthenticate.
+func UnaryAudit(logger *logging.Logger, auditService service.AuditService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		action, ok := methodActions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+		entry := &model.AuditEntry{
+			Action:    action,
+			ClientIp:  peerIp(ctx),
+			RequestId: logging.RequestIdFromContext(ctx),
+		}
+		if principal := auth.PrincipalFromContext(ctx); principal != nil {
+			entry.Actor = principal.Subject
+		}
+		switch req := req.(type) {
+		case *walletv1.DepositRequest:
+			entry.WalletId = req.GetWalletId()
+		case *walletv1.TransferRequest:
+			entry.WalletId = req.GetSenderWalletId()
+		}
+		if message, ok := req.(proto.Message); ok {
+			payload, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+			if err != nil {
+				logger.Error(ctx, "UnaryAudit - proto.MarshalOptions.Marshal", logging.Err(err))
+			}
+			hash := sha256.Sum256(payload)
+			entry.PayloadHash = hex.EncodeToString(hash[:])
+		}
+		resp, err := handler(audit.WithEntry(ctx, entry), req)
+		if entry.ID != "" {
+			return resp, err
+		}
+		entry.Outcome = model.AuditSuccess
+		if err != nil {
+			entry.Outcome = model.AuditFailure
+		}
+		// The call is recorded even if the client is gone.
+		if recordErr := auditService.RecordAuditEntry(context.Background(), entry); recordErr != nil {
+			logger.Error(ctx, "UnaryAudit - auditService.RecordAuditEntry", logging.Err(recordErr))
+		}
+		return resp, err
+	}
+}
+
+// peerIp returns the address of the client of the call.
+func peerIp(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	walletv1 "github.com/SergeyChupin/wallets-api/pkg/api/wallet/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+type auditServiceMock struct {
+	mock.Mock
+}
+
+func (auditService *auditServiceMock) RecordAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	args := auditService.Called(*entry)
+	return args.Error(0)
+}
+
+func (auditService *auditServiceMock) GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	args := auditService.Called(limit, offset, filter)
+	return args.Get(0).([]*model.AuditEntry), args.Error(1)
+}
+
+func TestUnaryAuditFailedCall(t *testing.T) {
+	// given
+	auditService := new(auditServiceMock)
+	ctx := auth.WithPrincipal(context.Background(), &model.Principal{Subject: "alice"})
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 50051}})
+	ctx = logging.WithRequestId(ctx, "request-1")
+	req := &walletv1.TransferRequest{SenderWalletId: "1001", RecipientWalletId: "1002", Amount: 10000}
+	info := &grpc.UnaryServerInfo{FullMethod: "/" + walletv1.WalletService_ServiceDesc.ServiceName + "/Transfer"}
+	auditService.On("RecordAuditEntry", mock.MatchedBy(func(entry model.AuditEntry) bool {
+		return entry.Actor == "alice" && entry.Action == "wallet.transfer" && entry.WalletId == "1001" &&
+			entry.Outcome == model.AuditFailure && entry.ClientIp == "192.0.2.1" && entry.RequestId == "request-1" &&
+			len(entry.PayloadHash) == 64
+	})).Return(nil)
+
+	// when
+	_, err := UnaryAudit(logger, auditService)(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, model.ErrInsufficientFunds
+	})
+
+	// then
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+	auditService.AssertExpectations(t)
+}
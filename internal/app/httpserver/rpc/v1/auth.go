@@ -0,0 +1,123 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	walletv1 "github.com/SergeyChupin/wallets-api/pkg/api/wallet/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodScopes maps the full method name of a call to the scope it requires,
+// methods not listed require the admin scope.
+var methodScopes = map[string]model.Scope{
+	"/" + walletv1.WalletService_ServiceDesc.ServiceName + "/CreateWallet":    model.WalletsWriteScope,
+	"/" + walletv1.WalletService_ServiceDesc.ServiceName + "/Deposit":         model.WalletsWriteScope,
+	"/" + walletv1.WalletService_ServiceDesc.ServiceName + "/Transfer":        model.TransfersCreateScope,
+	"/" + walletv1.WalletService_ServiceDesc.ServiceName + "/GetTransactions": model.WalletsReadScope,
+}
+
+// UnaryAuthenticate is the unary call counterpart of the Authenticate HTTP
+// middleware, the credentials are read from the authorization and the API key
+// metadata.
+func UnaryAuthenticate(
+	logger *logging.Logger, apiKeyService service.ApiKeyService, tokenVerifier auth.TokenVerifier, config auth.Config,
+) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, logger, apiKeyService, tokenVerifier, config, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthenticate is the streaming call counterpart of UnaryAuthenticate.
+func StreamAuthenticate(
+	logger *logging.Logger, apiKeyService service.ApiKeyService, tokenVerifier auth.TokenVerifier, config auth.Config,
+) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), logger, apiKeyService, tokenVerifier, config, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+func authenticate(
+	ctx context.Context,
+	logger *logging.Logger,
+	apiKeyService service.ApiKeyService,
+	tokenVerifier auth.TokenVerifier,
+	config auth.Config,
+	fullMethod string,
+) (context.Context, error) {
+	principal := auth.Anonymous
+	if config.Enabled {
+		var err error
+		if principal, err = principalOf(ctx, apiKeyService, tokenVerifier); err != nil {
+			switch {
+			case errors.Is(err, errMissingCredentials):
+				return nil, status.Error(
+					codes.Unauthenticated,
+					fmt.Sprintf("missing metadata '%s' or '%s'", auth.AuthorizationMetadata, auth.ApiKeyMetadata),
+				)
+			case errors.Is(err, model.ErrApiKeyNotFound):
+				return nil, status.Error(codes.Unauthenticated, "invalid api key")
+			case errors.Is(err, model.ErrInvalidToken):
+				logger.Warn(ctx, "authenticate - principalOf", logging.Err(err))
+				return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+			}
+			logger.Error(ctx, "authenticate - principalOf", logging.Err(err))
+			return nil, status.Error(codes.Internal, "unable to authenticate")
+		}
+	}
+	scope, ok := methodScopes[fullMethod]
+	if !ok {
+		scope = model.AdminScope
+	}
+	if !principal.HasScope(scope) {
+		return nil, status.Error(codes.PermissionDenied, fmt.Sprintf("principal is not granted scope '%s'", scope))
+	}
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+var errMissingCredentials = errors.New("missing credentials")
+
+func principalOf(ctx context.Context, apiKeyService service.ApiKeyService, tokenVerifier auth.TokenVerifier) (*model.Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(auth.AuthorizationMetadata); len(values) > 0 && tokenVerifier != nil {
+		if token, ok := auth.BearerToken(values[0]); ok {
+			return tokenVerifier.Verify(ctx, token)
+		}
+	}
+	values := md.Get(auth.ApiKeyMetadata)
+	if len(values) == 0 || values[0] == "" {
+		return nil, errMissingCredentials
+	}
+	apiKey, err := apiKeyService.Authenticate(ctx, values[0])
+	if err != nil {
+		return nil, err
+	}
+	return apiKey.Principal(), nil
+}
+
+// contextStream replaces the context of a streaming call, e.g. with the
+// principal of the call.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *contextStream) Context() context.Context {
+	return stream.ctx
+}
@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const requestIdMetadata = "x-request-id"
+
+// UnaryRequestId is the unary call counterpart of the RequestId HTTP
+// middleware, the id is read from and returned in the x-request-id metadata.
+func UnaryRequestId(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(withRequestId(ctx), req)
+}
+
+// StreamRequestId is the streaming call counterpart of UnaryRequestId.
+func StreamRequestId(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	return handler(srv, &contextStream{ServerStream: stream, ctx: withRequestId(stream.Context())})
+}
+
+func withRequestId(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestId := ""
+	if values := md.Get(requestIdMetadata); len(values) > 0 {
+		requestId = values[0]
+	}
+	if !logging.ValidRequestId(requestId) {
+		requestId = logging.NewRequestId()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIdMetadata, requestId))
+	return logging.WithRequestId(ctx, requestId)
+}
+
+// errorLevel returns the level to log the error of a service call at, the
+// errors toStatus does not map to an internal error are due to the request.
+func errorLevel(err error) logging.Level {
+	if status.Code(toStatus(err, "")) == codes.Internal {
+		return logging.LevelError
+	}
+	return logging.LevelWarn
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/ratelimit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryLimitIpRate is the unary call counterpart of the LimitIpRate HTTP
+// middleware, the route of a call is its full method name. The client address
+// is the peer one, calls do not pass the proxy X-Forwarded-For is trusted for.
+// It runs before UnaryAuthenticate.
+func UnaryLimitIpRate(limiter ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if ok, retryAfter := limiter.AllowIp(info.FullMethod, peerIp(ctx)); !ok {
+			return nil, errRateLimited(ctx, retryAfter)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamLimitIpRate is the streaming call counterpart of UnaryLimitIpRate.
+func StreamLimitIpRate(limiter ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if ok, retryAfter := limiter.AllowIp(info.FullMethod, peerIp(stream.Context())); !ok {
+			return errRateLimited(stream.Context(), retryAfter)
+		}
+		return handler(srv, stream)
+	}
+}
+
+// UnaryLimitApiKeyRate is the unary call counterpart of the LimitApiKeyRate
+// HTTP middleware. It runs after UnaryAuthenticate.
+func UnaryLimitApiKeyRate(limiter ratelimit.Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := limitApiKeyRate(ctx, limiter, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamLimitApiKeyRate is the streaming call counterpart of UnaryLimitApiKeyRate.
+func StreamLimitApiKeyRate(limiter ratelimit.Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := limitApiKeyRate(stream.Context(), limiter, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// limitApiKeyRate limits the calls of the principal of the context, calls
+// made as auth.Anonymous with authentication disabled are not limited.
+func limitApiKeyRate(ctx context.Context, limiter ratelimit.Limiter, route string) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil || principal == auth.Anonymous {
+		return nil
+	}
+	if ok, retryAfter := limiter.AllowApiKey(route, principal.Subject); !ok {
+		return errRateLimited(ctx, retryAfter)
+	}
+	return nil
+}
+
+// errRateLimited rejects the call with ResourceExhausted, the retry-after
+// header metadata is rounded up to whole seconds as the HTTP one.
+func errRateLimited(ctx context.Context, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+	return status.Error(codes.ResourceExhausted, "rate limit exceeded")
+}
@@ -0,0 +1,71 @@
+package v1
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/ratelimit"
+	walletv1 "github.com/SergeyChupin/wallets-api/pkg/api/wallet/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryLimitIpRate(t *testing.T) {
+	// given
+	transfer := "/" + walletv1.WalletService_ServiceDesc.ServiceName + "/Transfer"
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Groups: map[string]ratelimit.GroupConfig{
+			"transfers": {Routes: []string{transfer}, Ip: ratelimit.Limit{Rate: 1, Burst: 1}},
+		},
+	})
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 50051}})
+	info := &grpc.UnaryServerInfo{FullMethod: transfer}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	// when
+	_, firstErr := UnaryLimitIpRate(limiter)(ctx, nil, info, handler)
+	_, secondErr := UnaryLimitIpRate(limiter)(ctx, nil, info, handler)
+
+	// then
+	assert.NoError(t, firstErr)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(secondErr))
+}
+
+func TestUnaryLimitApiKeyRateSkipsAnonymous(t *testing.T) {
+	// given
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Groups: map[string]ratelimit.GroupConfig{
+			ratelimit.DefaultGroup: {ApiKey: ratelimit.Limit{Rate: 1, Burst: 1}},
+		},
+	})
+	info := &grpc.UnaryServerInfo{FullMethod: "/" + walletv1.WalletService_ServiceDesc.ServiceName + "/GetWallet"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+	anonymous := auth.WithPrincipal(context.Background(), auth.Anonymous)
+	alice := auth.WithPrincipal(context.Background(), &model.Principal{Subject: "alice"})
+
+	// when
+	var anonymousErrs []error
+	for i := 0; i < 3; i++ {
+		_, err := UnaryLimitApiKeyRate(limiter)(anonymous, nil, info, handler)
+		anonymousErrs = append(anonymousErrs, err)
+	}
+	_, firstErr := UnaryLimitApiKeyRate(limiter)(alice, nil, info, handler)
+	_, secondErr := UnaryLimitApiKeyRate(limiter)(alice, nil, info, handler)
+
+	// then
+	for _, err := range anonymousErrs {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, firstErr)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(secondErr))
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var tracer = otel.Tracer("github.com/SergeyChupin/wallets-api/internal/app/httpserver/rpc/v1")
+
+// UnaryTrace is the unary call counterpart of the Trace HTTP middleware, the
+// trace is continued from the traceparent metadata and the span is named after
+// the full method.
+func UnaryTrace(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startCallSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endCallSpan(span, err)
+	return resp, err
+}
+
+// StreamTrace is the streaming call counterpart of UnaryTrace.
+func StreamTrace(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startCallSpan(stream.Context(), info.FullMethod)
+	err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	endCallSpan(span, err)
+	return err
+}
+
+func startCallSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	serviceName, methodName := strings.TrimPrefix(fullMethod, "/"), ""
+	if i := strings.LastIndexByte(serviceName, '/'); i >= 0 {
+		serviceName, methodName = serviceName[:i], serviceName[i+1:]
+	}
+	return tracer.Start(
+		ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCServiceKey.String(serviceName),
+			semconv.RPCMethodKey.String(methodName),
+		),
+	)
+}
+
+// endCallSpan records the status of the call, errors of the request do not
+// fail the span of a server.
+func endCallSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// metadataCarrier reads the trace context from the metadata of a call.
+type metadataCarrier metadata.MD
+
+func (carrier metadataCarrier) Get(key string) string {
+	if values := metadata.MD(carrier).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (carrier metadataCarrier) Set(key string, value string) {
+	metadata.MD(carrier).Set(key, value)
+}
+
+func (carrier metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(carrier))
+	for key := range carrier {
+		keys = append(keys, key)
+	}
+	return keys
+}
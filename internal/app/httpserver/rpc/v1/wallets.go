@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/api/v1/dto"
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+	walletv1 "github.com/SergeyChupin/wallets-api/pkg/api/wallet/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type walletsServer struct {
+	walletv1.UnimplementedWalletServiceServer
+	logger        *logging.Logger
+	walletService service.WalletService
+}
+
+func NewWalletsServer(logger *logging.Logger, grpcServer *grpc.Server, walletService service.WalletService) {
+	walletsServer := &walletsServer{
+		logger:        logger,
+		walletService: walletService,
+	}
+	walletv1.RegisterWalletServiceServer(grpcServer, walletsServer)
+}
+
+func (walletsServer *walletsServer) CreateWallet(ctx context.Context, req *walletv1.CreateWalletRequest) (*walletv1.CreateWalletResponse, error) {
+	reqData := dto.CreateWalletRequest{
+		Name:     req.GetName(),
+		Currency: req.GetCurrency(),
+	}
+	if err := reqData.Validate(); err != nil {
+		walletsServer.logger.Warn(ctx, "walletsServer - CreateWallet - reqData.Validate", logging.Err(err))
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	id, err := walletsServer.walletService.CreateWallet(
+		ctx,
+		model.Wallet{
+			Name:     reqData.Name,
+			Currency: reqData.Currency,
+		},
+	)
+	if err != nil {
+		walletsServer.logger.Log(ctx, errorLevel(err), "walletsServer - CreateWallet - walletsServer.walletService.CreateWallet", logging.Err(err))
+		return nil, toStatus(err, "unable to create wallet")
+	}
+	return &walletv1.CreateWalletResponse{Id: id}, nil
+}
+
+func (walletsServer *walletsServer) Deposit(ctx context.Context, req *walletv1.DepositRequest) (*walletv1.DepositResponse, error) {
+	reqData := dto.DepositRequest{Amount: req.GetAmount()}
+	if err := reqData.Validate(); err != nil || req.GetWalletId() == "" {
+		walletsServer.logger.Warn(ctx, "walletsServer - Deposit - reqData.Validate", logging.Err(err))
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	depositTransaction, err := walletsServer.walletService.Deposit(ctx, req.GetWalletId(), reqData.Amount)
+	if err != nil {
+		walletsServer.logger.Log(ctx, errorLevel(err), "walletsServer - Deposit - walletsServer.walletService.Deposit", logging.Err(err))
+		return nil, toStatus(err, "unable to deposit wallet")
+	}
+	return &walletv1.DepositResponse{Balance: depositTransaction.RecipientWallet.Balance}, nil
+}
+
+func (walletsServer *walletsServer) Transfer(ctx context.Context, req *walletv1.TransferRequest) (*walletv1.TransferResponse, error) {
+	reqData := dto.TransferRequest{
+		Amount:         req.GetAmount(),
+		SenderWalletId: req.GetSenderWalletId(),
+	}
+	if err := reqData.Validate(); err != nil || req.GetRecipientWalletId() == "" {
+		walletsServer.logger.Warn(ctx, "walletsServer - Transfer - reqData.Validate", logging.Err(err))
+		return nil, status.Error(codes.InvalidArgument, "invalid request")
+	}
+	transferTransaction, err := walletsServer.walletService.Transfer(
+		ctx, reqData.SenderWalletId, req.GetRecipientWalletId(), reqData.Amount,
+	)
+	if err != nil {
+		walletsServer.logger.Log(ctx, errorLevel(err), "walletsServer - Transfer - walletsServer.walletService.Transfer", logging.Err(err))
+		return nil, toStatus(err, "unable to transfer money between wallets")
+	}
+	return &walletv1.TransferResponse{
+		SenderWalletBalance: transferTransaction.SenderWallet.Balance,
+		Balance:             transferTransaction.RecipientWallet.Balance,
+	}, nil
+}
+
+func (walletsServer *walletsServer) GetTransactions(req *walletv1.GetTransactionsRequest, stream walletv1.WalletService_GetTransactionsServer) error {
+	if req.GetWalletId() == "" || req.GetLimit() < 0 || req.GetOffset() < 0 {
+		return status.Error(codes.InvalidArgument, "invalid request")
+	}
+	limit, offset := -1, -1
+	if req.GetLimit() > 0 {
+		limit = int(req.GetLimit())
+	}
+	if req.GetOffset() > 0 {
+		offset = int(req.GetOffset())
+	}
+	filter := model.TransactionFilter{WalletId: req.GetWalletId()}
+	switch req.GetOperationType() {
+	case walletv1.OperationType_OPERATION_TYPE_DEPOSIT:
+		filter.OperationType = model.Deposit
+	case walletv1.OperationType_OPERATION_TYPE_TRANSFER:
+		filter.OperationType = model.Transfer
+	}
+	if req.GetProcessedAtGte() != nil {
+		filter.ProcessedAtGte = req.GetProcessedAtGte().AsTime()
+	}
+	if req.GetProcessedAtLte() != nil {
+		filter.ProcessedAtLte = req.GetProcessedAtLte().AsTime()
+	}
+	ctx := stream.Context()
+	if readConsistency(ctx) == database.StrongReadConsistency {
+		ctx = database.WithPrimaryReads(ctx)
+	}
+	transactions, err := walletsServer.walletService.GetTransactions(ctx, limit, offset, filter)
+	if err != nil {
+		walletsServer.logger.Log(ctx, errorLevel(err), "walletsServer - GetTransactions - walletsServer.walletService.GetTransactions", logging.Err(err))
+		return toStatus(err, "unable to get transactions")
+	}
+	for _, transaction := range transactions {
+		if err = stream.Send(toTransaction(transaction)); err != nil {
+			walletsServer.logger.Error(ctx, "walletsServer - GetTransactions - stream.Send", logging.Err(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// readConsistency returns the read consistency requested in the metadata, the
+// same header as in the HTTP API.
+func readConsistency(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(database.ReadConsistencyHeader)
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.ToLower(values[0])
+}
+
+func toTransaction(transaction *model.Transaction) *walletv1.Transaction {
+	respItem := &walletv1.Transaction{
+		Id:            transaction.ID,
+		OperationType: walletv1.OperationType_OPERATION_TYPE_DEPOSIT,
+		Amount:        transaction.Amount,
+		RecipientWallet: &walletv1.TransactionWallet{
+			Id:      transaction.RecipientWallet.ID,
+			Balance: transaction.RecipientWallet.Balance,
+		},
+		ProcessedAt: timestamppb.New(transaction.ProcessedAt),
+		Reference:   transaction.Reference,
+	}
+	if transaction.OperationType == model.Transfer {
+		respItem.OperationType = walletv1.OperationType_OPERATION_TYPE_TRANSFER
+	}
+	if transaction.SenderWallet != nil {
+		respItem.SenderWallet = &walletv1.TransactionWallet{
+			Id:      transaction.SenderWallet.ID,
+			Balance: transaction.SenderWallet.Balance,
+		}
+	}
+	return respItem
+}
+
+// toStatus maps domain errors to gRPC status codes, hiding other errors
+// behind the message.
+func toStatus(err error, message string) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+	if errors.Is(err, model.ErrWalletAccessDenied) {
+		return status.Error(codes.PermissionDenied, model.ErrWalletAccessDenied.Error())
+	}
+	if errors.Is(err, model.ErrWalletNotFound) {
+		return status.Error(codes.NotFound, model.ErrWalletNotFound.Error())
+	}
+	if errors.Is(err, model.ErrWalletAlreadyExists) {
+		return status.Error(codes.AlreadyExists, model.ErrWalletAlreadyExists.Error())
+	}
+	if errors.Is(err, model.ErrInsufficientFunds) {
+		return status.Error(codes.FailedPrecondition, model.ErrInsufficientFunds.Error())
+	}
+	if errors.Is(err, model.ErrWalletFrozen) {
+		return status.Error(codes.FailedPrecondition, model.ErrWalletFrozen.Error())
+	}
+	if errors.Is(err, model.ErrSameWallet) {
+		return status.Error(codes.InvalidArgument, model.ErrSameWallet.Error())
+	}
+	return status.Error(codes.Internal, message)
+}
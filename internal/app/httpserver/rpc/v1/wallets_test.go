@@ -0,0 +1,257 @@
+package v1
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	walletv1 "github.com/SergeyChupin/wallets-api/pkg/api/wallet/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var (
+	logger, _ = logging.New(os.Stdout, logging.Config{Level: logging.LevelDebug.String(), Format: logging.TextFormat})
+)
+
+type walletServiceMock struct {
+	mock.Mock
+}
+
+func (walletService *walletServiceMock) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
+	args := walletService.Called(wallet)
+	return args.String(0), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	args := walletService.Called(id)
+	return args.Get(0).(*model.Wallet), args.Error(1)
+}
+
+func (walletService *walletServiceMock) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	args := walletService.Called(recipientWalletId, amount)
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	args := walletService.Called(senderWalletId, recipientWalletId, amount)
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+	args := walletService.Called(limit, offset, filter)
+	return args.Get(0).([]*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	args := walletService.Called(walletId, transactionId)
+	return args.Get(0).([]*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) FreezeWallet(ctx context.Context, id string) error {
+	args := walletService.Called(id)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) UnfreezeWallet(ctx context.Context, id string) error {
+	args := walletService.Called(id)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) ShardWallet(ctx context.Context, id string, shards int) error {
+	args := walletService.Called(id, shards)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	args := walletService.Called()
+	return args.Get(0).([]*model.WalletReconciliation), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	args := walletService.Called(walletId)
+	return args.Get(0).([]*model.WalletGrant), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GrantWalletAccess(ctx context.Context, walletId string, subject string, role model.WalletRole) error {
+	args := walletService.Called(walletId, subject, role)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	args := walletService.Called(walletId, subject)
+	return args.Error(0)
+}
+
+func newClient(t *testing.T, walletService *walletServiceMock) walletv1.WalletServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	NewWalletsServer(logger, grpcServer, walletService)
+	go func() {
+		_ = grpcServer.Serve(listener)
+	}()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial(
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithInsecure(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return walletv1.NewWalletServiceClient(conn)
+}
+
+func TestCreateWallet(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	client := newClient(t, walletService)
+
+	walletService.On("CreateWallet", model.Wallet{
+		Name:     "wallet1",
+		Currency: "USD",
+	}).Return("1001", nil)
+
+	// when
+	resp, err := client.CreateWallet(context.Background(), &walletv1.CreateWalletRequest{
+		Name:     "wallet1",
+		Currency: "USD",
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "1001", resp.GetId())
+
+	walletService.AssertExpectations(t)
+}
+
+func TestCreateWalletInvalidArgument(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	client := newClient(t, walletService)
+
+	// when
+	_, err := client.CreateWallet(context.Background(), &walletv1.CreateWalletRequest{
+		Name:     "wallet1",
+		Currency: "EUR",
+	})
+
+	// then
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	walletService.AssertNumberOfCalls(t, "CreateWallet", 0)
+}
+
+func TestTransferInsufficientFunds(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	client := newClient(t, walletService)
+
+	walletService.On("Transfer", "1001", "1002", uint64(10000)).Return(
+		(*model.Transaction)(nil), model.ErrInsufficientFunds,
+	)
+
+	// when
+	_, err := client.Transfer(context.Background(), &walletv1.TransferRequest{
+		SenderWalletId:    "1001",
+		RecipientWalletId: "1002",
+		Amount:            10000,
+	})
+
+	// then
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, "insufficient funds", status.Convert(err).Message())
+
+	walletService.AssertExpectations(t)
+}
+
+func TestDepositWalletNotFound(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	client := newClient(t, walletService)
+
+	walletService.On("Deposit", "1001", uint64(10000)).Return(
+		(*model.Transaction)(nil), model.ErrWalletNotFound,
+	)
+
+	// when
+	_, err := client.Deposit(context.Background(), &walletv1.DepositRequest{
+		WalletId: "1001",
+		Amount:   10000,
+	})
+
+	// then
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	walletService.AssertExpectations(t)
+}
+
+func TestGetTransactions(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	client := newClient(t, walletService)
+
+	processedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	walletService.On("GetTransactions", 10, -1, model.TransactionFilter{
+		WalletId:      "1001",
+		OperationType: model.Transfer,
+	}).Return(
+		[]*model.Transaction{
+			{
+				ID:              "3001",
+				Amount:          10000,
+				ProcessedAt:     processedAt,
+				SenderWallet:    &model.Wallet{ID: "1001", Balance: 5000},
+				RecipientWallet: model.Wallet{ID: "1002", Balance: 10000},
+				OperationType:   model.Transfer,
+			},
+		},
+		nil,
+	)
+
+	// when
+	stream, err := client.GetTransactions(context.Background(), &walletv1.GetTransactionsRequest{
+		WalletId:      "1001",
+		Limit:         10,
+		OperationType: walletv1.OperationType_OPERATION_TYPE_TRANSFER,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var transactions []*walletv1.Transaction
+	for {
+		transaction, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	// then
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, "3001", transactions[0].GetId())
+	assert.Equal(t, walletv1.OperationType_OPERATION_TYPE_TRANSFER, transactions[0].GetOperationType())
+	assert.Equal(t, uint64(5000), transactions[0].GetSenderWallet().GetBalance())
+	assert.Equal(t, "1002", transactions[0].GetRecipientWallet().GetId())
+	assert.True(t, processedAt.Equal(transactions[0].GetProcessedAt().AsTime()))
+
+	walletService.AssertExpectations(t)
+}
@@ -0,0 +1,276 @@
+package walletctl
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"os/user"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/SergeyChupin/wallets-api/internal/app/httpserver/config"
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/database/postgres"
+	"github.com/SergeyChupin/wallets-api/internal/database/sqlite"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+	"github.com/SergeyChupin/wallets-api/internal/service"
+)
+
+const (
+	exitOk    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+var errAborted = errors.New("aborted")
+
+type command struct {
+	description string
+	// action is the audit log action of the command, empty if it is not audited.
+	action string
+	setup  func(ctl *walletctl, flagSet *flag.FlagSet) func(ctx context.Context) error
+}
+
+var commands = map[string]command{
+	"create":       {"create a wallet", "wallet.create", (*walletctl).createCommand},
+	"deposit":      {"deposit money to a wallet", "wallet.deposit", (*walletctl).depositCommand},
+	"transfer":     {"transfer money between wallets", "wallet.transfer", (*walletctl).transferCommand},
+	"transactions": {"list transactions of a wallet", "", (*walletctl).transactionsCommand},
+	"reconcile":    {"compare wallet balances with their transactions", "wallet.reconcile", (*walletctl).reconcileCommand},
+	"freeze":       {"block money movements of a wallet", "wallet.freeze", (*walletctl).freezeCommand},
+	"unfreeze":     {"allow money movements of a frozen wallet", "wallet.unfreeze", (*walletctl).unfreezeCommand},
+	"shard":        {"spread deposits to a hot wallet over balance shards", "wallet.shard", (*walletctl).shardCommand},
+	"issue-key":    {"issue an API key", "api_key.create", (*walletctl).issueKeyCommand},
+	"revoke-key":   {"revoke an API key", "api_key.revoke", (*walletctl).revokeKeyCommand},
+}
+
+// auditedWalletFlags are the flags naming the wallet an audited command targets.
+var auditedWalletFlags = []string{"wallet", "from"}
+
+type walletctl struct {
+	walletService service.WalletService
+	apiKeyService service.ApiKeyService
+	auditService  service.AuditService
+	actor         string
+	in            *bufio.Reader
+	out           io.Writer
+	errOut        io.Writer
+	configPath    string
+	output        string
+	yes           bool
+	verbose       bool
+}
+
+// Run executes the command given by args against the database of the config
+// and returns the exit code.
+func Run(args []string, in io.Reader, out io.Writer, errOut io.Writer) int {
+	ctl := &walletctl{
+		in:     bufio.NewReader(in),
+		out:    out,
+		errOut: errOut,
+	}
+	run, err := ctl.parse(args)
+	if err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			_, _ = fmt.Fprintln(errOut, "walletctl:", err)
+		}
+		return exitUsage
+	}
+
+	cfg, err := config.NewLoader(ctl.configPath).Load()
+	if err != nil {
+		_, _ = fmt.Fprintln(errOut, "walletctl:", err)
+		return exitError
+	}
+	logger := logging.Discard()
+	if ctl.verbose {
+		if logger, err = logging.New(errOut, logging.Config{Level: cfg.Log.Level, Format: logging.TextFormat}); err != nil {
+			_, _ = fmt.Fprintln(errOut, "walletctl:", err)
+			return exitError
+		}
+	}
+	repositories, closeDb, err := openRepositories(logger, cfg)
+	if err != nil {
+		_, _ = fmt.Fprintln(errOut, "walletctl:", err)
+		return exitError
+	}
+	defer closeDb()
+	ctl.walletService = service.NewWalletService(repositories.wallet)
+	ctl.apiKeyService = service.NewApiKeyService(repositories.apiKey)
+	ctl.auditService = service.NewAuditService(repositories.audit)
+	ctl.actor = currentActor()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err = run(ctx); err != nil {
+		_, _ = fmt.Fprintln(errOut, "walletctl:", err)
+		return exitError
+	}
+	return exitOk
+}
+
+type repositories struct {
+	wallet repository.WalletRepository
+	apiKey repository.ApiKeyRepository
+	audit  repository.AuditRepository
+}
+
+// openRepositories opens the database of the configured storage,
+// the in-memory storage lives in the server process only.
+func openRepositories(logger *logging.Logger, cfg *config.Config) (*repositories, func(), error) {
+	var db *sql.DB
+	var err error
+	var opened *repositories
+	switch cfg.Storage {
+	case config.PostgresStorage:
+		if db, err = postgres.Open(logger, cfg.Postgres); err != nil {
+			return nil, nil, err
+		}
+		opened = &repositories{
+			wallet: repository.NewWalletRepository(db),
+			apiKey: repository.NewApiKeyRepository(db),
+			audit:  repository.NewAuditRepository(db),
+		}
+	case config.SqliteStorage:
+		if db, err = sqlite.Open(logger, cfg.Sqlite); err != nil {
+			return nil, nil, err
+		}
+		opened = &repositories{
+			wallet: repository.NewSqliteWalletRepository(db),
+			apiKey: repository.NewSqliteApiKeyRepository(db),
+			audit:  repository.NewSqliteAuditRepository(db),
+		}
+	default:
+		return nil, nil, fmt.Errorf("storage %s is not supported", cfg.Storage)
+	}
+	return opened, func() {
+		_ = db.Close()
+	}, nil
+}
+
+// currentActor returns the audit log actor of the operating system user
+// running the command.
+func currentActor() string {
+	name := os.Getenv("USER")
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	if name == "" {
+		name = "unknown"
+	}
+	return "walletctl:" + name
+}
+
+// parse parses the command line and returns the command to run.
+func (ctl *walletctl) parse(args []string) (func(ctx context.Context) error, error) {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		ctl.usage()
+		return nil, flag.ErrHelp
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		ctl.usage()
+		return nil, fmt.Errorf("unknown command: %s", args[0])
+	}
+	flagSet := flag.NewFlagSet("walletctl "+args[0], flag.ContinueOnError)
+	flagSet.SetOutput(ctl.errOut)
+	flagSet.StringVar(&ctl.configPath, "config-path", "configs/httpserver.yaml", "path to config file")
+	flagSet.StringVar(&ctl.output, "output", outputTable, "output format: table, json or csv")
+	flagSet.BoolVar(&ctl.yes, "yes", false, "do not ask for confirmation")
+	flagSet.BoolVar(&ctl.verbose, "verbose", false, "log database connection")
+	run := cmd.setup(ctl, flagSet)
+	if err := flagSet.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if flagSet.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments: %s", strings.Join(flagSet.Args(), " "))
+	}
+	if ctl.output != outputTable && ctl.output != outputJson && ctl.output != outputCsv {
+		return nil, fmt.Errorf("unknown output format: %s", ctl.output)
+	}
+	if cmd.action != "" {
+		run = ctl.audited(cmd.action, flagSet, args, run)
+	}
+	return run, nil
+}
+
+// audited records the command in the audit log, as the API records its calls:
+// the payload hash covers the command line and the client ip is empty. Creating
+// a wallet, a deposit and a transfer record the entry along with the operation,
+// a command aborted by the user is not recorded.
+func (ctl *walletctl) audited(
+	action string, flagSet *flag.FlagSet, args []string, run func(ctx context.Context) error,
+) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		hash := sha256.Sum256([]byte(strings.Join(args, " ")))
+		entry := &model.AuditEntry{
+			Actor:       ctl.actor,
+			Action:      action,
+			PayloadHash: hex.EncodeToString(hash[:]),
+			RequestId:   logging.NewRequestId(),
+		}
+		for _, name := range auditedWalletFlags {
+			if walletFlag := flagSet.Lookup(name); walletFlag != nil {
+				entry.WalletId = walletFlag.Value.String()
+				break
+			}
+		}
+		err := run(audit.WithEntry(ctx, entry))
+		if errors.Is(err, errAborted) || entry.ID != "" {
+			return err
+		}
+		entry.Outcome = model.AuditSuccess
+		if err != nil {
+			entry.Outcome = model.AuditFailure
+		}
+		// The command is recorded even if it was interrupted.
+		if auditErr := ctl.auditService.RecordAuditEntry(context.Background(), entry); auditErr != nil && err == nil {
+			return auditErr
+		}
+		return err
+	}
+}
+
+func (ctl *walletctl) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	_, _ = fmt.Fprintln(ctl.errOut, "Usage: walletctl <command> [flags]")
+	_, _ = fmt.Fprintln(ctl.errOut)
+	_, _ = fmt.Fprintln(ctl.errOut, "Commands:")
+	for _, name := range names {
+		_, _ = fmt.Fprintf(ctl.errOut, "  %-14s %s\n", name, commands[name].description)
+	}
+	_, _ = fmt.Fprintln(ctl.errOut)
+	_, _ = fmt.Fprintln(ctl.errOut, "Run 'walletctl <command> -h' for the flags of the command.")
+}
+
+// confirm asks the user to confirm the action unless -yes is given.
+func (ctl *walletctl) confirm(prompt string) error {
+	if ctl.yes {
+		return nil
+	}
+	_, _ = fmt.Fprintf(ctl.errOut, "%s [y/N]: ", prompt)
+	answer, err := ctl.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "y" && answer != "yes" {
+		return errAborted
+	}
+	return nil
+}
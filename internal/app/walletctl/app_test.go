@@ -0,0 +1,321 @@
+package walletctl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type walletServiceMock struct {
+	mock.Mock
+}
+
+func (walletService *walletServiceMock) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
+	args := walletService.Called(wallet)
+	return args.String(0), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	args := walletService.Called(id)
+	return args.Get(0).(*model.Wallet), args.Error(1)
+}
+
+func (walletService *walletServiceMock) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	args := walletService.Called(recipientWalletId, amount)
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	args := walletService.Called(senderWalletId, recipientWalletId, amount)
+	return args.Get(0).(*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+	args := walletService.Called(limit, offset, filter)
+	return args.Get(0).([]*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	args := walletService.Called(walletId, transactionId)
+	return args.Get(0).([]*model.Transaction), args.Error(1)
+}
+
+func (walletService *walletServiceMock) FreezeWallet(ctx context.Context, id string) error {
+	args := walletService.Called(id)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) UnfreezeWallet(ctx context.Context, id string) error {
+	args := walletService.Called(id)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) ShardWallet(ctx context.Context, id string, shards int) error {
+	args := walletService.Called(id, shards)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	args := walletService.Called()
+	return args.Get(0).([]*model.WalletReconciliation), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	args := walletService.Called(walletId)
+	return args.Get(0).([]*model.WalletGrant), args.Error(1)
+}
+
+func (walletService *walletServiceMock) GrantWalletAccess(ctx context.Context, walletId string, subject string, role model.WalletRole) error {
+	args := walletService.Called(walletId, subject, role)
+	return args.Error(0)
+}
+
+func (walletService *walletServiceMock) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	args := walletService.Called(walletId, subject)
+	return args.Error(0)
+}
+
+type auditServiceMock struct {
+	mock.Mock
+}
+
+func (auditService *auditServiceMock) RecordAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	args := auditService.Called(entry)
+	return args.Error(0)
+}
+
+func (auditService *auditServiceMock) GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	args := auditService.Called(limit, offset, filter)
+	return args.Get(0).([]*model.AuditEntry), args.Error(1)
+}
+
+func newTestWalletctl(walletService *walletServiceMock, in string) (*walletctl, *bytes.Buffer) {
+	auditService := new(auditServiceMock)
+	auditService.On("RecordAuditEntry", mock.Anything).Return(nil).Maybe()
+	return newAuditedTestWalletctl(walletService, auditService, in)
+}
+
+func newAuditedTestWalletctl(walletService *walletServiceMock, auditService *auditServiceMock, in string) (*walletctl, *bytes.Buffer) {
+	out := new(bytes.Buffer)
+	return &walletctl{
+		walletService: walletService,
+		auditService:  auditService,
+		actor:         "walletctl:ops",
+		in:            bufio.NewReader(strings.NewReader(in)),
+		out:           out,
+		errOut:        new(bytes.Buffer),
+	}, out
+}
+
+func TestTransferDeclined(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	ctl, out := newTestWalletctl(walletService, "n\n")
+	run, err := ctl.parse([]string{"transfer", "-from", "1001", "-to", "1002", "-amount", "100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	err = run(context.Background())
+
+	// then
+	assert.ErrorIs(t, err, errAborted)
+	assert.Empty(t, out.String())
+
+	walletService.AssertExpectations(t)
+}
+
+func TestDepositConfirmed(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	ctl, out := newTestWalletctl(walletService, "y\n")
+	run, err := ctl.parse([]string{"deposit", "-wallet", "1001", "-amount", "100", "-output", "csv"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	processedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	walletService.On("Deposit", "1001", uint64(100)).Return(
+		&model.Transaction{
+			ID:              "2001",
+			Amount:          100,
+			ProcessedAt:     processedAt,
+			RecipientWallet: model.Wallet{ID: "1001"},
+			OperationType:   model.Deposit,
+		},
+		nil,
+	)
+
+	// when
+	err = run(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"id,operation_type,amount,sender_wallet_id,recipient_wallet_id,reference,processed_at\n"+
+			"2001,deposit,100,,1001,,2022-01-01T00:00:00Z\n",
+		out.String(),
+	)
+
+	walletService.AssertExpectations(t)
+}
+
+func TestReconcileNotBalanced(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	ctl, out := newTestWalletctl(walletService, "")
+	run, err := ctl.parse([]string{"reconcile"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletService.On("Reconcile").Return(
+		[]*model.WalletReconciliation{
+			{WalletId: "1001", WalletName: "first", Balance: 100, TransactionsBalance: 100},
+			{WalletId: "1002", WalletName: "second", Balance: 50, TransactionsBalance: 70},
+		},
+		nil,
+	)
+
+	// when
+	err = run(context.Background())
+
+	// then
+	assert.EqualError(t, err, "1 of 2 wallets are not balanced")
+	assert.Equal(t,
+		"WALLET ID  WALLET NAME  BALANCE  TRANSACTIONS BALANCE  BALANCED\n"+
+			"1001       first        100      100                   true\n"+
+			"1002       second       50       70                    false\n",
+		out.String(),
+	)
+
+	walletService.AssertExpectations(t)
+}
+
+func TestFreezeJsonOutput(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	ctl, out := newTestWalletctl(walletService, "")
+	run, err := ctl.parse([]string{"freeze", "-wallet", "1001", "-output", "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletService.On("FreezeWallet", "1001").Return(nil)
+	walletService.On("GetWallet", "1001").Return(
+		&model.Wallet{ID: "1001", Name: "first", Currency: "USD", Balance: 100, Frozen: true},
+		nil,
+	)
+
+	// when
+	err = run(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.JSONEq(t,
+		`{"id":"1001","name":"first","currency":"USD","balance":100,"frozen":true,"shards":0}`,
+		out.String(),
+	)
+
+	walletService.AssertExpectations(t)
+}
+
+func TestShardTableOutput(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	ctl, out := newTestWalletctl(walletService, "")
+	run, err := ctl.parse([]string{"shard", "-wallet", "1001", "-shards", "16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletService.On("ShardWallet", "1001", 16).Return(nil)
+	walletService.On("GetWallet", "1001").Return(
+		&model.Wallet{ID: "1001", Name: "collector", Currency: "USD", Balance: 100, Shards: 16},
+		nil,
+	)
+
+	// when
+	err = run(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"ID    NAME       CURRENCY  BALANCE  FROZEN  SHARDS\n"+
+			"1001  collector  USD       100      false   16\n",
+		out.String(),
+	)
+
+	walletService.AssertExpectations(t)
+}
+
+func TestFreezeAudited(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	auditService := new(auditServiceMock)
+	ctl, _ := newAuditedTestWalletctl(walletService, auditService, "")
+	run, err := ctl.parse([]string{"freeze", "-wallet", "1001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	walletService.On("FreezeWallet", "1001").Return(model.ErrWalletNotFound)
+	var recorded *model.AuditEntry
+	auditService.On("RecordAuditEntry", mock.Anything).Run(func(args mock.Arguments) {
+		recorded = args.Get(0).(*model.AuditEntry)
+	}).Return(nil)
+
+	// when
+	err = run(context.Background())
+
+	// then
+	assert.ErrorIs(t, err, model.ErrWalletNotFound)
+	if assert.NotNil(t, recorded) {
+		assert.Equal(t, "walletctl:ops", recorded.Actor)
+		assert.Equal(t, "wallet.freeze", recorded.Action)
+		assert.Equal(t, "1001", recorded.WalletId)
+		assert.Equal(t, model.AuditFailure, recorded.Outcome)
+		assert.NotEmpty(t, recorded.PayloadHash)
+		assert.NotEmpty(t, recorded.RequestId)
+	}
+
+	walletService.AssertExpectations(t)
+	auditService.AssertExpectations(t)
+}
+
+func TestTransferDeclinedNotAudited(t *testing.T) {
+	// given
+	walletService := new(walletServiceMock)
+	auditService := new(auditServiceMock)
+	ctl, _ := newAuditedTestWalletctl(walletService, auditService, "n\n")
+	run, err := ctl.parse([]string{"transfer", "-from", "1001", "-to", "1002", "-amount", "100"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	err = run(context.Background())
+
+	// then
+	assert.ErrorIs(t, err, errAborted)
+	auditService.AssertNumberOfCalls(t, "RecordAuditEntry", 0)
+}
+
+func TestParseUnknownOutput(t *testing.T) {
+	// given
+	ctl, _ := newTestWalletctl(new(walletServiceMock), "")
+
+	// when
+	_, err := ctl.parse([]string{"reconcile", "-output", "xml"})
+
+	// then
+	assert.EqualError(t, err, "unknown output format: xml")
+}
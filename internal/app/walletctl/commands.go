@@ -0,0 +1,321 @@
+package walletctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+type walletOutput struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	Balance  uint64 `json:"balance"`
+	Frozen   bool   `json:"frozen"`
+	Shards   int    `json:"shards"`
+}
+
+type transactionOutput struct {
+	ID                string    `json:"id"`
+	OperationType     string    `json:"operation_type"`
+	Amount            uint64    `json:"amount"`
+	SenderWalletId    string    `json:"sender_wallet_id,omitempty"`
+	RecipientWalletId string    `json:"recipient_wallet_id"`
+	Reference         string    `json:"reference,omitempty"`
+	ProcessedAt       time.Time `json:"processed_at"`
+}
+
+type reconciliationOutput struct {
+	WalletId            string `json:"wallet_id"`
+	WalletName          string `json:"wallet_name"`
+	Balance             int64  `json:"balance"`
+	TransactionsBalance int64  `json:"transactions_balance"`
+	Balanced            bool   `json:"balanced"`
+}
+
+type apiKeyOutput struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	Key    string   `json:"key"`
+}
+
+var transactionHeader = []string{
+	"id", "operation_type", "amount", "sender_wallet_id", "recipient_wallet_id", "reference", "processed_at",
+}
+
+func (ctl *walletctl) createCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	name := flagSet.String("name", "", "wallet name")
+	currency := flagSet.String("currency", "USD", "wallet currency")
+	return func(ctx context.Context) error {
+		if *name == "" {
+			return errors.New("flag -name is required")
+		}
+		id, err := ctl.walletService.CreateWallet(ctx, model.Wallet{Name: *name, Currency: *currency})
+		if err != nil {
+			return err
+		}
+		return ctl.printWallet(&walletOutput{ID: id, Name: *name, Currency: *currency})
+	}
+}
+
+func (ctl *walletctl) depositCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	walletId := flagSet.String("wallet", "", "recipient wallet id")
+	amount := flagSet.Uint64("amount", 0, "amount in minor units")
+	return func(ctx context.Context) error {
+		if *walletId == "" || *amount == 0 {
+			return errors.New("flags -wallet and -amount are required")
+		}
+		if err := ctl.confirm(fmt.Sprintf("Deposit %d to wallet %s?", *amount, *walletId)); err != nil {
+			return err
+		}
+		transaction, err := ctl.walletService.Deposit(ctx, *walletId, *amount)
+		if err != nil {
+			return err
+		}
+		return ctl.printTransactions([]*model.Transaction{transaction})
+	}
+}
+
+func (ctl *walletctl) transferCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	senderWalletId := flagSet.String("from", "", "sender wallet id")
+	recipientWalletId := flagSet.String("to", "", "recipient wallet id")
+	amount := flagSet.Uint64("amount", 0, "amount in minor units")
+	return func(ctx context.Context) error {
+		if *senderWalletId == "" || *recipientWalletId == "" || *amount == 0 {
+			return errors.New("flags -from, -to and -amount are required")
+		}
+		prompt := fmt.Sprintf("Transfer %d from wallet %s to wallet %s?", *amount, *senderWalletId, *recipientWalletId)
+		if err := ctl.confirm(prompt); err != nil {
+			return err
+		}
+		transaction, err := ctl.walletService.Transfer(ctx, *senderWalletId, *recipientWalletId, *amount)
+		if err != nil {
+			return err
+		}
+		return ctl.printTransactions([]*model.Transaction{transaction})
+	}
+}
+
+func (ctl *walletctl) transactionsCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	walletId := flagSet.String("wallet", "", "wallet id")
+	limit := flagSet.Int("limit", 100, "maximum number of transactions")
+	offset := flagSet.Int("offset", 0, "number of transactions to skip")
+	operationType := flagSet.String("type", "", "operation type: deposit or transfer")
+	from := flagSet.String("from", "", "processed at or after, RFC 3339")
+	to := flagSet.String("to", "", "processed at or before, RFC 3339")
+	return func(ctx context.Context) error {
+		if *walletId == "" {
+			return errors.New("flag -wallet is required")
+		}
+		filter := model.TransactionFilter{WalletId: *walletId}
+		var err error
+		if *operationType != "" {
+			if filter.OperationType, err = model.FromString(*operationType); err != nil {
+				return err
+			}
+		}
+		if *from != "" {
+			if filter.ProcessedAtGte, err = time.Parse(time.RFC3339, *from); err != nil {
+				return fmt.Errorf("invalid flag -from: %w", err)
+			}
+		}
+		if *to != "" {
+			if filter.ProcessedAtLte, err = time.Parse(time.RFC3339, *to); err != nil {
+				return fmt.Errorf("invalid flag -to: %w", err)
+			}
+		}
+		transactions, err := ctl.walletService.GetTransactions(ctx, *limit, *offset, filter)
+		if err != nil {
+			return err
+		}
+		return ctl.printTransactions(transactions)
+	}
+}
+
+func (ctl *walletctl) reconcileCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		reconciliations, err := ctl.walletService.Reconcile(ctx)
+		if err != nil {
+			return err
+		}
+		header := []string{"wallet_id", "wallet_name", "balance", "transactions_balance", "balanced"}
+		rows := make([][]string, 0, len(reconciliations))
+		values := make([]*reconciliationOutput, 0, len(reconciliations))
+		unbalanced := 0
+		for _, reconciliation := range reconciliations {
+			if !reconciliation.Balanced() {
+				unbalanced++
+			}
+			rows = append(rows, []string{
+				reconciliation.WalletId,
+				reconciliation.WalletName,
+				strconv.FormatInt(reconciliation.Balance, 10),
+				strconv.FormatInt(reconciliation.TransactionsBalance, 10),
+				strconv.FormatBool(reconciliation.Balanced()),
+			})
+			values = append(values, &reconciliationOutput{
+				WalletId:            reconciliation.WalletId,
+				WalletName:          reconciliation.WalletName,
+				Balance:             reconciliation.Balance,
+				TransactionsBalance: reconciliation.TransactionsBalance,
+				Balanced:            reconciliation.Balanced(),
+			})
+		}
+		if err = ctl.print(header, rows, values); err != nil {
+			return err
+		}
+		if unbalanced > 0 {
+			return fmt.Errorf("%d of %d wallets are not balanced", unbalanced, len(reconciliations))
+		}
+		return nil
+	}
+}
+
+func (ctl *walletctl) freezeCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	walletId := flagSet.String("wallet", "", "wallet id")
+	return func(ctx context.Context) error {
+		if *walletId == "" {
+			return errors.New("flag -wallet is required")
+		}
+		if err := ctl.walletService.FreezeWallet(ctx, *walletId); err != nil {
+			return err
+		}
+		return ctl.printWalletById(ctx, *walletId)
+	}
+}
+
+func (ctl *walletctl) unfreezeCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	walletId := flagSet.String("wallet", "", "wallet id")
+	return func(ctx context.Context) error {
+		if *walletId == "" {
+			return errors.New("flag -wallet is required")
+		}
+		if err := ctl.walletService.UnfreezeWallet(ctx, *walletId); err != nil {
+			return err
+		}
+		return ctl.printWalletById(ctx, *walletId)
+	}
+}
+
+func (ctl *walletctl) shardCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	walletId := flagSet.String("wallet", "", "wallet id")
+	shards := flagSet.Int("shards", 0, "number of balance shards, 0 stops sharding")
+	return func(ctx context.Context) error {
+		if *walletId == "" {
+			return errors.New("flag -wallet is required")
+		}
+		if err := ctl.walletService.ShardWallet(ctx, *walletId, *shards); err != nil {
+			return err
+		}
+		return ctl.printWalletById(ctx, *walletId)
+	}
+}
+
+// issueKeyCommand issues the first admin key of a deployment, the others can
+// be issued through the admin API.
+func (ctl *walletctl) issueKeyCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	name := flagSet.String("name", "", "API key name")
+	scopeList := flagSet.String("scopes", model.AdminScope.String(), "comma separated scopes: wallets:read, wallets:write, transfers:create, admin")
+	return func(ctx context.Context) error {
+		if *name == "" {
+			return errors.New("flag -name is required")
+		}
+		var scopes []model.Scope
+		for _, value := range strings.Split(*scopeList, ",") {
+			scope, err := model.ScopeFromString(strings.TrimSpace(value))
+			if err != nil {
+				return err
+			}
+			scopes = append(scopes, scope)
+		}
+		apiKey, key, err := ctl.apiKeyService.IssueApiKey(ctx, *name, scopes)
+		if err != nil {
+			return err
+		}
+		value := &apiKeyOutput{ID: apiKey.ID, Name: apiKey.Name, Prefix: apiKey.Prefix, Key: key}
+		for _, scope := range apiKey.Scopes {
+			value.Scopes = append(value.Scopes, scope.String())
+		}
+		header := []string{"id", "name", "prefix", "scopes", "key"}
+		rows := [][]string{{value.ID, value.Name, value.Prefix, strings.Join(value.Scopes, ","), value.Key}}
+		return ctl.print(header, rows, value)
+	}
+}
+
+func (ctl *walletctl) revokeKeyCommand(flagSet *flag.FlagSet) func(ctx context.Context) error {
+	id := flagSet.String("id", "", "API key id")
+	return func(ctx context.Context) error {
+		if *id == "" {
+			return errors.New("flag -id is required")
+		}
+		if err := ctl.confirm(fmt.Sprintf("Revoke API key %s?", *id)); err != nil {
+			return err
+		}
+		return ctl.apiKeyService.RevokeApiKey(ctx, *id)
+	}
+}
+
+func (ctl *walletctl) printWalletById(ctx context.Context, id string) error {
+	wallet, err := ctl.walletService.GetWallet(ctx, id)
+	if err != nil {
+		return err
+	}
+	return ctl.printWallet(&walletOutput{
+		ID:       wallet.ID,
+		Name:     wallet.Name,
+		Currency: wallet.Currency,
+		Balance:  wallet.Balance,
+		Frozen:   wallet.Frozen,
+		Shards:   wallet.Shards,
+	})
+}
+
+func (ctl *walletctl) printWallet(wallet *walletOutput) error {
+	header := []string{"id", "name", "currency", "balance", "frozen", "shards"}
+	rows := [][]string{{
+		wallet.ID,
+		wallet.Name,
+		wallet.Currency,
+		strconv.FormatUint(wallet.Balance, 10),
+		strconv.FormatBool(wallet.Frozen),
+		strconv.Itoa(wallet.Shards),
+	}}
+	return ctl.print(header, rows, wallet)
+}
+
+func (ctl *walletctl) printTransactions(transactions []*model.Transaction) error {
+	rows := make([][]string, 0, len(transactions))
+	values := make([]*transactionOutput, 0, len(transactions))
+	for _, transaction := range transactions {
+		value := &transactionOutput{
+			ID:                transaction.ID,
+			OperationType:     transaction.OperationType.String(),
+			Amount:            transaction.Amount,
+			RecipientWalletId: transaction.RecipientWallet.ID,
+			Reference:         transaction.Reference,
+			ProcessedAt:       transaction.ProcessedAt,
+		}
+		if transaction.SenderWallet != nil {
+			value.SenderWalletId = transaction.SenderWallet.ID
+		}
+		rows = append(rows, []string{
+			value.ID,
+			value.OperationType,
+			strconv.FormatUint(value.Amount, 10),
+			value.SenderWalletId,
+			value.RecipientWalletId,
+			value.Reference,
+			value.ProcessedAt.Format(time.RFC3339),
+		})
+		values = append(values, value)
+	}
+	return ctl.print(transactionHeader, rows, values)
+}
@@ -0,0 +1,48 @@
+package walletctl
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJson  = "json"
+	outputCsv   = "csv"
+)
+
+// print writes the rows in the output format, JSON output is the value itself.
+func (ctl *walletctl) print(header []string, rows [][]string, value interface{}) error {
+	switch ctl.output {
+	case outputJson:
+		encoder := json.NewEncoder(ctl.out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case outputCsv:
+		writer := csv.NewWriter(ctl.out)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(ctl.out, 0, 0, 2, ' ', 0)
+		columns := make([]string, 0, len(header))
+		for _, column := range header {
+			columns = append(columns, strings.ToUpper(strings.ReplaceAll(column, "_", " ")))
+		}
+		if _, err := writer.Write([]byte(strings.Join(columns, "\t") + "\n")); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if _, err := writer.Write([]byte(strings.Join(row, "\t") + "\n")); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	}
+}
@@ -0,0 +1,23 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+type entryKey struct{}
+
+// WithEntry returns ctx carrying the audit entry of the request. A repository
+// able to record the entry with the operation does so once the operation
+// succeeds and sets the id of the entry, an entry without an id is left to be
+// recorded by the caller.
+func WithEntry(ctx context.Context, entry *model.AuditEntry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// EntryFromContext returns the audit entry of ctx, nil if there is none.
+func EntryFromContext(ctx context.Context) *model.AuditEntry {
+	entry, _ := ctx.Value(entryKey{}).(*model.AuditEntry)
+	return entry
+}
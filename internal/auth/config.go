@@ -0,0 +1,44 @@
+package auth
+
+import "time"
+
+type Config struct {
+	// Enabled requires an API key or a bearer token on every API request.
+	// Disabled, every request is granted all scopes, which is meant for local
+	// development only.
+	Enabled bool      `yaml:"enabled" env:"AUTH_ENABLED"`
+	Jwt     JwtConfig `yaml:"jwt"`
+}
+
+type JwtConfig struct {
+	// Jwks is the URL or the file path of the JSON Web Key Set the bearer tokens
+	// are signed with, bearer tokens are not accepted when it is empty.
+	Jwks     string `yaml:"jwks" env:"AUTH_JWT_JWKS"`
+	Issuer   string `yaml:"issuer" env:"AUTH_JWT_ISSUER"`
+	Audience string `yaml:"audience" env:"AUTH_JWT_AUDIENCE"`
+	// SubjectClaim is the claim identifying the principal of a token.
+	SubjectClaim string `yaml:"subject-claim" env:"AUTH_JWT_SUBJECT_CLAIM"`
+	// ScopesClaim is the claim listing the scopes of a token, either as a space
+	// separated string or as an array.
+	ScopesClaim string `yaml:"scopes-claim" env:"AUTH_JWT_SCOPES_CLAIM"`
+	// ScopeMapping maps scopes of the identity provider to API scopes. Scopes
+	// that are not mapped are granted when they are API scopes and ignored
+	// otherwise.
+	ScopeMapping map[string]string `yaml:"scope-mapping"`
+	// RefreshInterval is how often a JWKS URL is fetched again, a token signed
+	// by an unknown key triggers an earlier fetch.
+	RefreshInterval time.Duration `yaml:"refresh-interval" env:"AUTH_JWT_REFRESH_INTERVAL"`
+	RequestTimeout  time.Duration `yaml:"request-timeout" env:"AUTH_JWT_REQUEST_TIMEOUT"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled: true,
+		Jwt: JwtConfig{
+			SubjectClaim:    "sub",
+			ScopesClaim:     "scope",
+			RefreshInterval: time.Hour,
+			RequestTimeout:  time.Second * 10,
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+type principalKey struct{}
+
+// WithPrincipal returns ctx carrying the principal the request is made by.
+func WithPrincipal(ctx context.Context, principal *model.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of ctx, nil if there is none.
+func PrincipalFromContext(ctx context.Context) *model.Principal {
+	principal, _ := ctx.Value(principalKey{}).(*model.Principal)
+	return principal
+}
+
+// ApiKeyId returns the id of the API key of ctx, empty if there is none.
+func ApiKeyId(ctx context.Context) string {
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		return principal.ApiKeyId
+	}
+	return ""
+}
+
+// Anonymous is the principal of requests when authentication is disabled,
+// it has no API key so transactions record none.
+var Anonymous = &model.Principal{Subject: "anonymous", Scopes: []model.Scope{model.AdminScope}}
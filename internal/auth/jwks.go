@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// minRefreshInterval limits how often tokens signed by unknown keys make the
+// key set be fetched again.
+const minRefreshInterval = time.Minute
+
+// errKeySetUnavailable is returned when the key set cannot be loaded, as
+// opposed to a token naming a key the set does not have.
+var errKeySetUnavailable = errors.New("json web key set is unavailable")
+
+// keySet caches the public keys of a JWKS by their key id. A JWKS URL is
+// fetched again every refresh interval, a stale set is used while the URL
+// cannot be fetched.
+type keySet struct {
+	source          string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func newKeySet(source string, client *http.Client, refreshInterval time.Duration) *keySet {
+	return &keySet{
+		source:          source,
+		client:          client,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// key returns the public key of the key id. A token without a key id can be
+// verified when the set has a single key.
+func (keySet *keySet) key(ctx context.Context, kid string) (interface{}, error) {
+	keySet.mu.Lock()
+	defer keySet.mu.Unlock()
+
+	sinceFetch := time.Since(keySet.fetchedAt)
+	key, ok := keySet.lookup(kid)
+	if ok && sinceFetch < keySet.refreshInterval {
+		return key, nil
+	}
+	if !ok && keySet.keys != nil && sinceFetch < minRefreshInterval {
+		return nil, fmt.Errorf("%w: unknown key '%s'", model.ErrInvalidToken, kid)
+	}
+
+	keys, err := keySet.fetch(ctx)
+	if err != nil {
+		if ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("%w: %v", errKeySetUnavailable, err)
+	}
+	keySet.keys = keys
+	keySet.fetchedAt = time.Now()
+
+	if key, ok = keySet.lookup(kid); !ok {
+		return nil, fmt.Errorf("%w: unknown key '%s'", model.ErrInvalidToken, kid)
+	}
+	return key, nil
+}
+
+func (keySet *keySet) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(keySet.keys) == 1 {
+		for _, key := range keySet.keys {
+			return key, true
+		}
+	}
+	key, ok := keySet.keys[kid]
+	return key, ok
+}
+
+func (keySet *keySet) fetch(ctx context.Context) (map[string]interface{}, error) {
+	var data []byte
+	if strings.HasPrefix(keySet.source, "http://") || strings.HasPrefix(keySet.source, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, keySet.source, nil)
+		if err != nil {
+			return nil, fmt.Errorf("keySet - fetch - http.NewRequestWithContext: %w", err)
+		}
+		resp, err := keySet.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("keySet - fetch - keySet.client.Do: %w", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("keySet - fetch: unexpected status %d", resp.StatusCode)
+		}
+		if data, err = io.ReadAll(io.LimitReader(resp.Body, 1<<20)); err != nil {
+			return nil, fmt.Errorf("keySet - fetch - io.ReadAll: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = os.ReadFile(keySet.source); err != nil {
+			return nil, fmt.Errorf("keySet - fetch - os.ReadFile: %w", err)
+		}
+	}
+	keys, err := parseKeySet(data)
+	if err != nil {
+		return nil, fmt.Errorf("keySet - fetch - parseKeySet: %w", err)
+	}
+	return keys, nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// parseKeySet returns the RSA and EC signing keys of a JWKS, other keys are
+// skipped.
+func parseKeySet(data []byte) (map[string]interface{}, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("parseKeySet - json.Unmarshal: %w", err)
+	}
+	keys := make(map[string]interface{}, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		var key interface{}
+		var err error
+		switch jwk.Kty {
+		case "RSA":
+			key, err = parseRsaKey(jwk)
+		case "EC":
+			key, err = parseEcKey(jwk)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parseKeySet: key '%s': %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("parseKeySet: no signing keys")
+	}
+	return keys, nil
+}
+
+func parseRsaKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("parseRsaKey - decodeBigInt: %w", err)
+	}
+	e, err := decodeBigInt(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("parseRsaKey - decodeBigInt: %w", err)
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("parseRsaKey: invalid exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func parseEcKey(jwk jsonWebKey) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch jwk.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("parseEcKey: unsupported curve '%s'", jwk.Crv)
+	}
+	x, err := decodeBigInt(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("parseEcKey - decodeBigInt: %w", err)
+	}
+	y, err := decodeBigInt(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("parseEcKey - decodeBigInt: %w", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("parseEcKey: point is not on the curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
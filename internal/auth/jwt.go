@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// AuthorizationHeader carries the bearer token of an HTTP request.
+	AuthorizationHeader = "Authorization"
+	// AuthorizationMetadata carries the bearer token of a gRPC call.
+	AuthorizationMetadata = "authorization"
+)
+
+// TokenVerifier verifies bearer tokens and returns the principal they are
+// issued to.
+type TokenVerifier interface {
+	// Verify returns an error wrapping model.ErrInvalidToken when the token is
+	// not valid.
+	Verify(ctx context.Context, token string) (*model.Principal, error)
+}
+
+// BearerToken returns the token of an authorization header value, false when
+// the value is not a bearer one.
+func BearerToken(authorization string) (string, bool) {
+	const scheme = "bearer "
+	if len(authorization) <= len(scheme) || !strings.EqualFold(authorization[:len(scheme)], scheme) {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(scheme):]), true
+}
+
+type jwtVerifier struct {
+	config JwtConfig
+	keySet *keySet
+	parser *jwt.Parser
+}
+
+// NewJwtVerifier returns a verifier of tokens signed by the keys of the
+// configured JWKS, with an RSA or an ECDSA algorithm, issued by the configured
+// issuer to the configured audience.
+func NewJwtVerifier(config JwtConfig, client *http.Client) (*jwtVerifier, error) {
+	if config.Jwks == "" {
+		return nil, errors.New("auth - NewJwtVerifier: jwks is required")
+	}
+	if config.Issuer == "" || config.Audience == "" {
+		return nil, errors.New("auth - NewJwtVerifier: issuer and audience are required")
+	}
+	if client == nil {
+		client = &http.Client{Timeout: config.RequestTimeout}
+	}
+	return &jwtVerifier{
+		config: config,
+		keySet: newKeySet(config.Jwks, client, config.RefreshInterval),
+		parser: jwt.NewParser(jwt.WithValidMethods([]string{
+			"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512",
+		})),
+	}, nil
+}
+
+func (verifier *jwtVerifier) Verify(ctx context.Context, token string) (*model.Principal, error) {
+	claims := jwt.MapClaims{}
+	if _, err := verifier.parser.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return verifier.keySet.key(ctx, kid)
+	}); err != nil {
+		if errors.Is(err, errKeySetUnavailable) {
+			return nil, fmt.Errorf("JwtVerifier - Verify - verifier.parser.ParseWithClaims: %w", err)
+		}
+		return nil, fmt.Errorf("JwtVerifier - Verify - verifier.parser.ParseWithClaims: %w: %v", model.ErrInvalidToken, err)
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, fmt.Errorf("JwtVerifier - Verify: %w: missing expiration", model.ErrInvalidToken)
+	}
+	if !claims.VerifyIssuer(verifier.config.Issuer, true) {
+		return nil, fmt.Errorf("JwtVerifier - Verify: %w: unexpected issuer", model.ErrInvalidToken)
+	}
+	if !claims.VerifyAudience(verifier.config.Audience, true) {
+		return nil, fmt.Errorf("JwtVerifier - Verify: %w: unexpected audience", model.ErrInvalidToken)
+	}
+	subject, _ := claims[verifier.config.SubjectClaim].(string)
+	if subject == "" {
+		return nil, fmt.Errorf("JwtVerifier - Verify: %w: missing claim '%s'", model.ErrInvalidToken, verifier.config.SubjectClaim)
+	}
+	return &model.Principal{
+		Subject: subject,
+		Scopes:  verifier.scopes(claims[verifier.config.ScopesClaim]),
+	}, nil
+}
+
+// scopes maps the scopes claim to API scopes, the claim is either a space
+// separated string or an array of strings.
+func (verifier *jwtVerifier) scopes(claim interface{}) []model.Scope {
+	var values []string
+	switch claim := claim.(type) {
+	case string:
+		values = strings.Fields(claim)
+	case []interface{}:
+		for _, value := range claim {
+			if value, ok := value.(string); ok {
+				values = append(values, value)
+			}
+		}
+	}
+	var scopes []model.Scope
+	for _, value := range values {
+		if mapped, ok := verifier.config.ScopeMapping[value]; ok {
+			value = mapped
+		}
+		if scope, err := model.ScopeFromString(value); err == nil {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeBigInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func writeJwks(t *testing.T, keys ...jsonWebKey) string {
+	data, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testJwtConfig(jwks string) JwtConfig {
+	config := NewConfig().Jwt
+	config.Jwks = jwks
+	config.Issuer = "https://id.example.com"
+	config.Audience = "wallets-api"
+	config.ScopeMapping = map[string]string{"payments": model.TransfersCreateScope.String()}
+	return config
+}
+
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestJwtVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwks := writeJwks(t, jsonWebKey{
+		Kty: "RSA",
+		Kid: "rsa-1",
+		Use: "sig",
+		N:   encodeBigInt(rsaKey.N),
+		E:   encodeBigInt(big.NewInt(int64(rsaKey.E))),
+	})
+	claims := func(changes jwt.MapClaims) jwt.MapClaims {
+		claims := jwt.MapClaims{
+			"iss":   "https://id.example.com",
+			"aud":   []string{"wallets-api", "other-api"},
+			"sub":   "user-1",
+			"exp":   time.Now().Add(time.Minute).Unix(),
+			"scope": "openid wallets:read payments",
+		}
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+				continue
+			}
+			claims[name] = value
+		}
+		return claims
+	}
+	tests := []struct {
+		name              string
+		token             string
+		expectedPrincipal *model.Principal
+	}{
+		{
+			name:  "valid",
+			token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(nil)),
+			expectedPrincipal: &model.Principal{
+				Subject: "user-1",
+				Scopes:  []model.Scope{model.WalletsReadScope, model.TransfersCreateScope},
+			},
+		},
+		{name: "unexpected issuer", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"iss": "https://other.example.com"}))},
+		{name: "unexpected audience", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"aud": "other-api"}))},
+		{name: "expired", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))},
+		{name: "missing expiration", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"exp": nil}))},
+		{name: "missing subject", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, claims(jwt.MapClaims{"sub": nil}))},
+		{name: "other key", token: signToken(t, jwt.SigningMethodRS256, "rsa-1", otherKey, claims(nil))},
+		{name: "unknown key", token: signToken(t, jwt.SigningMethodRS256, "rsa-2", otherKey, claims(nil))},
+		{name: "hmac", token: signToken(t, jwt.SigningMethodHS256, "rsa-1", []byte("secret"), claims(nil))},
+		{name: "malformed", token: "not-a-token"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// given
+			verifier, err := NewJwtVerifier(testJwtConfig(jwks), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// when
+			principal, err := verifier.Verify(context.Background(), test.token)
+
+			// then
+			if test.expectedPrincipal == nil {
+				assert.ErrorIs(t, err, model.ErrInvalidToken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedPrincipal, principal)
+		})
+	}
+}
+
+func TestJwtVerifierJwksUrl(t *testing.T) {
+	// given
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetches := 0
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		fetches++
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{"keys": []jsonWebKey{{
+			Kty: "EC",
+			Kid: "ec-1",
+			Crv: "P-256",
+			X:   encodeBigInt(ecKey.X),
+			Y:   encodeBigInt(ecKey.Y),
+		}}})
+	}))
+	defer jwksServer.Close()
+	verifier, err := NewJwtVerifier(testJwtConfig(jwksServer.URL), jwksServer.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := signToken(t, jwt.SigningMethodES256, "ec-1", ecKey, jwt.MapClaims{
+		"iss":   "https://id.example.com",
+		"aud":   "wallets-api",
+		"sub":   "user-1",
+		"exp":   time.Now().Add(time.Minute).Unix(),
+		"scope": []string{"wallets:write"},
+	})
+
+	// when
+	principal, err := verifier.Verify(context.Background(), token)
+	_, otherErr := verifier.Verify(context.Background(), token)
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, otherErr)
+	assert.Equal(t, &model.Principal{Subject: "user-1", Scopes: []model.Scope{model.WalletsWriteScope}}, principal)
+	assert.Equal(t, 1, fetches)
+}
+
+func TestJwtVerifierJwksUnavailable(t *testing.T) {
+	// given
+	verifier, err := NewJwtVerifier(testJwtConfig(filepath.Join(t.TempDir(), "missing.json")), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	_, err = verifier.Verify(context.Background(), signToken(t, jwt.SigningMethodRS256, "rsa-1", rsaKey, jwt.MapClaims{"sub": "user-1"}))
+
+	// then
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, model.ErrInvalidToken)
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		authorization string
+		expectedToken string
+		expectedOk    bool
+	}{
+		{authorization: "Bearer abc", expectedToken: "abc", expectedOk: true},
+		{authorization: "bearer abc", expectedToken: "abc", expectedOk: true},
+		{authorization: "Basic abc"},
+		{authorization: "Bearer "},
+		{authorization: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.authorization, func(t *testing.T) {
+			// when
+			token, ok := BearerToken(test.authorization)
+
+			// then
+			assert.Equal(t, test.expectedToken, token)
+			assert.Equal(t, test.expectedOk, ok)
+		})
+	}
+}
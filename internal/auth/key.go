@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	// ApiKeyHeader carries the API key of an HTTP request.
+	ApiKeyHeader = "X-API-Key"
+	// ApiKeyMetadata carries the API key of a gRPC call.
+	ApiKeyMetadata = "x-api-key"
+
+	keyPrefix = "wk_"
+	// prefixLength is the length of the key prefix stored in clear, it lets
+	// operators tell keys apart without revealing them.
+	prefixLength = len(keyPrefix) + 8
+)
+
+// GenerateKey returns a new random API key and its prefix.
+func GenerateKey() (string, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("auth - GenerateKey - rand.Read: %w", err)
+	}
+	key := keyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return key, key[:prefixLength], nil
+}
+
+// HashKey returns the hash the API key is stored and looked up by. Keys are
+// random with 256 bits of entropy, so a plain SHA-256 is enough.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKey(t *testing.T) {
+	// when
+	key, prefix, err := GenerateKey()
+	otherKey, _, otherErr := GenerateKey()
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, otherErr)
+	assert.True(t, strings.HasPrefix(key, prefix))
+	assert.True(t, strings.HasPrefix(prefix, "wk_"))
+	assert.NotEqual(t, key, otherKey)
+	assert.NotEqual(t, HashKey(key), HashKey(otherKey))
+	assert.Equal(t, HashKey(key), HashKey(key))
+}
@@ -0,0 +1,20 @@
+// Package buildinfo holds the build metadata of the binaries, injected at link
+// time:
+//
+//	go build -ldflags "-X github.com/SergeyChupin/wallets-api/internal/buildinfo.Version=v1.2.0 \
+//		-X github.com/SergeyChupin/wallets-api/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X github.com/SergeyChupin/wallets-api/internal/buildinfo.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import "runtime"
+
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// GoVersion returns the version of Go the binary is built with.
+func GoVersion() string {
+	return runtime.Version()
+}
@@ -0,0 +1,17 @@
+package consolidation
+
+import "time"
+
+type Config struct {
+	Enabled   bool          `yaml:"enabled" env:"CONSOLIDATION_ENABLED"`
+	Interval  time.Duration `yaml:"interval" env:"CONSOLIDATION_INTERVAL"`
+	BatchSize int           `yaml:"batch-size" env:"CONSOLIDATION_BATCH_SIZE"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled:   true,
+		Interval:  time.Second * 10,
+		BatchSize: 100,
+	}
+}
@@ -0,0 +1,83 @@
+package consolidation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+type Consolidator interface {
+	Start()
+	Stop()
+	Consolidate() error
+}
+
+type consolidator struct {
+	logger                *logging.Logger
+	walletShardRepository repository.WalletShardRepository
+	config                Config
+	stop                  chan struct{}
+	done                  sync.WaitGroup
+}
+
+func NewConsolidator(logger *logging.Logger, walletShardRepository repository.WalletShardRepository, config Config) *consolidator {
+	return &consolidator{
+		logger:                logger,
+		walletShardRepository: walletShardRepository,
+		config:                config,
+		stop:                  make(chan struct{}),
+	}
+}
+
+// Start merges the balance shards of sharded wallets in the background until Stop is called.
+func (consolidator *consolidator) Start() {
+	consolidator.logger.Info(context.Background(), "Starting balance shards consolidator")
+	consolidator.done.Add(1)
+	go func() {
+		defer consolidator.done.Done()
+		ticker := time.NewTicker(consolidator.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-consolidator.stop:
+				return
+			case <-ticker.C:
+				if err := consolidator.Consolidate(); err != nil {
+					consolidator.logger.Error(context.Background(), "consolidator - Start - consolidator.Consolidate", logging.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop waits for the current consolidation round to finish and stops the consolidator.
+func (consolidator *consolidator) Stop() {
+	consolidator.logger.Info(context.Background(), "Stopping balance shards consolidator")
+	close(consolidator.stop)
+	consolidator.done.Wait()
+}
+
+// Consolidate runs a single round: batches of wallets are merged until a batch
+// is not full.
+func (consolidator *consolidator) Consolidate() error {
+	for {
+		consolidated, err := consolidator.walletShardRepository.ConsolidateWalletShards(
+			context.Background(), consolidator.config.BatchSize,
+		)
+		if err != nil {
+			return fmt.Errorf("consolidator - Consolidate - consolidator.walletShardRepository.ConsolidateWalletShards: %w", err)
+		}
+		if consolidated < consolidator.config.BatchSize {
+			return nil
+		}
+		select {
+		case <-consolidator.stop:
+			return nil
+		default:
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package consolidation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var logger = logging.Discard()
+
+type walletShardRepositoryMock struct {
+	mock.Mock
+}
+
+func (walletShardRepository *walletShardRepositoryMock) ConsolidateWalletShards(ctx context.Context, limit int) (int, error) {
+	args := walletShardRepository.Called(limit)
+	return args.Int(0), args.Error(1)
+}
+
+func TestConsolidateUntilBatchIsNotFull(t *testing.T) {
+	// given
+	config := NewConfig()
+	config.BatchSize = 10
+	walletShardRepository := new(walletShardRepositoryMock)
+	walletShardRepository.On("ConsolidateWalletShards", 10).Return(10, nil).Twice()
+	walletShardRepository.On("ConsolidateWalletShards", 10).Return(3, nil).Once()
+	consolidator := NewConsolidator(logger, walletShardRepository, config)
+
+	// when
+	err := consolidator.Consolidate()
+
+	// then
+	assert.NoError(t, err)
+	walletShardRepository.AssertExpectations(t)
+	walletShardRepository.AssertNumberOfCalls(t, "ConsolidateWalletShards", 3)
+}
+
+func TestConsolidateError(t *testing.T) {
+	// given
+	walletShardRepository := new(walletShardRepositoryMock)
+	walletShardRepository.On("ConsolidateWalletShards", 100).Return(0, errors.New("connection refused"))
+	consolidator := NewConsolidator(logger, walletShardRepository, NewConfig())
+
+	// when
+	err := consolidator.Consolidate()
+
+	// then
+	assert.Error(t, err)
+	walletShardRepository.AssertExpectations(t)
+}
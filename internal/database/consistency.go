@@ -0,0 +1,23 @@
+package database
+
+import "context"
+
+// ReadConsistencyHeader set to StrongReadConsistency routes the reads of
+// a request to the primary, so they see the writes already made.
+const (
+	ReadConsistencyHeader = "X-Read-Consistency"
+	StrongReadConsistency = "strong"
+)
+
+type primaryReadsKey struct{}
+
+// WithPrimaryReads marks ctx so that reads are not routed to replicas.
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReads reports whether the reads of ctx have to go to the primary.
+func PrimaryReads(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
@@ -0,0 +1,11 @@
+package migrations
+
+type Config struct {
+	RunOnStartup bool `yaml:"run-on-startup" env:"MIGRATIONS_RUN_ON_STARTUP"`
+}
+
+func NewConfig() Config {
+	return Config{
+		RunOnStartup: false,
+	}
+}
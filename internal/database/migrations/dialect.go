@@ -0,0 +1,67 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Dialect holds the database specific parts of migrating a schema.
+type Dialect struct {
+	name             string
+	dir              string
+	tableExistsQuery string
+	createTableQuery string
+	// lock serializes migrations of concurrent instances on conn.
+	lock   func(ctx context.Context, conn *sql.Conn) error
+	unlock func(ctx context.Context, conn *sql.Conn) error
+}
+
+func (dialect Dialect) String() string {
+	return dialect.name
+}
+
+// lockKey is the Postgres advisory lock that serializes migrations of concurrent instances.
+const lockKey int64 = 7_317_240_516
+
+var (
+	Postgres = Dialect{
+		name:             "postgres",
+		dir:              "sql/postgres",
+		tableExistsQuery: "SELECT to_regclass('schema_migrations') IS NOT NULL",
+		createTableQuery: `
+			CREATE TABLE IF NOT EXISTS schema_migrations
+			(
+			    version    BIGINT PRIMARY KEY,
+			    name       TEXT                        NOT NULL,
+			    applied_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC')
+			)`,
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey)
+			return err
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn) error {
+			_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", lockKey)
+			return err
+		},
+	}
+	// Sqlite relies on write transactions being serialized, every migration
+	// re-reads the schema version inside its transaction.
+	Sqlite = Dialect{
+		name:             "sqlite",
+		dir:              "sql/sqlite",
+		tableExistsQuery: "SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations')",
+		createTableQuery: `
+			CREATE TABLE IF NOT EXISTS schema_migrations
+			(
+			    version    INTEGER PRIMARY KEY,
+			    name       TEXT      NOT NULL,
+			    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)`,
+		lock: func(ctx context.Context, conn *sql.Conn) error {
+			return nil
+		},
+		unlock: func(ctx context.Context, conn *sql.Conn) error {
+			return nil
+		},
+	}
+)
@@ -0,0 +1,101 @@
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed sql/postgres/*.sql sql/sqlite/*.sql
+var files embed.FS
+
+// Migration is a schema change, Down reverts Up.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Load returns the embedded migrations of the dialect ordered by version.
+func Load(dialect Dialect) ([]*Migration, error) {
+	return load(files, dialect.dir)
+}
+
+// LatestVersion returns the schema version the binary expects.
+func LatestVersion(dialect Dialect) (int, error) {
+	migrations, err := Load(dialect)
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// load reads migrations from files named <version>_<name>.up.sql and <version>_<name>.down.sql.
+func load(fsys fs.FS, dir string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("migrations - load - fs.ReadDir: %w", err)
+	}
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migrations - load: unexpected file %s", fileName)
+		}
+		baseName := strings.TrimSuffix(fileName, "."+direction+".sql")
+		separator := strings.Index(baseName, "_")
+		if separator <= 0 {
+			return nil, fmt.Errorf("migrations - load: invalid file name %s", fileName)
+		}
+		version, err := strconv.Atoi(baseName[:separator])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrations - load: invalid version in file name %s", fileName)
+		}
+		content, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("migrations - load - fs.ReadFile: %w", err)
+		}
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: baseName[separator+1:]}
+			byVersion[version] = migration
+		} else if migration.Name != baseName[separator+1:] {
+			return nil, fmt.Errorf("migrations - load: conflicting names for version %d", version)
+		}
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migrations - load: missing version %d", i+1)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migrations - load: version %d should have up and down files", migration.Version)
+		}
+	}
+	return migrations, nil
+}
@@ -0,0 +1,79 @@
+package migrations
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadEmbedded(t *testing.T) {
+	for _, dialect := range []Dialect{Postgres, Sqlite} {
+		t.Run(dialect.String(), func(t *testing.T) {
+			testLoadEmbedded(t, dialect)
+		})
+	}
+}
+
+func testLoadEmbedded(t *testing.T, dialect Dialect) {
+	// when
+	migrations, err := Load(dialect)
+
+	// then
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version)
+		assert.NotEmpty(t, migration.Up)
+		assert.NotEmpty(t, migration.Down)
+	}
+}
+
+func TestLoadOrdersByVersion(t *testing.T) {
+	// given
+	fsys := fstest.MapFS{
+		"sql/0002_second.up.sql":   {Data: []byte("up 2")},
+		"sql/0002_second.down.sql": {Data: []byte("down 2")},
+		"sql/0001_first.up.sql":    {Data: []byte("up 1")},
+		"sql/0001_first.down.sql":  {Data: []byte("down 1")},
+	}
+
+	// when
+	migrations, err := load(fsys, "sql")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []*Migration{
+		{Version: 1, Name: "first", Up: "up 1", Down: "down 1"},
+		{Version: 2, Name: "second", Up: "up 2", Down: "down 2"},
+	}, migrations)
+}
+
+func TestLoadMissingVersion(t *testing.T) {
+	// given
+	fsys := fstest.MapFS{
+		"sql/0001_first.up.sql":   {Data: []byte("up 1")},
+		"sql/0001_first.down.sql": {Data: []byte("down 1")},
+		"sql/0003_third.up.sql":   {Data: []byte("up 3")},
+		"sql/0003_third.down.sql": {Data: []byte("down 3")},
+	}
+
+	// when
+	_, err := load(fsys, "sql")
+
+	// then
+	assert.EqualError(t, err, "migrations - load: missing version 2")
+}
+
+func TestLoadMissingDown(t *testing.T) {
+	// given
+	fsys := fstest.MapFS{
+		"sql/0001_first.up.sql": {Data: []byte("up 1")},
+	}
+
+	// when
+	_, err := load(fsys, "sql")
+
+	// then
+	assert.EqualError(t, err, "migrations - load: version 1 should have up and down files")
+}
@@ -0,0 +1,216 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+)
+
+var ErrSchemaOutdated = errors.New("database schema is outdated")
+
+type Migrator interface {
+	Up(ctx context.Context) error
+	Down(ctx context.Context, steps int) error
+	Force(ctx context.Context, version int) error
+	Version(ctx context.Context) (int, error)
+	Check(ctx context.Context) error
+}
+
+type migrator struct {
+	logger  *logging.Logger
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewMigrator(logger *logging.Logger, db *sql.DB, dialect Dialect) *migrator {
+	return &migrator{
+		logger:  logger,
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+// Up applies all migrations newer than the current schema version.
+func (migrator *migrator) Up(ctx context.Context) error {
+	migrations, err := Load(migrator.dialect)
+	if err != nil {
+		return fmt.Errorf("Migrator - Up - Load: %w", err)
+	}
+	return migrator.locked(ctx, func(conn *sql.Conn) error {
+		for _, migration := range migrations {
+			applied, err := migrator.apply(ctx, conn, migration.Version-1, migration.Up,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("Migrator - Up - migrator.apply %d: %w", migration.Version, err)
+			}
+			if applied {
+				migrator.logger.Info(ctx, "Applied migration", logging.Int("version", migration.Version), logging.String("name", migration.Name))
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the given number of the latest applied migrations.
+func (migrator *migrator) Down(ctx context.Context, steps int) error {
+	migrations, err := Load(migrator.dialect)
+	if err != nil {
+		return fmt.Errorf("Migrator - Down - Load: %w", err)
+	}
+	return migrator.locked(ctx, func(conn *sql.Conn) error {
+		version, err := currentVersion(ctx, conn)
+		if err != nil {
+			return fmt.Errorf("Migrator - Down - currentVersion: %w", err)
+		}
+		if version > len(migrations) {
+			return fmt.Errorf("Migrator - Down: schema version %d is newer than the binary", version)
+		}
+		for ; steps > 0 && version > 0; steps, version = steps-1, version-1 {
+			migration := migrations[version-1]
+			applied, err := migrator.apply(ctx, conn, migration.Version, migration.Down,
+				"DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			if err != nil {
+				return fmt.Errorf("Migrator - Down - migrator.apply %d: %w", migration.Version, err)
+			}
+			if !applied {
+				return fmt.Errorf("Migrator - Down: schema version changed concurrently")
+			}
+			migrator.logger.Info(ctx, "Reverted migration", logging.Int("version", migration.Version), logging.String("name", migration.Name))
+		}
+		return nil
+	})
+}
+
+// Force records the schema as being at the version without running migrations,
+// it is meant for databases whose schema was created by other means.
+func (migrator *migrator) Force(ctx context.Context, version int) error {
+	migrations, err := Load(migrator.dialect)
+	if err != nil {
+		return fmt.Errorf("Migrator - Force - Load: %w", err)
+	}
+	if version < 0 || version > len(migrations) {
+		return fmt.Errorf("Migrator - Force: unknown version %d", version)
+	}
+	return migrator.locked(ctx, func(conn *sql.Conn) error {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("Migrator - Force - conn.BeginTx: %w", err)
+		}
+		defer func() {
+			_ = tx.Rollback()
+		}()
+		if _, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+			return fmt.Errorf("Migrator - Force - tx.ExecContext: %w", err)
+		}
+		for _, migration := range migrations[:version] {
+			_, err = tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", migration.Version, migration.Name)
+			if err != nil {
+				return fmt.Errorf("Migrator - Force - tx.ExecContext: %w", err)
+			}
+		}
+		if err = tx.Commit(); err != nil {
+			return fmt.Errorf("Migrator - Force - tx.Commit: %w", err)
+		}
+		return nil
+	})
+}
+
+// Version returns the current schema version, 0 when no migration is applied.
+func (migrator *migrator) Version(ctx context.Context) (int, error) {
+	var exists bool
+	if err := migrator.db.QueryRowContext(ctx, migrator.dialect.tableExistsQuery).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("Migrator - Version - migrator.db.QueryRowContext: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+	var version int
+	err := migrator.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("Migrator - Version - migrator.db.QueryRowContext: %w", err)
+	}
+	return version, nil
+}
+
+// Check returns ErrSchemaOutdated when the schema is older than the binary expects.
+func (migrator *migrator) Check(ctx context.Context) error {
+	expected, err := LatestVersion(migrator.dialect)
+	if err != nil {
+		return fmt.Errorf("Migrator - Check - LatestVersion: %w", err)
+	}
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("Migrator - Check - migrator.Version: %w", err)
+	}
+	if version < expected {
+		return fmt.Errorf("Migrator - Check: version %d, expected %d: %w", version, expected, ErrSchemaOutdated)
+	}
+	if version > expected {
+		migrator.logger.Warn(ctx, "Database schema version is newer than expected", logging.Int("version", version), logging.Int("expected", expected))
+	}
+	return nil
+}
+
+// locked runs f on a single connection holding the migrations lock of the dialect.
+func (migrator *migrator) locked(ctx context.Context, f func(conn *sql.Conn) error) error {
+	conn, err := migrator.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("Migrator - locked - migrator.db.Conn: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+	if err = migrator.dialect.lock(ctx, conn); err != nil {
+		return fmt.Errorf("Migrator - locked - migrator.dialect.lock: %w", err)
+	}
+	defer func() {
+		if err := migrator.dialect.unlock(context.Background(), conn); err != nil {
+			migrator.logger.Error(ctx, "Migrator - locked - migrator.dialect.unlock", logging.Err(err))
+		}
+	}()
+	if _, err = conn.ExecContext(ctx, migrator.dialect.createTableQuery); err != nil {
+		return fmt.Errorf("Migrator - locked - create schema_migrations: %w", err)
+	}
+	return f(conn)
+}
+
+// apply runs the migration script and records it in one transaction, provided
+// the schema is at the version when the transaction starts.
+func (migrator *migrator) apply(ctx context.Context, conn *sql.Conn, version int, script string, record string, args ...interface{}) (bool, error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("conn.BeginTx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	current, err := currentVersion(ctx, tx)
+	if err != nil {
+		return false, fmt.Errorf("currentVersion: %w", err)
+	}
+	if current != version {
+		return false, nil
+	}
+	if _, err = tx.ExecContext(ctx, script); err != nil {
+		return false, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if _, err = tx.ExecContext(ctx, record, args...); err != nil {
+		return false, fmt.Errorf("tx.ExecContext: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("tx.Commit: %w", err)
+	}
+	return true, nil
+}
+
+func currentVersion(ctx context.Context, queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}) (int, error) {
+	var version int
+	err := queryer.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
@@ -0,0 +1,5 @@
+DROP TABLE deposit_import_rows;
+
+DROP TABLE deposit_imports;
+
+ALTER TABLE transactions DROP COLUMN reference;
@@ -0,0 +1,25 @@
+ALTER TABLE transactions ADD COLUMN reference TEXT NULL UNIQUE;
+
+CREATE TABLE deposit_imports
+(
+    id          UUID                                 DEFAULT uuid_generate_v4() PRIMARY KEY,
+    status      TEXT                        NOT NULL,
+    dry_run     BOOLEAN                     NOT NULL,
+    atomic      BOOLEAN                     NOT NULL,
+    created_at  TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+    finished_at TIMESTAMP WITHOUT TIME ZONE NULL
+);
+
+CREATE TABLE deposit_import_rows
+(
+    import_id      UUID   NOT NULL,
+    line           INT    NOT NULL,
+    wallet_id      TEXT   NOT NULL,
+    amount         BIGINT NOT NULL,
+    reference      TEXT   NOT NULL,
+    status         TEXT   NOT NULL,
+    error          TEXT   NULL,
+    transaction_id UUID   NULL,
+    PRIMARY KEY (import_id, line),
+    FOREIGN KEY (import_id) REFERENCES deposit_imports (id)
+);
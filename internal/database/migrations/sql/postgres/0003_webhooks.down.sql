@@ -0,0 +1,5 @@
+DROP TABLE webhook_deliveries;
+
+DROP TABLE webhook_subscriptions;
+
+DROP TABLE outbox_events;
@@ -0,0 +1,37 @@
+CREATE TABLE outbox_events
+(
+    id            UUID                                 DEFAULT uuid_generate_v4() PRIMARY KEY,
+    event_type    TEXT                        NOT NULL,
+    payload       JSONB                       NOT NULL,
+    created_at    TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+    dispatched_at TIMESTAMP WITHOUT TIME ZONE NULL
+);
+
+CREATE INDEX outbox_events_undispatched_idx ON outbox_events (created_at) WHERE dispatched_at IS NULL;
+
+CREATE TABLE webhook_subscriptions
+(
+    id          UUID                                 DEFAULT uuid_generate_v4() PRIMARY KEY,
+    url         TEXT                        NOT NULL,
+    secret      TEXT                        NOT NULL,
+    event_types TEXT[]                      NOT NULL,
+    active      BOOLEAN                     NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC')
+);
+
+CREATE TABLE webhook_deliveries
+(
+    id              UUID                                 DEFAULT uuid_generate_v4() PRIMARY KEY,
+    subscription_id UUID                        NOT NULL,
+    event_id        UUID                        NOT NULL,
+    status          TEXT                        NOT NULL,
+    attempts        INT                         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+    last_error      TEXT                        NULL,
+    delivered_at    TIMESTAMP WITHOUT TIME ZONE NULL,
+    UNIQUE (subscription_id, event_id),
+    FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id),
+    FOREIGN KEY (event_id) REFERENCES outbox_events (id)
+);
+
+CREATE INDEX webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
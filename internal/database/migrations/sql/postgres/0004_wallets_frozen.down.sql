@@ -0,0 +1 @@
+ALTER TABLE wallets DROP COLUMN frozen;
@@ -0,0 +1 @@
+ALTER TABLE wallets ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT FALSE;
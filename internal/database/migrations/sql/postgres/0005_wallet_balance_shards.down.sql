@@ -0,0 +1,7 @@
+UPDATE wallets w SET balance = w.balance + s.balance
+FROM (SELECT wallet_id, SUM(balance) AS balance FROM wallet_balance_shards GROUP BY wallet_id) s
+WHERE w.id = s.wallet_id;
+
+DROP TABLE wallet_balance_shards;
+
+ALTER TABLE wallets DROP COLUMN balance_shards;
@@ -0,0 +1,12 @@
+ALTER TABLE wallets ADD COLUMN balance_shards INT NOT NULL DEFAULT 0 CHECK (balance_shards >= 0);
+
+CREATE TABLE wallet_balance_shards
+(
+    wallet_id UUID   NOT NULL,
+    shard     INT    NOT NULL,
+    balance   BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    PRIMARY KEY (wallet_id, shard),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+);
+
+CREATE INDEX wallet_balance_shards_pending_idx ON wallet_balance_shards (wallet_id) WHERE balance > 0;
@@ -0,0 +1,22 @@
+CREATE TABLE wallet_entries
+(
+    wallet_id      UUID                        NOT NULL,
+    transaction_id UUID                        NOT NULL,
+    operation_type TEXT                        NOT NULL,
+    amount         BIGINT                      NOT NULL,
+    balance        BIGINT                      NOT NULL,
+    processed_at   TIMESTAMP WITHOUT TIME ZONE NOT NULL,
+    PRIMARY KEY (wallet_id, transaction_id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions (id)
+);
+
+INSERT INTO wallet_entries (wallet_id, transaction_id, operation_type, amount, balance, processed_at)
+SELECT recipient_wallet_id, id, operation_type, amount, recipient_wallet_balance, processed_at
+FROM transactions
+UNION ALL
+SELECT sender_wallet_id, id, operation_type, -amount, sender_wallet_balance, processed_at
+FROM transactions
+WHERE sender_wallet_id IS NOT NULL;
+
+CREATE INDEX wallet_entries_history_idx ON wallet_entries (wallet_id, processed_at, transaction_id);
@@ -0,0 +1,5 @@
+ALTER TABLE deposit_imports DROP COLUMN api_key_id;
+
+ALTER TABLE transactions DROP COLUMN api_key_id;
+
+DROP TABLE api_keys;
@@ -0,0 +1,15 @@
+CREATE TABLE api_keys
+(
+    id         UUID                                 DEFAULT uuid_generate_v4() PRIMARY KEY,
+    name       TEXT                        NOT NULL,
+    prefix     TEXT                        NOT NULL,
+    key_hash   TEXT                        NOT NULL UNIQUE,
+    scopes     TEXT[]                      NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+    rotated_at TIMESTAMP WITHOUT TIME ZONE NULL,
+    revoked_at TIMESTAMP WITHOUT TIME ZONE NULL
+);
+
+ALTER TABLE transactions ADD COLUMN api_key_id UUID NULL REFERENCES api_keys (id);
+
+ALTER TABLE deposit_imports ADD COLUMN api_key_id UUID NULL REFERENCES api_keys (id);
@@ -0,0 +1,3 @@
+DROP TABLE wallet_grants;
+
+ALTER TABLE wallets DROP COLUMN owner;
@@ -0,0 +1,10 @@
+ALTER TABLE wallets ADD COLUMN owner TEXT NULL;
+
+CREATE TABLE wallet_grants
+(
+    wallet_id  UUID                        NOT NULL REFERENCES wallets (id),
+    subject    TEXT                        NOT NULL,
+    role       TEXT                        NOT NULL,
+    created_at TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+    PRIMARY KEY (wallet_id, subject)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE wallets DROP COLUMN owner_id;
+
+DROP TABLE owners;
@@ -0,0 +1,15 @@
+CREATE TABLE owners
+(
+    id          UUID                                 DEFAULT uuid_generate_v4() PRIMARY KEY,
+    external_id TEXT                        NULL UNIQUE,
+    name        TEXT                        NOT NULL,
+    email       TEXT                        NULL,
+    phone       TEXT                        NULL,
+    kyc_tier    INTEGER                     NOT NULL DEFAULT 0 CHECK (kyc_tier >= 0),
+    created_at  TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC'),
+    updated_at  TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC')
+);
+
+ALTER TABLE wallets ADD COLUMN owner_id UUID NULL REFERENCES owners (id);
+
+CREATE UNIQUE INDEX wallets_owner_id_currency_idx ON wallets (owner_id, currency);
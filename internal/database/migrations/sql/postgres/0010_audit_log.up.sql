@@ -0,0 +1,29 @@
+CREATE TABLE audit_log
+(
+    id           UUID                                 DEFAULT uuid_generate_v4() PRIMARY KEY,
+    actor        TEXT                        NOT NULL,
+    action       TEXT                        NOT NULL,
+    wallet_id    TEXT                        NULL,
+    payload_hash TEXT                        NOT NULL,
+    outcome      TEXT                        NOT NULL,
+    client_ip    TEXT                        NOT NULL,
+    request_id   TEXT                        NULL,
+    created_at   TIMESTAMP WITHOUT TIME ZONE NOT NULL DEFAULT (now() AT TIME ZONE 'UTC')
+);
+
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at, id);
+
+CREATE INDEX audit_log_wallet_id_idx ON audit_log (wallet_id, created_at);
+
+CREATE FUNCTION audit_log_append_only() RETURNS TRIGGER AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE
+    ON audit_log
+    FOR EACH ROW
+EXECUTE FUNCTION audit_log_append_only();
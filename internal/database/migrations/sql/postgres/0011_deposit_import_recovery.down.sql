@@ -0,0 +1,3 @@
+ALTER TABLE deposit_imports DROP COLUMN lease_until;
+
+DROP TABLE deposit_references;
@@ -0,0 +1,11 @@
+CREATE TABLE deposit_references
+(
+    reference TEXT PRIMARY KEY
+);
+
+INSERT INTO deposit_references (reference)
+SELECT reference
+FROM transactions
+WHERE reference IS NOT NULL;
+
+ALTER TABLE deposit_imports ADD COLUMN lease_until TIMESTAMP WITHOUT TIME ZONE NULL;
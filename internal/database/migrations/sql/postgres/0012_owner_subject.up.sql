@@ -0,0 +1,19 @@
+ALTER TABLE owners ADD COLUMN subject TEXT NULL UNIQUE;
+
+-- An owner gets the subject owning all of its wallets, unless the subject owns
+-- wallets of another owner too. The wallets keep their owners.
+UPDATE owners
+SET subject = (SELECT MIN(owner) FROM wallets WHERE owner_id = owners.id)
+WHERE EXISTS (SELECT 1 FROM wallets WHERE owner_id = owners.id)
+  AND NOT EXISTS (
+    SELECT 1
+    FROM wallets
+    WHERE owner_id = owners.id
+      AND (owner IS NULL OR owner <> (SELECT MIN(owner) FROM wallets WHERE owner_id = owners.id))
+  )
+  AND NOT EXISTS (
+    SELECT 1
+    FROM wallets
+    WHERE owner = (SELECT MIN(owner) FROM wallets WHERE owner_id = owners.id)
+      AND owner_id <> owners.id
+  );
@@ -0,0 +1 @@
+ALTER TABLE deposit_imports DROP COLUMN actor;
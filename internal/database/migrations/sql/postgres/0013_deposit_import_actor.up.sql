@@ -0,0 +1,5 @@
+ALTER TABLE deposit_imports ADD COLUMN actor TEXT NULL;
+
+UPDATE deposit_imports
+SET actor = 'apikey:' || api_key_id
+WHERE api_key_id IS NOT NULL;
@@ -0,0 +1,27 @@
+CREATE TABLE wallets
+(
+    id       TEXT PRIMARY KEY,
+    name     TEXT    NOT NULL UNIQUE,
+    currency TEXT    NOT NULL,
+    balance  INTEGER NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    frozen   BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE transactions
+(
+    id                       TEXT PRIMARY KEY,
+    operation_type           TEXT      NOT NULL,
+    amount                   INTEGER   NOT NULL,
+    sender_wallet_id         TEXT      NULL,
+    sender_wallet_balance    INTEGER   NULL,
+    recipient_wallet_id      TEXT      NOT NULL,
+    recipient_wallet_balance INTEGER   NOT NULL,
+    processed_at             TIMESTAMP NOT NULL,
+    reference                TEXT      NULL UNIQUE,
+    FOREIGN KEY (sender_wallet_id) REFERENCES wallets (id),
+    FOREIGN KEY (recipient_wallet_id) REFERENCES wallets (id)
+);
+
+CREATE INDEX transactions_sender_wallet_idx ON transactions (sender_wallet_id, processed_at);
+
+CREATE INDEX transactions_recipient_wallet_idx ON transactions (recipient_wallet_id, processed_at);
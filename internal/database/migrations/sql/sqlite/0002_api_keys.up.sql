@@ -0,0 +1,13 @@
+CREATE TABLE api_keys
+(
+    id         TEXT PRIMARY KEY,
+    name       TEXT      NOT NULL,
+    prefix     TEXT      NOT NULL,
+    key_hash   TEXT      NOT NULL UNIQUE,
+    scopes     TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    rotated_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL
+);
+
+ALTER TABLE transactions ADD COLUMN api_key_id TEXT NULL;
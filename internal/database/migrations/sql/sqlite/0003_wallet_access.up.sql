@@ -0,0 +1,11 @@
+ALTER TABLE wallets ADD COLUMN owner TEXT NULL;
+
+CREATE TABLE wallet_grants
+(
+    wallet_id  TEXT      NOT NULL,
+    subject    TEXT      NOT NULL,
+    role       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (wallet_id, subject),
+    FOREIGN KEY (wallet_id) REFERENCES wallets (id)
+);
@@ -0,0 +1,5 @@
+DROP INDEX wallets_owner_id_currency_idx;
+
+ALTER TABLE wallets DROP COLUMN owner_id;
+
+DROP TABLE owners;
@@ -0,0 +1,15 @@
+CREATE TABLE owners
+(
+    id          TEXT PRIMARY KEY,
+    external_id TEXT      NULL UNIQUE,
+    name        TEXT      NOT NULL,
+    email       TEXT      NULL,
+    phone       TEXT      NULL,
+    kyc_tier    INTEGER   NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP NOT NULL,
+    updated_at  TIMESTAMP NOT NULL
+);
+
+ALTER TABLE wallets ADD COLUMN owner_id TEXT NULL;
+
+CREATE UNIQUE INDEX wallets_owner_id_currency_idx ON wallets (owner_id, currency);
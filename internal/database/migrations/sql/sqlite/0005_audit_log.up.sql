@@ -0,0 +1,30 @@
+CREATE TABLE audit_log
+(
+    id           TEXT PRIMARY KEY,
+    actor        TEXT      NOT NULL,
+    action       TEXT      NOT NULL,
+    wallet_id    TEXT      NULL,
+    payload_hash TEXT      NOT NULL,
+    outcome      TEXT      NOT NULL,
+    client_ip    TEXT      NOT NULL,
+    request_id   TEXT      NULL,
+    created_at   TIMESTAMP NOT NULL
+);
+
+CREATE INDEX audit_log_created_at_idx ON audit_log (created_at);
+
+CREATE INDEX audit_log_wallet_id_idx ON audit_log (wallet_id, created_at);
+
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE
+    ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER audit_log_no_delete
+    BEFORE DELETE
+    ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,3 @@
+DROP INDEX owners_subject_idx;
+
+ALTER TABLE owners DROP COLUMN subject;
@@ -1,11 +1,42 @@
 package postgres
 
+import "time"
+
+// Pool names the connection pool used by the wallet repository.
+const (
+	DatabaseSqlPool = "database-sql"
+	PgxPool         = "pgxpool"
+)
+
 type Config struct {
-	Url string `yaml:"url" env:"POSTGRES_URL"`
+	Url  string `yaml:"url" env:"POSTGRES_URL"`
+	Pool string `yaml:"pool" env:"POSTGRES_POOL"`
+	// MaxIdleConns applies to database/sql only, pgxpool keeps up to MaxOpenConns idle.
+	MaxOpenConns       int           `yaml:"max-open-conns" env:"POSTGRES_MAX_OPEN_CONNS"`
+	MaxIdleConns       int           `yaml:"max-idle-conns" env:"POSTGRES_MAX_IDLE_CONNS"`
+	ConnMaxLifetime    time.Duration `yaml:"conn-max-lifetime" env:"POSTGRES_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime    time.Duration `yaml:"conn-max-idle-time" env:"POSTGRES_CONN_MAX_IDLE_TIME"`
+	ConnectTimeout     time.Duration `yaml:"connect-timeout" env:"POSTGRES_CONNECT_TIMEOUT"`
+	ConnectBackoffBase time.Duration `yaml:"connect-backoff-base" env:"POSTGRES_CONNECT_BACKOFF_BASE"`
+	ConnectBackoffMax  time.Duration `yaml:"connect-backoff-max" env:"POSTGRES_CONNECT_BACKOFF_MAX"`
+	// ReplicaUrls are read replicas of Url, ReplicaMaxLag of 0 accepts any lag.
+	ReplicaUrls          []string      `yaml:"replica-urls" env:"POSTGRES_REPLICA_URLS"`
+	ReplicaCheckInterval time.Duration `yaml:"replica-check-interval" env:"POSTGRES_REPLICA_CHECK_INTERVAL"`
+	ReplicaMaxLag        time.Duration `yaml:"replica-max-lag" env:"POSTGRES_REPLICA_MAX_LAG"`
 }
 
 func NewConfig() Config {
 	return Config{
-		Url: "postgres://127.0.0.1:5432/postgres",
+		Url:                  "postgres://127.0.0.1:5432/postgres",
+		Pool:                 DatabaseSqlPool,
+		MaxOpenConns:         20,
+		MaxIdleConns:         10,
+		ConnMaxLifetime:      30 * time.Minute,
+		ConnMaxIdleTime:      5 * time.Minute,
+		ConnectTimeout:       30 * time.Second,
+		ConnectBackoffBase:   500 * time.Millisecond,
+		ConnectBackoffMax:    5 * time.Second,
+		ReplicaCheckInterval: 5 * time.Second,
+		ReplicaMaxLag:        10 * time.Second,
 	}
 }
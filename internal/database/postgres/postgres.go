@@ -1,23 +1,86 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
+	"time"
 
-	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/jackc/pgx/v4/stdlib"
 )
 
-func Open(logger *log.Logger, config Config) (*sql.DB, error) {
-	logger.Println("Opening postgres - pgx data source")
-	db, err := sql.Open("pgx", config.Url)
+func Open(logger *logging.Logger, config Config) (*sql.DB, error) {
+	logger.Info(context.Background(), "Opening postgres - pgx data source")
+	connConfig, err := pgx.ParseConfig(config.Url)
 	if err != nil {
-		return nil, fmt.Errorf("postgres - Open - sql.Open: %w", err)
+		return nil, fmt.Errorf("postgres - Open - pgx.ParseConfig: %w", err)
 	}
-	logger.Println("Trying to connect to postgres database")
-	if err = db.Ping(); err != nil {
-		return nil, fmt.Errorf("postgres - Open - db.Ping: %w", err)
+	withQueryLog(logger, connConfig)
+	db := stdlib.OpenDB(*connConfig)
+	db.SetMaxOpenConns(config.MaxOpenConns)
+	db.SetMaxIdleConns(config.MaxIdleConns)
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	if err = connect(logger, config, db.PingContext); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("postgres - Open - connect: %w", err)
 	}
-	logger.Println("Connect to postgres database successful")
+	logger.Info(context.Background(), "Connect to postgres database successful")
 	return db, nil
 }
+
+// OpenPool opens a native pgx pool with the same settings as Open.
+func OpenPool(logger *logging.Logger, config Config) (*pgxpool.Pool, error) {
+	logger.Info(context.Background(), "Opening postgres - pgx pool")
+	poolConfig, err := pgxpool.ParseConfig(config.Url)
+	if err != nil {
+		return nil, fmt.Errorf("postgres - OpenPool - pgxpool.ParseConfig: %w", err)
+	}
+	poolConfig.MaxConns = int32(config.MaxOpenConns)
+	poolConfig.MaxConnLifetime = config.ConnMaxLifetime
+	poolConfig.MaxConnIdleTime = config.ConnMaxIdleTime
+	poolConfig.LazyConnect = true
+	withQueryLog(logger, poolConfig.ConnConfig)
+	pool, err := pgxpool.ConnectConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("postgres - OpenPool - pgxpool.ConnectConfig: %w", err)
+	}
+	if err = connect(logger, config, pool.Ping); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("postgres - OpenPool - connect: %w", err)
+	}
+	logger.Info(context.Background(), "Connect to postgres pool successful")
+	return pool, nil
+}
+
+// connect pings the database until it answers, waiting between attempts with
+// exponential backoff, and gives up after the connect timeout. Without the
+// timeout it pings once.
+func connect(logger *logging.Logger, config Config, ping func(ctx context.Context) error) error {
+	if config.ConnectTimeout <= 0 {
+		return ping(context.Background())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.ConnectTimeout)
+	defer cancel()
+	backoff := config.ConnectBackoffBase
+	for attempt := 1; ; attempt++ {
+		logger.Info(ctx, "Trying to connect to postgres database", logging.Int("attempt", attempt))
+		err := ping(ctx)
+		if err == nil {
+			return nil
+		}
+		logger.Warn(ctx, "postgres - connect - ping", logging.Err(err))
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("gave up after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > config.ConnectBackoffMax {
+			backoff = config.ConnectBackoffMax
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+var logger = logging.Discard()
+
+func TestConnectRetries(t *testing.T) {
+	// given
+	config := NewConfig()
+	config.ConnectBackoffBase = time.Millisecond
+	config.ConnectBackoffMax = 2 * time.Millisecond
+	attempts := 0
+	ping := func(ctx context.Context) error {
+		attempts++
+		if attempts < 4 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+
+	// when
+	err := connect(logger, config, ping)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 4, attempts)
+}
+
+func TestConnectGivesUp(t *testing.T) {
+	// given
+	config := NewConfig()
+	config.ConnectTimeout = 20 * time.Millisecond
+	config.ConnectBackoffBase = time.Millisecond
+	config.ConnectBackoffMax = 5 * time.Millisecond
+	pingErr := errors.New("connection refused")
+	ping := func(ctx context.Context) error {
+		return pingErr
+	}
+
+	// when
+	err := connect(logger, config, ping)
+
+	// then
+	assert.ErrorIs(t, err, pingErr)
+}
+
+func TestConnectWithoutTimeoutPingsOnce(t *testing.T) {
+	// given
+	config := NewConfig()
+	config.ConnectTimeout = 0
+	attempts := 0
+	ping := func(ctx context.Context) error {
+		attempts++
+		return errors.New("connection refused")
+	}
+
+	// when
+	err := connect(logger, config, ping)
+
+	// then
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts)
+}
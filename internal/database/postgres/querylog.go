@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+	"sort"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/jackc/pgx/v4"
+)
+
+// queryLogFields maps the data pgx logs a query with to the fields of the
+// line, the arguments are left out as they may hold secrets.
+var queryLogFields = map[string]string{
+	"sql":        "sql",
+	"time":       "latency",
+	"rowCount":   "rows",
+	"commandTag": "command_tag",
+	"err":        "error",
+}
+
+// queryLogger logs the queries run by pgx at debug level, with the request id
+// of the query context.
+type queryLogger struct {
+	logger *logging.Logger
+}
+
+func (queryLogger *queryLogger) Log(ctx context.Context, level pgx.LogLevel, msg string, data map[string]interface{}) {
+	fields := make([]logging.Field, 0, len(data))
+	for key, value := range data {
+		if name, ok := queryLogFields[key]; ok {
+			fields = append(fields, logging.Any(name, value))
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Key < fields[j].Key
+	})
+	queryLogger.logger.Debug(ctx, "postgres - "+msg, fields...)
+}
+
+// withQueryLog makes the connections of connConfig log their queries if the
+// logger writes debug lines.
+func withQueryLog(logger *logging.Logger, connConfig *pgx.ConnConfig) {
+	if logger.Enabled(logging.LevelDebug) {
+		connConfig.Logger = &queryLogger{logger: logger}
+		connConfig.LogLevel = pgx.LogLevelInfo
+	}
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/stdlib"
+)
+
+// replicaLagQuery returns the replay lag of a standby in seconds, a standby
+// that replayed everything it received has no lag however old its last
+// transaction is.
+const replicaLagQuery = "SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 " +
+	"ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END"
+
+type replica struct {
+	url     string
+	db      *sql.DB
+	healthy bool
+}
+
+// Replicas routes reads round-robin to the read replicas that passed the last
+// health check. A replica is healthy if it answers and lags behind the primary
+// no more than the configured bound.
+type Replicas struct {
+	logger  *logging.Logger
+	config  Config
+	check   func(ctx context.Context, db *sql.DB) (time.Duration, error)
+	mu      sync.RWMutex
+	all     []*replica
+	healthy []*sql.DB
+	next    uint64
+	stop    chan struct{}
+	done    sync.WaitGroup
+}
+
+// OpenReplicas opens the replicas of the config without connecting, so an
+// unavailable replica does not prevent the start.
+func OpenReplicas(logger *logging.Logger, config Config) (*Replicas, error) {
+	replicas := &Replicas{
+		logger: logger,
+		config: config,
+		check:  checkReplica,
+		stop:   make(chan struct{}),
+	}
+	for _, url := range config.ReplicaUrls {
+		connConfig, err := pgx.ParseConfig(url)
+		if err != nil {
+			replicas.Close()
+			return nil, fmt.Errorf("postgres - OpenReplicas - pgx.ParseConfig: %w", err)
+		}
+		withQueryLog(logger, connConfig)
+		db := stdlib.OpenDB(*connConfig)
+		db.SetMaxOpenConns(config.MaxOpenConns)
+		db.SetMaxIdleConns(config.MaxIdleConns)
+		db.SetConnMaxLifetime(config.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+		replicas.all = append(replicas.all, &replica{url: url, db: db})
+	}
+	return replicas, nil
+}
+
+// Next returns a healthy replica, nil if there is none.
+func (replicas *Replicas) Next() *sql.DB {
+	replicas.mu.RLock()
+	defer replicas.mu.RUnlock()
+	if len(replicas.healthy) == 0 {
+		return nil
+	}
+	next := atomic.AddUint64(&replicas.next, 1)
+	return replicas.healthy[next%uint64(len(replicas.healthy))]
+}
+
+// DBs returns all replicas, healthy or not.
+func (replicas *Replicas) DBs() []*sql.DB {
+	dbs := make([]*sql.DB, 0, len(replicas.all))
+	for _, replica := range replicas.all {
+		dbs = append(dbs, replica.db)
+	}
+	return dbs
+}
+
+// Start checks the replicas and keeps checking them in the background until Stop is called.
+func (replicas *Replicas) Start() {
+	replicas.logger.Info(context.Background(), "Starting replicas health check")
+	replicas.Check()
+	replicas.done.Add(1)
+	go func() {
+		defer replicas.done.Done()
+		ticker := time.NewTicker(replicas.config.ReplicaCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-replicas.stop:
+				return
+			case <-ticker.C:
+				replicas.Check()
+			}
+		}
+	}()
+}
+
+func (replicas *Replicas) Stop() {
+	replicas.logger.Info(context.Background(), "Stopping replicas health check")
+	close(replicas.stop)
+	replicas.done.Wait()
+}
+
+func (replicas *Replicas) Close() {
+	for _, replica := range replicas.all {
+		_ = replica.db.Close()
+	}
+}
+
+// Check updates the healthy replicas, logging the ones whose health changed.
+func (replicas *Replicas) Check() {
+	healthy := make([]*sql.DB, 0, len(replicas.all))
+	for i, replica := range replicas.all {
+		ctx, cancel := context.WithTimeout(context.Background(), replicas.config.ReplicaCheckInterval)
+		lag, err := replicas.check(ctx, replica.db)
+		cancel()
+		if err == nil && replicas.config.ReplicaMaxLag > 0 && lag > replicas.config.ReplicaMaxLag {
+			err = fmt.Errorf("lag %s exceeds %s", lag, replicas.config.ReplicaMaxLag)
+		}
+		wasHealthy := replica.healthy
+		replica.healthy = err == nil
+		if replica.healthy {
+			healthy = append(healthy, replica.db)
+		}
+		if wasHealthy && !replica.healthy {
+			replicas.logger.Warn(context.Background(), "Replica is unhealthy", logging.Int("replica", i), logging.Err(err))
+		} else if !wasHealthy && replica.healthy {
+			replicas.logger.Info(context.Background(), "Replica is healthy", logging.Int("replica", i))
+		}
+	}
+	replicas.mu.Lock()
+	replicas.healthy = healthy
+	replicas.mu.Unlock()
+}
+
+func checkReplica(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var lagSeconds float64
+	if err := db.QueryRowContext(ctx, replicaLagQuery).Scan(&lagSeconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(lagSeconds * float64(time.Second)), nil
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestReplicas(t *testing.T, health map[int]func() (time.Duration, error)) *Replicas {
+	config := NewConfig()
+	config.ReplicaUrls = []string{
+		"postgres://replica-0:5432/wallets",
+		"postgres://replica-1:5432/wallets",
+		"postgres://replica-2:5432/wallets",
+	}
+	replicas, err := OpenReplicas(logger, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(replicas.Close)
+	replicas.check = func(ctx context.Context, db *sql.DB) (time.Duration, error) {
+		for i, replica := range replicas.all {
+			if replica.db == db {
+				return health[i]()
+			}
+		}
+		return 0, errors.New("unknown replica")
+	}
+	return replicas
+}
+
+func TestReplicasRoundRobin(t *testing.T) {
+	// given
+	healthy := func() (time.Duration, error) { return time.Second, nil }
+	replicas := openTestReplicas(t, map[int]func() (time.Duration, error){
+		0: healthy,
+		1: func() (time.Duration, error) { return 0, errors.New("connection refused") },
+		2: healthy,
+	})
+
+	// when
+	replicas.Check()
+	first, second, third := replicas.Next(), replicas.Next(), replicas.Next()
+
+	// then
+	dbs := replicas.DBs()
+	assert.ElementsMatch(t, []*sql.DB{dbs[0], dbs[2]}, []*sql.DB{first, second})
+	assert.NotSame(t, first, second)
+	assert.Same(t, first, third)
+}
+
+func TestReplicasSkipLagging(t *testing.T) {
+	// given
+	replicas := openTestReplicas(t, map[int]func() (time.Duration, error){
+		0: func() (time.Duration, error) { return time.Minute, nil },
+		1: func() (time.Duration, error) { return time.Minute, nil },
+		2: func() (time.Duration, error) { return time.Minute, nil },
+	})
+
+	// when
+	replicas.Check()
+
+	// then
+	assert.Nil(t, replicas.Next())
+}
+
+func TestReplicasRecover(t *testing.T) {
+	// given
+	var err error = errors.New("connection refused")
+	check := func() (time.Duration, error) { return 0, err }
+	replicas := openTestReplicas(t, map[int]func() (time.Duration, error){0: check, 1: check, 2: check})
+	replicas.Check()
+
+	// when
+	err = nil
+	replicas.Check()
+
+	// then
+	assert.NotNil(t, replicas.Next())
+}
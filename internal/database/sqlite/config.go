@@ -0,0 +1,15 @@
+package sqlite
+
+import "time"
+
+type Config struct {
+	Path        string        `yaml:"path" env:"SQLITE_PATH"`
+	BusyTimeout time.Duration `yaml:"busy-timeout" env:"SQLITE_BUSY_TIMEOUT"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Path:        "wallets.db",
+		BusyTimeout: 5 * time.Second,
+	}
+}
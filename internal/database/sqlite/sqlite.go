@@ -0,0 +1,33 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Open opens the database file. Transactions start with BEGIN IMMEDIATE, so
+// write transactions are serialized and wait up to the busy timeout for each other.
+func Open(logger *logging.Logger, config Config) (*sql.DB, error) {
+	logger.Info(context.Background(), "Opening sqlite - sqlite3 data source", logging.String("path", config.Path))
+	params := url.Values{}
+	params.Set("_foreign_keys", "on")
+	params.Set("_journal_mode", "WAL")
+	params.Set("_txlock", "immediate")
+	params.Set("_busy_timeout", strconv.FormatInt(config.BusyTimeout.Milliseconds(), 10))
+	db, err := sql.Open("sqlite3", "file:"+config.Path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite - Open - sql.Open: %w", err)
+	}
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("sqlite - Open - db.Ping: %w", err)
+	}
+	logger.Info(context.Background(), "Open sqlite database successful")
+	return db, nil
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// PoolStats is a snapshot of a database connection pool.
+type PoolStats struct {
+	Name         string
+	MaxOpen      int
+	Open         int
+	InUse        int
+	Idle         int
+	WaitCount    int64
+	WaitDuration time.Duration
+}
+
+// Saturated tells whether every connection of the pool is in use, further
+// queries wait for a connection.
+func (stats PoolStats) Saturated() bool {
+	return stats.MaxOpen > 0 && stats.InUse >= stats.MaxOpen
+}
+
+func SqlPoolStats(name string, db *sql.DB) PoolStats {
+	stats := db.Stats()
+	return PoolStats{
+		Name:         name,
+		MaxOpen:      stats.MaxOpenConnections,
+		Open:         stats.OpenConnections,
+		InUse:        stats.InUse,
+		Idle:         stats.Idle,
+		WaitCount:    stats.WaitCount,
+		WaitDuration: stats.WaitDuration,
+	}
+}
+
+// PgxPoolStats counts the acquires that waited for a connection as waits,
+// their duration is not tracked by pgxpool.
+func PgxPoolStats(name string, pool *pgxpool.Pool) PoolStats {
+	stats := pool.Stat()
+	return PoolStats{
+		Name:      name,
+		MaxOpen:   int(stats.MaxConns()),
+		Open:      int(stats.TotalConns()),
+		InUse:     int(stats.AcquiredConns()),
+		Idle:      int(stats.IdleConns()),
+		WaitCount: stats.EmptyAcquireCount(),
+	}
+}
@@ -0,0 +1,149 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+type Broker interface {
+	// Subscribe returns the transactions of the wallet committed from now on.
+	// The channel is closed when the subscriber falls behind, the database
+	// connection is lost or the broker stops; the subscriber is expected to
+	// resume from the last transaction it has seen.
+	Subscribe(walletId string) (<-chan *model.Transaction, func())
+}
+
+type subscription struct {
+	transactions chan *model.Transaction
+}
+
+type broker struct {
+	logger              *logging.Logger
+	transactionListener repository.TransactionListener
+	config              Config
+	mu                  sync.Mutex
+	subscriptions       map[string]map[*subscription]struct{}
+	stopped             bool
+	cancel              context.CancelFunc
+	done                sync.WaitGroup
+}
+
+func NewBroker(logger *logging.Logger, transactionListener repository.TransactionListener, config Config) *broker {
+	return &broker{
+		logger:              logger,
+		transactionListener: transactionListener,
+		config:              config,
+		subscriptions:       make(map[string]map[*subscription]struct{}),
+	}
+}
+
+// Start listens to the committed transactions of all server instances in the
+// background, reconnecting until Stop is called.
+func (broker *broker) Start() {
+	broker.logger.Info(context.Background(), "Starting events broker")
+	ctx, cancel := context.WithCancel(context.Background())
+	broker.cancel = cancel
+	broker.done.Add(1)
+	go func() {
+		defer broker.done.Done()
+		for {
+			err := broker.transactionListener.Listen(ctx, broker.publish)
+			if ctx.Err() != nil {
+				return
+			}
+			broker.logger.Error(ctx, "broker - Start - broker.transactionListener.Listen", logging.Err(err))
+			// Transactions committed while reconnecting are lost, so the
+			// subscribers are made to resume from the database.
+			broker.closeSubscriptions()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(broker.config.ReconnectInterval):
+			}
+		}
+	}()
+}
+
+// Stop stops listening and ends all subscriptions.
+func (broker *broker) Stop() {
+	broker.logger.Info(context.Background(), "Stopping events broker")
+	broker.mu.Lock()
+	broker.stopped = true
+	broker.mu.Unlock()
+	if broker.cancel != nil {
+		broker.cancel()
+	}
+	broker.done.Wait()
+	broker.closeSubscriptions()
+}
+
+func (broker *broker) Subscribe(walletId string) (<-chan *model.Transaction, func()) {
+	sub := &subscription{
+		transactions: make(chan *model.Transaction, broker.config.BufferSize),
+	}
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if broker.stopped {
+		close(sub.transactions)
+		return sub.transactions, func() {}
+	}
+	if broker.subscriptions[walletId] == nil {
+		broker.subscriptions[walletId] = make(map[*subscription]struct{})
+	}
+	broker.subscriptions[walletId][sub] = struct{}{}
+	return sub.transactions, func() {
+		broker.mu.Lock()
+		defer broker.mu.Unlock()
+		broker.unsubscribe(walletId, sub)
+	}
+}
+
+func (broker *broker) publish(transaction *model.Transaction) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	walletIds := []string{transaction.RecipientWallet.ID}
+	if transaction.SenderWallet != nil {
+		walletIds = append(walletIds, transaction.SenderWallet.ID)
+	}
+	for _, walletId := range walletIds {
+		for sub := range broker.subscriptions[walletId] {
+			select {
+			case sub.transactions <- transaction:
+			default:
+				broker.logger.Warn(context.Background(), "broker - publish - subscriber fell behind", logging.String("wallet_id", walletId))
+				broker.unsubscribe(walletId, sub)
+			}
+		}
+	}
+}
+
+func (broker *broker) closeSubscriptions() {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	for walletId, subs := range broker.subscriptions {
+		for sub := range subs {
+			broker.unsubscribe(walletId, sub)
+		}
+	}
+}
+
+// unsubscribe must be called with mu held.
+func (broker *broker) unsubscribe(walletId string, sub *subscription) {
+	subs, ok := broker.subscriptions[walletId]
+	if !ok {
+		return
+	}
+	if _, ok = subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(broker.subscriptions, walletId)
+	}
+	close(sub.transactions)
+}
@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	logger, _ = logging.New(os.Stdout, logging.Config{Level: logging.LevelDebug.String(), Format: logging.TextFormat})
+)
+
+type transactionListenerStub struct {
+	transactions chan *model.Transaction
+}
+
+func (transactionListener *transactionListenerStub) Listen(ctx context.Context, handle func(transaction *model.Transaction)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case transaction := <-transactionListener.transactions:
+			handle(transaction)
+		}
+	}
+}
+
+func newTransfer(senderWalletId string, recipientWalletId string) *model.Transaction {
+	return &model.Transaction{
+		ID:              "1001",
+		Amount:          10000,
+		SenderWallet:    &model.Wallet{ID: senderWalletId, Balance: 0},
+		RecipientWallet: model.Wallet{ID: recipientWalletId, Balance: 10000},
+		OperationType:   model.Transfer,
+	}
+}
+
+func TestBrokerPublishesToSenderAndRecipient(t *testing.T) {
+	// given
+	transactionListener := &transactionListenerStub{transactions: make(chan *model.Transaction)}
+	broker := NewBroker(logger, transactionListener, NewConfig())
+	broker.Start()
+	defer broker.Stop()
+
+	senderTransactions, unsubscribeSender := broker.Subscribe("2001")
+	defer unsubscribeSender()
+	recipientTransactions, unsubscribeRecipient := broker.Subscribe("2002")
+	defer unsubscribeRecipient()
+	otherTransactions, unsubscribeOther := broker.Subscribe("2003")
+	defer unsubscribeOther()
+
+	// when
+	transactionListener.transactions <- newTransfer("2001", "2002")
+
+	// then
+	assert.Equal(t, "1001", (<-senderTransactions).ID)
+	assert.Equal(t, "1001", (<-recipientTransactions).ID)
+	assert.Len(t, otherTransactions, 0)
+}
+
+func TestBrokerClosesSlowSubscription(t *testing.T) {
+	// given
+	config := NewConfig()
+	config.BufferSize = 1
+	broker := NewBroker(logger, nil, config)
+	transactions, unsubscribe := broker.Subscribe("2001")
+	defer unsubscribe()
+
+	// when
+	broker.publish(newTransfer("2001", "2002"))
+	broker.publish(newTransfer("2001", "2002"))
+
+	// then
+	_, ok := <-transactions
+	assert.True(t, ok)
+	_, ok = <-transactions
+	assert.False(t, ok)
+}
+
+func TestBrokerStopClosesSubscriptions(t *testing.T) {
+	// given
+	transactionListener := &transactionListenerStub{transactions: make(chan *model.Transaction)}
+	broker := NewBroker(logger, transactionListener, NewConfig())
+	broker.Start()
+	transactions, unsubscribe := broker.Subscribe("2001")
+	defer unsubscribe()
+
+	// when
+	broker.Stop()
+
+	// then
+	_, ok := <-transactions
+	assert.False(t, ok)
+	transactionsAfterStop, _ := broker.Subscribe("2001")
+	_, ok = <-transactionsAfterStop
+	assert.False(t, ok)
+}
@@ -0,0 +1,17 @@
+package events
+
+import "time"
+
+type Config struct {
+	Heartbeat         time.Duration `yaml:"heartbeat" env:"EVENTS_HEARTBEAT"`
+	ReconnectInterval time.Duration `yaml:"reconnect-interval" env:"EVENTS_RECONNECT_INTERVAL"`
+	BufferSize        int           `yaml:"buffer-size" env:"EVENTS_BUFFER_SIZE"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Heartbeat:         time.Second * 15,
+		ReconnectInterval: time.Second * 5,
+		BufferSize:        64,
+	}
+}
@@ -0,0 +1,26 @@
+package grpcserver
+
+import "time"
+
+type Config struct {
+	Enabled             bool          `yaml:"enabled" env:"GRPC_ENABLED"`
+	Port                string        `yaml:"port" env:"GRPC_PORT"`
+	ShutdownGracePeriod time.Duration `yaml:"shutdown-grace-period" env:"GRPC_SHUTDOWN_GRACE_PERIOD"`
+	// MaxConcurrentRequests bounds the calls served at once, the calls over the
+	// bound are rejected with Unavailable. Open streams count as served calls.
+	// 0 is unbounded.
+	MaxConcurrentRequests int `yaml:"max-concurrent-requests" env:"GRPC_MAX_CONCURRENT_REQUESTS"`
+	// ShedOnPoolSaturation rejects calls with Unavailable while every
+	// connection of a database pool is in use.
+	ShedOnPoolSaturation bool `yaml:"shed-on-pool-saturation" env:"GRPC_SHED_ON_POOL_SATURATION"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled:               true,
+		Port:                  "9090",
+		ShutdownGracePeriod:   time.Second * 30,
+		MaxConcurrentRequests: 1024,
+		ShedOnPoolSaturation:  true,
+	}
+}
@@ -0,0 +1,65 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"google.golang.org/grpc"
+)
+
+type server struct {
+	logger      *logging.Logger
+	grpcServer  *grpc.Server
+	config      Config
+	loadShedder *loadShedder
+}
+
+// NewServer returns the server of the services registered by register. Calls
+// are shed before the interceptors of opts run.
+func NewServer(logger *logging.Logger, config Config, register func(grpcServer *grpc.Server), opts ...grpc.ServerOption) *server {
+	loadShedder := newLoadShedder(config.MaxConcurrentRequests)
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(loadShedder.unary),
+		grpc.ChainStreamInterceptor(loadShedder.stream),
+	}, opts...)
+	grpcServer := grpc.NewServer(opts...)
+	register(grpcServer)
+	return &server{
+		logger:      logger,
+		grpcServer:  grpcServer,
+		config:      config,
+		loadShedder: loadShedder,
+	}
+}
+
+// ShedLoad makes the server reject calls while overloaded tells so.
+func (server *server) ShedLoad(overloaded func() bool) {
+	server.loadShedder.overloaded = overloaded
+}
+
+func (server *server) Start() error {
+	server.logger.Info(context.Background(), "Starting grpc server", logging.String("port", server.config.Port))
+	listener, err := net.Listen("tcp", ":"+server.config.Port)
+	if err != nil {
+		return err
+	}
+	return server.grpcServer.Serve(listener)
+}
+
+// GracefulShutdown waits for the pending calls to finish and cancels the ones
+// still running after the grace period.
+func (server *server) GracefulShutdown() {
+	server.logger.Info(context.Background(), "Shutdown grpc server")
+	stopped := make(chan struct{})
+	go func() {
+		server.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(server.config.ShutdownGracePeriod):
+		server.grpcServer.Stop()
+	}
+}
@@ -0,0 +1,69 @@
+package grpcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// loadShedder rejects calls with Unavailable while the server serves as many
+// calls as it is allowed to, or while it is overloaded, the same way the HTTP
+// server sheds requests. Open streams count as served calls.
+type loadShedder struct {
+	slots      chan struct{}
+	overloaded func() bool
+}
+
+func newLoadShedder(maxConcurrentRequests int) *loadShedder {
+	loadShedder := &loadShedder{}
+	if maxConcurrentRequests > 0 {
+		loadShedder.slots = make(chan struct{}, maxConcurrentRequests)
+	}
+	return loadShedder
+}
+
+func (loadShedder *loadShedder) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	release, err := loadShedder.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return handler(ctx, req)
+}
+
+func (loadShedder *loadShedder) stream(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	release, err := loadShedder.acquire(stream.Context())
+	if err != nil {
+		return err
+	}
+	defer release()
+	return handler(srv, stream)
+}
+
+// acquire takes a slot for the call, the returned function releases it.
+func (loadShedder *loadShedder) acquire(ctx context.Context) (func(), error) {
+	if loadShedder.overloaded != nil && loadShedder.overloaded() {
+		return nil, errOverloaded(ctx)
+	}
+	if loadShedder.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case loadShedder.slots <- struct{}{}:
+		return func() {
+			<-loadShedder.slots
+		}, nil
+	default:
+		return nil, errOverloaded(ctx)
+	}
+}
+
+// errOverloaded rejects the call, the retry-after metadata mirrors the
+// Retry-After header of the HTTP server.
+func errOverloaded(ctx context.Context) error {
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", "1"))
+	return status.Error(codes.Unavailable, "server is overloaded")
+}
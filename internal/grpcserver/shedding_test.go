@@ -0,0 +1,64 @@
+package grpcserver
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadShedderMaxConcurrentRequests(t *testing.T) {
+	// given
+	started, release := make(chan struct{}), make(chan struct{})
+	loadShedder := newLoadShedder(1)
+	info := &grpc.UnaryServerInfo{FullMethod: "/wallet.v1.WalletService/GetWallet"}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = loadShedder.unary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			started <- struct{}{}
+			<-release
+			return nil, nil
+		})
+	}()
+	<-started
+
+	// when
+	_, err := loadShedder.unary(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	// then
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	close(release)
+	wg.Wait()
+	assert.Len(t, loadShedder.slots, 0)
+}
+
+func TestLoadShedderOverloaded(t *testing.T) {
+	// given
+	loadShedder := newLoadShedder(0)
+	overloaded := true
+	loadShedder.overloaded = func() bool {
+		return overloaded
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/wallet.v1.WalletService/GetWallet"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "served", nil
+	}
+
+	// when
+	_, shedErr := loadShedder.unary(context.Background(), nil, info, handler)
+	overloaded = false
+	resp, err := loadShedder.unary(context.Background(), nil, info, handler)
+
+	// then
+	assert.Equal(t, codes.Unavailable, status.Code(shedErr))
+	assert.NoError(t, err)
+	assert.Equal(t, "served", resp)
+}
@@ -0,0 +1,15 @@
+package health
+
+import "time"
+
+type Config struct {
+	// Timeout bounds the checks of a readiness probe, a check still running
+	// when it expires fails.
+	Timeout time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Timeout: time.Second * 2,
+	}
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrDraining = errors.New("server is draining")
+
+// Check returns an error when a dependency the server needs to serve requests,
+// such as the database, is unavailable.
+type Check func(ctx context.Context) error
+
+type CheckResult struct {
+	Name string
+	Err  error
+}
+
+type Checker interface {
+	// Ready runs every check and returns their results in the order they were
+	// added, it returns ErrDraining without running them once Drain is called.
+	Ready(ctx context.Context) ([]CheckResult, error)
+	Drain()
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+type checker struct {
+	checks   []namedCheck
+	draining int32
+	config   Config
+}
+
+func NewChecker(config Config) *checker {
+	return &checker{
+		config: config,
+	}
+}
+
+// AddCheck adds a check to the readiness probe, it is called before the
+// checker is used.
+func (checker *checker) AddCheck(name string, check Check) *checker {
+	checker.checks = append(checker.checks, namedCheck{name: name, check: check})
+	return checker
+}
+
+func (checker *checker) Ready(ctx context.Context) ([]CheckResult, error) {
+	if atomic.LoadInt32(&checker.draining) == 1 {
+		return nil, ErrDraining
+	}
+	ctx, cancel := context.WithTimeout(ctx, checker.config.Timeout)
+	defer cancel()
+	results := make([]CheckResult, len(checker.checks))
+	var wg sync.WaitGroup
+	for i, check := range checker.checks {
+		wg.Add(1)
+		go func(i int, check namedCheck) {
+			defer wg.Done()
+			results[i] = CheckResult{Name: check.name, Err: runCheck(ctx, check.check)}
+		}(i, check)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// Drain makes the server not ready for good, so that it is taken out of load
+// balancing before it stops accepting connections.
+func (checker *checker) Drain() {
+	atomic.StoreInt32(&checker.draining, 1)
+}
+
+// runCheck returns the error of the check, or the error of the context if it
+// expires first, as a check may not honor it.
+func runCheck(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,55 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckerReady(t *testing.T) {
+	// given
+	errDown := errors.New("connection refused")
+	checker := NewChecker(Config{Timeout: 50 * time.Millisecond}).
+		AddCheck("postgres", func(ctx context.Context) error {
+			return nil
+		}).
+		AddCheck("schema", func(ctx context.Context) error {
+			return errDown
+		}).
+		AddCheck("replica", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		})
+
+	// when
+	results, err := checker.Ready(context.Background())
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []CheckResult{
+		{Name: "postgres"},
+		{Name: "schema", Err: errDown},
+		{Name: "replica", Err: context.DeadlineExceeded},
+	}, results)
+}
+
+func TestCheckerDrain(t *testing.T) {
+	// given
+	checked := false
+	checker := NewChecker(NewConfig()).AddCheck("postgres", func(ctx context.Context) error {
+		checked = true
+		return nil
+	})
+
+	// when
+	checker.Drain()
+	results, err := checker.Ready(context.Background())
+
+	// then
+	assert.ErrorIs(t, err, ErrDraining)
+	assert.Empty(t, results)
+	assert.False(t, checked)
+}
@@ -0,0 +1,21 @@
+package logging
+
+const (
+	JsonFormat = "json"
+	TextFormat = "text"
+)
+
+type Config struct {
+	// Level is the lowest level logged: debug, info, warn or error.
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format is json, a JSON object per line, or text, logfmt lines for
+	// reading in a terminal.
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Level:  LevelInfo.String(),
+		Format: JsonFormat,
+	}
+}
@@ -0,0 +1,255 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "debug",
+	LevelInfo:  "info",
+	LevelWarn:  "warn",
+	LevelError: "error",
+}
+
+func LevelFromString(level string) (Level, error) {
+	for value, name := range levelNames {
+		if name == level {
+			return value, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level: %s", level)
+}
+
+func (level Level) String() string {
+	return levelNames[level]
+}
+
+// Field is a key and value of a log line.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key string, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration is logged in seconds in JSON lines, as 1.5s in text ones.
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err is the error field of a line, the message of err.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Logger writes leveled lines of fields, JSON objects or logfmt. Every line
+// has the time, the level, the message and the request id of the context if
+// any, followed by the fields of the logger and those of the call.
+type Logger struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	level  Level
+	format string
+	fields []Field
+	now    func() time.Time
+	exit   func(code int)
+}
+
+func New(out io.Writer, config Config) (*Logger, error) {
+	level, err := LevelFromString(config.Level)
+	if err != nil {
+		return nil, fmt.Errorf("logging - New - LevelFromString: %w", err)
+	}
+	if config.Format != JsonFormat && config.Format != TextFormat {
+		return nil, fmt.Errorf("logging - New - unknown log format: %s", config.Format)
+	}
+	return &Logger{
+		mu:     &sync.Mutex{},
+		out:    out,
+		level:  level,
+		format: config.Format,
+		now:    time.Now,
+		exit:   os.Exit,
+	}, nil
+}
+
+// Discard returns a logger writing nothing.
+func Discard() *Logger {
+	logger, _ := New(io.Discard, Config{Level: LevelError.String(), Format: TextFormat})
+	return logger
+}
+
+// With returns a logger adding fields to every line, sharing the output of
+// logger.
+func (logger *Logger) With(fields ...Field) *Logger {
+	with := *logger
+	with.fields = append(append(make([]Field, 0, len(logger.fields)+len(fields)), logger.fields...), fields...)
+	return &with
+}
+
+// Enabled returns true if lines of level are written.
+func (logger *Logger) Enabled(level Level) bool {
+	return level >= logger.level
+}
+
+func (logger *Logger) Debug(ctx context.Context, msg string, fields ...Field) {
+	logger.Log(ctx, LevelDebug, msg, fields...)
+}
+
+func (logger *Logger) Info(ctx context.Context, msg string, fields ...Field) {
+	logger.Log(ctx, LevelInfo, msg, fields...)
+}
+
+func (logger *Logger) Warn(ctx context.Context, msg string, fields ...Field) {
+	logger.Log(ctx, LevelWarn, msg, fields...)
+}
+
+func (logger *Logger) Error(ctx context.Context, msg string, fields ...Field) {
+	logger.Log(ctx, LevelError, msg, fields...)
+}
+
+// Fatal logs an error line and exits, it is meant for the start of a command.
+func (logger *Logger) Fatal(msg string, fields ...Field) {
+	logger.Log(context.Background(), LevelError, msg, fields...)
+	logger.exit(1)
+}
+
+func (logger *Logger) Log(ctx context.Context, level Level, msg string, fields ...Field) {
+	if !logger.Enabled(level) {
+		return
+	}
+	line := make([]Field, 0, 4+len(logger.fields)+len(fields))
+	line = append(line, Field{"time", logger.now()}, Field{"level", level}, Field{"msg", msg})
+	if requestId := RequestIdFromContext(ctx); requestId != "" {
+		line = append(line, Field{"request_id", requestId})
+	}
+	line = append(append(line, logger.fields...), fields...)
+	var buf bytes.Buffer
+	if logger.format == JsonFormat {
+		writeJson(&buf, line)
+	} else {
+		writeText(&buf, line)
+	}
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	_, _ = logger.out.Write(buf.Bytes())
+}
+
+// StdLogger returns a standard library logger writing its lines at level, for
+// the libraries logging with one, e.g. http.Server.
+func (logger *Logger) StdLogger(level Level) *log.Logger {
+	return log.New(&stdWriter{logger: logger, level: level}, "", 0)
+}
+
+type stdWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (writer *stdWriter) Write(p []byte) (int, error) {
+	writer.logger.Log(context.Background(), writer.level, strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+func writeJson(buf *bytes.Buffer, line []Field) {
+	buf.WriteByte('{')
+	for i, field := range line {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field.Key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		var value interface{}
+		switch fieldValue := field.Value.(type) {
+		case time.Time:
+			value = fieldValue.UTC().Format(time.RFC3339Nano)
+		case time.Duration:
+			value = fieldValue.Seconds()
+		case error:
+			value = fieldValue.Error()
+		case fmt.Stringer:
+			value = fieldValue.String()
+		default:
+			value = fieldValue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			encoded, _ = json.Marshal(fmt.Sprint(value))
+		}
+		buf.Write(encoded)
+	}
+	buf.WriteString("}\n")
+}
+
+func writeText(buf *bytes.Buffer, line []Field) {
+	for i, field := range line {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		buf.WriteString(field.Key)
+		buf.WriteByte('=')
+		var value string
+		switch fieldValue := field.Value.(type) {
+		case time.Time:
+			value = fieldValue.UTC().Format(time.RFC3339Nano)
+		case error:
+			value = fieldValue.Error()
+		case nil:
+			value = ""
+		default:
+			value = fmt.Sprint(fieldValue)
+		}
+		if needsQuoting(value) {
+			value = strconv.Quote(value)
+		}
+		buf.WriteString(value)
+	}
+	buf.WriteByte('\n')
+}
+
+func needsQuoting(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r == '"' || r == '=' || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(t *testing.T, buf *bytes.Buffer, config Config) *Logger {
+	logger, err := New(buf, config)
+	assert.NoError(t, err)
+	logger.now = func() time.Time {
+		return time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return logger
+}
+
+func TestLoggerJson(t *testing.T) {
+	// given
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, Config{Level: "info", Format: JsonFormat}).With(String("component", "test"))
+	ctx := WithRequestId(context.Background(), "request-1")
+
+	// when
+	logger.Warn(ctx, "walletsApi - Deposit", Duration("latency", 1500*time.Millisecond), Err(errors.New("wallet not found")))
+
+	// then
+	assert.JSONEq(t, `{
+		"time": "2022-01-01T00:00:00Z",
+		"level": "warn",
+		"msg": "walletsApi - Deposit",
+		"request_id": "request-1",
+		"component": "test",
+		"latency": 1.5,
+		"error": "wallet not found"
+	}`, buf.String())
+}
+
+func TestLoggerText(t *testing.T) {
+	// given
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, Config{Level: "debug", Format: TextFormat})
+
+	// when
+	logger.Debug(context.Background(), "Applied migration", Int("version", 5), String("name", "audit log"))
+
+	// then
+	assert.Equal(t, `time=2022-01-01T00:00:00Z level=debug msg="Applied migration" version=5 name="audit log"`+"\n", buf.String())
+}
+
+func TestLoggerLevel(t *testing.T) {
+	// given
+	var buf bytes.Buffer
+	logger := newTestLogger(t, &buf, Config{Level: "warn", Format: TextFormat})
+
+	// when
+	logger.Info(context.Background(), "skipped")
+	logger.Error(context.Background(), "written")
+
+	// then
+	assert.Equal(t, "time=2022-01-01T00:00:00Z level=error msg=written\n", buf.String())
+	assert.False(t, logger.Enabled(LevelInfo))
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	// when
+	_, levelErr := New(&bytes.Buffer{}, Config{Level: "verbose", Format: JsonFormat})
+	_, formatErr := New(&bytes.Buffer{}, Config{Level: "info", Format: "xml"})
+
+	// then
+	assert.Error(t, levelErr)
+	assert.Error(t, formatErr)
+}
+
+func TestValidRequestId(t *testing.T) {
+	assert.True(t, ValidRequestId("4bf92f3577b34da6a3ce929d0e0e4736"))
+	assert.True(t, ValidRequestId(NewRequestId()))
+	assert.False(t, ValidRequestId(""))
+	assert.False(t, ValidRequestId("request 1"))
+	assert.False(t, ValidRequestId(string(make([]byte, 129))))
+}
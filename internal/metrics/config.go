@@ -0,0 +1,13 @@
+package metrics
+
+type Config struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+	Path    string `yaml:"path" env:"METRICS_PATH"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled: true,
+		Path:    "/metrics",
+	}
+}
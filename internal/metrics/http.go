@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HttpMetrics counts the HTTP requests served and their latency by method,
+// route template and status.
+type HttpMetrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+func NewHttpMetrics(registerer prometheus.Registerer) *HttpMetrics {
+	factory := promauto.With(registerer)
+	return &HttpMetrics{
+		requests: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests served.",
+		}, []string{"method", "route", "status"}),
+		duration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Latency of the HTTP requests served.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+	}
+}
+
+// ObserveRequest counts a request served, the route is the template of its
+// path, so that the requests of a route share a series.
+func (httpMetrics *HttpMetrics) ObserveRequest(method string, route string, status int, latency time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	httpMetrics.requests.WithLabelValues(method, route, statusLabel).Inc()
+	httpMetrics.duration.WithLabelValues(method, route, statusLabel).Observe(latency.Seconds())
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletMetrics(t *testing.T) {
+	// given
+	walletMetrics := NewWalletMetrics(prometheus.NewRegistry())
+
+	// when
+	walletMetrics.ObserveOperation(model.Deposit, "USD", 1000, nil)
+	walletMetrics.ObserveOperation(model.Transfer, "USD", 500, nil)
+	walletMetrics.ObserveOperation(model.Transfer, "USD", 5000, errors.New("connection reset by peer"))
+	walletMetrics.ObserveOperation(model.Transfer, "EUR", 5000, model.ErrInsufficientFunds)
+	walletMetrics.ObserveOperation(model.Deposit, "", 100, model.ErrWalletNotFound)
+	walletMetrics.ObserveRetry(model.Transfer)
+
+	// then
+	assert.Equal(t, float64(1), testutil.ToFloat64(walletMetrics.operations.WithLabelValues("deposit", "USD", SuccessOutcome)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(walletMetrics.operations.WithLabelValues("transfer", "USD", ErrorOutcome)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(walletMetrics.operations.WithLabelValues("transfer", "EUR", RejectedOutcome)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(walletMetrics.operations.WithLabelValues("deposit", UnknownCurrency, RejectedOutcome)))
+	assert.Equal(t, float64(500), testutil.ToFloat64(walletMetrics.amounts.WithLabelValues("transfer", "USD")))
+	assert.Equal(t, 1, testutil.CollectAndCount(walletMetrics.insufficientFunds))
+	assert.Equal(t, float64(1), testutil.ToFloat64(walletMetrics.insufficientFunds.WithLabelValues("EUR")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(walletMetrics.retries.WithLabelValues("transfer")))
+}
+
+func TestPoolStatsCollector(t *testing.T) {
+	// given
+	collector := NewPoolStatsCollector(func() []database.PoolStats {
+		return []database.PoolStats{
+			{Name: "postgres", MaxOpen: 20, Open: 5, InUse: 2, Idle: 3, WaitCount: 7, WaitDuration: 1500 * time.Millisecond},
+		}
+	})
+
+	// when
+	err := testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP db_pool_in_use_connections Connections of the pool in use.
+# TYPE db_pool_in_use_connections gauge
+db_pool_in_use_connections{pool="postgres"} 2
+# HELP db_pool_wait_count_total Acquires that waited for a connection of the pool.
+# TYPE db_pool_wait_count_total counter
+db_pool_wait_count_total{pool="postgres"} 7
+# HELP db_pool_wait_duration_seconds_total Time spent waiting for a connection of the pool, not tracked by pgxpool.
+# TYPE db_pool_wait_duration_seconds_total counter
+db_pool_wait_duration_seconds_total{pool="postgres"} 1.5
+`), "db_pool_in_use_connections", "db_pool_wait_count_total", "db_pool_wait_duration_seconds_total")
+
+	// then
+	assert.NoError(t, err)
+}
@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"github.com/SergeyChupin/wallets-api/internal/database"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	poolMaxOpenDesc = prometheus.NewDesc(
+		"db_pool_max_open_connections",
+		"Maximum number of open connections of the pool.",
+		[]string{"pool"}, nil,
+	)
+	poolOpenDesc = prometheus.NewDesc(
+		"db_pool_open_connections",
+		"Open connections of the pool, in use and idle.",
+		[]string{"pool"}, nil,
+	)
+	poolInUseDesc = prometheus.NewDesc(
+		"db_pool_in_use_connections",
+		"Connections of the pool in use.",
+		[]string{"pool"}, nil,
+	)
+	poolIdleDesc = prometheus.NewDesc(
+		"db_pool_idle_connections",
+		"Idle connections of the pool.",
+		[]string{"pool"}, nil,
+	)
+	poolWaitCountDesc = prometheus.NewDesc(
+		"db_pool_wait_count_total",
+		"Acquires that waited for a connection of the pool.",
+		[]string{"pool"}, nil,
+	)
+	poolWaitDurationDesc = prometheus.NewDesc(
+		"db_pool_wait_duration_seconds_total",
+		"Time spent waiting for a connection of the pool, not tracked by pgxpool.",
+		[]string{"pool"}, nil,
+	)
+)
+
+// poolStatsCollector exposes the stats of the database connection pools,
+// labelled by pool name. The stats are taken on each scrape.
+type poolStatsCollector struct {
+	poolStats func() []database.PoolStats
+}
+
+func NewPoolStatsCollector(poolStats func() []database.PoolStats) *poolStatsCollector {
+	return &poolStatsCollector{
+		poolStats: poolStats,
+	}
+}
+
+func (collector *poolStatsCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- poolMaxOpenDesc
+	descs <- poolOpenDesc
+	descs <- poolInUseDesc
+	descs <- poolIdleDesc
+	descs <- poolWaitCountDesc
+	descs <- poolWaitDurationDesc
+}
+
+func (collector *poolStatsCollector) Collect(metrics chan<- prometheus.Metric) {
+	for _, stats := range collector.poolStats() {
+		metrics <- prometheus.MustNewConstMetric(poolMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpen), stats.Name)
+		metrics <- prometheus.MustNewConstMetric(poolOpenDesc, prometheus.GaugeValue, float64(stats.Open), stats.Name)
+		metrics <- prometheus.MustNewConstMetric(poolInUseDesc, prometheus.GaugeValue, float64(stats.InUse), stats.Name)
+		metrics <- prometheus.MustNewConstMetric(poolIdleDesc, prometheus.GaugeValue, float64(stats.Idle), stats.Name)
+		metrics <- prometheus.MustNewConstMetric(poolWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount), stats.Name)
+		metrics <- prometheus.MustNewConstMetric(poolWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds(), stats.Name)
+	}
+}
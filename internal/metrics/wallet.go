@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"errors"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcomes of the wallet operations: rejected operations are refused because
+// of the request, such as a missing wallet or insufficient funds, failed ones
+// because of the server.
+const (
+	SuccessOutcome  = "success"
+	RejectedOutcome = "rejected"
+	ErrorOutcome    = "error"
+)
+
+// UnknownCurrency labels the operations on wallets whose currency could not
+// be found, such as missing ones.
+const UnknownCurrency = "unknown"
+
+var rejectedErrors = []error{
+	model.ErrWalletNotFound,
+	model.ErrInsufficientFunds,
+	model.ErrWalletFrozen,
+	model.ErrSameWallet,
+	model.ErrWalletAccessDenied,
+}
+
+// WalletMetrics counts the deposits and transfers by currency and outcome, the
+// amounts moved, the transfers rejected for insufficient funds and the retries
+// of transactions conflicting with concurrent ones.
+type WalletMetrics struct {
+	operations        *prometheus.CounterVec
+	amounts           *prometheus.CounterVec
+	insufficientFunds *prometheus.CounterVec
+	retries           *prometheus.CounterVec
+}
+
+func NewWalletMetrics(registerer prometheus.Registerer) *WalletMetrics {
+	factory := promauto.With(registerer)
+	return &WalletMetrics{
+		operations: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "wallet_operations_total",
+			Help: "Deposits and transfers by currency and outcome.",
+		}, []string{"operation", "currency", "outcome"}),
+		amounts: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "wallet_amount_moved_total",
+			Help: "Amount moved by deposits and transfers, in minor units of the currency.",
+		}, []string{"operation", "currency"}),
+		insufficientFunds: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "wallet_insufficient_funds_total",
+			Help: "Transfers rejected for insufficient funds of the sender.",
+		}, []string{"currency"}),
+		retries: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "wallet_transaction_retries_total",
+			Help: "Retries of deposits and transfers conflicting with concurrent transactions.",
+		}, []string{"operation"}),
+	}
+}
+
+// ObserveOperation counts a deposit or a transfer of the amount, the currency
+// is the one of the wallet the money is moved from, or to for deposits.
+func (walletMetrics *WalletMetrics) ObserveOperation(operation model.OperationType, currency string, amount uint64, err error) {
+	if currency == "" {
+		currency = UnknownCurrency
+	}
+	walletMetrics.operations.WithLabelValues(operation.String(), currency, outcome(err)).Inc()
+	if err == nil {
+		walletMetrics.amounts.WithLabelValues(operation.String(), currency).Add(float64(amount))
+	}
+	if errors.Is(err, model.ErrInsufficientFunds) {
+		walletMetrics.insufficientFunds.WithLabelValues(currency).Inc()
+	}
+}
+
+// ObserveRetry counts a retry of the operation.
+func (walletMetrics *WalletMetrics) ObserveRetry(operation model.OperationType) {
+	walletMetrics.retries.WithLabelValues(operation.String()).Inc()
+}
+
+func outcome(err error) string {
+	if err == nil {
+		return SuccessOutcome
+	}
+	for _, rejectedErr := range rejectedErrors {
+		if errors.Is(err, rejectedErr) {
+			return RejectedOutcome
+		}
+	}
+	return ErrorOutcome
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type Scope struct {
+	value string
+}
+
+func (scope Scope) String() string {
+	return scope.value
+}
+
+var (
+	UnknownScope = Scope{""}
+	// WalletsReadScope allows reading wallets, their transactions and events.
+	WalletsReadScope = Scope{"wallets:read"}
+	// WalletsWriteScope allows creating wallets and depositing to them.
+	WalletsWriteScope    = Scope{"wallets:write"}
+	TransfersCreateScope = Scope{"transfers:create"}
+	// AdminScope grants every other scope and the admin endpoints.
+	AdminScope = Scope{"admin"}
+)
+
+func ScopeFromString(value string) (Scope, error) {
+	switch value {
+	case WalletsReadScope.value:
+		return WalletsReadScope, nil
+	case WalletsWriteScope.value:
+		return WalletsWriteScope, nil
+	case TransfersCreateScope.value:
+		return TransfersCreateScope, nil
+	case AdminScope.value:
+		return AdminScope, nil
+	}
+	return UnknownScope, fmt.Errorf("%w: %s", ErrInvalidScope, value)
+}
+
+// ApiKey is the identity of an API client. The key itself is shown once when it
+// is issued or rotated, only its hash is stored.
+type ApiKey struct {
+	ID        string
+	Name      string
+	Prefix    string
+	Hash      string
+	Scopes    []Scope
+	CreatedAt time.Time
+	RotatedAt *time.Time
+	RevokedAt *time.Time
+}
+
+// HasScope tells whether the key is granted the scope, admin keys are granted all.
+func (apiKey *ApiKey) HasScope(scope Scope) bool {
+	return hasScope(apiKey.Scopes, scope)
+}
+
+// Principal returns the principal of the requests made with the key.
+func (apiKey *ApiKey) Principal() *Principal {
+	return &Principal{
+		Subject:  "apikey:" + apiKey.ID,
+		ApiKeyId: apiKey.ID,
+		Scopes:   apiKey.Scopes,
+	}
+}
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditOutcome tells whether an audited action succeeded.
+type AuditOutcome struct {
+	value string
+}
+
+func (outcome AuditOutcome) String() string {
+	return outcome.value
+}
+
+var (
+	UnknownAuditOutcome = AuditOutcome{""}
+	AuditSuccess        = AuditOutcome{"success"}
+	AuditFailure        = AuditOutcome{"failure"}
+)
+
+func AuditOutcomeFromString(value string) (AuditOutcome, error) {
+	switch value {
+	case AuditSuccess.value:
+		return AuditSuccess, nil
+	case AuditFailure.value:
+		return AuditFailure, nil
+	}
+	return UnknownAuditOutcome, fmt.Errorf("unknown audit outcome: %s", value)
+}
+
+// AuditEntry records who made a state-changing call, what it was and whether
+// it succeeded. Entries are never updated nor deleted.
+type AuditEntry struct {
+	ID string
+	// Actor is the subject of the principal the call was made by.
+	Actor  string
+	Action string
+	// WalletId is the wallet the action targets, empty if it targets none.
+	WalletId string
+	// PayloadHash is the hex encoded SHA-256 of the request body.
+	PayloadHash string
+	Outcome     AuditOutcome
+	ClientIp    string
+	RequestId   string
+	CreatedAt   time.Time
+}
+
+type AuditFilter struct {
+	Actor        string
+	Action       string
+	WalletId     string
+	Outcome      AuditOutcome
+	RequestId    string
+	CreatedAtGte time.Time
+	CreatedAtLte time.Time
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type DepositImportStatus struct {
+	value string
+}
+
+func (status DepositImportStatus) String() string {
+	return status.value
+}
+
+var (
+	UnknownDepositImportStatus = DepositImportStatus{""}
+	DepositImportPending       = DepositImportStatus{"pending"}
+	DepositImportProcessing    = DepositImportStatus{"processing"}
+	DepositImportCompleted     = DepositImportStatus{"completed"}
+	DepositImportFailed        = DepositImportStatus{"failed"}
+)
+
+func DepositImportStatusFromString(value string) (DepositImportStatus, error) {
+	switch value {
+	case DepositImportPending.value:
+		return DepositImportPending, nil
+	case DepositImportProcessing.value:
+		return DepositImportProcessing, nil
+	case DepositImportCompleted.value:
+		return DepositImportCompleted, nil
+	case DepositImportFailed.value:
+		return DepositImportFailed, nil
+	}
+	return UnknownDepositImportStatus, fmt.Errorf("unknown deposit import status: %s", value)
+}
+
+type DepositImportRowStatus struct {
+	value string
+}
+
+func (status DepositImportRowStatus) String() string {
+	return status.value
+}
+
+var (
+	UnknownDepositImportRowStatus = DepositImportRowStatus{""}
+	// DepositImportRowPending is a valid row waiting to be applied.
+	DepositImportRowPending = DepositImportRowStatus{"pending"}
+	// DepositImportRowValid is a row that would be applied by a dry run import.
+	DepositImportRowValid      = DepositImportRowStatus{"valid"}
+	DepositImportRowInvalid    = DepositImportRowStatus{"invalid"}
+	DepositImportRowApplied    = DepositImportRowStatus{"applied"}
+	DepositImportRowDuplicate  = DepositImportRowStatus{"duplicate"}
+	DepositImportRowFailed     = DepositImportRowStatus{"failed"}
+	DepositImportRowRolledBack = DepositImportRowStatus{"rolled_back"}
+)
+
+func DepositImportRowStatusFromString(value string) (DepositImportRowStatus, error) {
+	switch value {
+	case DepositImportRowPending.value:
+		return DepositImportRowPending, nil
+	case DepositImportRowValid.value:
+		return DepositImportRowValid, nil
+	case DepositImportRowInvalid.value:
+		return DepositImportRowInvalid, nil
+	case DepositImportRowApplied.value:
+		return DepositImportRowApplied, nil
+	case DepositImportRowDuplicate.value:
+		return DepositImportRowDuplicate, nil
+	case DepositImportRowFailed.value:
+		return DepositImportRowFailed, nil
+	case DepositImportRowRolledBack.value:
+		return DepositImportRowRolledBack, nil
+	}
+	return UnknownDepositImportRowStatus, fmt.Errorf("unknown deposit import row status: %s", value)
+}
+
+type DepositImport struct {
+	ID         string
+	Status     DepositImportStatus
+	DryRun     bool
+	Atomic     bool
+	CreatedAt  time.Time
+	FinishedAt *time.Time
+	Rows       []*DepositImportRow
+	// ApiKeyId is the API key that started the import, its transactions record it.
+	ApiKeyId string
+	// Actor is the subject of the principal that started the import, its
+	// deposits are audited as made by it.
+	Actor string
+}
+
+type DepositImportRow struct {
+	Line          int
+	WalletId      string
+	Amount        uint64
+	Reference     string
+	Status        DepositImportRowStatus
+	Error         string
+	TransactionId string
+}
+
+// CountRows returns the number of import rows with the status.
+func (depositImport *DepositImport) CountRows(status DepositImportRowStatus) int {
+	count := 0
+	for _, row := range depositImport.Rows {
+		if row.Status == status {
+			count++
+		}
+	}
+	return count
+}
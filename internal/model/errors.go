@@ -0,0 +1,27 @@
+package model
+
+import "errors"
+
+var (
+	ErrWalletNotFound        = errors.New("wallet not found")
+	ErrWalletAlreadyExists   = errors.New("wallet already exists")
+	ErrInsufficientFunds     = errors.New("insufficient funds")
+	ErrWalletFrozen          = errors.New("wallet is frozen")
+	ErrSameWallet            = errors.New("sender wallet should be different than recipient wallet")
+	ErrInvalidWalletShards   = errors.New("invalid number of wallet balance shards")
+	ErrShardsNotSupported    = errors.New("wallet balance shards are not supported by the storage")
+	ErrDepositImportNotFound = errors.New("deposit import not found")
+	ErrWebhookNotFound       = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound      = errors.New("webhook delivery not found")
+	ErrApiKeyNotFound        = errors.New("api key not found")
+	ErrInvalidScope          = errors.New("invalid api key scope")
+	ErrInvalidToken          = errors.New("invalid bearer token")
+	ErrWalletAccessDenied    = errors.New("wallet access denied")
+	ErrWalletGrantNotFound   = errors.New("wallet grant not found")
+	ErrInvalidWalletRole     = errors.New("invalid wallet role")
+	ErrOwnerNotFound         = errors.New("owner not found")
+	ErrOwnerAlreadyExists    = errors.New("owner already exists")
+	ErrOwnerHasWallets       = errors.New("owner holds wallets")
+	ErrOwnerSubjectChanged   = errors.New("owner subject cannot be changed")
+	ErrTransactionConflict   = errors.New("transaction conflicts with a concurrent transaction")
+)
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// MaxKycTier is the highest KYC tier of an owner, tier 0 is an owner that is
+// not verified.
+const MaxKycTier = 3
+
+// Owner is the customer holding wallets, at most one wallet per currency.
+type Owner struct {
+	ID string
+	// ExternalID is the identifier of the customer in the system of the client,
+	// unique among the owners if set.
+	ExternalID string
+	// Subject is the subject of the principal of the customer, unique among the
+	// owners if set. It owns the wallets of the owner and cannot be changed.
+	Subject   string
+	Name      string
+	Email     string
+	Phone     string
+	KycTier   int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,27 @@
+package model
+
+// Principal is the caller an API request is made by, identified either by an
+// API key or by a bearer token of the identity provider.
+type Principal struct {
+	// Subject identifies the caller: the subject claim of a bearer token or
+	// "apikey:" followed by the id of an API key.
+	Subject string
+	// ApiKeyId is the id of the API key the request is made with, empty for
+	// bearer tokens.
+	ApiKeyId string
+	Scopes   []Scope
+}
+
+// HasScope tells whether the principal is granted the scope, admins are granted all.
+func (principal *Principal) HasScope(scope Scope) bool {
+	return hasScope(principal.Scopes, scope)
+}
+
+func hasScope(scopes []Scope, scope Scope) bool {
+	for _, granted := range scopes {
+		if granted == scope || granted == AdminScope {
+			return true
+		}
+	}
+	return false
+}
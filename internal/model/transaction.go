@@ -30,11 +30,16 @@ func FromString(value string) (OperationType, error) {
 }
 
 type Transaction struct {
+	ID              string
 	Amount          uint64
 	ProcessedAt     time.Time
 	SenderWallet    *Wallet
 	RecipientWallet Wallet
 	OperationType   OperationType
+	Reference       string
+	// ApiKeyId is the API key that created the transaction, empty if it was
+	// not created through the API.
+	ApiKeyId string
 }
 
 type TransactionFilter struct {
@@ -1,8 +1,33 @@
 package model
 
+// MaxWalletShards bounds the number of balance shards of a wallet.
+const MaxWalletShards = 256
+
 type Wallet struct {
 	ID       string
 	Name     string
 	Currency string
 	Balance  uint64
+	// Owner is the subject of the principal owning the wallet, a user or an
+	// organisation, empty if the wallet was not created through the API. The
+	// wallet of an owner record is owned by the subject of the owner.
+	Owner string
+	// OwnerId is the owner holding the wallet, empty if the wallet is not held
+	// by an owner.
+	OwnerId string
+	Frozen  bool
+	// Shards is the number of balance shards deposits are spread over, 0 if the balance is not sharded.
+	Shards int
+}
+
+// WalletReconciliation is the balance of the wallet compared with the sum of its transactions.
+type WalletReconciliation struct {
+	WalletId            string
+	WalletName          string
+	Balance             int64
+	TransactionsBalance int64
+}
+
+func (reconciliation *WalletReconciliation) Balanced() bool {
+	return reconciliation.Balance == reconciliation.TransactionsBalance
 }
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// WalletRole is the access a principal has to a wallet, each role includes
+// the access of the previous one.
+type WalletRole struct {
+	value string
+	rank  int
+}
+
+func (role WalletRole) String() string {
+	return role.value
+}
+
+var (
+	UnknownWalletRole = WalletRole{"", 0}
+	// ViewerRole allows reading the wallet and its transactions.
+	ViewerRole = WalletRole{"viewer", 1}
+	// SpenderRole also allows transferring from the wallet.
+	SpenderRole = WalletRole{"spender", 2}
+	// OwnerRole also allows granting roles on the wallet.
+	OwnerRole = WalletRole{"owner", 3}
+)
+
+func WalletRoleFromString(value string) (WalletRole, error) {
+	switch value {
+	case ViewerRole.value:
+		return ViewerRole, nil
+	case SpenderRole.value:
+		return SpenderRole, nil
+	case OwnerRole.value:
+		return OwnerRole, nil
+	}
+	return UnknownWalletRole, fmt.Errorf("%w: %s", ErrInvalidWalletRole, value)
+}
+
+// Includes tells whether the role grants the access of the other role.
+func (role WalletRole) Includes(other WalletRole) bool {
+	return role.rank > 0 && role.rank >= other.rank
+}
+
+// WalletGrant gives a principal, identified by its subject, a role on a wallet
+// in addition to the owner of the wallet.
+type WalletGrant struct {
+	WalletId  string
+	Subject   string
+	Role      WalletRole
+	CreatedAt time.Time
+}
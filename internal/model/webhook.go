@@ -0,0 +1,88 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type EventType struct {
+	value string
+}
+
+func (eventType EventType) String() string {
+	return eventType.value
+}
+
+var (
+	UnknownEvent  = EventType{""}
+	DepositEvent  = EventType{"transaction.deposit"}
+	TransferEvent = EventType{"transaction.transfer"}
+)
+
+func EventTypeFromString(value string) (EventType, error) {
+	switch value {
+	case DepositEvent.value:
+		return DepositEvent, nil
+	case TransferEvent.value:
+		return TransferEvent, nil
+	}
+	return UnknownEvent, fmt.Errorf("unknown event type: %s", value)
+}
+
+// Event is a wallet event recorded in the outbox together with the change it describes.
+type Event struct {
+	ID        string
+	Type      EventType
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+type WebhookSubscription struct {
+	ID         string
+	Url        string
+	Secret     string
+	EventTypes []EventType
+	Active     bool
+	CreatedAt  time.Time
+}
+
+type WebhookDeliveryStatus struct {
+	value string
+}
+
+func (status WebhookDeliveryStatus) String() string {
+	return status.value
+}
+
+var (
+	UnknownWebhookDeliveryStatus = WebhookDeliveryStatus{""}
+	WebhookDeliveryPending       = WebhookDeliveryStatus{"pending"}
+	WebhookDeliveryDelivered     = WebhookDeliveryStatus{"delivered"}
+	WebhookDeliveryDead          = WebhookDeliveryStatus{"dead"}
+)
+
+func WebhookDeliveryStatusFromString(value string) (WebhookDeliveryStatus, error) {
+	switch value {
+	case WebhookDeliveryPending.value:
+		return WebhookDeliveryPending, nil
+	case WebhookDeliveryDelivered.value:
+		return WebhookDeliveryDelivered, nil
+	case WebhookDeliveryDead.value:
+		return WebhookDeliveryDead, nil
+	}
+	return UnknownWebhookDeliveryStatus, fmt.Errorf("unknown webhook delivery status: %s", value)
+}
+
+// WebhookDelivery is a single event to be delivered to a single subscription.
+type WebhookDelivery struct {
+	ID             string
+	SubscriptionId string
+	Url            string
+	Secret         string
+	Event          Event
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	DeliveredAt    *time.Time
+}
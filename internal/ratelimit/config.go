@@ -0,0 +1,45 @@
+package ratelimit
+
+// DefaultGroup is the route group of the routes not listed in another group.
+const DefaultGroup = "default"
+
+type Config struct {
+	Enabled bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	// TrustForwardedFor identifies the client by the last address of the
+	// X-Forwarded-For header, the one appended by the proxy in front of the
+	// server. Enable it only behind a proxy, clients can send the header too.
+	TrustForwardedFor bool `yaml:"trust-forwarded-for" env:"RATE_LIMIT_TRUST_FORWARDED_FOR"`
+	// Groups are the limits of route groups by name, the default group limits
+	// the routes not listed in another group.
+	Groups map[string]GroupConfig `yaml:"groups"`
+}
+
+type GroupConfig struct {
+	// Routes are the routes of the group as the method and the path template
+	// below the API prefix, e.g. "POST /wallets/{id}/transfer".
+	Routes []string `yaml:"routes"`
+	// ApiKey limits the requests of every principal, an API key or the subject
+	// of a bearer token.
+	ApiKey Limit `yaml:"api-key"`
+	// Ip limits the requests of every client address, authenticated or not.
+	Ip Limit `yaml:"ip"`
+}
+
+// Limit is a token bucket: a client makes up to Burst requests at once and
+// Rate requests per second on average. A zero rate is unlimited.
+type Limit struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled: true,
+		Groups: map[string]GroupConfig{
+			DefaultGroup: {
+				ApiKey: Limit{Rate: 100, Burst: 200},
+				Ip:     Limit{Rate: 200, Burst: 400},
+			},
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter tells whether a client may make a request to a route, route being
+// the method and the path template of the request. A rejected client may retry
+// after the returned duration.
+type Limiter interface {
+	AllowIp(route string, ip string) (bool, time.Duration)
+	AllowApiKey(route string, subject string) (bool, time.Duration)
+}
+
+type limiter struct {
+	groups       map[string]*group
+	defaultGroup *group
+}
+
+type group struct {
+	apiKey *buckets
+	ip     *buckets
+}
+
+// NewLimiter returns the limiter of the route groups of the config, routes
+// outside of every group are not limited unless there is a default group.
+func NewLimiter(config Config) *limiter {
+	limiter := &limiter{
+		groups: make(map[string]*group),
+	}
+	for name, groupConfig := range config.Groups {
+		group := &group{
+			apiKey: newBuckets(groupConfig.ApiKey),
+			ip:     newBuckets(groupConfig.Ip),
+		}
+		if name == DefaultGroup {
+			limiter.defaultGroup = group
+		}
+		for _, route := range groupConfig.Routes {
+			limiter.groups[route] = group
+		}
+	}
+	return limiter
+}
+
+func (limiter *limiter) AllowIp(route string, ip string) (bool, time.Duration) {
+	group := limiter.group(route)
+	if group == nil {
+		return true, 0
+	}
+	return group.ip.take(ip, time.Now())
+}
+
+func (limiter *limiter) AllowApiKey(route string, subject string) (bool, time.Duration) {
+	group := limiter.group(route)
+	if group == nil {
+		return true, 0
+	}
+	return group.apiKey.take(subject, time.Now())
+}
+
+func (limiter *limiter) group(route string) *group {
+	if group, ok := limiter.groups[route]; ok {
+		return group
+	}
+	return limiter.defaultGroup
+}
+
+// buckets are the token buckets of the clients of a route group. A bucket that
+// has filled up again is dropped, a new one is full too.
+type buckets struct {
+	limit   Limit
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	sweptAt time.Time
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newBuckets(limit Limit) *buckets {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &buckets{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// take takes a token from the bucket of the client, if there is none it
+// returns the time until the next token.
+func (buckets *buckets) take(client string, now time.Time) (bool, time.Duration) {
+	if buckets.limit.Rate <= 0 {
+		return true, 0
+	}
+	buckets.mu.Lock()
+	defer buckets.mu.Unlock()
+
+	buckets.sweep(now)
+	burst := float64(buckets.limit.Burst)
+	clientBucket, ok := buckets.buckets[client]
+	if !ok {
+		clientBucket = &bucket{tokens: burst, updatedAt: now}
+		buckets.buckets[client] = clientBucket
+	}
+	elapsed := now.Sub(clientBucket.updatedAt).Seconds()
+	clientBucket.tokens = math.Min(burst, clientBucket.tokens+elapsed*buckets.limit.Rate)
+	clientBucket.updatedAt = now
+	if clientBucket.tokens >= 1 {
+		clientBucket.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - clientBucket.tokens) / buckets.limit.Rate * float64(time.Second))
+}
+
+// sweep drops the full buckets, at most once per the time a bucket takes to
+// fill up, the caller holds the lock.
+func (buckets *buckets) sweep(now time.Time) {
+	fillTime := time.Duration(float64(buckets.limit.Burst) / buckets.limit.Rate * float64(time.Second))
+	if now.Sub(buckets.sweptAt) < fillTime {
+		return
+	}
+	for client, clientBucket := range buckets.buckets {
+		if now.Sub(clientBucket.updatedAt) >= fillTime {
+			delete(buckets.buckets, client)
+		}
+	}
+	buckets.sweptAt = now
+}
@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketsTake(t *testing.T) {
+	// given
+	buckets := newBuckets(Limit{Rate: 2, Burst: 3})
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// when
+	var allowed int
+	for i := 0; i < 5; i++ {
+		if ok, _ := buckets.take("client-1", now); ok {
+			allowed++
+		}
+	}
+	rejected, retryAfter := buckets.take("client-1", now)
+	other, _ := buckets.take("client-2", now)
+	refilled, _ := buckets.take("client-1", now.Add(retryAfter))
+
+	// then
+	assert.Equal(t, 3, allowed)
+	assert.False(t, rejected)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+	assert.True(t, other)
+	assert.True(t, refilled)
+}
+
+func TestBucketsSweep(t *testing.T) {
+	// given
+	buckets := newBuckets(Limit{Rate: 1, Burst: 10})
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	buckets.take("client-1", now)
+	buckets.take("client-2", now.Add(5*time.Second))
+
+	// when
+	buckets.take("client-3", now.Add(12*time.Second))
+
+	// then
+	assert.NotContains(t, buckets.buckets, "client-1")
+	assert.Contains(t, buckets.buckets, "client-2")
+	assert.Contains(t, buckets.buckets, "client-3")
+}
+
+func TestLimiterGroups(t *testing.T) {
+	// given
+	config := Config{
+		Groups: map[string]GroupConfig{
+			DefaultGroup: {ApiKey: Limit{Rate: 100, Burst: 100}},
+			"transfers": {
+				Routes: []string{"POST /wallets/{id}/transfer"},
+				ApiKey: Limit{Rate: 1, Burst: 1},
+			},
+		},
+	}
+	limiter := NewLimiter(config)
+
+	// when
+	first, _ := limiter.AllowApiKey("POST /wallets/{id}/transfer", "apikey:1")
+	second, retryAfter := limiter.AllowApiKey("POST /wallets/{id}/transfer", "apikey:1")
+	other, _ := limiter.AllowApiKey("GET /wallets/{id}/transactions", "apikey:1")
+	ip, _ := limiter.AllowIp("POST /wallets/{id}/transfer", "10.0.0.1")
+
+	// then
+	assert.True(t, first)
+	assert.False(t, second)
+	assert.Greater(t, retryAfter, time.Duration(0))
+	assert.True(t, other)
+	assert.True(t, ip)
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/jackc/pgtype"
+)
+
+type ApiKeyRepository interface {
+	CreateApiKey(ctx context.Context, apiKey model.ApiKey) (string, error)
+	// GetApiKeyByHash returns the key of the hash unless it is revoked.
+	GetApiKeyByHash(ctx context.Context, hash string) (*model.ApiKey, error)
+	GetApiKeys(ctx context.Context) ([]*model.ApiKey, error)
+	// RotateApiKey replaces the prefix and the hash of a key that is not revoked,
+	// the key keeps its id and scopes.
+	RotateApiKey(ctx context.Context, id string, prefix string, hash string, rotatedAt time.Time) (*model.ApiKey, error)
+	RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewApiKeyRepository(db *sql.DB) *apiKeyRepository {
+	return &apiKeyRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = "id, name, prefix, key_hash, scopes, created_at, rotated_at, revoked_at"
+
+func (apiKeyRepository *apiKeyRepository) CreateApiKey(ctx context.Context, apiKey model.ApiKey) (string, error) {
+	var scopesArray pgtype.TextArray
+	if err := scopesArray.Set(scopeStrings(apiKey.Scopes)); err != nil {
+		return "", fmt.Errorf("ApiKeyRepository - CreateApiKey - scopesArray.Set: %w", err)
+	}
+	var id string
+	if err := apiKeyRepository.db.QueryRowContext(
+		ctx,
+		"INSERT INTO api_keys(name, prefix, key_hash, scopes, created_at) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.Hash,
+		&scopesArray,
+		apiKey.CreatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("ApiKeyRepository - CreateApiKey - apiKeyRepository.db.QueryRowContext: %w", err)
+	}
+	return id, nil
+}
+
+func (apiKeyRepository *apiKeyRepository) GetApiKeyByHash(ctx context.Context, hash string) (*model.ApiKey, error) {
+	apiKey, err := scanApiKey(apiKeyRepository.db.QueryRowContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL",
+		hash,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("ApiKeyRepository - GetApiKeyByHash - scanApiKey: %w", model.ErrApiKeyNotFound)
+		}
+		return nil, fmt.Errorf("ApiKeyRepository - GetApiKeyByHash - scanApiKey: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (apiKeyRepository *apiKeyRepository) GetApiKeys(ctx context.Context) ([]*model.ApiKey, error) {
+	rows, err := apiKeyRepository.db.QueryContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ApiKeyRepository - GetApiKeys - apiKeyRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var apiKeys []*model.ApiKey
+	for rows.Next() {
+		apiKey, err := scanApiKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ApiKeyRepository - GetApiKeys - scanApiKey: %w", err)
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ApiKeyRepository - GetApiKeys - rows.Err: %w", err)
+	}
+	return apiKeys, nil
+}
+
+func (apiKeyRepository *apiKeyRepository) RotateApiKey(
+	ctx context.Context, id string, prefix string, hash string, rotatedAt time.Time,
+) (*model.ApiKey, error) {
+	apiKey, err := scanApiKey(apiKeyRepository.db.QueryRowContext(
+		ctx,
+		"UPDATE api_keys SET prefix = $1, key_hash = $2, rotated_at = $3 WHERE id = $4 AND revoked_at IS NULL RETURNING "+apiKeyColumns,
+		prefix,
+		hash,
+		rotatedAt,
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("ApiKeyRepository - RotateApiKey - scanApiKey: %w", model.ErrApiKeyNotFound)
+		}
+		return nil, fmt.Errorf("ApiKeyRepository - RotateApiKey - scanApiKey: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (apiKeyRepository *apiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := apiKeyRepository.db.ExecContext(
+		ctx,
+		"UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL",
+		revokedAt,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("ApiKeyRepository - RevokeApiKey - apiKeyRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ApiKeyRepository - RevokeApiKey - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("ApiKeyRepository - RevokeApiKey - apiKeyRepository.db.ExecContext: %w", model.ErrApiKeyNotFound)
+	}
+	return nil
+}
+
+func scanApiKey(row pgRow) (*model.ApiKey, error) {
+	apiKey := new(model.ApiKey)
+	var scopesArray pgtype.TextArray
+	var rotatedAt, revokedAt sql.NullTime
+	if err := row.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.Hash,
+		&scopesArray,
+		&apiKey.CreatedAt,
+		&rotatedAt,
+		&revokedAt,
+	); err != nil {
+		return nil, err
+	}
+	scopes := make([]string, 0, len(scopesArray.Elements))
+	for _, element := range scopesArray.Elements {
+		scopes = append(scopes, element.String)
+	}
+	var err error
+	if apiKey.Scopes, err = scopesFromStrings(scopes); err != nil {
+		return nil, fmt.Errorf("scanApiKey - scopesFromStrings: %w", err)
+	}
+	if rotatedAt.Valid {
+		apiKey.RotatedAt = &rotatedAt.Time
+	}
+	if revokedAt.Valid {
+		apiKey.RevokedAt = &revokedAt.Time
+	}
+	return apiKey, nil
+}
+
+func scopeStrings(scopes []model.Scope) []string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, scope.String())
+	}
+	return values
+}
+
+func scopesFromStrings(values []string) ([]model.Scope, error) {
+	scopes := make([]model.Scope, 0, len(values))
+	for _, value := range values {
+		scope, err := model.ScopeFromString(value)
+		if err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testApiKeyRepository is the contract every ApiKeyRepository implementation
+// satisfies, newRepositories returns empty repositories of the same storage.
+func testApiKeyRepository(t *testing.T, newRepositories func(t *testing.T) (ApiKeyRepository, WalletRepository)) {
+	ctx := context.Background()
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	createApiKey := func(t *testing.T, apiKeyRepository ApiKeyRepository, name string, hash string) *model.ApiKey {
+		apiKey := model.ApiKey{
+			Name:      name,
+			Prefix:    "wk_" + hash,
+			Hash:      hash,
+			Scopes:    []model.Scope{model.WalletsReadScope, model.TransfersCreateScope},
+			CreatedAt: createdAt,
+		}
+		id, err := apiKeyRepository.CreateApiKey(ctx, apiKey)
+		require.NoError(t, err)
+		apiKey.ID = id
+		return &apiKey
+	}
+
+	t.Run("GetApiKeyByHash", func(t *testing.T) {
+		// given
+		apiKeyRepository, _ := newRepositories(t)
+		apiKey := createApiKey(t, apiKeyRepository, "first", "hash-1")
+		createApiKey(t, apiKeyRepository, "second", "hash-2")
+
+		// when
+		found, err := apiKeyRepository.GetApiKeyByHash(ctx, "hash-1")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, apiKey, found)
+	})
+
+	t.Run("GetApiKeyByHashNotFound", func(t *testing.T) {
+		// given
+		apiKeyRepository, _ := newRepositories(t)
+		createApiKey(t, apiKeyRepository, "first", "hash-1")
+
+		// when
+		_, err := apiKeyRepository.GetApiKeyByHash(ctx, "hash-2")
+
+		// then
+		assert.ErrorIs(t, err, model.ErrApiKeyNotFound)
+	})
+
+	t.Run("RotateApiKey", func(t *testing.T) {
+		// given
+		apiKeyRepository, _ := newRepositories(t)
+		apiKey := createApiKey(t, apiKeyRepository, "first", "hash-1")
+		rotatedAt := createdAt.Add(time.Hour)
+
+		// when
+		rotated, err := apiKeyRepository.RotateApiKey(ctx, apiKey.ID, "wk_hash-2", "hash-2", rotatedAt)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, apiKey.ID, rotated.ID)
+		assert.Equal(t, "hash-2", rotated.Hash)
+		assert.Equal(t, apiKey.Scopes, rotated.Scopes)
+		require.NotNil(t, rotated.RotatedAt)
+		assert.True(t, rotatedAt.Equal(*rotated.RotatedAt))
+		_, err = apiKeyRepository.GetApiKeyByHash(ctx, "hash-1")
+		assert.ErrorIs(t, err, model.ErrApiKeyNotFound)
+		_, err = apiKeyRepository.GetApiKeyByHash(ctx, "hash-2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("RevokeApiKey", func(t *testing.T) {
+		// given
+		apiKeyRepository, _ := newRepositories(t)
+		apiKey := createApiKey(t, apiKeyRepository, "first", "hash-1")
+
+		// when
+		err := apiKeyRepository.RevokeApiKey(ctx, apiKey.ID, createdAt.Add(time.Hour))
+
+		// then
+		require.NoError(t, err)
+		_, err = apiKeyRepository.GetApiKeyByHash(ctx, "hash-1")
+		assert.ErrorIs(t, err, model.ErrApiKeyNotFound)
+		_, err = apiKeyRepository.RotateApiKey(ctx, apiKey.ID, "wk_hash-2", "hash-2", createdAt.Add(time.Hour))
+		assert.ErrorIs(t, err, model.ErrApiKeyNotFound)
+		assert.ErrorIs(t, apiKeyRepository.RevokeApiKey(ctx, apiKey.ID, createdAt.Add(time.Hour)), model.ErrApiKeyNotFound)
+		apiKeys, err := apiKeyRepository.GetApiKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, apiKeys, 1)
+		assert.NotNil(t, apiKeys[0].RevokedAt)
+	})
+
+	t.Run("TransactionsRecordApiKey", func(t *testing.T) {
+		// given
+		apiKeyRepository, walletRepository := newRepositories(t)
+		apiKey := createApiKey(t, apiKeyRepository, "first", "hash-1")
+		senderId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "sender", Currency: "USD"})
+		require.NoError(t, err)
+		recipientId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "recipient", Currency: "USD"})
+		require.NoError(t, err)
+		_, err = walletRepository.Deposit(ctx, senderId, 100)
+		require.NoError(t, err)
+
+		// when
+		transfer, err := walletRepository.Transfer(auth.WithPrincipal(ctx, apiKey.Principal()), senderId, recipientId, 40)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, apiKey.ID, transfer.ApiKeyId)
+		transactions, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: senderId})
+		require.NoError(t, err)
+		require.Len(t, transactions, 2)
+		assert.Equal(t, apiKey.ID, transactions[0].ApiKeyId)
+		assert.Empty(t, transactions[1].ApiKeyId)
+	})
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// AuditRepository appends to the audit log, which has no update nor delete.
+type AuditRepository interface {
+	CreateAuditEntry(ctx context.Context, entry model.AuditEntry) (string, error)
+	// GetAuditEntries returns the entries matching the filter, newest first, a
+	// limit or an offset of -1 is ignored.
+	GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error)
+}
+
+type auditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *auditRepository {
+	return &auditRepository{
+		db: db,
+	}
+}
+
+const auditColumns = "id, actor, action, COALESCE(wallet_id, ''), payload_hash, outcome, client_ip, COALESCE(request_id, ''), created_at"
+
+func (auditRepository *auditRepository) CreateAuditEntry(ctx context.Context, entry model.AuditEntry) (string, error) {
+	var id string
+	if err := auditRepository.db.QueryRowContext(
+		ctx,
+		"INSERT INTO audit_log(actor, action, wallet_id, payload_hash, outcome, client_ip, request_id, created_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		entry.Actor,
+		entry.Action,
+		nullString(entry.WalletId),
+		entry.PayloadHash,
+		entry.Outcome.String(),
+		entry.ClientIp,
+		nullString(entry.RequestId),
+		entry.CreatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("AuditRepository - CreateAuditEntry - auditRepository.db.QueryRowContext: %w", err)
+	}
+	return id, nil
+}
+
+func (auditRepository *auditRepository) GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log WHERE TRUE"
+	var values []interface{}
+	for _, condition := range auditConditions(filter) {
+		values = append(values, condition.value)
+		query += " AND " + condition.column + " $" + strconv.Itoa(len(values))
+	}
+	query += " ORDER BY created_at DESC, id DESC"
+	if limit > -1 {
+		values = append(values, limit)
+		query += " LIMIT $" + strconv.Itoa(len(values))
+	}
+	if offset > -1 {
+		values = append(values, offset)
+		query += " OFFSET $" + strconv.Itoa(len(values))
+	}
+	rows, err := auditRepository.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepository - GetAuditEntries - auditRepository.db.QueryContext: %w", err)
+	}
+	entries, err := scanAuditEntries(rows)
+	if err != nil {
+		return nil, fmt.Errorf("AuditRepository - GetAuditEntries - scanAuditEntries: %w", err)
+	}
+	return entries, nil
+}
+
+type auditCondition struct {
+	// column is the column and the comparison operator of the condition.
+	column string
+	value  interface{}
+}
+
+// auditConditions returns the conditions of the filter, times are compared
+// in UTC like they are stored.
+func auditConditions(filter model.AuditFilter) []auditCondition {
+	var conditions []auditCondition
+	if filter.Actor != "" {
+		conditions = append(conditions, auditCondition{"actor =", filter.Actor})
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, auditCondition{"action =", filter.Action})
+	}
+	if filter.WalletId != "" {
+		conditions = append(conditions, auditCondition{"wallet_id =", filter.WalletId})
+	}
+	if filter.Outcome != model.UnknownAuditOutcome {
+		conditions = append(conditions, auditCondition{"outcome =", filter.Outcome.String()})
+	}
+	if filter.RequestId != "" {
+		conditions = append(conditions, auditCondition{"request_id =", filter.RequestId})
+	}
+	if !filter.CreatedAtGte.IsZero() {
+		conditions = append(conditions, auditCondition{"created_at >=", filter.CreatedAtGte.UTC()})
+	}
+	if !filter.CreatedAtLte.IsZero() {
+		conditions = append(conditions, auditCondition{"created_at <=", filter.CreatedAtLte.UTC()})
+	}
+	return conditions
+}
+
+func scanAuditEntries(rows *sql.Rows) ([]*model.AuditEntry, error) {
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		entry := new(model.AuditEntry)
+		var outcome string
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.Actor,
+			&entry.Action,
+			&entry.WalletId,
+			&entry.PayloadHash,
+			&outcome,
+			&entry.ClientIp,
+			&entry.RequestId,
+			&entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanAuditEntries - rows.Scan: %w", err)
+		}
+		var err error
+		if entry.Outcome, err = model.AuditOutcomeFromString(outcome); err != nil {
+			return nil, fmt.Errorf("scanAuditEntries - model.AuditOutcomeFromString: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanAuditEntries - rows.Err: %w", err)
+	}
+	return entries, nil
+}
+
+// auditSql completes a statement whose inserted CTE returns the row of a
+// successful operation: it records the pending audit entry, passed as the
+// parameters of pendingAuditEntry.args starting at $first, unless its id is
+// NULL. The target wallet is the walletId expression.
+func auditSql(first int, walletId string) string {
+	param := func(offset int) string {
+		return "$" + strconv.Itoa(first+offset)
+	}
+	return ", audited AS (INSERT INTO audit_log(id, actor, action, wallet_id, payload_hash, outcome, client_ip, request_id, created_at) " +
+		"SELECT " + param(0) + "::UUID, " + param(1) + "::TEXT, " + param(2) + "::TEXT, " + walletId + "::TEXT, " + param(3) + "::TEXT, " +
+		"'" + model.AuditSuccess.String() + "', " + param(4) + "::TEXT, " + param(5) + "::TEXT, " + param(6) + "::TIMESTAMP " +
+		"FROM inserted WHERE " + param(0) + "::UUID IS NOT NULL)"
+}
+
+// pendingAuditEntry is the audit entry of the context of an operation, to be
+// recorded with the operation if it succeeds.
+type pendingAuditEntry struct {
+	entry *model.AuditEntry
+	id    string
+}
+
+// newPendingAuditEntry returns the entry of ctx unless there is none or it
+// is already recorded, then the pending entry records nothing.
+func newPendingAuditEntry(ctx context.Context) (pendingAuditEntry, error) {
+	entry := audit.EntryFromContext(ctx)
+	if entry == nil || entry.ID != "" {
+		return pendingAuditEntry{}, nil
+	}
+	id, err := newId()
+	if err != nil {
+		return pendingAuditEntry{}, fmt.Errorf("newPendingAuditEntry - newId: %w", err)
+	}
+	return pendingAuditEntry{entry: entry, id: id}, nil
+}
+
+// args returns the parameters of auditSql, all NULL if there is no entry.
+func (pending pendingAuditEntry) args(now time.Time) []interface{} {
+	if pending.entry == nil {
+		return make([]interface{}, 7)
+	}
+	return []interface{}{
+		pending.id,
+		pending.entry.Actor,
+		pending.entry.Action,
+		pending.entry.PayloadHash,
+		pending.entry.ClientIp,
+		nullString(pending.entry.RequestId),
+		now,
+	}
+}
+
+// recorded completes the entry once the operation is committed, so that the
+// caller does not record it again.
+func (pending pendingAuditEntry) recorded(walletId string, now time.Time) {
+	if pending.entry == nil {
+		return
+	}
+	pending.entry.ID = pending.id
+	pending.entry.WalletId = walletId
+	pending.entry.Outcome = model.AuditSuccess
+	pending.entry.CreatedAt = now
+}
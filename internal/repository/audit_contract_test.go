@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAuditRepository is the contract every AuditRepository implementation
+// satisfies, newRepositories returns empty repositories of the same storage
+// whose wallet repository records the audit entries with the operations.
+func testAuditRepository(t *testing.T, newRepositories func(t *testing.T) (AuditRepository, WalletRepository)) {
+	ctx := context.Background()
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newEntry := func(action string) *model.AuditEntry {
+		return &model.AuditEntry{
+			Actor:       "alice",
+			Action:      action,
+			PayloadHash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			ClientIp:    "192.0.2.1",
+			RequestId:   "request-" + action,
+		}
+	}
+
+	t.Run("GetAuditEntries", func(t *testing.T) {
+		// given
+		auditRepository, _ := newRepositories(t)
+		for i, action := range []string{"wallet.create", "wallet.deposit", "wallet.deposit"} {
+			entry := newEntry(action)
+			entry.Outcome = model.AuditSuccess
+			entry.CreatedAt = createdAt.Add(time.Duration(i) * time.Minute)
+			_, err := auditRepository.CreateAuditEntry(ctx, *entry)
+			require.NoError(t, err)
+		}
+		failed := newEntry("api_key.revoke")
+		failed.Actor = "bob"
+		failed.Outcome = model.AuditFailure
+		failed.CreatedAt = createdAt.Add(time.Hour)
+		failedId, err := auditRepository.CreateAuditEntry(ctx, *failed)
+		require.NoError(t, err)
+
+		// when
+		all, err := auditRepository.GetAuditEntries(ctx, -1, -1, model.AuditFilter{})
+		require.NoError(t, err)
+		deposits, err := auditRepository.GetAuditEntries(ctx, 1, 1, model.AuditFilter{Action: "wallet.deposit"})
+		require.NoError(t, err)
+		failures, err := auditRepository.GetAuditEntries(ctx, -1, -1, model.AuditFilter{Outcome: model.AuditFailure, Actor: "bob"})
+		require.NoError(t, err)
+		early, err := auditRepository.GetAuditEntries(ctx, -1, -1, model.AuditFilter{CreatedAtLte: createdAt.Add(time.Minute)})
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, all, 4)
+		assert.Equal(t, failedId, all[0].ID)
+		assert.Equal(t, "wallet.create", all[3].Action)
+		require.Len(t, deposits, 1)
+		assert.True(t, createdAt.Add(time.Minute).Equal(deposits[0].CreatedAt))
+		require.Len(t, failures, 1)
+		assert.Equal(t, model.AuditFailure, failures[0].Outcome)
+		assert.Equal(t, "192.0.2.1", failures[0].ClientIp)
+		assert.Equal(t, "request-api_key.revoke", failures[0].RequestId)
+		assert.Empty(t, failures[0].WalletId)
+		assert.Len(t, early, 2)
+	})
+
+	t.Run("RecordedWithOperations", func(t *testing.T) {
+		// given
+		auditRepository, walletRepository := newRepositories(t)
+		createEntry := newEntry("wallet.create")
+		depositEntry := newEntry("wallet.deposit")
+		transferEntry := newEntry("wallet.transfer")
+
+		// when
+		senderId, err := walletRepository.CreateWallet(audit.WithEntry(ctx, createEntry), model.Wallet{Name: "sender", Currency: "USD"})
+		require.NoError(t, err)
+		recipientId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "recipient", Currency: "USD"})
+		require.NoError(t, err)
+		_, err = walletRepository.Deposit(audit.WithEntry(ctx, depositEntry), senderId, 100)
+		require.NoError(t, err)
+		_, err = walletRepository.Transfer(audit.WithEntry(ctx, transferEntry), senderId, recipientId, 40)
+		require.NoError(t, err)
+
+		// then
+		entries, err := auditRepository.GetAuditEntries(ctx, -1, -1, model.AuditFilter{WalletId: senderId})
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		for _, entry := range []*model.AuditEntry{createEntry, depositEntry, transferEntry} {
+			assert.NotEmpty(t, entry.ID)
+			assert.Equal(t, senderId, entry.WalletId)
+			assert.Equal(t, model.AuditSuccess, entry.Outcome)
+		}
+		assert.Equal(t, transferEntry.ID, entries[0].ID)
+		assert.Equal(t, "wallet.transfer", entries[0].Action)
+		assert.Equal(t, "alice", entries[0].Actor)
+		assert.Equal(t, transferEntry.PayloadHash, entries[0].PayloadHash)
+		assert.Equal(t, createEntry.ID, entries[2].ID)
+	})
+
+	t.Run("NotRecordedWithFailedOperation", func(t *testing.T) {
+		// given
+		auditRepository, walletRepository := newRepositories(t)
+		senderId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "sender", Currency: "USD"})
+		require.NoError(t, err)
+		recipientId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "recipient", Currency: "USD"})
+		require.NoError(t, err)
+		entry := newEntry("wallet.transfer")
+
+		// when
+		_, err = walletRepository.Transfer(audit.WithEntry(ctx, entry), senderId, recipientId, 40)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+		assert.Empty(t, entry.ID)
+		entries, err := auditRepository.GetAuditEntries(ctx, -1, -1, model.AuditFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,376 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+type DepositImportRepository interface {
+	CreateDepositImport(ctx context.Context, depositImport *model.DepositImport, lease time.Duration) (string, error)
+	UpdateDepositImport(ctx context.Context, depositImport *model.DepositImport, rows []*model.DepositImportRow) error
+	GetDepositImport(ctx context.Context, id string) (*model.DepositImport, error)
+	ApplyDepositImportRows(ctx context.Context, importId string, rows []*model.DepositImportRow, dryRun bool, atomic bool) (bool, error)
+	RenewDepositImportLease(ctx context.Context, id string, lease time.Duration) error
+	ClaimStaleDepositImports(ctx context.Context, limit int, lease time.Duration) ([]string, error)
+}
+
+type depositImportRepository struct {
+	db *sql.DB
+}
+
+func NewDepositImportRepository(db *sql.DB) *depositImportRepository {
+	return &depositImportRepository{
+		db: db,
+	}
+}
+
+// CreateDepositImport stores the import leased to the caller for lease, the
+// lease has to be renewed while the import is processed.
+func (depositImportRepository *depositImportRepository) CreateDepositImport(ctx context.Context, depositImport *model.DepositImport, lease time.Duration) (string, error) {
+	tx, err := depositImportRepository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("DepositImportRepository - CreateDepositImport - depositImportRepository.db.BeginTx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var id string
+	if err = tx.QueryRowContext(
+		ctx,
+		"INSERT INTO deposit_imports(status, dry_run, atomic, created_at, api_key_id, lease_until, actor) VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		depositImport.Status,
+		depositImport.DryRun,
+		depositImport.Atomic,
+		depositImport.CreatedAt,
+		sql.NullString{String: depositImport.ApiKeyId, Valid: depositImport.ApiKeyId != ""},
+		time.Now().UTC().Add(lease),
+		nullString(depositImport.Actor),
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("DepositImportRepository - CreateDepositImport - tx.QueryRowContext: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(
+		ctx,
+		"INSERT INTO deposit_import_rows(import_id, line, wallet_id, amount, reference, status, error) VALUES($1, $2, $3, $4, $5, $6, $7)",
+	)
+	if err != nil {
+		return "", fmt.Errorf("DepositImportRepository - CreateDepositImport - tx.PrepareContext: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+	for _, row := range depositImport.Rows {
+		if _, err = stmt.ExecContext(
+			ctx,
+			id,
+			row.Line,
+			row.WalletId,
+			row.Amount,
+			row.Reference,
+			row.Status,
+			sql.NullString{String: row.Error, Valid: row.Error != ""},
+		); err != nil {
+			return "", fmt.Errorf("DepositImportRepository - CreateDepositImport - stmt.ExecContext: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("DepositImportRepository - CreateDepositImport - tx.Commit: %w", err)
+	}
+	return id, nil
+}
+
+func (depositImportRepository *depositImportRepository) UpdateDepositImport(ctx context.Context, depositImport *model.DepositImport, rows []*model.DepositImportRow) error {
+	tx, err := depositImportRepository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("DepositImportRepository - UpdateDepositImport - depositImportRepository.db.BeginTx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err = tx.ExecContext(
+		ctx,
+		"UPDATE deposit_imports SET status = $1, finished_at = $2 WHERE id = $3",
+		depositImport.Status,
+		depositImport.FinishedAt,
+		depositImport.ID,
+	); err != nil {
+		return fmt.Errorf("DepositImportRepository - UpdateDepositImport - tx.ExecContext: %w", err)
+	}
+
+	if err = updateDepositImportRows(ctx, tx, depositImport.ID, rows); err != nil {
+		return fmt.Errorf("DepositImportRepository - UpdateDepositImport - updateDepositImportRows: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("DepositImportRepository - UpdateDepositImport - tx.Commit: %w", err)
+	}
+	return nil
+}
+
+// updateDepositImportRows stores the outcome of the rows of the import.
+func updateDepositImportRows(ctx context.Context, tx *sql.Tx, importId string, rows []*model.DepositImportRow) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	stmt, err := tx.PrepareContext(
+		ctx,
+		"UPDATE deposit_import_rows SET status = $1, error = $2, transaction_id = $3 WHERE import_id = $4 AND line = $5",
+	)
+	if err != nil {
+		return fmt.Errorf("updateDepositImportRows - tx.PrepareContext: %w", err)
+	}
+	defer func() {
+		_ = stmt.Close()
+	}()
+	for _, row := range rows {
+		if _, err = stmt.ExecContext(
+			ctx,
+			row.Status,
+			sql.NullString{String: row.Error, Valid: row.Error != ""},
+			sql.NullString{String: row.TransactionId, Valid: row.TransactionId != ""},
+			importId,
+			row.Line,
+		); err != nil {
+			return fmt.Errorf("updateDepositImportRows - stmt.ExecContext: %w", err)
+		}
+	}
+	return nil
+}
+
+func (depositImportRepository *depositImportRepository) GetDepositImport(ctx context.Context, id string) (*model.DepositImport, error) {
+	depositImport := new(model.DepositImport)
+	var status string
+	var finishedAt sql.NullTime
+	var apiKeyId, actor sql.NullString
+	if err := depositImportRepository.db.QueryRowContext(
+		ctx,
+		"SELECT id, status, dry_run, atomic, created_at, finished_at, api_key_id, actor FROM deposit_imports WHERE id = $1",
+		id,
+	).Scan(
+		&depositImport.ID,
+		&status,
+		&depositImport.DryRun,
+		&depositImport.Atomic,
+		&depositImport.CreatedAt,
+		&finishedAt,
+		&apiKeyId,
+		&actor,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("DepositImportRepository - GetDepositImport - depositImportRepository.db.QueryRowContext: %w", model.ErrDepositImportNotFound)
+		}
+		return nil, fmt.Errorf("DepositImportRepository - GetDepositImport - depositImportRepository.db.QueryRowContext: %w", err)
+	}
+	importStatus, err := model.DepositImportStatusFromString(status)
+	if err != nil {
+		return nil, fmt.Errorf("DepositImportRepository - GetDepositImport - model.DepositImportStatusFromString: %w", err)
+	}
+	depositImport.Status = importStatus
+	depositImport.ApiKeyId = apiKeyId.String
+	depositImport.Actor = actor.String
+	if finishedAt.Valid {
+		depositImport.FinishedAt = &finishedAt.Time
+	}
+
+	rows, err := depositImportRepository.db.QueryContext(
+		ctx,
+		"SELECT line, wallet_id, amount, reference, status, error, transaction_id FROM deposit_import_rows WHERE import_id = $1 ORDER BY line",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("DepositImportRepository - GetDepositImport - depositImportRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	for rows.Next() {
+		row := new(model.DepositImportRow)
+		var rowStatus string
+		var rowError, transactionId sql.NullString
+		if err = rows.Scan(
+			&row.Line,
+			&row.WalletId,
+			&row.Amount,
+			&row.Reference,
+			&rowStatus,
+			&rowError,
+			&transactionId,
+		); err != nil {
+			return nil, fmt.Errorf("DepositImportRepository - GetDepositImport - rows.Scan: %w", err)
+		}
+		if row.Status, err = model.DepositImportRowStatusFromString(rowStatus); err != nil {
+			return nil, fmt.Errorf("DepositImportRepository - GetDepositImport - model.DepositImportRowStatusFromString: %w", err)
+		}
+		row.Error = rowError.String
+		row.TransactionId = transactionId.String
+		depositImport.Rows = append(depositImport.Rows, row)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("DepositImportRepository - GetDepositImport - rows.Err: %w", err)
+	}
+	return depositImport, nil
+}
+
+// ApplyDepositImportRows deposits the rows of the import within a single database
+// transaction and sets the outcome of every row. Every row claims its reference in
+// deposit_references first, rows whose reference is already claimed, possibly by a
+// concurrent import, are skipped as duplicates. A row that fails is rolled back to
+// a savepoint, so that its reference stays free for a corrected upload. The
+// transaction is committed together with the outcomes of the rows, and true is
+// returned, unless it is a dry run or a row of an atomic import failed. The deposits
+// record the API key of ctx, and each deposit records a copy of the audit entry of
+// ctx targeting the wallet of the row.
+func (depositImportRepository *depositImportRepository) ApplyDepositImportRows(
+	ctx context.Context, importId string, rows []*model.DepositImportRow, dryRun bool, atomic bool,
+) (bool, error) {
+	tx, err := depositImportRepository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("DepositImportRepository - ApplyDepositImportRows - depositImportRepository.db.BeginTx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC()
+	failed := false
+	for _, row := range rows {
+		if _, err = tx.ExecContext(ctx, "SAVEPOINT deposit_import_row"); err != nil {
+			return false, fmt.Errorf("DepositImportRepository - ApplyDepositImportRows - tx.ExecContext: %w", err)
+		}
+		if err = applyDepositImportRow(ctx, tx, row, now); err != nil {
+			return false, fmt.Errorf("DepositImportRepository - ApplyDepositImportRows - applyDepositImportRow: %w", err)
+		}
+		statement := "RELEASE SAVEPOINT deposit_import_row"
+		if row.Status == model.DepositImportRowFailed {
+			statement = "ROLLBACK TO SAVEPOINT deposit_import_row"
+			failed = true
+		}
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			return false, fmt.Errorf("DepositImportRepository - ApplyDepositImportRows - tx.ExecContext: %w", err)
+		}
+	}
+
+	if dryRun || (atomic && failed) {
+		return false, nil
+	}
+	if err = updateDepositImportRows(ctx, tx, importId, rows); err != nil {
+		return false, fmt.Errorf("DepositImportRepository - ApplyDepositImportRows - updateDepositImportRows: %w", err)
+	}
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("DepositImportRepository - ApplyDepositImportRows - tx.Commit: %w", err)
+	}
+	return true, nil
+}
+
+// applyDepositImportRow claims the reference of the row and deposits it, the row
+// is marked applied, duplicate or failed if its wallet cannot take deposits.
+func applyDepositImportRow(ctx context.Context, tx *sql.Tx, row *model.DepositImportRow, now time.Time) error {
+	result, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO deposit_references(reference) VALUES($1) ON CONFLICT (reference) DO NOTHING",
+		row.Reference,
+	)
+	if err != nil {
+		return fmt.Errorf("applyDepositImportRow - tx.ExecContext: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("applyDepositImportRow - result.RowsAffected: %w", err)
+	}
+	if claimed == 0 {
+		row.Status = model.DepositImportRowDuplicate
+		return nil
+	}
+	transaction, err := deposit(rowAuditContext(ctx), sqlTx{tx: tx}, row.WalletId, row.Amount, row.Reference, now)
+	if err != nil {
+		if errors.Is(err, model.ErrWalletNotFound) || errors.Is(err, model.ErrWalletFrozen) {
+			row.Status = model.DepositImportRowFailed
+			row.Error = model.ErrWalletNotFound.Error()
+			if errors.Is(err, model.ErrWalletFrozen) {
+				row.Error = model.ErrWalletFrozen.Error()
+			}
+			return nil
+		}
+		return fmt.Errorf("applyDepositImportRow - deposit: %w", err)
+	}
+	row.Status = model.DepositImportRowApplied
+	row.TransactionId = transaction.ID
+	return nil
+}
+
+// rowAuditContext returns ctx carrying a fresh copy of its audit entry, so that
+// every row of an import is audited, ctx itself if it has no entry.
+func rowAuditContext(ctx context.Context) context.Context {
+	entry := audit.EntryFromContext(ctx)
+	if entry == nil {
+		return ctx
+	}
+	rowEntry := *entry
+	rowEntry.ID = ""
+	return audit.WithEntry(ctx, &rowEntry)
+}
+
+// RenewDepositImportLease extends the lease of an import being processed.
+func (depositImportRepository *depositImportRepository) RenewDepositImportLease(ctx context.Context, id string, lease time.Duration) error {
+	if _, err := depositImportRepository.db.ExecContext(
+		ctx,
+		"UPDATE deposit_imports SET lease_until = $1 WHERE id = $2",
+		time.Now().UTC().Add(lease),
+		id,
+	); err != nil {
+		return fmt.Errorf("DepositImportRepository - RenewDepositImportLease - depositImportRepository.db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// ClaimStaleDepositImports leases up to limit unfinished imports whose lease
+// expired, e.g. because the process applying them stopped, and returns their ids.
+// Imports locked by another instance are skipped.
+func (depositImportRepository *depositImportRepository) ClaimStaleDepositImports(ctx context.Context, limit int, lease time.Duration) ([]string, error) {
+	now := time.Now().UTC()
+	rows, err := depositImportRepository.db.QueryContext(
+		ctx,
+		`UPDATE deposit_imports SET lease_until = $1
+		WHERE id IN (
+			SELECT id FROM deposit_imports
+			WHERE finished_at IS NULL AND (lease_until IS NULL OR lease_until < $2)
+			ORDER BY created_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		now.Add(lease),
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("DepositImportRepository - ClaimStaleDepositImports - depositImportRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("DepositImportRepository - ClaimStaleDepositImports - rows.Scan: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("DepositImportRepository - ClaimStaleDepositImports - rows.Err: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyDepositImportRowsConcurrentReference(t *testing.T) {
+	// given
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	ctx := context.Background()
+	walletId, err := NewWalletRepository(db).CreateWallet(ctx, model.Wallet{Name: "wallet", Currency: "USD"})
+	require.NoError(t, err)
+	depositImportRepository := NewDepositImportRepository(db)
+	const imports = 8
+
+	// when
+	rows := make([]*model.DepositImportRow, imports)
+	var wg sync.WaitGroup
+	for i := range rows {
+		rows[i] = &model.DepositImportRow{Line: 1, WalletId: walletId, Amount: 100, Reference: "ref-1", Status: model.DepositImportRowPending}
+		importId := createTestDepositImport(t, depositImportRepository, rows[i])
+		wg.Add(1)
+		go func(importId string, row *model.DepositImportRow) {
+			defer wg.Done()
+			_, err := depositImportRepository.ApplyDepositImportRows(ctx, importId, []*model.DepositImportRow{row}, false, false)
+			assert.NoError(t, err)
+		}(importId, rows[i])
+	}
+	wg.Wait()
+
+	// then
+	applied := 0
+	for _, row := range rows {
+		if row.Status == model.DepositImportRowApplied {
+			applied++
+			continue
+		}
+		assert.Equal(t, model.DepositImportRowDuplicate, row.Status)
+	}
+	assert.Equal(t, 1, applied)
+	wallet, err := NewWalletRepository(db).GetWallet(ctx, walletId)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(100), wallet.Balance)
+}
+
+func TestClaimStaleDepositImports(t *testing.T) {
+	// given
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	depositImportRepository := NewDepositImportRepository(db)
+	ctx := context.Background()
+	newImport := func() *model.DepositImport {
+		return &model.DepositImport{Status: model.DepositImportProcessing, CreatedAt: time.Now().UTC()}
+	}
+	staleId, err := depositImportRepository.CreateDepositImport(ctx, newImport(), -time.Second)
+	require.NoError(t, err)
+	_, err = depositImportRepository.CreateDepositImport(ctx, newImport(), time.Minute)
+	require.NoError(t, err)
+	finished := newImport()
+	finished.ID, err = depositImportRepository.CreateDepositImport(ctx, finished, -time.Second)
+	require.NoError(t, err)
+	finishedAt := time.Now().UTC()
+	finished.Status = model.DepositImportCompleted
+	finished.FinishedAt = &finishedAt
+	require.NoError(t, depositImportRepository.UpdateDepositImport(ctx, finished, nil))
+
+	// when
+	claimed, err := depositImportRepository.ClaimStaleDepositImports(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	claimedAgain, err := depositImportRepository.ClaimStaleDepositImports(ctx, 10, time.Minute)
+	require.NoError(t, err)
+
+	// then
+	assert.Equal(t, []string{staleId}, claimed)
+	assert.Empty(t, claimedAgain)
+}
+
+func TestApplyDepositImportRowsAudited(t *testing.T) {
+	// given
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	ctx := context.Background()
+	walletRepository := NewWalletRepository(db)
+	firstId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "first", Currency: "USD"})
+	require.NoError(t, err)
+	secondId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "second", Currency: "USD"})
+	require.NoError(t, err)
+	rows := []*model.DepositImportRow{
+		{Line: 1, WalletId: firstId, Amount: 100, Reference: "ref-1", Status: model.DepositImportRowPending},
+		{Line: 2, WalletId: secondId, Amount: 200, Reference: "ref-2", Status: model.DepositImportRowPending},
+	}
+	depositImportRepository := NewDepositImportRepository(db)
+	importId := createTestDepositImport(t, depositImportRepository, rows...)
+	ctx = audit.WithEntry(ctx, &model.AuditEntry{Actor: "apikey:7001", Action: "deposits.import.deposit", RequestId: "request-1"})
+
+	// when
+	committed, err := depositImportRepository.ApplyDepositImportRows(ctx, importId, rows, false, false)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, committed)
+	entries, err := NewAuditRepository(db).GetAuditEntries(context.Background(), -1, -1, model.AuditFilter{Actor: "apikey:7001"})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	walletIds := []string{entries[0].WalletId, entries[1].WalletId}
+	assert.ElementsMatch(t, []string{firstId, secondId}, walletIds)
+	for _, entry := range entries {
+		assert.Equal(t, "deposits.import.deposit", entry.Action)
+		assert.Equal(t, model.AuditSuccess, entry.Outcome)
+		assert.Equal(t, "request-1", entry.RequestId)
+	}
+}
+
+func TestApplyDepositImportRowsFailedRowReleasesReference(t *testing.T) {
+	// given
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	ctx := context.Background()
+	walletRepository := NewWalletRepository(db)
+	walletId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "wallet", Currency: "USD"})
+	require.NoError(t, err)
+	missingWalletId := "6f1c2b7a-0d4e-4b8a-9c3f-5e2d1a0b9c87"
+	depositImportRepository := NewDepositImportRepository(db)
+	failedRows := []*model.DepositImportRow{
+		{Line: 1, WalletId: walletId, Amount: 100, Reference: "ref-1", Status: model.DepositImportRowPending},
+		{Line: 2, WalletId: missingWalletId, Amount: 200, Reference: "ref-2", Status: model.DepositImportRowPending},
+	}
+	failedImportId := createTestDepositImport(t, depositImportRepository, failedRows...)
+	committed, err := depositImportRepository.ApplyDepositImportRows(ctx, failedImportId, failedRows, false, false)
+	require.NoError(t, err)
+	require.True(t, committed)
+	require.Equal(t, model.DepositImportRowFailed, failedRows[1].Status)
+	correctedRows := []*model.DepositImportRow{
+		{Line: 1, WalletId: walletId, Amount: 200, Reference: "ref-2", Status: model.DepositImportRowPending},
+	}
+	correctedImportId := createTestDepositImport(t, depositImportRepository, correctedRows...)
+
+	// when
+	committed, err = depositImportRepository.ApplyDepositImportRows(ctx, correctedImportId, correctedRows, false, false)
+
+	// then
+	require.NoError(t, err)
+	assert.True(t, committed)
+	assert.Equal(t, model.DepositImportRowApplied, correctedRows[0].Status)
+	wallet, err := walletRepository.GetWallet(ctx, walletId)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(300), wallet.Balance)
+}
+
+func TestApplyDepositImportRowsStoresOutcomes(t *testing.T) {
+	// given
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	ctx := context.Background()
+	walletId, err := NewWalletRepository(db).CreateWallet(ctx, model.Wallet{Name: "wallet", Currency: "USD"})
+	require.NoError(t, err)
+	depositImportRepository := NewDepositImportRepository(db)
+	rows := []*model.DepositImportRow{
+		{Line: 1, WalletId: walletId, Amount: 100, Reference: "ref-1", Status: model.DepositImportRowPending},
+		{Line: 2, WalletId: "6f1c2b7a-0d4e-4b8a-9c3f-5e2d1a0b9c87", Amount: 200, Reference: "ref-2", Status: model.DepositImportRowPending},
+	}
+	importId := createTestDepositImport(t, depositImportRepository, rows...)
+
+	// when
+	committed, err := depositImportRepository.ApplyDepositImportRows(ctx, importId, rows, false, false)
+
+	// then
+	require.NoError(t, err)
+	require.True(t, committed)
+	stored, err := depositImportRepository.GetDepositImport(ctx, importId)
+	require.NoError(t, err)
+	require.Len(t, stored.Rows, 2)
+	assert.Equal(t, model.DepositImportRowApplied, stored.Rows[0].Status)
+	assert.Equal(t, rows[0].TransactionId, stored.Rows[0].TransactionId)
+	assert.NotEmpty(t, stored.Rows[0].TransactionId)
+	assert.Equal(t, model.DepositImportRowFailed, stored.Rows[1].Status)
+	assert.Equal(t, model.ErrWalletNotFound.Error(), stored.Rows[1].Error)
+}
+
+// createTestDepositImport stores a processing import of the rows and returns its id.
+func createTestDepositImport(t *testing.T, depositImportRepository DepositImportRepository, rows ...*model.DepositImportRow) string {
+	depositImport := &model.DepositImport{Status: model.DepositImportProcessing, CreatedAt: time.Now().UTC(), Rows: rows}
+	id, err := depositImportRepository.CreateDepositImport(context.Background(), depositImport, time.Minute)
+	require.NoError(t, err)
+	return id
+}
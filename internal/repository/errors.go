@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgconn"
+)
+
+// Postgres error codes mapped to domain errors.
+const (
+	checkViolation       = "23514"
+	foreignKeyViolation  = "23503"
+	notNullViolation     = "23502"
+	uniqueViolation      = "23505"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// isTransactionConflict tells whether the transaction was aborted because of a
+// concurrent one, such as an opposite transfer between the same wallets, and
+// may succeed if run again.
+func isTransactionConflict(err error) bool {
+	return isPgError(err, serializationFailure) || isPgError(err, deadlockDetected)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// localTransactionListener is the TransactionListener of the single process
+// storages, they notify it after committing a transaction.
+type localTransactionListener struct {
+	listenersMu sync.Mutex
+	listeners   map[*func(transaction *model.Transaction)]struct{}
+}
+
+func newLocalTransactionListener() localTransactionListener {
+	return localTransactionListener{
+		listeners: make(map[*func(transaction *model.Transaction)]struct{}),
+	}
+}
+
+// Listen passes every committed transaction to handle until ctx is done.
+func (transactionListener *localTransactionListener) Listen(ctx context.Context, handle func(transaction *model.Transaction)) error {
+	transactionListener.listenersMu.Lock()
+	transactionListener.listeners[&handle] = struct{}{}
+	transactionListener.listenersMu.Unlock()
+
+	<-ctx.Done()
+
+	transactionListener.listenersMu.Lock()
+	delete(transactionListener.listeners, &handle)
+	transactionListener.listenersMu.Unlock()
+	return ctx.Err()
+}
+
+func (transactionListener *localTransactionListener) notify(transaction *model.Transaction) {
+	transactionListener.listenersMu.Lock()
+	listeners := make([]func(transaction *model.Transaction), 0, len(transactionListener.listeners))
+	for listener := range transactionListener.listeners {
+		listeners = append(listeners, *listener)
+	}
+	transactionListener.listenersMu.Unlock()
+
+	for _, listener := range listeners {
+		listener(transaction)
+	}
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// memoryApiKeyRepository keeps API keys in process memory next to the
+// in-memory wallets, the keys are lost on restart.
+type memoryApiKeyRepository struct {
+	mu      sync.RWMutex
+	apiKeys []*model.ApiKey
+}
+
+func NewMemoryApiKeyRepository() *memoryApiKeyRepository {
+	return &memoryApiKeyRepository{}
+}
+
+func (apiKeyRepository *memoryApiKeyRepository) CreateApiKey(ctx context.Context, apiKey model.ApiKey) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("MemoryApiKeyRepository - CreateApiKey - newId: %w", err)
+	}
+	apiKey.ID = id
+
+	apiKeyRepository.mu.Lock()
+	defer apiKeyRepository.mu.Unlock()
+
+	apiKeyRepository.apiKeys = append(apiKeyRepository.apiKeys, copyApiKey(&apiKey))
+	return id, nil
+}
+
+func (apiKeyRepository *memoryApiKeyRepository) GetApiKeyByHash(ctx context.Context, hash string) (*model.ApiKey, error) {
+	apiKeyRepository.mu.RLock()
+	defer apiKeyRepository.mu.RUnlock()
+
+	for _, apiKey := range apiKeyRepository.apiKeys {
+		if apiKey.Hash == hash && apiKey.RevokedAt == nil {
+			return copyApiKey(apiKey), nil
+		}
+	}
+	return nil, fmt.Errorf("MemoryApiKeyRepository - GetApiKeyByHash: %w", model.ErrApiKeyNotFound)
+}
+
+func (apiKeyRepository *memoryApiKeyRepository) GetApiKeys(ctx context.Context) ([]*model.ApiKey, error) {
+	apiKeyRepository.mu.RLock()
+	defer apiKeyRepository.mu.RUnlock()
+
+	apiKeys := make([]*model.ApiKey, 0, len(apiKeyRepository.apiKeys))
+	for _, apiKey := range apiKeyRepository.apiKeys {
+		apiKeys = append(apiKeys, copyApiKey(apiKey))
+	}
+	return apiKeys, nil
+}
+
+func (apiKeyRepository *memoryApiKeyRepository) RotateApiKey(
+	ctx context.Context, id string, prefix string, hash string, rotatedAt time.Time,
+) (*model.ApiKey, error) {
+	apiKeyRepository.mu.Lock()
+	defer apiKeyRepository.mu.Unlock()
+
+	apiKey, err := apiKeyRepository.activeApiKey(id)
+	if err != nil {
+		return nil, fmt.Errorf("MemoryApiKeyRepository - RotateApiKey - apiKeyRepository.activeApiKey: %w", err)
+	}
+	apiKey.Prefix = prefix
+	apiKey.Hash = hash
+	apiKey.RotatedAt = &rotatedAt
+	return copyApiKey(apiKey), nil
+}
+
+func (apiKeyRepository *memoryApiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	apiKeyRepository.mu.Lock()
+	defer apiKeyRepository.mu.Unlock()
+
+	apiKey, err := apiKeyRepository.activeApiKey(id)
+	if err != nil {
+		return fmt.Errorf("MemoryApiKeyRepository - RevokeApiKey - apiKeyRepository.activeApiKey: %w", err)
+	}
+	apiKey.RevokedAt = &revokedAt
+	return nil
+}
+
+// activeApiKey returns the key unless it is revoked, the caller holds the lock.
+func (apiKeyRepository *memoryApiKeyRepository) activeApiKey(id string) (*model.ApiKey, error) {
+	for _, apiKey := range apiKeyRepository.apiKeys {
+		if apiKey.ID == id && apiKey.RevokedAt == nil {
+			return apiKey, nil
+		}
+	}
+	return nil, model.ErrApiKeyNotFound
+}
+
+func copyApiKey(apiKey *model.ApiKey) *model.ApiKey {
+	apiKeyCopy := *apiKey
+	apiKeyCopy.Scopes = append([]model.Scope(nil), apiKey.Scopes...)
+	return &apiKeyCopy
+}
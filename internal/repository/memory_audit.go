@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// memoryAuditRepository keeps the audit log in process memory next to the
+// in-memory wallets, the log is lost on restart.
+type memoryAuditRepository struct {
+	mu      sync.RWMutex
+	entries []*model.AuditEntry
+}
+
+func NewMemoryAuditRepository() *memoryAuditRepository {
+	return &memoryAuditRepository{}
+}
+
+func (auditRepository *memoryAuditRepository) CreateAuditEntry(ctx context.Context, entry model.AuditEntry) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("MemoryAuditRepository - CreateAuditEntry - newId: %w", err)
+	}
+	entry.ID = id
+	auditRepository.append(entry)
+	return id, nil
+}
+
+func (auditRepository *memoryAuditRepository) append(entry model.AuditEntry) {
+	auditRepository.mu.Lock()
+	defer auditRepository.mu.Unlock()
+
+	auditRepository.entries = append(auditRepository.entries, &entry)
+}
+
+func (auditRepository *memoryAuditRepository) GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	auditRepository.mu.RLock()
+	defer auditRepository.mu.RUnlock()
+
+	var entries []*model.AuditEntry
+	for i := len(auditRepository.entries) - 1; i >= 0; i-- {
+		entry := auditRepository.entries[i]
+		if matchAuditFilter(entry, filter) {
+			entryCopy := *entry
+			entries = append(entries, &entryCopy)
+		}
+	}
+	if offset > 0 {
+		if offset >= len(entries) {
+			return nil, nil
+		}
+		entries = entries[offset:]
+	}
+	if limit > -1 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
+
+func matchAuditFilter(entry *model.AuditEntry, filter model.AuditFilter) bool {
+	return (filter.Actor == "" || entry.Actor == filter.Actor) &&
+		(filter.Action == "" || entry.Action == filter.Action) &&
+		(filter.WalletId == "" || entry.WalletId == filter.WalletId) &&
+		(filter.Outcome == model.UnknownAuditOutcome || entry.Outcome == filter.Outcome) &&
+		(filter.RequestId == "" || entry.RequestId == filter.RequestId) &&
+		(filter.CreatedAtGte.IsZero() || !entry.CreatedAt.Before(filter.CreatedAtGte)) &&
+		(filter.CreatedAtLte.IsZero() || !entry.CreatedAt.After(filter.CreatedAtLte))
+}
+
+// recordPendingAuditEntry appends the pending audit entry of an operation
+// applied to the in-memory wallets, the caller holds the lock of the wallets
+// so the entry is recorded with the operation.
+func (auditRepository *memoryAuditRepository) recordPendingAuditEntry(pending pendingAuditEntry, walletId string, now time.Time) {
+	if pending.entry == nil {
+		return
+	}
+	pending.recorded(walletId, now)
+	auditRepository.append(*pending.entry)
+}
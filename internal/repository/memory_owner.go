@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// memoryOwnerRepository keeps owners in process memory next to the in-memory
+// wallets, the owners are lost on restart.
+type memoryOwnerRepository struct {
+	mu     sync.RWMutex
+	owners []*model.Owner
+}
+
+func NewMemoryOwnerRepository() *memoryOwnerRepository {
+	return &memoryOwnerRepository{}
+}
+
+func (ownerRepository *memoryOwnerRepository) CreateOwner(ctx context.Context, owner model.Owner) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("MemoryOwnerRepository - CreateOwner - newId: %w", err)
+	}
+	owner.ID = id
+
+	ownerRepository.mu.Lock()
+	defer ownerRepository.mu.Unlock()
+
+	if ownerRepository.taken(owner) {
+		return "", fmt.Errorf("MemoryOwnerRepository - CreateOwner: %w", model.ErrOwnerAlreadyExists)
+	}
+	ownerCopy := owner
+	ownerRepository.owners = append(ownerRepository.owners, &ownerCopy)
+	return id, nil
+}
+
+func (ownerRepository *memoryOwnerRepository) GetOwner(ctx context.Context, id string) (*model.Owner, error) {
+	ownerRepository.mu.RLock()
+	defer ownerRepository.mu.RUnlock()
+
+	for _, owner := range ownerRepository.owners {
+		if owner.ID == id {
+			ownerCopy := *owner
+			return &ownerCopy, nil
+		}
+	}
+	return nil, fmt.Errorf("MemoryOwnerRepository - GetOwner: %w", model.ErrOwnerNotFound)
+}
+
+func (ownerRepository *memoryOwnerRepository) GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, error) {
+	ownerRepository.mu.RLock()
+	defer ownerRepository.mu.RUnlock()
+
+	var owners []*model.Owner
+	for _, owner := range ownerRepository.owners {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if limit > -1 && len(owners) >= limit {
+			break
+		}
+		ownerCopy := *owner
+		owners = append(owners, &ownerCopy)
+	}
+	return owners, nil
+}
+
+func (ownerRepository *memoryOwnerRepository) UpdateOwner(ctx context.Context, owner model.Owner) error {
+	ownerRepository.mu.Lock()
+	defer ownerRepository.mu.Unlock()
+
+	// The subject is kept, only the external ID can conflict.
+	owner.Subject = ""
+	if ownerRepository.taken(owner) {
+		return fmt.Errorf("MemoryOwnerRepository - UpdateOwner: %w", model.ErrOwnerAlreadyExists)
+	}
+	for _, existingOwner := range ownerRepository.owners {
+		if existingOwner.ID == owner.ID {
+			owner.CreatedAt = existingOwner.CreatedAt
+			owner.Subject = existingOwner.Subject
+			*existingOwner = owner
+			return nil
+		}
+	}
+	return fmt.Errorf("MemoryOwnerRepository - UpdateOwner: %w", model.ErrOwnerNotFound)
+}
+
+func (ownerRepository *memoryOwnerRepository) DeleteOwner(ctx context.Context, id string) error {
+	ownerRepository.mu.Lock()
+	defer ownerRepository.mu.Unlock()
+
+	for i, owner := range ownerRepository.owners {
+		if owner.ID == id {
+			ownerRepository.owners = append(ownerRepository.owners[:i], ownerRepository.owners[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("MemoryOwnerRepository - DeleteOwner: %w", model.ErrOwnerNotFound)
+}
+
+// taken tells whether another owner has the external ID or the subject of the
+// owner, the caller holds the lock.
+func (ownerRepository *memoryOwnerRepository) taken(owner model.Owner) bool {
+	for _, existingOwner := range ownerRepository.owners {
+		if existingOwner.ID == owner.ID {
+			continue
+		}
+		if (owner.ExternalID != "" && existingOwner.ExternalID == owner.ExternalID) ||
+			(owner.Subject != "" && existingOwner.Subject == owner.Subject) {
+			return true
+		}
+	}
+	return false
+}
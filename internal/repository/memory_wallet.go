@@ -0,0 +1,389 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+var errBalanceOverflow = errors.New("balance overflow")
+
+// memoryWalletRepository keeps wallets and transactions in process memory.
+// It is meant for development and tests, the data is lost on restart.
+type memoryWalletRepository struct {
+	mu           sync.RWMutex
+	wallets      map[string]*model.Wallet
+	transactions []*model.Transaction
+	grants       []*model.WalletGrant
+	auditLog     *memoryAuditRepository
+	localTransactionListener
+}
+
+func NewMemoryWalletRepository() *memoryWalletRepository {
+	return &memoryWalletRepository{
+		wallets:                  make(map[string]*model.Wallet),
+		localTransactionListener: newLocalTransactionListener(),
+	}
+}
+
+// WithAuditLog records the audit entries of the operations in the audit log
+// along with the operations, without it the entries are left to the caller.
+func (walletRepository *memoryWalletRepository) WithAuditLog(auditLog *memoryAuditRepository) *memoryWalletRepository {
+	walletRepository.auditLog = auditLog
+	return walletRepository
+}
+
+// recordPendingAuditEntry records the audit entry of an operation, the caller
+// holds the lock.
+func (walletRepository *memoryWalletRepository) recordPendingAuditEntry(pending pendingAuditEntry, walletId string, now time.Time) {
+	if walletRepository.auditLog != nil {
+		walletRepository.auditLog.recordPendingAuditEntry(pending, walletId, now)
+	}
+}
+
+func (walletRepository *memoryWalletRepository) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("MemoryWalletRepository - CreateWallet - newId: %w", err)
+	}
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return "", fmt.Errorf("MemoryWalletRepository - CreateWallet - newPendingAuditEntry: %w", err)
+	}
+
+	walletRepository.mu.Lock()
+	defer walletRepository.mu.Unlock()
+
+	for _, existingWallet := range walletRepository.wallets {
+		if existingWallet.Name == wallet.Name {
+			return "", fmt.Errorf("MemoryWalletRepository - CreateWallet: %w", model.ErrWalletAlreadyExists)
+		}
+		if wallet.OwnerId != "" && existingWallet.OwnerId == wallet.OwnerId && existingWallet.Currency == wallet.Currency {
+			return "", fmt.Errorf("MemoryWalletRepository - CreateWallet: %w", model.ErrWalletAlreadyExists)
+		}
+	}
+	walletRepository.wallets[id] = &model.Wallet{
+		ID:       id,
+		Name:     wallet.Name,
+		Currency: wallet.Currency,
+		Owner:    wallet.Owner,
+		OwnerId:  wallet.OwnerId,
+	}
+	walletRepository.recordPendingAuditEntry(pendingAudit, id, time.Now().UTC())
+	return id, nil
+}
+
+func (walletRepository *memoryWalletRepository) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	walletRepository.mu.RLock()
+	defer walletRepository.mu.RUnlock()
+
+	wallet, ok := walletRepository.wallets[id]
+	if !ok {
+		return nil, fmt.Errorf("MemoryWalletRepository - GetWallet: %w", model.ErrWalletNotFound)
+	}
+	walletCopy := *wallet
+	return &walletCopy, nil
+}
+
+func (walletRepository *memoryWalletRepository) GetOwnerWallets(ctx context.Context, ownerId string) ([]*model.Wallet, error) {
+	walletRepository.mu.RLock()
+	defer walletRepository.mu.RUnlock()
+
+	var wallets []*model.Wallet
+	for _, wallet := range walletRepository.wallets {
+		if wallet.OwnerId == ownerId {
+			walletCopy := *wallet
+			wallets = append(wallets, &walletCopy)
+		}
+	}
+	sort.Slice(wallets, func(i, j int) bool {
+		if wallets[i].Currency != wallets[j].Currency {
+			return wallets[i].Currency < wallets[j].Currency
+		}
+		return wallets[i].Name < wallets[j].Name
+	})
+	return wallets, nil
+}
+
+func (walletRepository *memoryWalletRepository) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	id, err := newId()
+	if err != nil {
+		return nil, fmt.Errorf("MemoryWalletRepository - Deposit - newId: %w", err)
+	}
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("MemoryWalletRepository - Deposit - newPendingAuditEntry: %w", err)
+	}
+
+	walletRepository.mu.Lock()
+	recipientWallet, err := walletRepository.movableWallet(recipientWalletId)
+	if err != nil {
+		walletRepository.mu.Unlock()
+		return nil, fmt.Errorf("MemoryWalletRepository - Deposit - walletRepository.movableWallet: %w", err)
+	}
+	if recipientWallet.Balance+amount > math.MaxInt64 || recipientWallet.Balance+amount < amount {
+		walletRepository.mu.Unlock()
+		return nil, fmt.Errorf("MemoryWalletRepository - Deposit: %w", errBalanceOverflow)
+	}
+	recipientWallet.Balance += amount
+
+	transaction := &model.Transaction{
+		ID:          id,
+		Amount:      amount,
+		ProcessedAt: time.Now().UTC(),
+		RecipientWallet: model.Wallet{
+			ID:      recipientWalletId,
+			Balance: recipientWallet.Balance,
+		},
+		OperationType: model.Deposit,
+		ApiKeyId:      auth.ApiKeyId(ctx),
+	}
+	walletRepository.transactions = append(walletRepository.transactions, transaction)
+	walletRepository.recordPendingAuditEntry(pendingAudit, recipientWalletId, transaction.ProcessedAt)
+	walletRepository.mu.Unlock()
+
+	walletRepository.notify(copyTransaction(transaction))
+	return copyTransaction(transaction), nil
+}
+
+func (walletRepository *memoryWalletRepository) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	id, err := newId()
+	if err != nil {
+		return nil, fmt.Errorf("MemoryWalletRepository - Transfer - newId: %w", err)
+	}
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("MemoryWalletRepository - Transfer - newPendingAuditEntry: %w", err)
+	}
+
+	walletRepository.mu.Lock()
+	senderWallet, err := walletRepository.movableWallet(senderWalletId)
+	if err != nil {
+		walletRepository.mu.Unlock()
+		return nil, fmt.Errorf("MemoryWalletRepository - Transfer - walletRepository.movableWallet: %w", err)
+	}
+	if senderWallet.Balance < amount {
+		walletRepository.mu.Unlock()
+		return nil, fmt.Errorf("MemoryWalletRepository - Transfer: %w", model.ErrInsufficientFunds)
+	}
+	recipientWallet, err := walletRepository.movableWallet(recipientWalletId)
+	if err != nil {
+		walletRepository.mu.Unlock()
+		return nil, fmt.Errorf("MemoryWalletRepository - Transfer - walletRepository.movableWallet: %w", err)
+	}
+	senderWallet.Balance -= amount
+	recipientWallet.Balance += amount
+
+	transaction := &model.Transaction{
+		ID:          id,
+		Amount:      amount,
+		ProcessedAt: time.Now().UTC(),
+		SenderWallet: &model.Wallet{
+			ID:      senderWalletId,
+			Balance: senderWallet.Balance,
+		},
+		RecipientWallet: model.Wallet{
+			ID:      recipientWalletId,
+			Balance: recipientWallet.Balance,
+		},
+		OperationType: model.Transfer,
+		ApiKeyId:      auth.ApiKeyId(ctx),
+	}
+	walletRepository.transactions = append(walletRepository.transactions, transaction)
+	walletRepository.recordPendingAuditEntry(pendingAudit, senderWalletId, transaction.ProcessedAt)
+	walletRepository.mu.Unlock()
+
+	walletRepository.notify(copyTransaction(transaction))
+	return copyTransaction(transaction), nil
+}
+
+// movableWallet returns the wallet if money can be moved, the caller holds the lock.
+func (walletRepository *memoryWalletRepository) movableWallet(id string) (*model.Wallet, error) {
+	wallet, ok := walletRepository.wallets[id]
+	if !ok {
+		return nil, model.ErrWalletNotFound
+	}
+	if wallet.Frozen {
+		return nil, model.ErrWalletFrozen
+	}
+	return wallet, nil
+}
+
+func (walletRepository *memoryWalletRepository) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+	walletRepository.mu.RLock()
+	defer walletRepository.mu.RUnlock()
+
+	var transactions []*model.Transaction
+	for i := len(walletRepository.transactions) - 1; i >= 0; i-- {
+		transaction := walletRepository.transactions[i]
+		if !isWalletTransaction(transaction, filter.WalletId) {
+			continue
+		}
+		if filter.OperationType != model.UnknownOperation && transaction.OperationType != filter.OperationType {
+			continue
+		}
+		if !filter.ProcessedAtGte.IsZero() && transaction.ProcessedAt.Before(filter.ProcessedAtGte) {
+			continue
+		}
+		if !filter.ProcessedAtLte.IsZero() && transaction.ProcessedAt.After(filter.ProcessedAtLte) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		if limit > -1 && len(transactions) >= limit {
+			break
+		}
+		transactions = append(transactions, copyTransaction(transaction))
+	}
+	return transactions, nil
+}
+
+// GetTransactionsAfter returns the transactions of the wallet processed after the
+// given one, oldest first. Nothing is returned if the given transaction is unknown.
+func (walletRepository *memoryWalletRepository) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	walletRepository.mu.RLock()
+	defer walletRepository.mu.RUnlock()
+
+	var transactions []*model.Transaction
+	found := false
+	for _, transaction := range walletRepository.transactions {
+		if !found {
+			found = transaction.ID == transactionId
+			continue
+		}
+		if isWalletTransaction(transaction, walletId) {
+			transactions = append(transactions, copyTransaction(transaction))
+		}
+	}
+	return transactions, nil
+}
+
+func (walletRepository *memoryWalletRepository) SetWalletFrozen(ctx context.Context, id string, frozen bool) error {
+	walletRepository.mu.Lock()
+	defer walletRepository.mu.Unlock()
+
+	wallet, ok := walletRepository.wallets[id]
+	if !ok {
+		return fmt.Errorf("MemoryWalletRepository - SetWalletFrozen: %w", model.ErrWalletNotFound)
+	}
+	wallet.Frozen = frozen
+	return nil
+}
+
+// SetWalletShards is not supported, deposits do not contend in memory.
+func (walletRepository *memoryWalletRepository) SetWalletShards(ctx context.Context, id string, shards int) error {
+	return fmt.Errorf("MemoryWalletRepository - SetWalletShards: %w", model.ErrShardsNotSupported)
+}
+
+// Reconcile compares the balance of every wallet with the sum of its transactions.
+func (walletRepository *memoryWalletRepository) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	walletRepository.mu.RLock()
+	defer walletRepository.mu.RUnlock()
+
+	transactionsBalances := make(map[string]int64, len(walletRepository.wallets))
+	for _, transaction := range walletRepository.transactions {
+		transactionsBalances[transaction.RecipientWallet.ID] += int64(transaction.Amount)
+		if transaction.SenderWallet != nil {
+			transactionsBalances[transaction.SenderWallet.ID] -= int64(transaction.Amount)
+		}
+	}
+	reconciliations := make([]*model.WalletReconciliation, 0, len(walletRepository.wallets))
+	for _, wallet := range walletRepository.wallets {
+		reconciliations = append(reconciliations, &model.WalletReconciliation{
+			WalletId:            wallet.ID,
+			WalletName:          wallet.Name,
+			Balance:             int64(wallet.Balance),
+			TransactionsBalance: transactionsBalances[wallet.ID],
+		})
+	}
+	sort.Slice(reconciliations, func(i, j int) bool {
+		return reconciliations[i].WalletName < reconciliations[j].WalletName
+	})
+	return reconciliations, nil
+}
+
+func (walletRepository *memoryWalletRepository) GetWalletRole(ctx context.Context, walletId string, subject string) (model.WalletRole, error) {
+	walletRepository.mu.RLock()
+	defer walletRepository.mu.RUnlock()
+
+	wallet, ok := walletRepository.wallets[walletId]
+	if !ok {
+		return model.UnknownWalletRole, fmt.Errorf("MemoryWalletRepository - GetWalletRole: %w", model.ErrWalletNotFound)
+	}
+	if wallet.Owner != "" && wallet.Owner == subject {
+		return model.OwnerRole, nil
+	}
+	for _, grant := range walletRepository.grants {
+		if grant.WalletId == walletId && grant.Subject == subject {
+			return grant.Role, nil
+		}
+	}
+	return model.UnknownWalletRole, nil
+}
+
+func (walletRepository *memoryWalletRepository) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	walletRepository.mu.RLock()
+	defer walletRepository.mu.RUnlock()
+
+	var grants []*model.WalletGrant
+	for _, grant := range walletRepository.grants {
+		if grant.WalletId == walletId {
+			grantCopy := *grant
+			grants = append(grants, &grantCopy)
+		}
+	}
+	return grants, nil
+}
+
+func (walletRepository *memoryWalletRepository) GrantWalletAccess(ctx context.Context, grant model.WalletGrant) error {
+	walletRepository.mu.Lock()
+	defer walletRepository.mu.Unlock()
+
+	if _, ok := walletRepository.wallets[grant.WalletId]; !ok {
+		return fmt.Errorf("MemoryWalletRepository - GrantWalletAccess: %w", model.ErrWalletNotFound)
+	}
+	for _, existingGrant := range walletRepository.grants {
+		if existingGrant.WalletId == grant.WalletId && existingGrant.Subject == grant.Subject {
+			existingGrant.Role = grant.Role
+			return nil
+		}
+	}
+	walletRepository.grants = append(walletRepository.grants, &grant)
+	return nil
+}
+
+func (walletRepository *memoryWalletRepository) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	walletRepository.mu.Lock()
+	defer walletRepository.mu.Unlock()
+
+	for i, grant := range walletRepository.grants {
+		if grant.WalletId == walletId && grant.Subject == subject {
+			walletRepository.grants = append(walletRepository.grants[:i], walletRepository.grants[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("MemoryWalletRepository - RevokeWalletAccess: %w", model.ErrWalletGrantNotFound)
+}
+
+func isWalletTransaction(transaction *model.Transaction, walletId string) bool {
+	return transaction.RecipientWallet.ID == walletId ||
+		(transaction.SenderWallet != nil && transaction.SenderWallet.ID == walletId)
+}
+
+func copyTransaction(transaction *model.Transaction) *model.Transaction {
+	transactionCopy := *transaction
+	if transaction.SenderWallet != nil {
+		senderWallet := *transaction.SenderWallet
+		transactionCopy.SenderWallet = &senderWallet
+	}
+	return &transactionCopy
+}
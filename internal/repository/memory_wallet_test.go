@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+)
+
+func TestMemoryWalletRepository(t *testing.T) {
+	testWalletRepository(t, func(t *testing.T) WalletRepository {
+		return NewMemoryWalletRepository()
+	})
+}
+
+func TestMemoryApiKeyRepository(t *testing.T) {
+	testApiKeyRepository(t, func(t *testing.T) (ApiKeyRepository, WalletRepository) {
+		return NewMemoryApiKeyRepository(), NewMemoryWalletRepository()
+	})
+}
+
+func TestMemoryOwnerRepository(t *testing.T) {
+	testOwnerRepository(t, func(t *testing.T) (OwnerRepository, WalletRepository) {
+		return NewMemoryOwnerRepository(), NewMemoryWalletRepository()
+	})
+}
+
+func TestMemoryAuditRepository(t *testing.T) {
+	testAuditRepository(t, func(t *testing.T) (AuditRepository, WalletRepository) {
+		auditRepository := NewMemoryAuditRepository()
+		return auditRepository, NewMemoryWalletRepository().WithAuditLog(auditRepository)
+	})
+}
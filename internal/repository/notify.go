@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/jackc/pgx/v4/stdlib"
+)
+
+// TransactionsChannel is the Postgres notification channel of committed transactions.
+const TransactionsChannel = "wallet_transactions"
+
+type TransactionListener interface {
+	Listen(ctx context.Context, handle func(transaction *model.Transaction)) error
+}
+
+type transactionListener struct {
+	db *sql.DB
+}
+
+func NewTransactionListener(db *sql.DB) *transactionListener {
+	return &transactionListener{
+		db: db,
+	}
+}
+
+// Listen holds a dedicated connection listening to TransactionsChannel and
+// passes every committed transaction to handle until ctx is done or the
+// connection fails.
+func (transactionListener *transactionListener) Listen(ctx context.Context, handle func(transaction *model.Transaction)) error {
+	conn, err := transactionListener.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("TransactionListener - Listen - transactionListener.db.Conn: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pgxConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgxConn.Exec(ctx, "LISTEN "+TransactionsChannel); err != nil {
+			return fmt.Errorf("TransactionListener - Listen - pgxConn.Exec: %w", err)
+		}
+		for {
+			notification, err := pgxConn.WaitForNotification(ctx)
+			if err != nil {
+				return fmt.Errorf("TransactionListener - Listen - pgxConn.WaitForNotification: %w", err)
+			}
+			var payload transactionEventPayload
+			if err = json.Unmarshal([]byte(notification.Payload), &payload); err != nil {
+				return fmt.Errorf("TransactionListener - Listen - json.Unmarshal: %w", err)
+			}
+			operationType, err := model.FromString(payload.OperationType)
+			if err != nil {
+				return fmt.Errorf("TransactionListener - Listen - model.FromString: %w", err)
+			}
+			transaction := &model.Transaction{
+				ID:          payload.TransactionId,
+				Amount:      payload.Amount,
+				ProcessedAt: payload.ProcessedAt,
+				RecipientWallet: model.Wallet{
+					ID:      payload.RecipientWalletId,
+					Balance: payload.RecipientWalletBalance,
+				},
+				OperationType: operationType,
+				Reference:     payload.Reference,
+			}
+			if payload.SenderWalletId != nil && payload.SenderWalletBalance != nil {
+				transaction.SenderWallet = &model.Wallet{
+					ID:      *payload.SenderWalletId,
+					Balance: *payload.SenderWalletBalance,
+				}
+			}
+			handle(transaction)
+		}
+	})
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+type transactionEventPayload struct {
+	TransactionId          string    `json:"transaction_id"`
+	OperationType          string    `json:"operation_type"`
+	Amount                 uint64    `json:"amount"`
+	SenderWalletId         *string   `json:"sender_wallet_id,omitempty"`
+	SenderWalletBalance    *uint64   `json:"sender_wallet_balance,omitempty"`
+	RecipientWalletId      string    `json:"recipient_wallet_id"`
+	RecipientWalletBalance uint64    `json:"recipient_wallet_balance"`
+	Reference              string    `json:"reference,omitempty"`
+	ProcessedAt            time.Time `json:"processed_at"`
+}
+
+// transactionEventSql completes a statement whose inserted CTE returns the
+// inserted transaction: it records the event of the transaction in the outbox
+// and notifies the listeners of every server instance, both take effect only if
+// the statement is committed. The payload matches transactionEventPayload and
+// the statement returns the id and the balances of the transaction.
+func transactionEventSql(eventType model.EventType) string {
+	return ", event AS (SELECT id, sender_wallet_balance, recipient_wallet_balance, processed_at, " +
+		"json_strip_nulls(json_build_object(" +
+		"'transaction_id', id, 'operation_type', operation_type, 'amount', amount, " +
+		"'sender_wallet_id', sender_wallet_id, 'sender_wallet_balance', sender_wallet_balance, " +
+		"'recipient_wallet_id', recipient_wallet_id, 'recipient_wallet_balance', recipient_wallet_balance, " +
+		"'reference', reference, 'processed_at', to_char(processed_at, 'YYYY-MM-DD\"T\"HH24:MI:SS.US\"Z\"')" +
+		"))::TEXT AS payload FROM inserted), " +
+		"outbox AS (INSERT INTO outbox_events(event_type, payload, created_at) " +
+		"SELECT '" + eventType.String() + "', payload::JSONB, processed_at FROM event) " +
+		"SELECT id, sender_wallet_balance, recipient_wallet_balance FROM event, pg_notify('" + TransactionsChannel + "', event.payload)"
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+type OwnerRepository interface {
+	CreateOwner(ctx context.Context, owner model.Owner) (string, error)
+	GetOwner(ctx context.Context, id string) (*model.Owner, error)
+	// GetOwners returns the owners ordered by creation, a limit or an offset of
+	// -1 is ignored.
+	GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, error)
+	// UpdateOwner replaces the details of the owner, its creation time and
+	// subject are kept.
+	UpdateOwner(ctx context.Context, owner model.Owner) error
+	DeleteOwner(ctx context.Context, id string) error
+}
+
+type ownerRepository struct {
+	db *sql.DB
+}
+
+func NewOwnerRepository(db *sql.DB) *ownerRepository {
+	return &ownerRepository{
+		db: db,
+	}
+}
+
+const ownerColumns = "id, COALESCE(external_id, ''), COALESCE(subject, ''), name, COALESCE(email, ''), COALESCE(phone, ''), kyc_tier, created_at, updated_at"
+
+func (ownerRepository *ownerRepository) CreateOwner(ctx context.Context, owner model.Owner) (string, error) {
+	var id string
+	if err := ownerRepository.db.QueryRowContext(
+		ctx,
+		"INSERT INTO owners(external_id, subject, name, email, phone, kyc_tier, created_at, updated_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
+		nullString(owner.ExternalID),
+		nullString(owner.Subject),
+		owner.Name,
+		nullString(owner.Email),
+		nullString(owner.Phone),
+		owner.KycTier,
+		owner.CreatedAt,
+		owner.UpdatedAt,
+	).Scan(&id); err != nil {
+		if isPgError(err, uniqueViolation) {
+			return "", fmt.Errorf("OwnerRepository - CreateOwner - ownerRepository.db.QueryRowContext: %w", model.ErrOwnerAlreadyExists)
+		}
+		return "", fmt.Errorf("OwnerRepository - CreateOwner - ownerRepository.db.QueryRowContext: %w", err)
+	}
+	return id, nil
+}
+
+func (ownerRepository *ownerRepository) GetOwner(ctx context.Context, id string) (*model.Owner, error) {
+	owner, err := scanOwner(ownerRepository.db.QueryRowContext(
+		ctx,
+		"SELECT "+ownerColumns+" FROM owners WHERE id = $1",
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("OwnerRepository - GetOwner - scanOwner: %w", model.ErrOwnerNotFound)
+		}
+		return nil, fmt.Errorf("OwnerRepository - GetOwner - scanOwner: %w", err)
+	}
+	return owner, nil
+}
+
+func (ownerRepository *ownerRepository) GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, error) {
+	query := "SELECT " + ownerColumns + " FROM owners ORDER BY created_at, id"
+	var values []interface{}
+	if limit > -1 {
+		values = append(values, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(values))
+	}
+	if offset > -1 {
+		values = append(values, offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(values))
+	}
+	rows, err := ownerRepository.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerRepository - GetOwners - ownerRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var owners []*model.Owner
+	for rows.Next() {
+		owner, err := scanOwner(rows)
+		if err != nil {
+			return nil, fmt.Errorf("OwnerRepository - GetOwners - scanOwner: %w", err)
+		}
+		owners = append(owners, owner)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("OwnerRepository - GetOwners - rows.Err: %w", err)
+	}
+	return owners, nil
+}
+
+func (ownerRepository *ownerRepository) UpdateOwner(ctx context.Context, owner model.Owner) error {
+	result, err := ownerRepository.db.ExecContext(
+		ctx,
+		"UPDATE owners SET external_id = $1, name = $2, email = $3, phone = $4, kyc_tier = $5, updated_at = $6 WHERE id = $7",
+		nullString(owner.ExternalID),
+		owner.Name,
+		nullString(owner.Email),
+		nullString(owner.Phone),
+		owner.KycTier,
+		owner.UpdatedAt,
+		owner.ID,
+	)
+	if err != nil {
+		if isPgError(err, uniqueViolation) {
+			return fmt.Errorf("OwnerRepository - UpdateOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerAlreadyExists)
+		}
+		return fmt.Errorf("OwnerRepository - UpdateOwner - ownerRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("OwnerRepository - UpdateOwner - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("OwnerRepository - UpdateOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerNotFound)
+	}
+	return nil
+}
+
+func (ownerRepository *ownerRepository) DeleteOwner(ctx context.Context, id string) error {
+	result, err := ownerRepository.db.ExecContext(ctx, "DELETE FROM owners WHERE id = $1", id)
+	if err != nil {
+		if isPgError(err, foreignKeyViolation) {
+			return fmt.Errorf("OwnerRepository - DeleteOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerHasWallets)
+		}
+		return fmt.Errorf("OwnerRepository - DeleteOwner - ownerRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("OwnerRepository - DeleteOwner - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("OwnerRepository - DeleteOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerNotFound)
+	}
+	return nil
+}
+
+func scanOwner(row pgRow) (*model.Owner, error) {
+	owner := new(model.Owner)
+	if err := row.Scan(
+		&owner.ID,
+		&owner.ExternalID,
+		&owner.Subject,
+		&owner.Name,
+		&owner.Email,
+		&owner.Phone,
+		&owner.KycTier,
+		&owner.CreatedAt,
+		&owner.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return owner, nil
+}
+
+// nullString stores an empty string as NULL.
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
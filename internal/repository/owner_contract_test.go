@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testOwnerRepository is the contract every OwnerRepository implementation
+// satisfies, newRepositories returns empty repositories of the same storage.
+func testOwnerRepository(t *testing.T, newRepositories func(t *testing.T) (OwnerRepository, WalletRepository)) {
+	ctx := context.Background()
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	createOwner := func(t *testing.T, ownerRepository OwnerRepository, name string, externalId string) *model.Owner {
+		owner := model.Owner{
+			ExternalID: externalId,
+			Name:       name,
+			Email:      name + "@example.com",
+			KycTier:    1,
+			CreatedAt:  createdAt,
+			UpdatedAt:  createdAt,
+		}
+		id, err := ownerRepository.CreateOwner(ctx, owner)
+		require.NoError(t, err)
+		owner.ID = id
+		return &owner
+	}
+
+	t.Run("GetOwner", func(t *testing.T) {
+		// given
+		ownerRepository, _ := newRepositories(t)
+		owner := createOwner(t, ownerRepository, "alice", "customer-1")
+		createOwner(t, ownerRepository, "bob", "")
+
+		// when
+		found, err := ownerRepository.GetOwner(ctx, owner.ID)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, owner.ID, found.ID)
+		assert.Equal(t, "customer-1", found.ExternalID)
+		assert.Equal(t, "alice@example.com", found.Email)
+		assert.Empty(t, found.Phone)
+		assert.Equal(t, 1, found.KycTier)
+		assert.True(t, createdAt.Equal(found.CreatedAt))
+	})
+
+	t.Run("CreateOwnerDuplicateExternalId", func(t *testing.T) {
+		// given
+		ownerRepository, _ := newRepositories(t)
+		createOwner(t, ownerRepository, "alice", "customer-1")
+		createOwner(t, ownerRepository, "bob", "")
+
+		// when
+		_, err := ownerRepository.CreateOwner(ctx, model.Owner{ExternalID: "customer-1", Name: "carol", CreatedAt: createdAt, UpdatedAt: createdAt})
+		_, errWithoutExternalId := ownerRepository.CreateOwner(ctx, model.Owner{Name: "dave", CreatedAt: createdAt, UpdatedAt: createdAt})
+
+		// then
+		assert.ErrorIs(t, err, model.ErrOwnerAlreadyExists)
+		assert.NoError(t, errWithoutExternalId)
+	})
+
+	t.Run("OwnerSubject", func(t *testing.T) {
+		// given
+		ownerRepository, _ := newRepositories(t)
+		owner := model.Owner{Subject: "user-1", Name: "alice", CreatedAt: createdAt, UpdatedAt: createdAt}
+		id, err := ownerRepository.CreateOwner(ctx, owner)
+		require.NoError(t, err)
+		owner.ID = id
+
+		// when
+		_, duplicateErr := ownerRepository.CreateOwner(ctx, model.Owner{Subject: "user-1", Name: "bob", CreatedAt: createdAt, UpdatedAt: createdAt})
+		owner.Subject = "user-2"
+		updateErr := ownerRepository.UpdateOwner(ctx, owner)
+
+		// then
+		assert.ErrorIs(t, duplicateErr, model.ErrOwnerAlreadyExists)
+		require.NoError(t, updateErr)
+		found, err := ownerRepository.GetOwner(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", found.Subject)
+	})
+
+	t.Run("GetOwners", func(t *testing.T) {
+		// given
+		ownerRepository, _ := newRepositories(t)
+		createOwner(t, ownerRepository, "alice", "customer-1")
+		bob := createOwner(t, ownerRepository, "bob", "customer-2")
+		createOwner(t, ownerRepository, "carol", "customer-3")
+
+		// when
+		owners, err := ownerRepository.GetOwners(ctx, 1, 1)
+		allOwners, allErr := ownerRepository.GetOwners(ctx, -1, -1)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, owners, 1)
+		assert.Equal(t, bob.ID, owners[0].ID)
+		require.NoError(t, allErr)
+		assert.Len(t, allOwners, 3)
+	})
+
+	t.Run("UpdateOwner", func(t *testing.T) {
+		// given
+		ownerRepository, _ := newRepositories(t)
+		owner := createOwner(t, ownerRepository, "alice", "customer-1")
+		createOwner(t, ownerRepository, "bob", "customer-2")
+		owner.Name = "alice smith"
+		owner.KycTier = 2
+		owner.UpdatedAt = createdAt.Add(time.Hour)
+
+		// when
+		err := ownerRepository.UpdateOwner(ctx, *owner)
+
+		// then
+		require.NoError(t, err)
+		found, err := ownerRepository.GetOwner(ctx, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "alice smith", found.Name)
+		assert.Equal(t, 2, found.KycTier)
+		assert.True(t, owner.UpdatedAt.Equal(found.UpdatedAt))
+		owner.ExternalID = "customer-2"
+		assert.ErrorIs(t, ownerRepository.UpdateOwner(ctx, *owner), model.ErrOwnerAlreadyExists)
+		assert.ErrorIs(t, ownerRepository.UpdateOwner(ctx, model.Owner{ID: "00000000-0000-0000-0000-000000000000", Name: "nobody"}), model.ErrOwnerNotFound)
+	})
+
+	t.Run("DeleteOwner", func(t *testing.T) {
+		// given
+		ownerRepository, _ := newRepositories(t)
+		owner := createOwner(t, ownerRepository, "alice", "customer-1")
+
+		// when
+		err := ownerRepository.DeleteOwner(ctx, owner.ID)
+
+		// then
+		require.NoError(t, err)
+		_, err = ownerRepository.GetOwner(ctx, owner.ID)
+		assert.ErrorIs(t, err, model.ErrOwnerNotFound)
+		assert.ErrorIs(t, ownerRepository.DeleteOwner(ctx, owner.ID), model.ErrOwnerNotFound)
+	})
+
+	t.Run("GetOwnerWallets", func(t *testing.T) {
+		// given
+		ownerRepository, walletRepository := newRepositories(t)
+		owner := createOwner(t, ownerRepository, "alice", "customer-1")
+		usdId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "alice-usd", Currency: "USD", OwnerId: owner.ID})
+		require.NoError(t, err)
+		eurId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "alice-eur", Currency: "EUR", OwnerId: owner.ID})
+		require.NoError(t, err)
+		_, err = walletRepository.CreateWallet(ctx, model.Wallet{Name: "other", Currency: "USD"})
+		require.NoError(t, err)
+
+		// when
+		wallets, err := walletRepository.GetOwnerWallets(ctx, owner.ID)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, wallets, 2)
+		assert.Equal(t, eurId, wallets[0].ID)
+		assert.Equal(t, usdId, wallets[1].ID)
+		assert.Equal(t, owner.ID, wallets[1].OwnerId)
+		_, err = walletRepository.CreateWallet(ctx, model.Wallet{Name: "alice-usd-2", Currency: "USD", OwnerId: owner.ID})
+		assert.ErrorIs(t, err, model.ErrWalletAlreadyExists)
+	})
+}
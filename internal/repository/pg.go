@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// pgQuerier runs Postgres queries either through database/sql or natively
+// through pgxpool. Rows not found are reported as sql.ErrNoRows by both.
+type pgQuerier interface {
+	QueryRow(ctx context.Context, query string, args ...interface{}) pgRow
+	Query(ctx context.Context, query string, args ...interface{}) (pgRows, error)
+	Exec(ctx context.Context, query string, args ...interface{}) (int64, error)
+}
+
+type pgDB interface {
+	pgQuerier
+	Begin(ctx context.Context) (pgTx, error)
+}
+
+type pgTx interface {
+	pgQuerier
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+type pgRow interface {
+	Scan(dest ...interface{}) error
+}
+
+type pgRows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+	Close()
+}
+
+// sqlDB adapts a database/sql pool.
+type sqlDB struct {
+	db *sql.DB
+}
+
+func (db sqlDB) QueryRow(ctx context.Context, query string, args ...interface{}) pgRow {
+	return db.db.QueryRowContext(ctx, query, args...)
+}
+
+func (db sqlDB) Query(ctx context.Context, query string, args ...interface{}) (pgRows, error) {
+	rows, err := db.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlRows{rows}, nil
+}
+
+func (db sqlDB) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return sqlExec(db.db.ExecContext(ctx, query, args...))
+}
+
+func (db sqlDB) Begin(ctx context.Context) (pgTx, error) {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sqlTx{tx}, nil
+}
+
+// sqlTx adapts a database/sql transaction.
+type sqlTx struct {
+	tx *sql.Tx
+}
+
+func (tx sqlTx) QueryRow(ctx context.Context, query string, args ...interface{}) pgRow {
+	return tx.tx.QueryRowContext(ctx, query, args...)
+}
+
+func (tx sqlTx) Query(ctx context.Context, query string, args ...interface{}) (pgRows, error) {
+	rows, err := tx.tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return sqlRows{rows}, nil
+}
+
+func (tx sqlTx) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return sqlExec(tx.tx.ExecContext(ctx, query, args...))
+}
+
+func (tx sqlTx) Commit(ctx context.Context) error {
+	return tx.tx.Commit()
+}
+
+func (tx sqlTx) Rollback(ctx context.Context) error {
+	return tx.tx.Rollback()
+}
+
+type sqlRows struct {
+	*sql.Rows
+}
+
+func (rows sqlRows) Close() {
+	_ = rows.Rows.Close()
+}
+
+func sqlExec(result sql.Result, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// pgxDB adapts a pgx pool.
+type pgxDB struct {
+	pool *pgxpool.Pool
+}
+
+func (db pgxDB) QueryRow(ctx context.Context, query string, args ...interface{}) pgRow {
+	return pgxRow{db.pool.QueryRow(ctx, query, args...)}
+}
+
+func (db pgxDB) Query(ctx context.Context, query string, args ...interface{}) (pgRows, error) {
+	return db.pool.Query(ctx, query, args...)
+}
+
+func (db pgxDB) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := db.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (db pgxDB) Begin(ctx context.Context) (pgTx, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return pgxTx{tx}, nil
+}
+
+// pgxTx adapts a pgx transaction.
+type pgxTx struct {
+	tx pgx.Tx
+}
+
+func (tx pgxTx) QueryRow(ctx context.Context, query string, args ...interface{}) pgRow {
+	return pgxRow{tx.tx.QueryRow(ctx, query, args...)}
+}
+
+func (tx pgxTx) Query(ctx context.Context, query string, args ...interface{}) (pgRows, error) {
+	return tx.tx.Query(ctx, query, args...)
+}
+
+func (tx pgxTx) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	tag, err := tx.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (tx pgxTx) Commit(ctx context.Context) error {
+	return tx.tx.Commit(ctx)
+}
+
+func (tx pgxTx) Rollback(ctx context.Context) error {
+	return tx.tx.Rollback(ctx)
+}
+
+type pgxRow struct {
+	row pgx.Row
+}
+
+func (row pgxRow) Scan(dest ...interface{}) error {
+	err := row.row.Scan(dest...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/SergeyChupin/wallets-api/internal/repository")
+
+// tracedQuerier starts a client span for each query, named after its first
+// keyword and holding the statement without its arguments. The span of a row
+// ends once it is scanned, the span of rows once they are closed.
+type tracedQuerier struct {
+	querier pgQuerier
+}
+
+func (querier tracedQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) pgRow {
+	ctx, span := startQuerySpan(ctx, query)
+	return tracedRow{row: querier.querier.QueryRow(ctx, query, args...), span: span}
+}
+
+func (querier tracedQuerier) Query(ctx context.Context, query string, args ...interface{}) (pgRows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	rows, err := querier.querier.Query(ctx, query, args...)
+	if err != nil {
+		endQuerySpan(span, err)
+		return nil, err
+	}
+	return &tracedRows{pgRows: rows, span: span}, nil
+}
+
+func (querier tracedQuerier) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	affected, err := querier.querier.Exec(ctx, query, args...)
+	endQuerySpan(span, err)
+	return affected, err
+}
+
+// tracedDB traces the queries of a pool and of its transactions.
+type tracedDB struct {
+	tracedQuerier
+	db pgDB
+}
+
+func newTracedDB(db pgDB) tracedDB {
+	return tracedDB{
+		tracedQuerier: tracedQuerier{querier: db},
+		db:            db,
+	}
+}
+
+func (db tracedDB) Begin(ctx context.Context) (pgTx, error) {
+	tx, err := db.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tracedTx{tracedQuerier: tracedQuerier{querier: tx}, tx: tx}, nil
+}
+
+type tracedTx struct {
+	tracedQuerier
+	tx pgTx
+}
+
+func (tx tracedTx) Commit(ctx context.Context) error {
+	ctx, span := startQuerySpan(ctx, "COMMIT")
+	err := tx.tx.Commit(ctx)
+	endQuerySpan(span, err)
+	return err
+}
+
+func (tx tracedTx) Rollback(ctx context.Context) error {
+	return tx.tx.Rollback(ctx)
+}
+
+type tracedRow struct {
+	row  pgRow
+	span trace.Span
+}
+
+func (row tracedRow) Scan(dest ...interface{}) error {
+	err := row.row.Scan(dest...)
+	endQuerySpan(row.span, err)
+	return err
+}
+
+type tracedRows struct {
+	pgRows
+	span trace.Span
+}
+
+func (rows *tracedRows) Close() {
+	rows.pgRows.Close()
+	endQuerySpan(rows.span, rows.pgRows.Err())
+}
+
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := query
+	if i := strings.IndexByte(query, ' '); i >= 0 {
+		operation = query[:i]
+	}
+	operation = strings.ToUpper(operation)
+	return tracer.Start(
+		ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationKey.String(operation),
+			semconv.DBStatementKey.String(query),
+		),
+	)
+}
+
+// endQuerySpan ends the span of a query, a row not found is not an error.
+func endQuerySpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
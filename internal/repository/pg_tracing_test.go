@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type stubQuerier struct {
+	err error
+}
+
+func (querier stubQuerier) QueryRow(ctx context.Context, query string, args ...interface{}) pgRow {
+	return stubRow{err: querier.err}
+}
+
+func (querier stubQuerier) Query(ctx context.Context, query string, args ...interface{}) (pgRows, error) {
+	return nil, querier.err
+}
+
+func (querier stubQuerier) Exec(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	return 0, querier.err
+}
+
+type stubRow struct {
+	err error
+}
+
+func (row stubRow) Scan(dest ...interface{}) error {
+	return row.err
+}
+
+func TestTracedQuerier(t *testing.T) {
+	// given
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	ctx := context.Background()
+
+	// when
+	_ = tracedQuerier{querier: stubQuerier{err: sql.ErrNoRows}}.
+		QueryRow(ctx, "SELECT id FROM wallets WHERE id = $1", "1001").Scan()
+	_, _ = tracedQuerier{querier: stubQuerier{err: errors.New("connection reset")}}.
+		Exec(ctx, "update wallets SET frozen = true WHERE id = $1", "1001")
+
+	// then
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "SELECT", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), attribute.String("db.statement", "SELECT id FROM wallets WHERE id = $1"))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "UPDATE", spans[1].Name())
+	assert.Contains(t, spans[1].Attributes(), attribute.String("db.operation", "UPDATE"))
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// sqliteApiKeyRepository stores API keys in the SQLite database of the wallets,
+// the scopes of a key are stored comma separated.
+type sqliteApiKeyRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteApiKeyRepository(db *sql.DB) *sqliteApiKeyRepository {
+	return &sqliteApiKeyRepository{
+		db: db,
+	}
+}
+
+func (apiKeyRepository *sqliteApiKeyRepository) CreateApiKey(ctx context.Context, apiKey model.ApiKey) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("SqliteApiKeyRepository - CreateApiKey - newId: %w", err)
+	}
+	if _, err = apiKeyRepository.db.ExecContext(
+		ctx,
+		"INSERT INTO api_keys(id, name, prefix, key_hash, scopes, created_at) VALUES(?, ?, ?, ?, ?, ?)",
+		id,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.Hash,
+		strings.Join(scopeStrings(apiKey.Scopes), ","),
+		apiKey.CreatedAt.UTC().Format(sqliteTimeLayout),
+	); err != nil {
+		return "", fmt.Errorf("SqliteApiKeyRepository - CreateApiKey - apiKeyRepository.db.ExecContext: %w", err)
+	}
+	return id, nil
+}
+
+func (apiKeyRepository *sqliteApiKeyRepository) GetApiKeyByHash(ctx context.Context, hash string) (*model.ApiKey, error) {
+	apiKey, err := sqliteScanApiKey(apiKeyRepository.db.QueryRowContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = ? AND revoked_at IS NULL",
+		hash,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("SqliteApiKeyRepository - GetApiKeyByHash - sqliteScanApiKey: %w", model.ErrApiKeyNotFound)
+		}
+		return nil, fmt.Errorf("SqliteApiKeyRepository - GetApiKeyByHash - sqliteScanApiKey: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (apiKeyRepository *sqliteApiKeyRepository) GetApiKeys(ctx context.Context) ([]*model.ApiKey, error) {
+	rows, err := apiKeyRepository.db.QueryContext(
+		ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at, rowid",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteApiKeyRepository - GetApiKeys - apiKeyRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var apiKeys []*model.ApiKey
+	for rows.Next() {
+		apiKey, err := sqliteScanApiKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("SqliteApiKeyRepository - GetApiKeys - sqliteScanApiKey: %w", err)
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("SqliteApiKeyRepository - GetApiKeys - rows.Err: %w", err)
+	}
+	return apiKeys, nil
+}
+
+func (apiKeyRepository *sqliteApiKeyRepository) RotateApiKey(
+	ctx context.Context, id string, prefix string, hash string, rotatedAt time.Time,
+) (*model.ApiKey, error) {
+	apiKey, err := sqliteScanApiKey(apiKeyRepository.db.QueryRowContext(
+		ctx,
+		"UPDATE api_keys SET prefix = ?, key_hash = ?, rotated_at = ? WHERE id = ? AND revoked_at IS NULL RETURNING "+apiKeyColumns,
+		prefix,
+		hash,
+		rotatedAt.UTC().Format(sqliteTimeLayout),
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("SqliteApiKeyRepository - RotateApiKey - sqliteScanApiKey: %w", model.ErrApiKeyNotFound)
+		}
+		return nil, fmt.Errorf("SqliteApiKeyRepository - RotateApiKey - sqliteScanApiKey: %w", err)
+	}
+	return apiKey, nil
+}
+
+func (apiKeyRepository *sqliteApiKeyRepository) RevokeApiKey(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := apiKeyRepository.db.ExecContext(
+		ctx,
+		"UPDATE api_keys SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL",
+		revokedAt.UTC().Format(sqliteTimeLayout),
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("SqliteApiKeyRepository - RevokeApiKey - apiKeyRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SqliteApiKeyRepository - RevokeApiKey - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("SqliteApiKeyRepository - RevokeApiKey - apiKeyRepository.db.ExecContext: %w", model.ErrApiKeyNotFound)
+	}
+	return nil
+}
+
+func sqliteScanApiKey(row pgRow) (*model.ApiKey, error) {
+	apiKey := new(model.ApiKey)
+	var scopes string
+	var rotatedAt, revokedAt sql.NullTime
+	if err := row.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.Hash,
+		&scopes,
+		&apiKey.CreatedAt,
+		&rotatedAt,
+		&revokedAt,
+	); err != nil {
+		return nil, err
+	}
+	var err error
+	if apiKey.Scopes, err = scopesFromStrings(strings.Split(scopes, ",")); err != nil {
+		return nil, fmt.Errorf("sqliteScanApiKey - scopesFromStrings: %w", err)
+	}
+	if rotatedAt.Valid {
+		apiKey.RotatedAt = &rotatedAt.Time
+	}
+	if revokedAt.Valid {
+		apiKey.RevokedAt = &revokedAt.Time
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// sqliteAuditRepository appends to the audit log in the SQLite database of
+// the wallets.
+type sqliteAuditRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteAuditRepository(db *sql.DB) *sqliteAuditRepository {
+	return &sqliteAuditRepository{
+		db: db,
+	}
+}
+
+func (auditRepository *sqliteAuditRepository) CreateAuditEntry(ctx context.Context, entry model.AuditEntry) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("SqliteAuditRepository - CreateAuditEntry - newId: %w", err)
+	}
+	entry.ID = id
+	if err = sqliteInsertAuditEntry(ctx, auditRepository.db, &entry); err != nil {
+		return "", fmt.Errorf("SqliteAuditRepository - CreateAuditEntry - sqliteInsertAuditEntry: %w", err)
+	}
+	return id, nil
+}
+
+func (auditRepository *sqliteAuditRepository) GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	query := "SELECT " + auditColumns + " FROM audit_log WHERE TRUE"
+	var values []interface{}
+	for _, condition := range auditConditions(filter) {
+		if createdAt, ok := condition.value.(time.Time); ok {
+			condition.value = createdAt.Format(sqliteTimeLayout)
+		}
+		values = append(values, condition.value)
+		query += " AND " + condition.column + " ?"
+	}
+	query += " ORDER BY created_at DESC, rowid DESC"
+	// SQLite requires LIMIT for OFFSET, a negative limit means no limit.
+	values = append(values, limit)
+	query += " LIMIT ?"
+	if offset > -1 {
+		values = append(values, offset)
+		query += " OFFSET ?"
+	}
+	rows, err := auditRepository.db.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteAuditRepository - GetAuditEntries - auditRepository.db.QueryContext: %w", err)
+	}
+	entries, err := scanAuditEntries(rows)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteAuditRepository - GetAuditEntries - scanAuditEntries: %w", err)
+	}
+	return entries, nil
+}
+
+type sqliteExecer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func sqliteInsertAuditEntry(ctx context.Context, db sqliteExecer, entry *model.AuditEntry) error {
+	if _, err := db.ExecContext(
+		ctx,
+		"INSERT INTO audit_log(id, actor, action, wallet_id, payload_hash, outcome, client_ip, request_id, created_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		entry.ID,
+		entry.Actor,
+		entry.Action,
+		nullString(entry.WalletId),
+		entry.PayloadHash,
+		entry.Outcome.String(),
+		entry.ClientIp,
+		nullString(entry.RequestId),
+		entry.CreatedAt.UTC().Format(sqliteTimeLayout),
+	); err != nil {
+		return fmt.Errorf("sqliteInsertAuditEntry - db.ExecContext: %w", err)
+	}
+	return nil
+}
+
+// sqliteInsertPendingAuditEntry records the pending audit entry in the
+// transaction of the operation, pending.recorded completes the entry once
+// the transaction is committed.
+func sqliteInsertPendingAuditEntry(ctx context.Context, tx *sql.Tx, pending pendingAuditEntry, walletId string, now time.Time) error {
+	if pending.entry == nil {
+		return nil
+	}
+	entry := *pending.entry
+	entry.ID = pending.id
+	entry.WalletId = walletId
+	entry.Outcome = model.AuditSuccess
+	entry.CreatedAt = now
+	return sqliteInsertAuditEntry(ctx, tx, &entry)
+}
@@ -0,0 +1,23 @@
+//go:build cgo
+// +build cgo
+
+package repository
+
+import (
+	"errors"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+func isSqliteUniqueViolation(err error) bool {
+	return isSqliteError(err, sqlite3.ErrConstraintUnique)
+}
+
+func isSqliteCheckViolation(err error) bool {
+	return isSqliteError(err, sqlite3.ErrConstraintCheck)
+}
+
+func isSqliteError(err error, code sqlite3.ErrNoExtended) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == code
+}
@@ -0,0 +1,15 @@
+//go:build !cgo
+// +build !cgo
+
+package repository
+
+// Without cgo the sqlite driver fails to open any database, so there are no
+// constraint violations to map.
+
+func isSqliteUniqueViolation(err error) bool {
+	return false
+}
+
+func isSqliteCheckViolation(err error) bool {
+	return false
+}
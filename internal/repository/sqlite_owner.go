@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// sqliteOwnerRepository stores owners in the SQLite database of the wallets.
+// The wallets do not reference the owners with a foreign key, the owner of a
+// wallet is checked by the service.
+type sqliteOwnerRepository struct {
+	db *sql.DB
+}
+
+func NewSqliteOwnerRepository(db *sql.DB) *sqliteOwnerRepository {
+	return &sqliteOwnerRepository{
+		db: db,
+	}
+}
+
+func (ownerRepository *sqliteOwnerRepository) CreateOwner(ctx context.Context, owner model.Owner) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("SqliteOwnerRepository - CreateOwner - newId: %w", err)
+	}
+	if _, err = ownerRepository.db.ExecContext(
+		ctx,
+		"INSERT INTO owners(id, external_id, subject, name, email, phone, kyc_tier, created_at, updated_at) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id,
+		nullString(owner.ExternalID),
+		nullString(owner.Subject),
+		owner.Name,
+		nullString(owner.Email),
+		nullString(owner.Phone),
+		owner.KycTier,
+		owner.CreatedAt.UTC().Format(sqliteTimeLayout),
+		owner.UpdatedAt.UTC().Format(sqliteTimeLayout),
+	); err != nil {
+		if isSqliteUniqueViolation(err) {
+			return "", fmt.Errorf("SqliteOwnerRepository - CreateOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerAlreadyExists)
+		}
+		return "", fmt.Errorf("SqliteOwnerRepository - CreateOwner - ownerRepository.db.ExecContext: %w", err)
+	}
+	return id, nil
+}
+
+func (ownerRepository *sqliteOwnerRepository) GetOwner(ctx context.Context, id string) (*model.Owner, error) {
+	owner, err := scanOwner(ownerRepository.db.QueryRowContext(
+		ctx,
+		"SELECT "+ownerColumns+" FROM owners WHERE id = ?",
+		id,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("SqliteOwnerRepository - GetOwner - scanOwner: %w", model.ErrOwnerNotFound)
+		}
+		return nil, fmt.Errorf("SqliteOwnerRepository - GetOwner - scanOwner: %w", err)
+	}
+	return owner, nil
+}
+
+func (ownerRepository *sqliteOwnerRepository) GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	// SQLite requires LIMIT for OFFSET, a negative limit means no limit.
+	rows, err := ownerRepository.db.QueryContext(
+		ctx,
+		"SELECT "+ownerColumns+" FROM owners ORDER BY created_at, rowid LIMIT ? OFFSET ?",
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteOwnerRepository - GetOwners - ownerRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var owners []*model.Owner
+	for rows.Next() {
+		owner, err := scanOwner(rows)
+		if err != nil {
+			return nil, fmt.Errorf("SqliteOwnerRepository - GetOwners - scanOwner: %w", err)
+		}
+		owners = append(owners, owner)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("SqliteOwnerRepository - GetOwners - rows.Err: %w", err)
+	}
+	return owners, nil
+}
+
+func (ownerRepository *sqliteOwnerRepository) UpdateOwner(ctx context.Context, owner model.Owner) error {
+	result, err := ownerRepository.db.ExecContext(
+		ctx,
+		"UPDATE owners SET external_id = ?, name = ?, email = ?, phone = ?, kyc_tier = ?, updated_at = ? WHERE id = ?",
+		nullString(owner.ExternalID),
+		owner.Name,
+		nullString(owner.Email),
+		nullString(owner.Phone),
+		owner.KycTier,
+		owner.UpdatedAt.UTC().Format(sqliteTimeLayout),
+		owner.ID,
+	)
+	if err != nil {
+		if isSqliteUniqueViolation(err) {
+			return fmt.Errorf("SqliteOwnerRepository - UpdateOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerAlreadyExists)
+		}
+		return fmt.Errorf("SqliteOwnerRepository - UpdateOwner - ownerRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SqliteOwnerRepository - UpdateOwner - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("SqliteOwnerRepository - UpdateOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerNotFound)
+	}
+	return nil
+}
+
+func (ownerRepository *sqliteOwnerRepository) DeleteOwner(ctx context.Context, id string) error {
+	result, err := ownerRepository.db.ExecContext(ctx, "DELETE FROM owners WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("SqliteOwnerRepository - DeleteOwner - ownerRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SqliteOwnerRepository - DeleteOwner - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("SqliteOwnerRepository - DeleteOwner - ownerRepository.db.ExecContext: %w", model.ErrOwnerNotFound)
+	}
+	return nil
+}
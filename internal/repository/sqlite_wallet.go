@@ -0,0 +1,487 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// sqliteTimeLayout stores times in UTC with the precision of Postgres, it sorts
+// lexicographically and is parsed back by the driver for TIMESTAMP columns.
+const sqliteTimeLayout = "2006-01-02 15:04:05.000000"
+
+// sqliteWalletRepository stores wallets in a SQLite database opened by sqlite.Open,
+// whose write transactions are serialized. Committed transactions are notified
+// to the listeners of this process only.
+type sqliteWalletRepository struct {
+	db *sql.DB
+	localTransactionListener
+}
+
+func NewSqliteWalletRepository(db *sql.DB) *sqliteWalletRepository {
+	return &sqliteWalletRepository{
+		db:                       db,
+		localTransactionListener: newLocalTransactionListener(),
+	}
+}
+
+func (walletRepository *sqliteWalletRepository) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("SqliteWalletRepository - CreateWallet - newId: %w", err)
+	}
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return "", fmt.Errorf("SqliteWalletRepository - CreateWallet - newPendingAuditEntry: %w", err)
+	}
+	tx, err := walletRepository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("SqliteWalletRepository - CreateWallet - walletRepository.db.BeginTx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	if _, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO wallets(id, name, currency, balance, owner, owner_id) VALUES(?, ?, ?, ?, ?, ?)",
+		id,
+		wallet.Name,
+		wallet.Currency,
+		0,
+		sql.NullString{String: wallet.Owner, Valid: wallet.Owner != ""},
+		sql.NullString{String: wallet.OwnerId, Valid: wallet.OwnerId != ""},
+	); err != nil {
+		if isSqliteUniqueViolation(err) {
+			return "", fmt.Errorf("SqliteWalletRepository - CreateWallet - tx.ExecContext: %w", model.ErrWalletAlreadyExists)
+		}
+		return "", fmt.Errorf("SqliteWalletRepository - CreateWallet - tx.ExecContext: %w", err)
+	}
+	if err = sqliteInsertPendingAuditEntry(ctx, tx, pendingAudit, id, now); err != nil {
+		return "", fmt.Errorf("SqliteWalletRepository - CreateWallet - sqliteInsertPendingAuditEntry: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", fmt.Errorf("SqliteWalletRepository - CreateWallet - tx.Commit: %w", err)
+	}
+	pendingAudit.recorded(id, now)
+	return id, nil
+}
+
+const sqliteWalletColumns = "id, name, currency, balance, COALESCE(owner, ''), COALESCE(owner_id, ''), frozen"
+
+func (walletRepository *sqliteWalletRepository) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	wallet := new(model.Wallet)
+	if err := walletRepository.db.QueryRowContext(
+		ctx,
+		"SELECT "+sqliteWalletColumns+" FROM wallets WHERE id = ?",
+		id,
+	).Scan(&wallet.ID, &wallet.Name, &wallet.Currency, &wallet.Balance, &wallet.Owner, &wallet.OwnerId, &wallet.Frozen); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("SqliteWalletRepository - GetWallet - walletRepository.db.QueryRowContext: %w", model.ErrWalletNotFound)
+		}
+		return nil, fmt.Errorf("SqliteWalletRepository - GetWallet - walletRepository.db.QueryRowContext: %w", err)
+	}
+	return wallet, nil
+}
+
+func (walletRepository *sqliteWalletRepository) GetOwnerWallets(ctx context.Context, ownerId string) ([]*model.Wallet, error) {
+	rows, err := walletRepository.db.QueryContext(
+		ctx,
+		"SELECT "+sqliteWalletColumns+" FROM wallets WHERE owner_id = ? ORDER BY currency, name",
+		ownerId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetOwnerWallets - walletRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var wallets []*model.Wallet
+	for rows.Next() {
+		wallet := new(model.Wallet)
+		if err = rows.Scan(&wallet.ID, &wallet.Name, &wallet.Currency, &wallet.Balance, &wallet.Owner, &wallet.OwnerId, &wallet.Frozen); err != nil {
+			return nil, fmt.Errorf("SqliteWalletRepository - GetOwnerWallets - rows.Scan: %w", err)
+		}
+		wallets = append(wallets, wallet)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetOwnerWallets - rows.Err: %w", err)
+	}
+	return wallets, nil
+}
+
+func (walletRepository *sqliteWalletRepository) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Deposit - newPendingAuditEntry: %w", err)
+	}
+	tx, err := walletRepository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Deposit - walletRepository.db.BeginTx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	recipientWalletBalance, err := sqliteUpdateBalance(ctx, tx, recipientWalletId, int64(amount))
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Deposit - sqliteUpdateBalance: %w", err)
+	}
+
+	transaction := &model.Transaction{
+		Amount:      amount,
+		ProcessedAt: now,
+		RecipientWallet: model.Wallet{
+			ID:      recipientWalletId,
+			Balance: recipientWalletBalance,
+		},
+		OperationType: model.Deposit,
+		ApiKeyId:      auth.ApiKeyId(ctx),
+	}
+	if transaction.ID, err = sqliteInsertTransaction(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Deposit - sqliteInsertTransaction: %w", err)
+	}
+	if err = sqliteInsertPendingAuditEntry(ctx, tx, pendingAudit, recipientWalletId, now); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Deposit - sqliteInsertPendingAuditEntry: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Deposit - tx.Commit: %w", err)
+	}
+	pendingAudit.recorded(recipientWalletId, now)
+
+	walletRepository.notify(copyTransaction(transaction))
+	return transaction, nil
+}
+
+func (walletRepository *sqliteWalletRepository) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Transfer - newPendingAuditEntry: %w", err)
+	}
+	tx, err := walletRepository.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Transfer - walletRepository.db.BeginTx: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	senderWalletBalance, err := sqliteUpdateBalance(ctx, tx, senderWalletId, -int64(amount))
+	if err != nil {
+		if isSqliteCheckViolation(err) {
+			return nil, fmt.Errorf("SqliteWalletRepository - Transfer - sqliteUpdateBalance: %w", model.ErrInsufficientFunds)
+		}
+		return nil, fmt.Errorf("SqliteWalletRepository - Transfer - sqliteUpdateBalance: %w", err)
+	}
+	recipientWalletBalance, err := sqliteUpdateBalance(ctx, tx, recipientWalletId, int64(amount))
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Transfer - sqliteUpdateBalance: %w", err)
+	}
+
+	transaction := &model.Transaction{
+		Amount:      amount,
+		ProcessedAt: now,
+		SenderWallet: &model.Wallet{
+			ID:      senderWalletId,
+			Balance: senderWalletBalance,
+		},
+		RecipientWallet: model.Wallet{
+			ID:      recipientWalletId,
+			Balance: recipientWalletBalance,
+		},
+		OperationType: model.Transfer,
+		ApiKeyId:      auth.ApiKeyId(ctx),
+	}
+	if transaction.ID, err = sqliteInsertTransaction(ctx, tx, transaction); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Transfer - sqliteInsertTransaction: %w", err)
+	}
+	if err = sqliteInsertPendingAuditEntry(ctx, tx, pendingAudit, senderWalletId, now); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Transfer - sqliteInsertPendingAuditEntry: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Transfer - tx.Commit: %w", err)
+	}
+	pendingAudit.recorded(senderWalletId, now)
+
+	walletRepository.notify(copyTransaction(transaction))
+	return transaction, nil
+}
+
+// sqliteUpdateBalance adds delta to the balance of the wallet unless it is frozen
+// and returns the new balance.
+func sqliteUpdateBalance(ctx context.Context, tx *sql.Tx, walletId string, delta int64) (uint64, error) {
+	result, err := tx.ExecContext(
+		ctx,
+		"UPDATE wallets SET balance = balance + ? WHERE id = ? AND NOT frozen",
+		delta,
+		walletId,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("sqliteUpdateBalance - tx.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("sqliteUpdateBalance - result.RowsAffected: %w", err)
+	}
+	var balance uint64
+	var frozen bool
+	if err = tx.QueryRowContext(
+		ctx,
+		"SELECT balance, frozen FROM wallets WHERE id = ?",
+		walletId,
+	).Scan(&balance, &frozen); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("sqliteUpdateBalance - tx.QueryRowContext: %w", model.ErrWalletNotFound)
+		}
+		return 0, fmt.Errorf("sqliteUpdateBalance - tx.QueryRowContext: %w", err)
+	}
+	if affected == 0 && frozen {
+		return 0, fmt.Errorf("sqliteUpdateBalance - tx.ExecContext: %w", model.ErrWalletFrozen)
+	}
+	return balance, nil
+}
+
+func sqliteInsertTransaction(ctx context.Context, tx *sql.Tx, transaction *model.Transaction) (string, error) {
+	id, err := newId()
+	if err != nil {
+		return "", fmt.Errorf("sqliteInsertTransaction - newId: %w", err)
+	}
+	var senderWalletId sql.NullString
+	var senderWalletBalance sql.NullInt64
+	if transaction.SenderWallet != nil {
+		senderWalletId = sql.NullString{String: transaction.SenderWallet.ID, Valid: true}
+		senderWalletBalance = sql.NullInt64{Int64: int64(transaction.SenderWallet.Balance), Valid: true}
+	}
+	if _, err = tx.ExecContext(
+		ctx,
+		"INSERT INTO transactions(id, operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at, reference, api_key_id) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		id,
+		transaction.OperationType.String(),
+		transaction.Amount,
+		senderWalletId,
+		senderWalletBalance,
+		transaction.RecipientWallet.ID,
+		transaction.RecipientWallet.Balance,
+		transaction.ProcessedAt.Format(sqliteTimeLayout),
+		sql.NullString{String: transaction.Reference, Valid: transaction.Reference != ""},
+		sql.NullString{String: transaction.ApiKeyId, Valid: transaction.ApiKeyId != ""},
+	); err != nil {
+		return "", fmt.Errorf("sqliteInsertTransaction - tx.ExecContext: %w", err)
+	}
+	return id, nil
+}
+
+func (walletRepository *sqliteWalletRepository) SetWalletFrozen(ctx context.Context, id string, frozen bool) error {
+	result, err := walletRepository.db.ExecContext(
+		ctx,
+		"UPDATE wallets SET frozen = ? WHERE id = ?",
+		frozen,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("SqliteWalletRepository - SetWalletFrozen - walletRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SqliteWalletRepository - SetWalletFrozen - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("SqliteWalletRepository - SetWalletFrozen - walletRepository.db.ExecContext: %w", model.ErrWalletNotFound)
+	}
+	return nil
+}
+
+// SetWalletShards is not supported, SQLite serializes all writes anyway.
+func (walletRepository *sqliteWalletRepository) SetWalletShards(ctx context.Context, id string, shards int) error {
+	return fmt.Errorf("SqliteWalletRepository - SetWalletShards: %w", model.ErrShardsNotSupported)
+}
+
+// Reconcile compares the balance of every wallet with the sum of its transactions.
+func (walletRepository *sqliteWalletRepository) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	rows, err := walletRepository.db.QueryContext(
+		ctx,
+		"SELECT w.id, w.name, w.balance, COALESCE(c.amount, 0) - COALESCE(d.amount, 0) FROM wallets w "+
+			"LEFT JOIN (SELECT recipient_wallet_id AS wallet_id, SUM(amount) AS amount FROM transactions GROUP BY recipient_wallet_id) c ON c.wallet_id = w.id "+
+			"LEFT JOIN (SELECT sender_wallet_id AS wallet_id, SUM(amount) AS amount FROM transactions WHERE sender_wallet_id IS NOT NULL GROUP BY sender_wallet_id) d ON d.wallet_id = w.id "+
+			"ORDER BY w.name",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Reconcile - walletRepository.db.QueryContext: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var reconciliations []*model.WalletReconciliation
+	for rows.Next() {
+		reconciliation := new(model.WalletReconciliation)
+		if err = rows.Scan(
+			&reconciliation.WalletId,
+			&reconciliation.WalletName,
+			&reconciliation.Balance,
+			&reconciliation.TransactionsBalance,
+		); err != nil {
+			return nil, fmt.Errorf("SqliteWalletRepository - Reconcile - rows.Scan: %w", err)
+		}
+		reconciliations = append(reconciliations, reconciliation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - Reconcile - rows.Err: %w", err)
+	}
+	return reconciliations, nil
+}
+
+func (walletRepository *sqliteWalletRepository) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+	query := "SELECT id, operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at, reference, api_key_id FROM transactions WHERE (sender_wallet_id = ? OR recipient_wallet_id = ?)"
+	var filterValues []interface{}
+	filterValues = append(filterValues, filter.WalletId, filter.WalletId)
+	if filter.OperationType != model.UnknownOperation {
+		filterValues = append(filterValues, filter.OperationType.String())
+		query += " AND operation_type = ?"
+	}
+	if !filter.ProcessedAtGte.IsZero() {
+		filterValues = append(filterValues, filter.ProcessedAtGte.UTC().Format(sqliteTimeLayout))
+		query += " AND processed_at >= ?"
+	}
+	if !filter.ProcessedAtLte.IsZero() {
+		filterValues = append(filterValues, filter.ProcessedAtLte.UTC().Format(sqliteTimeLayout))
+		query += " AND processed_at <= ?"
+	}
+	query += " ORDER BY processed_at DESC, rowid DESC"
+	// SQLite requires LIMIT for OFFSET, a negative limit means no limit.
+	filterValues = append(filterValues, limit)
+	query += " LIMIT ?"
+	if offset > -1 {
+		filterValues = append(filterValues, offset)
+		query += " OFFSET ?"
+	}
+
+	rows, err := walletRepository.db.QueryContext(
+		ctx,
+		query, filterValues...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetTransactions - walletRepository.db.QueryContext: %w", err)
+	}
+	transactions, err := scanTransactions(sqlRows{rows})
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetTransactions - scanTransactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// GetTransactionsAfter returns the transactions of the wallet committed after the
+// given one, oldest first. Nothing is returned if the given transaction is unknown.
+func (walletRepository *sqliteWalletRepository) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	rows, err := walletRepository.db.QueryContext(
+		ctx,
+		"SELECT id, operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at, reference, api_key_id FROM transactions "+
+			"WHERE (sender_wallet_id = ? OR recipient_wallet_id = ?) AND rowid > (SELECT rowid FROM transactions WHERE id = ?) "+
+			"ORDER BY rowid",
+		walletId,
+		walletId,
+		transactionId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetTransactionsAfter - walletRepository.db.QueryContext: %w", err)
+	}
+	transactions, err := scanTransactions(sqlRows{rows})
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetTransactionsAfter - scanTransactions: %w", err)
+	}
+	return transactions, nil
+}
+
+func (walletRepository *sqliteWalletRepository) GetWalletRole(ctx context.Context, walletId string, subject string) (model.WalletRole, error) {
+	var role string
+	if err := walletRepository.db.QueryRowContext(
+		ctx,
+		"SELECT CASE WHEN w.owner = ? THEN 'owner' ELSE COALESCE(g.role, '') END FROM wallets w "+
+			"LEFT JOIN wallet_grants g ON g.wallet_id = w.id AND g.subject = ? WHERE w.id = ?",
+		subject,
+		subject,
+		walletId,
+	).Scan(&role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.UnknownWalletRole, fmt.Errorf("SqliteWalletRepository - GetWalletRole - walletRepository.db.QueryRowContext: %w", model.ErrWalletNotFound)
+		}
+		return model.UnknownWalletRole, fmt.Errorf("SqliteWalletRepository - GetWalletRole - walletRepository.db.QueryRowContext: %w", err)
+	}
+	return walletRoleFromString(role)
+}
+
+func (walletRepository *sqliteWalletRepository) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	rows, err := walletRepository.db.QueryContext(
+		ctx,
+		"SELECT wallet_id, subject, role, created_at FROM wallet_grants WHERE wallet_id = ? ORDER BY created_at, subject",
+		walletId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetWalletGrants - walletRepository.db.QueryContext: %w", err)
+	}
+	grants, err := scanWalletGrants(sqlRows{rows})
+	if err != nil {
+		return nil, fmt.Errorf("SqliteWalletRepository - GetWalletGrants - scanWalletGrants: %w", err)
+	}
+	return grants, nil
+}
+
+func (walletRepository *sqliteWalletRepository) GrantWalletAccess(ctx context.Context, grant model.WalletGrant) error {
+	result, err := walletRepository.db.ExecContext(
+		ctx,
+		"INSERT INTO wallet_grants(wallet_id, subject, role, created_at) SELECT id, ?, ?, ? FROM wallets WHERE id = ? "+
+			"ON CONFLICT (wallet_id, subject) DO UPDATE SET role = excluded.role",
+		grant.Subject,
+		grant.Role.String(),
+		grant.CreatedAt.UTC().Format(sqliteTimeLayout),
+		grant.WalletId,
+	)
+	if err != nil {
+		return fmt.Errorf("SqliteWalletRepository - GrantWalletAccess - walletRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SqliteWalletRepository - GrantWalletAccess - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("SqliteWalletRepository - GrantWalletAccess - walletRepository.db.ExecContext: %w", model.ErrWalletNotFound)
+	}
+	return nil
+}
+
+func (walletRepository *sqliteWalletRepository) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	result, err := walletRepository.db.ExecContext(
+		ctx,
+		"DELETE FROM wallet_grants WHERE wallet_id = ? AND subject = ?",
+		walletId,
+		subject,
+	)
+	if err != nil {
+		return fmt.Errorf("SqliteWalletRepository - RevokeWalletAccess - walletRepository.db.ExecContext: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("SqliteWalletRepository - RevokeWalletAccess - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("SqliteWalletRepository - RevokeWalletAccess - walletRepository.db.ExecContext: %w", model.ErrWalletGrantNotFound)
+	}
+	return nil
+}
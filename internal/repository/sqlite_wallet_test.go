@@ -0,0 +1,118 @@
+//go:build cgo
+// +build cgo
+
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/database/migrations"
+	"github.com/SergeyChupin/wallets-api/internal/database/sqlite"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSqliteWalletRepository(t *testing.T) {
+	testWalletRepository(t, func(t *testing.T) WalletRepository {
+		return NewSqliteWalletRepository(openTestSqlite(t))
+	})
+}
+
+func TestSqliteApiKeyRepository(t *testing.T) {
+	testApiKeyRepository(t, func(t *testing.T) (ApiKeyRepository, WalletRepository) {
+		db := openTestSqlite(t)
+		return NewSqliteApiKeyRepository(db), NewSqliteWalletRepository(db)
+	})
+}
+
+func TestSqliteOwnerRepository(t *testing.T) {
+	testOwnerRepository(t, func(t *testing.T) (OwnerRepository, WalletRepository) {
+		db := openTestSqlite(t)
+		return NewSqliteOwnerRepository(db), NewSqliteWalletRepository(db)
+	})
+}
+
+func TestSqliteAuditRepository(t *testing.T) {
+	testAuditRepository(t, func(t *testing.T) (AuditRepository, WalletRepository) {
+		db := openTestSqlite(t)
+		return NewSqliteAuditRepository(db), NewSqliteWalletRepository(db)
+	})
+}
+
+func TestSqliteAuditLogAppendOnly(t *testing.T) {
+	// given
+	db := openTestSqlite(t)
+	ctx := context.Background()
+	id, err := NewSqliteAuditRepository(db).CreateAuditEntry(ctx, model.AuditEntry{
+		Actor: "alice", Action: "wallet.create", Outcome: model.AuditSuccess, CreatedAt: time.Now(),
+	})
+	require.NoError(t, err)
+
+	// when
+	_, updateErr := db.ExecContext(ctx, "UPDATE audit_log SET actor = 'bob' WHERE id = ?", id)
+	_, deleteErr := db.ExecContext(ctx, "DELETE FROM audit_log WHERE id = ?", id)
+
+	// then
+	require.Error(t, updateErr)
+	require.Error(t, deleteErr)
+}
+
+func TestSqliteOwnerSubjectMigrationBackfills(t *testing.T) {
+	// given
+	db := openTestSqlite(t)
+	ctx := context.Background()
+	ownerRepository := NewSqliteOwnerRepository(db)
+	walletRepository := NewSqliteWalletRepository(db)
+	createdAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	aliceId, err := ownerRepository.CreateOwner(ctx, model.Owner{Name: "alice", CreatedAt: createdAt, UpdatedAt: createdAt})
+	require.NoError(t, err)
+	mixedId, err := ownerRepository.CreateOwner(ctx, model.Owner{Name: "mixed", CreatedAt: createdAt, UpdatedAt: createdAt})
+	require.NoError(t, err)
+	wallets := []model.Wallet{
+		{Name: "alice-usd", Currency: "USD", Owner: "alice", OwnerId: aliceId},
+		{Name: "alice-eur", Currency: "EUR", Owner: "alice", OwnerId: aliceId},
+		{Name: "mixed-usd", Currency: "USD", Owner: "bob", OwnerId: mixedId},
+		{Name: "mixed-eur", Currency: "EUR", Owner: "carol", OwnerId: mixedId},
+	}
+	for _, wallet := range wallets {
+		_, err := walletRepository.CreateWallet(ctx, wallet)
+		require.NoError(t, err)
+	}
+	migrator := migrations.NewMigrator(logging.Discard(), db, migrations.Sqlite)
+	require.NoError(t, migrator.Down(ctx, 1))
+
+	// when
+	err = migrator.Up(ctx)
+
+	// then
+	require.NoError(t, err)
+	alice, err := ownerRepository.GetOwner(ctx, aliceId)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", alice.Subject)
+	mixed, err := ownerRepository.GetOwner(ctx, mixedId)
+	require.NoError(t, err)
+	assert.Empty(t, mixed.Subject)
+	mixedWallets, err := walletRepository.GetOwnerWallets(ctx, mixedId)
+	require.NoError(t, err)
+	require.Len(t, mixedWallets, 2)
+	assert.ElementsMatch(t, []string{"bob", "carol"}, []string{mixedWallets[0].Owner, mixedWallets[1].Owner})
+}
+
+func openTestSqlite(t *testing.T) *sql.DB {
+	logger := logging.Discard()
+	config := sqlite.NewConfig()
+	config.Path = filepath.Join(t.TempDir(), "wallets.db")
+	db, err := sqlite.Open(logger, config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	require.NoError(t, migrations.NewMigrator(logger, db, migrations.Sqlite).Up(context.Background()))
+	return db
+}
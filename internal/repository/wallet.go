@@ -1,80 +1,213 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/database"
 	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type WalletRepository interface {
-	CreateWallet(wallet model.Wallet) (string, error)
-	Deposit(recipientWalletId string, amount uint64) (*model.Transaction, error)
-	Transfer(senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error)
-	GetTransactions(limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error)
+	CreateWallet(ctx context.Context, wallet model.Wallet) (string, error)
+	GetWallet(ctx context.Context, id string) (*model.Wallet, error)
+	// GetOwnerWallets returns the wallets held by the owner, ordered by currency.
+	GetOwnerWallets(ctx context.Context, ownerId string) ([]*model.Wallet, error)
+	Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error)
+	Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error)
+	GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error)
+	GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error)
+	SetWalletFrozen(ctx context.Context, id string, frozen bool) error
+	SetWalletShards(ctx context.Context, id string, shards int) error
+	Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error)
+	// GetWalletRole returns the role of the subject on the wallet, owner if it
+	// owns the wallet and UnknownWalletRole if it is granted none.
+	GetWalletRole(ctx context.Context, walletId string, subject string) (model.WalletRole, error)
+	GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error)
+	// GrantWalletAccess grants the role to the subject, replacing the role it
+	// was granted before.
+	GrantWalletAccess(ctx context.Context, grant model.WalletGrant) error
+	RevokeWalletAccess(ctx context.Context, walletId string, subject string) error
 }
 
 type walletRepository struct {
-	db *sql.DB
+	db       pgDB
+	replicas Replicas
+}
+
+// Replicas returns the read replica to query, nil if no replica is available.
+type Replicas interface {
+	Next() *sql.DB
 }
 
 func NewWalletRepository(db *sql.DB) *walletRepository {
 	return &walletRepository{
-		db: db,
+		db: newTracedDB(sqlDB{db: db}),
+	}
+}
+
+// NewPgxWalletRepository returns the Postgres repository running on a native
+// pgx pool, it skips the database/sql layer on the hot path.
+func NewPgxWalletRepository(pool *pgxpool.Pool) *walletRepository {
+	return &walletRepository{
+		db: newTracedDB(pgxDB{pool: pool}),
+	}
+}
+
+// WithReplicas routes the read-only methods to the replicas, falling back to
+// the primary if there is no healthy replica or the context asks for primary reads.
+func (walletRepository *walletRepository) WithReplicas(replicas Replicas) *walletRepository {
+	walletRepository.replicas = replicas
+	return walletRepository
+}
+
+func (walletRepository *walletRepository) reader(ctx context.Context) pgQuerier {
+	if walletRepository.replicas == nil || database.PrimaryReads(ctx) {
+		return walletRepository.db
 	}
+	if replica := walletRepository.replicas.Next(); replica != nil {
+		return tracedQuerier{querier: sqlDB{db: replica}}
+	}
+	return walletRepository.db
 }
 
-func (walletRepository *walletRepository) CreateWallet(wallet model.Wallet) (string, error) {
+// createWalletSql creates the wallet and records the audit entry of its
+// creation in a single statement.
+var createWalletSql = "WITH inserted AS (INSERT INTO wallets(name, currency, balance, owner, owner_id) VALUES($1, $2, $3, $4, $5) RETURNING id)" +
+	auditSql(6, "id") + " SELECT id FROM inserted"
+
+func (walletRepository *walletRepository) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return "", fmt.Errorf("WalletRepository - CreateWallet - newPendingAuditEntry: %w", err)
+	}
 	var id string
-	if err := walletRepository.db.QueryRow(
-		"INSERT INTO wallets(name, currency, balance) VALUES($1, $2, $3) RETURNING id",
-		wallet.Name,
-		wallet.Currency,
-		0,
+	if err = walletRepository.db.QueryRow(
+		ctx,
+		createWalletSql,
+		append([]interface{}{
+			wallet.Name,
+			wallet.Currency,
+			0,
+			sql.NullString{String: wallet.Owner, Valid: wallet.Owner != ""},
+			sql.NullString{String: wallet.OwnerId, Valid: wallet.OwnerId != ""},
+		}, pendingAudit.args(now)...)...,
 	).Scan(&id); err != nil {
+		if isPgError(err, uniqueViolation) {
+			return "", fmt.Errorf("WalletRepository - CreateWallet - walletRepository.db.QueryRow: %w", model.ErrWalletAlreadyExists)
+		}
 		return "", fmt.Errorf("WalletRepository - CreateWallet - walletRepository.db.QueryRow: %w", err)
 	}
+	pendingAudit.recorded(id, now)
 	return id, nil
 }
 
-func (walletRepository *walletRepository) Deposit(recipientWalletId string, amount uint64) (*model.Transaction, error) {
-	tx, err := walletRepository.db.Begin()
+var walletColumns = "id, name, currency, balance + " + shardsBalanceSql("wallets.id") + ", COALESCE(owner, ''), COALESCE(owner_id::TEXT, ''), frozen, balance_shards"
+
+func (walletRepository *walletRepository) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	wallet := new(model.Wallet)
+	if err := walletRepository.reader(ctx).QueryRow(
+		ctx,
+		"SELECT "+walletColumns+" FROM wallets WHERE id = $1",
+		id,
+	).Scan(&wallet.ID, &wallet.Name, &wallet.Currency, &wallet.Balance, &wallet.Owner, &wallet.OwnerId, &wallet.Frozen, &wallet.Shards); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("WalletRepository - GetWallet - walletRepository.reader.QueryRow: %w", model.ErrWalletNotFound)
+		}
+		return nil, fmt.Errorf("WalletRepository - GetWallet - walletRepository.reader.QueryRow: %w", err)
+	}
+	return wallet, nil
+}
+
+func (walletRepository *walletRepository) GetOwnerWallets(ctx context.Context, ownerId string) ([]*model.Wallet, error) {
+	rows, err := walletRepository.reader(ctx).Query(
+		ctx,
+		"SELECT "+walletColumns+" FROM wallets WHERE owner_id = $1 ORDER BY currency, name",
+		ownerId,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("WalletRepository - Deposit - walletRepository.db.Begin: %w", err)
+		return nil, fmt.Errorf("WalletRepository - GetOwnerWallets - walletRepository.reader.Query: %w", err)
 	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
+	defer rows.Close()
 
-	now := time.Now().UTC()
+	var wallets []*model.Wallet
+	for rows.Next() {
+		wallet := new(model.Wallet)
+		if err = rows.Scan(
+			&wallet.ID, &wallet.Name, &wallet.Currency, &wallet.Balance, &wallet.Owner, &wallet.OwnerId, &wallet.Frozen, &wallet.Shards,
+		); err != nil {
+			return nil, fmt.Errorf("WalletRepository - GetOwnerWallets - rows.Scan: %w", err)
+		}
+		wallets = append(wallets, wallet)
+	}
 
-	var recipientWalletBalance uint64
-	if err = tx.QueryRow(
-		"UPDATE wallets SET balance = balance + $1 WHERE id = $2 RETURNING balance",
-		amount,
-		recipientWalletId,
-	).Scan(&recipientWalletBalance); err != nil {
-		return nil, fmt.Errorf("WalletRepository - Deposit - tx.QueryRow: %w", err)
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("WalletRepository - GetOwnerWallets - rows.Err: %w", err)
 	}
+	return wallets, nil
+}
 
-	if _, err = tx.Exec(
-		"INSERT INTO transactions(operation_type, amount, recipient_wallet_id, recipient_wallet_balance, processed_at) VALUES($1, $2, $3, $4, $5)",
-		model.Deposit,
-		amount,
-		recipientWalletId,
-		recipientWalletBalance,
-		now,
-	); err != nil {
-		return nil, fmt.Errorf("WalletRepository - Deposit - tx.Exec: %w", err)
+func (walletRepository *walletRepository) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	transaction, err := deposit(ctx, walletRepository.db, recipientWalletId, amount, "", time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - Deposit - deposit: %w", err)
 	}
+	return transaction, nil
+}
 
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("WalletRepository - Deposit - tx.Commit: %w", err)
+// depositSql credits the wallet and records the deposit transaction, its event
+// and its audit entry in a single statement. Nothing is inserted if the wallet
+// is not credited.
+var depositSql = "WITH " + creditSql("$1", "$2", "TRUE") + ", " +
+	"inserted AS (INSERT INTO transactions(operation_type, amount, recipient_wallet_id, recipient_wallet_balance, processed_at, reference, api_key_id) " +
+	"SELECT $3::TEXT, $2, $1, balance, $4::TIMESTAMP, $5::TEXT, $6::UUID FROM credited RETURNING *)" +
+	walletEntriesSql + auditSql(7, "recipient_wallet_id") + transactionEventSql(model.DepositEvent)
+
+// deposit credits the wallet and records the deposit transaction with a single
+// statement, so q is either the pool or a transaction. An empty reference is
+// stored as NULL, the transaction records the API key and the audit entry of ctx.
+func deposit(ctx context.Context, q pgQuerier, recipientWalletId string, amount uint64, reference string, now time.Time) (*model.Transaction, error) {
+	// Postgres keeps microseconds, the returned transaction matches the stored one.
+	now = now.Truncate(time.Microsecond)
+	apiKeyId := auth.ApiKeyId(ctx)
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("deposit - newPendingAuditEntry: %w", err)
 	}
+	var transactionId string
+	var senderWalletBalance *uint64
+	var recipientWalletBalance uint64
+	if err = q.QueryRow(
+		ctx,
+		depositSql,
+		append([]interface{}{
+			recipientWalletId,
+			amount,
+			model.Deposit,
+			now,
+			sql.NullString{String: reference, Valid: reference != ""},
+			sql.NullString{String: apiKeyId, Valid: apiKeyId != ""},
+		}, pendingAudit.args(now)...)...,
+	).Scan(&transactionId, &senderWalletBalance, &recipientWalletBalance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("deposit - q.QueryRow: %w", notUpdatedWalletError(ctx, q, recipientWalletId))
+		}
+		if isTransactionConflict(err) {
+			return nil, fmt.Errorf("deposit - q.QueryRow: %w", model.ErrTransactionConflict)
+		}
+		return nil, fmt.Errorf("deposit - q.QueryRow: %w", err)
+	}
+	pendingAudit.recorded(recipientWalletId, now)
 
 	return &model.Transaction{
+		ID:          transactionId,
 		Amount:      amount,
 		ProcessedAt: now,
 		RecipientWallet: model.Wallet{
@@ -82,71 +215,168 @@ func (walletRepository *walletRepository) Deposit(recipientWalletId string, amou
 			Balance: recipientWalletBalance,
 		},
 		OperationType: model.Deposit,
+		Reference:     reference,
+		ApiKeyId:      apiKeyId,
 	}, nil
 }
 
-func (walletRepository *walletRepository) Transfer(senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
-	tx, err := walletRepository.db.Begin()
-	if err != nil {
-		return nil, fmt.Errorf("WalletRepository - Transfer - walletRepository.db.Begin: %w", err)
+// notUpdatedWalletError tells why the balance of the wallet was not updated:
+// the wallet does not exist or is frozen.
+func notUpdatedWalletError(ctx context.Context, q pgQuerier, walletId string) error {
+	var frozen bool
+	if err := q.QueryRow(
+		ctx,
+		"SELECT frozen FROM wallets WHERE id = $1",
+		walletId,
+	).Scan(&frozen); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.ErrWalletNotFound
+		}
+		return err
 	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
+	if frozen {
+		return model.ErrWalletFrozen
+	}
+	return model.ErrWalletNotFound
+}
 
-	now := time.Now().UTC()
+// transferSql debits the sender, credits the recipient and records the transfer
+// transaction, its event and its audit entry in a single statement. The recipient is credited only
+// if the sender is debited, and a recipient not credited fails the statement on
+// the NOT NULL recipient_wallet_balance, so the sender is never debited alone.
+// A sharded sender is debited from its consolidated balance.
+var transferSql = "WITH sender AS (UPDATE wallets SET balance = balance - $3 WHERE id = $1 AND NOT frozen RETURNING balance), " +
+	creditSql("$2", "$3", "EXISTS (SELECT 1 FROM sender)") + ", " +
+	"inserted AS (INSERT INTO transactions(operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at, api_key_id) " +
+	"SELECT $4::TEXT, $3, $1, sender.balance + " + shardsBalanceSql("$1") + ", $2, (SELECT balance FROM credited), $5::TIMESTAMP, $6::UUID FROM sender RETURNING *)" +
+	walletEntriesSql + auditSql(7, "sender_wallet_id") + transactionEventSql(model.TransferEvent)
 
-	var senderWalletBalance uint64
-	if err = tx.QueryRow(
-		"UPDATE wallets SET balance = balance - $1 WHERE id = $2 RETURNING balance",
-		amount,
-		senderWalletId,
-	).Scan(&senderWalletBalance); err != nil {
-		return nil, fmt.Errorf("WalletRepository - Transfer - tx.QueryRow: %w", err)
+func (walletRepository *walletRepository) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	transaction, err := walletRepository.transfer(ctx, senderWalletId, recipientWalletId, amount)
+	if errors.Is(err, model.ErrInsufficientFunds) {
+		// The funds of a sharded sender may wait in its shards.
+		consolidated, consolidateErr := consolidateWalletShards(ctx, walletRepository.db, senderWalletId)
+		if consolidateErr != nil {
+			return nil, fmt.Errorf("WalletRepository - Transfer - consolidateWalletShards: %w", consolidateErr)
+		}
+		if consolidated {
+			transaction, err = walletRepository.transfer(ctx, senderWalletId, recipientWalletId, amount)
+		}
 	}
-
-	var recipientWalletBalance uint64
-	if err = tx.QueryRow(
-		"UPDATE wallets SET balance = balance + $1 WHERE id = $2 RETURNING balance",
-		amount,
-		recipientWalletId,
-	).Scan(&recipientWalletBalance); err != nil {
-		return nil, fmt.Errorf("WalletRepository - Transfer - tx.QueryRow: %w", err)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - Transfer - walletRepository.transfer: %w", err)
 	}
+	return transaction, nil
+}
 
-	if _, err = tx.Exec(
-		"INSERT INTO transactions(operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at) VALUES($1, $2, $3, $4, $5, $6, $7)",
-		model.Transfer,
-		amount,
-		senderWalletId,
-		senderWalletBalance,
-		recipientWalletId,
-		recipientWalletBalance,
-		now,
-	); err != nil {
-		return nil, fmt.Errorf("WalletRepository - Transfer - tx.Exec: %w", err)
+func (walletRepository *walletRepository) transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	apiKeyId := auth.ApiKeyId(ctx)
+	pendingAudit, err := newPendingAuditEntry(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("transfer - newPendingAuditEntry: %w", err)
 	}
 
-	if err = tx.Commit(); err != nil {
-		return nil, fmt.Errorf("WalletRepository - Transfer - tx.Commit: %w", err)
+	var transactionId string
+	var senderWalletBalance *uint64
+	var recipientWalletBalance uint64
+	if err = walletRepository.db.QueryRow(
+		ctx,
+		transferSql,
+		append([]interface{}{
+			senderWalletId,
+			recipientWalletId,
+			amount,
+			model.Transfer,
+			now,
+			sql.NullString{String: apiKeyId, Valid: apiKeyId != ""},
+		}, pendingAudit.args(now)...)...,
+	).Scan(&transactionId, &senderWalletBalance, &recipientWalletBalance); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("transfer - walletRepository.db.QueryRow: %w", notUpdatedWalletError(ctx, walletRepository.db, senderWalletId))
+		}
+		if isPgError(err, notNullViolation) {
+			return nil, fmt.Errorf("transfer - walletRepository.db.QueryRow: %w", notUpdatedWalletError(ctx, walletRepository.db, recipientWalletId))
+		}
+		if isPgError(err, checkViolation) {
+			return nil, fmt.Errorf("transfer - walletRepository.db.QueryRow: %w", model.ErrInsufficientFunds)
+		}
+		if isTransactionConflict(err) {
+			return nil, fmt.Errorf("transfer - walletRepository.db.QueryRow: %w", model.ErrTransactionConflict)
+		}
+		return nil, fmt.Errorf("transfer - walletRepository.db.QueryRow: %w", err)
 	}
+	pendingAudit.recorded(senderWalletId, now)
 
 	return &model.Transaction{
+		ID:          transactionId,
 		Amount:      amount,
 		ProcessedAt: now,
 		SenderWallet: &model.Wallet{
 			ID:      senderWalletId,
-			Balance: senderWalletBalance,
+			Balance: *senderWalletBalance,
 		},
 		RecipientWallet: model.Wallet{
 			ID:      recipientWalletId,
 			Balance: recipientWalletBalance,
 		},
 		OperationType: model.Transfer,
+		ApiKeyId:      apiKeyId,
 	}, nil
 }
 
+func (walletRepository *walletRepository) SetWalletFrozen(ctx context.Context, id string, frozen bool) error {
+	affected, err := walletRepository.db.Exec(
+		ctx,
+		"UPDATE wallets SET frozen = $1 WHERE id = $2",
+		frozen,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("WalletRepository - SetWalletFrozen - walletRepository.db.Exec: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("WalletRepository - SetWalletFrozen - walletRepository.db.Exec: %w", model.ErrWalletNotFound)
+	}
+	return nil
+}
+
+// Reconcile compares the balance of every wallet with the sum of its transactions.
+func (walletRepository *walletRepository) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	rows, err := walletRepository.reader(ctx).Query(
+		ctx,
+		"SELECT w.id, w.name, w.balance + "+shardsBalanceSql("w.id")+", (COALESCE(c.amount, 0) - COALESCE(d.amount, 0))::BIGINT FROM wallets w "+
+			"LEFT JOIN (SELECT recipient_wallet_id AS wallet_id, SUM(amount) AS amount FROM transactions GROUP BY recipient_wallet_id) c ON c.wallet_id = w.id "+
+			"LEFT JOIN (SELECT sender_wallet_id AS wallet_id, SUM(amount) AS amount FROM transactions WHERE sender_wallet_id IS NOT NULL GROUP BY sender_wallet_id) d ON d.wallet_id = w.id "+
+			"ORDER BY w.name",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - Reconcile - walletRepository.reader.Query: %w", err)
+	}
+	defer rows.Close()
+
+	var reconciliations []*model.WalletReconciliation
+	for rows.Next() {
+		reconciliation := new(model.WalletReconciliation)
+		if err = rows.Scan(
+			&reconciliation.WalletId,
+			&reconciliation.WalletName,
+			&reconciliation.Balance,
+			&reconciliation.TransactionsBalance,
+		); err != nil {
+			return nil, fmt.Errorf("WalletRepository - Reconcile - rows.Scan: %w", err)
+		}
+		reconciliations = append(reconciliations, reconciliation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("WalletRepository - Reconcile - rows.Err: %w", err)
+	}
+	return reconciliations, nil
+}
+
 type transaction struct {
+	id                     string
 	amount                 uint64
 	processedAt            time.Time
 	senderWalletId         sql.NullString
@@ -154,25 +384,28 @@ type transaction struct {
 	recipientWalletId      string
 	recipientWalletBalance uint64
 	operationType          string
+	reference              sql.NullString
+	apiKeyId               sql.NullString
 }
 
-func (walletRepository *walletRepository) GetTransactions(limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
-	query := "SELECT operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at FROM transactions WHERE (sender_wallet_id = $1 OR recipient_wallet_id = $1)"
+func (walletRepository *walletRepository) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+	query := "SELECT t.id, t.operation_type, t.amount, t.sender_wallet_id, t.sender_wallet_balance, t.recipient_wallet_id, t.recipient_wallet_balance, t.processed_at, t.reference, t.api_key_id " +
+		"FROM wallet_entries e JOIN transactions t ON t.id = e.transaction_id WHERE e.wallet_id = $1"
 	var filterValues []interface{}
 	filterValues = append(filterValues, filter.WalletId)
 	if filter.OperationType != model.UnknownOperation {
 		filterValues = append(filterValues, filter.OperationType)
-		query += " AND operation_type = $" + strconv.Itoa(len(filterValues))
+		query += " AND e.operation_type = $" + strconv.Itoa(len(filterValues))
 	}
 	if !filter.ProcessedAtGte.IsZero() {
 		filterValues = append(filterValues, filter.ProcessedAtGte)
-		query += " AND processed_at >= $" + strconv.Itoa(len(filterValues))
+		query += " AND e.processed_at >= $" + strconv.Itoa(len(filterValues))
 	}
 	if !filter.ProcessedAtLte.IsZero() {
 		filterValues = append(filterValues, filter.ProcessedAtLte)
-		query += " AND processed_at <= $" + strconv.Itoa(len(filterValues))
+		query += " AND e.processed_at <= $" + strconv.Itoa(len(filterValues))
 	}
-	query += " ORDER BY processed_at DESC"
+	query += " ORDER BY e.processed_at DESC, e.transaction_id DESC"
 	if limit > -1 {
 		filterValues = append(filterValues, limit)
 		query += " LIMIT $" + strconv.Itoa(len(filterValues))
@@ -182,20 +415,50 @@ func (walletRepository *walletRepository) GetTransactions(limit int, offset int,
 		query += " OFFSET $" + strconv.Itoa(len(filterValues))
 	}
 
-	rows, err := walletRepository.db.Query(
+	rows, err := walletRepository.reader(ctx).Query(
+		ctx,
 		query, filterValues...,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("WalletRepository - GetTransactions - walletRepository.db.Query: %w", err)
+		return nil, fmt.Errorf("WalletRepository - GetTransactions - walletRepository.reader.Query: %w", err)
 	}
-	defer func() {
-		_ = rows.Close()
-	}()
+	transactions, err := scanTransactions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - GetTransactions - scanTransactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// GetTransactionsAfter returns the transactions of the wallet processed after the
+// given one, oldest first. Nothing is returned if the given transaction is unknown.
+func (walletRepository *walletRepository) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	rows, err := walletRepository.reader(ctx).Query(
+		ctx,
+		"SELECT t.id, t.operation_type, t.amount, t.sender_wallet_id, t.sender_wallet_balance, t.recipient_wallet_id, t.recipient_wallet_balance, t.processed_at, t.reference, t.api_key_id "+
+			"FROM wallet_entries e JOIN transactions t ON t.id = e.transaction_id "+
+			"WHERE e.wallet_id = $1 AND (e.processed_at, e.transaction_id) > (SELECT processed_at, transaction_id FROM wallet_entries WHERE wallet_id = $1 AND transaction_id = $2) "+
+			"ORDER BY e.processed_at, e.transaction_id",
+		walletId,
+		transactionId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - GetTransactionsAfter - walletRepository.reader.Query: %w", err)
+	}
+	transactions, err := scanTransactions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - GetTransactionsAfter - scanTransactions: %w", err)
+	}
+	return transactions, nil
+}
+
+func scanTransactions(rows pgRows) ([]*model.Transaction, error) {
+	defer rows.Close()
 
 	var transactions []*model.Transaction
 	for rows.Next() {
 		transactionEntity := transaction{}
-		if err = rows.Scan(
+		if err := rows.Scan(
+			&transactionEntity.id,
 			&transactionEntity.operationType,
 			&transactionEntity.amount,
 			&transactionEntity.senderWalletId,
@@ -203,23 +466,28 @@ func (walletRepository *walletRepository) GetTransactions(limit int, offset int,
 			&transactionEntity.recipientWalletId,
 			&transactionEntity.recipientWalletBalance,
 			&transactionEntity.processedAt,
+			&transactionEntity.reference,
+			&transactionEntity.apiKeyId,
 		); err != nil {
-			return nil, fmt.Errorf("WalletRepository - GetTransactions - rows.Scan: %w", err)
+			return nil, fmt.Errorf("scanTransactions - rows.Scan: %w", err)
 		}
 		operationType, err := model.FromString(transactionEntity.operationType)
 		if err != nil {
-			return nil, fmt.Errorf("WalletRepository - GetTransactions - model.FromString: %w", err)
+			return nil, fmt.Errorf("scanTransactions - model.FromString: %w", err)
 		}
 		transaction := new(model.Transaction)
+		transaction.ID = transactionEntity.id
 		transaction.Amount = transactionEntity.amount
 		transaction.ProcessedAt = transactionEntity.processedAt
 		transaction.RecipientWallet.ID = transactionEntity.recipientWalletId
 		transaction.RecipientWallet.Balance = transactionEntity.recipientWalletBalance
 		transaction.OperationType = operationType
+		transaction.Reference = transactionEntity.reference.String
+		transaction.ApiKeyId = transactionEntity.apiKeyId.String
 		if transactionEntity.senderWalletBalance.Valid {
 			senderWalletBalance, err := strconv.ParseUint(transactionEntity.senderWalletBalance.String, 10, 64)
 			if err != nil {
-				return nil, fmt.Errorf("WalletRepository - GetTransactions - strconv.ParseUint: %w", err)
+				return nil, fmt.Errorf("scanTransactions - strconv.ParseUint: %w", err)
 			}
 			transaction.SenderWallet = &model.Wallet{
 				ID:      transactionEntity.senderWalletId.String,
@@ -229,9 +497,8 @@ func (walletRepository *walletRepository) GetTransactions(limit int, offset int,
 		transactions = append(transactions, transaction)
 	}
 
-	err = rows.Err()
-	if err != nil {
-		return nil, fmt.Errorf("WalletRepository - GetTransactions - rows.Err: %w", err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanTransactions - rows.Err: %w", err)
 	}
 
 	return transactions, nil
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// Access checks read the primary, a grant revoked a moment ago must not be
+// honoured by a lagging replica.
+
+const walletRoleSql = "SELECT CASE WHEN w.owner = $2 THEN 'owner' ELSE COALESCE(g.role, '') END FROM wallets w " +
+	"LEFT JOIN wallet_grants g ON g.wallet_id = w.id AND g.subject = $2 WHERE w.id = $1"
+
+func (walletRepository *walletRepository) GetWalletRole(ctx context.Context, walletId string, subject string) (model.WalletRole, error) {
+	var role string
+	if err := walletRepository.db.QueryRow(ctx, walletRoleSql, walletId, subject).Scan(&role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.UnknownWalletRole, fmt.Errorf("WalletRepository - GetWalletRole - walletRepository.db.QueryRow: %w", model.ErrWalletNotFound)
+		}
+		return model.UnknownWalletRole, fmt.Errorf("WalletRepository - GetWalletRole - walletRepository.db.QueryRow: %w", err)
+	}
+	return walletRoleFromString(role)
+}
+
+func (walletRepository *walletRepository) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	rows, err := walletRepository.db.Query(
+		ctx,
+		"SELECT wallet_id, subject, role, created_at FROM wallet_grants WHERE wallet_id = $1 ORDER BY created_at, subject",
+		walletId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - GetWalletGrants - walletRepository.db.Query: %w", err)
+	}
+	grants, err := scanWalletGrants(rows)
+	if err != nil {
+		return nil, fmt.Errorf("WalletRepository - GetWalletGrants - scanWalletGrants: %w", err)
+	}
+	return grants, nil
+}
+
+func (walletRepository *walletRepository) GrantWalletAccess(ctx context.Context, grant model.WalletGrant) error {
+	affected, err := walletRepository.db.Exec(
+		ctx,
+		"INSERT INTO wallet_grants(wallet_id, subject, role, created_at) SELECT id, $2, $3, $4 FROM wallets WHERE id = $1 "+
+			"ON CONFLICT (wallet_id, subject) DO UPDATE SET role = EXCLUDED.role",
+		grant.WalletId,
+		grant.Subject,
+		grant.Role.String(),
+		grant.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("WalletRepository - GrantWalletAccess - walletRepository.db.Exec: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("WalletRepository - GrantWalletAccess - walletRepository.db.Exec: %w", model.ErrWalletNotFound)
+	}
+	return nil
+}
+
+func (walletRepository *walletRepository) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	affected, err := walletRepository.db.Exec(
+		ctx,
+		"DELETE FROM wallet_grants WHERE wallet_id = $1 AND subject = $2",
+		walletId,
+		subject,
+	)
+	if err != nil {
+		return fmt.Errorf("WalletRepository - RevokeWalletAccess - walletRepository.db.Exec: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("WalletRepository - RevokeWalletAccess - walletRepository.db.Exec: %w", model.ErrWalletGrantNotFound)
+	}
+	return nil
+}
+
+func scanWalletGrants(rows pgRows) ([]*model.WalletGrant, error) {
+	defer rows.Close()
+
+	var grants []*model.WalletGrant
+	for rows.Next() {
+		grant := new(model.WalletGrant)
+		var role string
+		if err := rows.Scan(&grant.WalletId, &grant.Subject, &role, &grant.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanWalletGrants - rows.Scan: %w", err)
+		}
+		var err error
+		if grant.Role, err = model.WalletRoleFromString(role); err != nil {
+			return nil, fmt.Errorf("scanWalletGrants - model.WalletRoleFromString: %w", err)
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scanWalletGrants - rows.Err: %w", err)
+	}
+	return grants, nil
+}
+
+func walletRoleFromString(role string) (model.WalletRole, error) {
+	if role == "" {
+		return model.UnknownWalletRole, nil
+	}
+	walletRole, err := model.WalletRoleFromString(role)
+	if err != nil {
+		return model.UnknownWalletRole, fmt.Errorf("walletRoleFromString - model.WalletRoleFromString: %w", err)
+	}
+	return walletRole, nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/database/postgres"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// The benchmarks compare the single statement deposit and transfer with the
+// transaction of separate statements they replaced, against the database of
+// WALLETS_TEST_POSTGRES_URL:
+//
+//	WALLETS_TEST_POSTGRES_URL=postgres://... make bench
+
+func BenchmarkDeposit(b *testing.B) {
+	benchmarkWalletRepository(b, func(b *testing.B, db pgDB, walletRepository *walletRepository) {
+		ctx := context.Background()
+		walletId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "recipient", Currency: "USD"})
+		require.NoError(b, err)
+
+		b.Run("statement", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := walletRepository.Deposit(ctx, walletId, 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run("transaction", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := depositInTransaction(ctx, db, walletId, 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
+
+// BenchmarkDepositHotWallet deposits concurrently to a single wallet, with and
+// without balance shards.
+func BenchmarkDepositHotWallet(b *testing.B) {
+	benchmarkWalletRepository(b, func(b *testing.B, db pgDB, walletRepository *walletRepository) {
+		ctx := context.Background()
+		for _, shards := range []int{0, 16} {
+			walletId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: fmt.Sprintf("collector-%d", shards), Currency: "USD"})
+			require.NoError(b, err)
+			require.NoError(b, walletRepository.SetWalletShards(ctx, walletId, shards))
+
+			b.Run(fmt.Sprintf("shards-%d", shards), func(b *testing.B) {
+				b.RunParallel(func(pb *testing.PB) {
+					for pb.Next() {
+						if _, err := walletRepository.Deposit(ctx, walletId, 1); err != nil {
+							b.Error(err)
+							return
+						}
+					}
+				})
+			})
+		}
+	})
+}
+
+func BenchmarkTransfer(b *testing.B) {
+	benchmarkWalletRepository(b, func(b *testing.B, db pgDB, walletRepository *walletRepository) {
+		ctx := context.Background()
+		senderWalletId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "sender", Currency: "USD"})
+		require.NoError(b, err)
+		recipientWalletId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "recipient", Currency: "USD"})
+		require.NoError(b, err)
+		_, err = walletRepository.Deposit(ctx, senderWalletId, 1<<40)
+		require.NoError(b, err)
+
+		b.Run("statement", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := walletRepository.Transfer(ctx, senderWalletId, recipientWalletId, 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run("transaction", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := transferInTransaction(ctx, db, senderWalletId, recipientWalletId, 1); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	})
+}
+
+// benchmarkWalletRepository runs the benchmark on both database/sql and pgxpool.
+func benchmarkWalletRepository(b *testing.B, benchmark func(b *testing.B, db pgDB, walletRepository *walletRepository)) {
+	db := openTestPostgres(b)
+	config := postgres.NewConfig()
+	config.Url = os.Getenv(testPostgresUrlEnv)
+	pool, err := postgres.OpenPool(logging.Discard(), config)
+	require.NoError(b, err)
+	b.Cleanup(pool.Close)
+
+	pools := []struct {
+		name             string
+		db               pgDB
+		walletRepository *walletRepository
+	}{
+		{name: postgres.DatabaseSqlPool, db: sqlDB{db: db}, walletRepository: NewWalletRepository(db)},
+		{name: postgres.PgxPool, db: pgxDB{pool: pool}, walletRepository: NewPgxWalletRepository(pool)},
+	}
+	for _, pool := range pools {
+		b.Run(pool.name, func(b *testing.B) {
+			truncateTestPostgres(b, db)
+			benchmark(b, pool.db, pool.walletRepository)
+		})
+	}
+}
+
+// depositInTransaction deposits with a statement per round trip, as deposits were made before.
+func depositInTransaction(ctx context.Context, db pgDB, walletId string, amount uint64) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+	now := time.Now().UTC()
+	payload := transactionEventPayload{OperationType: model.Deposit.String(), Amount: amount, RecipientWalletId: walletId, ProcessedAt: now}
+	if err = tx.QueryRow(
+		ctx,
+		"UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND NOT frozen RETURNING balance",
+		amount, walletId,
+	).Scan(&payload.RecipientWalletBalance); err != nil {
+		return err
+	}
+	if err = tx.QueryRow(
+		ctx,
+		"INSERT INTO transactions(operation_type, amount, recipient_wallet_id, recipient_wallet_balance, processed_at) VALUES($1, $2, $3, $4, $5) RETURNING id",
+		model.Deposit, amount, walletId, payload.RecipientWalletBalance, now,
+	).Scan(&payload.TransactionId); err != nil {
+		return err
+	}
+	if err = recordEventInTransaction(ctx, tx, model.DepositEvent, payload); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// transferInTransaction transfers with a statement per round trip, as transfers were made before.
+func transferInTransaction(ctx context.Context, db pgDB, senderWalletId string, recipientWalletId string, amount uint64) error {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+	now := time.Now().UTC()
+	var senderWalletBalance uint64
+	payload := transactionEventPayload{
+		OperationType:       model.Transfer.String(),
+		Amount:              amount,
+		SenderWalletId:      &senderWalletId,
+		SenderWalletBalance: &senderWalletBalance,
+		RecipientWalletId:   recipientWalletId,
+		ProcessedAt:         now,
+	}
+	if err = tx.QueryRow(
+		ctx,
+		"UPDATE wallets SET balance = balance - $1 WHERE id = $2 AND NOT frozen RETURNING balance",
+		amount, senderWalletId,
+	).Scan(&senderWalletBalance); err != nil {
+		return err
+	}
+	if err = tx.QueryRow(
+		ctx,
+		"UPDATE wallets SET balance = balance + $1 WHERE id = $2 AND NOT frozen RETURNING balance",
+		amount, recipientWalletId,
+	).Scan(&payload.RecipientWalletBalance); err != nil {
+		return err
+	}
+	if err = tx.QueryRow(
+		ctx,
+		"INSERT INTO transactions(operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at) VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id",
+		model.Transfer, amount, senderWalletId, senderWalletBalance, recipientWalletId, payload.RecipientWalletBalance, now,
+	).Scan(&payload.TransactionId); err != nil {
+		return err
+	}
+	if err = recordEventInTransaction(ctx, tx, model.TransferEvent, payload); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func recordEventInTransaction(ctx context.Context, tx pgTx, eventType model.EventType, payload transactionEventPayload) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.Exec(
+		ctx,
+		"INSERT INTO outbox_events(event_type, payload, created_at) VALUES($1, $2, $3)",
+		eventType, string(data), payload.ProcessedAt,
+	); err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, "SELECT pg_notify($1, $2)", TransactionsChannel, string(data))
+	return err
+}
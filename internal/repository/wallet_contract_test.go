@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unknownId is a well-formed id that no wallet or transaction has.
+const unknownId = "00000000-0000-4000-8000-000000000000"
+
+// testWalletRepository is the contract every WalletRepository implementation
+// satisfies, newRepository returns an empty repository.
+func testWalletRepository(t *testing.T, newRepository func(t *testing.T) WalletRepository) {
+	ctx := context.Background()
+
+	createWallet := func(t *testing.T, walletRepository WalletRepository, name string, balance uint64) string {
+		id, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: name, Currency: "USD"})
+		require.NoError(t, err)
+		if balance > 0 {
+			_, err = walletRepository.Deposit(ctx, id, balance)
+			require.NoError(t, err)
+		}
+		return id
+	}
+
+	getBalance := func(t *testing.T, walletRepository WalletRepository, id string) uint64 {
+		wallet, err := walletRepository.GetWallet(ctx, id)
+		require.NoError(t, err)
+		return wallet.Balance
+	}
+
+	t.Run("CreateWallet", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+
+		// when
+		id, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "first", Currency: "USD", Balance: 100})
+
+		// then
+		require.NoError(t, err)
+		wallet, err := walletRepository.GetWallet(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, &model.Wallet{ID: id, Name: "first", Currency: "USD"}, wallet)
+	})
+
+	t.Run("CreateWalletDuplicateName", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		createWallet(t, walletRepository, "first", 0)
+
+		// when
+		_, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "first", Currency: "EUR"})
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletAlreadyExists)
+	})
+
+	t.Run("GetWalletNotFound", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+
+		// when
+		_, err := walletRepository.GetWallet(ctx, unknownId)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletNotFound)
+	})
+
+	t.Run("Deposit", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		id := createWallet(t, walletRepository, "first", 100)
+
+		// when
+		transaction, err := walletRepository.Deposit(ctx, id, 50)
+
+		// then
+		require.NoError(t, err)
+		assert.NotEmpty(t, transaction.ID)
+		assert.Equal(t, model.Deposit, transaction.OperationType)
+		assert.Equal(t, uint64(50), transaction.Amount)
+		assert.Nil(t, transaction.SenderWallet)
+		assert.Equal(t, model.Wallet{ID: id, Balance: 150}, transaction.RecipientWallet)
+		assert.Equal(t, uint64(150), getBalance(t, walletRepository, id))
+	})
+
+	t.Run("DepositNotFound", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+
+		// when
+		_, err := walletRepository.Deposit(ctx, unknownId, 50)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletNotFound)
+	})
+
+	t.Run("DepositFrozen", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		id := createWallet(t, walletRepository, "first", 100)
+		require.NoError(t, walletRepository.SetWalletFrozen(ctx, id, true))
+
+		// when
+		_, err := walletRepository.Deposit(ctx, id, 50)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletFrozen)
+		assert.Equal(t, uint64(100), getBalance(t, walletRepository, id))
+	})
+
+	t.Run("Transfer", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		senderId := createWallet(t, walletRepository, "sender", 100)
+		recipientId := createWallet(t, walletRepository, "recipient", 10)
+
+		// when
+		transaction, err := walletRepository.Transfer(ctx, senderId, recipientId, 30)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, model.Transfer, transaction.OperationType)
+		assert.Equal(t, uint64(30), transaction.Amount)
+		assert.Equal(t, &model.Wallet{ID: senderId, Balance: 70}, transaction.SenderWallet)
+		assert.Equal(t, model.Wallet{ID: recipientId, Balance: 40}, transaction.RecipientWallet)
+		assert.Equal(t, uint64(70), getBalance(t, walletRepository, senderId))
+		assert.Equal(t, uint64(40), getBalance(t, walletRepository, recipientId))
+	})
+
+	t.Run("TransferInsufficientFunds", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		senderId := createWallet(t, walletRepository, "sender", 100)
+		recipientId := createWallet(t, walletRepository, "recipient", 0)
+
+		// when
+		_, err := walletRepository.Transfer(ctx, senderId, recipientId, 101)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+		assert.Equal(t, uint64(100), getBalance(t, walletRepository, senderId))
+		assert.Equal(t, uint64(0), getBalance(t, walletRepository, recipientId))
+	})
+
+	t.Run("TransferRecipientNotFound", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		senderId := createWallet(t, walletRepository, "sender", 100)
+
+		// when
+		_, err := walletRepository.Transfer(ctx, senderId, unknownId, 50)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletNotFound)
+		assert.Equal(t, uint64(100), getBalance(t, walletRepository, senderId))
+	})
+
+	t.Run("TransferRecipientFrozen", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		senderId := createWallet(t, walletRepository, "sender", 100)
+		recipientId := createWallet(t, walletRepository, "recipient", 0)
+		require.NoError(t, walletRepository.SetWalletFrozen(ctx, recipientId, true))
+
+		// when
+		_, err := walletRepository.Transfer(ctx, senderId, recipientId, 50)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletFrozen)
+		assert.Equal(t, uint64(100), getBalance(t, walletRepository, senderId))
+	})
+
+	t.Run("TransferConcurrent", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		senderId := createWallet(t, walletRepository, "sender", 10)
+		recipientId := createWallet(t, walletRepository, "recipient", 0)
+
+		// when
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		succeeded, insufficient := 0, 0
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := walletRepository.Transfer(ctx, senderId, recipientId, 1)
+				mu.Lock()
+				defer mu.Unlock()
+				if err == nil {
+					succeeded++
+				} else if assert.ErrorIs(t, err, model.ErrInsufficientFunds) {
+					insufficient++
+				}
+			}()
+		}
+		wg.Wait()
+
+		// then
+		assert.Equal(t, 10, succeeded)
+		assert.Equal(t, 10, insufficient)
+		assert.Equal(t, uint64(0), getBalance(t, walletRepository, senderId))
+		assert.Equal(t, uint64(10), getBalance(t, walletRepository, recipientId))
+	})
+
+	t.Run("GetTransactions", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		firstId := createWallet(t, walletRepository, "first", 0)
+		secondId := createWallet(t, walletRepository, "second", 0)
+		otherId := createWallet(t, walletRepository, "other", 0)
+		deposit, err := walletRepository.Deposit(ctx, firstId, 100)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		transfer, err := walletRepository.Transfer(ctx, firstId, secondId, 30)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		_, err = walletRepository.Deposit(ctx, otherId, 100)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		lastDeposit, err := walletRepository.Deposit(ctx, firstId, 5)
+		require.NoError(t, err)
+
+		transactionIds := func(transactions []*model.Transaction) []string {
+			ids := make([]string, 0, len(transactions))
+			for _, transaction := range transactions {
+				ids = append(ids, transaction.ID)
+			}
+			return ids
+		}
+
+		// when
+		all, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: firstId})
+		require.NoError(t, err)
+		page, err := walletRepository.GetTransactions(ctx, 1, 1, model.TransactionFilter{WalletId: firstId})
+		require.NoError(t, err)
+		deposits, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{
+			WalletId:      firstId,
+			OperationType: model.Deposit,
+		})
+		require.NoError(t, err)
+		recipient, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: secondId})
+		require.NoError(t, err)
+		window, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{
+			WalletId:       firstId,
+			ProcessedAtGte: transfer.ProcessedAt.Add(-time.Microsecond),
+			ProcessedAtLte: transfer.ProcessedAt.Add(time.Microsecond),
+		})
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, []string{lastDeposit.ID, transfer.ID, deposit.ID}, transactionIds(all))
+		assert.Equal(t, []string{transfer.ID}, transactionIds(page))
+		assert.Equal(t, []string{lastDeposit.ID, deposit.ID}, transactionIds(deposits))
+		assert.Equal(t, []string{transfer.ID}, transactionIds(recipient))
+		assert.Equal(t, []string{transfer.ID}, transactionIds(window))
+	})
+
+	t.Run("GetTransactionsAfter", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		firstId := createWallet(t, walletRepository, "first", 0)
+		secondId := createWallet(t, walletRepository, "second", 0)
+		deposit, err := walletRepository.Deposit(ctx, firstId, 100)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		_, err = walletRepository.Deposit(ctx, secondId, 100)
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+		transfer, err := walletRepository.Transfer(ctx, secondId, firstId, 30)
+		require.NoError(t, err)
+
+		// when
+		after, err := walletRepository.GetTransactionsAfter(ctx, firstId, deposit.ID)
+		require.NoError(t, err)
+		unknown, err := walletRepository.GetTransactionsAfter(ctx, firstId, unknownId)
+		require.NoError(t, err)
+
+		// then
+		require.Len(t, after, 1)
+		assert.Equal(t, transfer.ID, after[0].ID)
+		assert.Empty(t, unknown)
+	})
+
+	t.Run("SetWalletFrozenNotFound", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+
+		// when
+		err := walletRepository.SetWalletFrozen(ctx, unknownId, true)
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletNotFound)
+	})
+
+	t.Run("Reconcile", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		secondId := createWallet(t, walletRepository, "second", 100)
+		firstId := createWallet(t, walletRepository, "first", 50)
+		_, err := walletRepository.Transfer(ctx, secondId, firstId, 30)
+		require.NoError(t, err)
+
+		// when
+		reconciliations, err := walletRepository.Reconcile(ctx)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []*model.WalletReconciliation{
+			{WalletId: firstId, WalletName: "first", Balance: 80, TransactionsBalance: 80},
+			{WalletId: secondId, WalletName: "second", Balance: 70, TransactionsBalance: 70},
+		}, reconciliations)
+	})
+
+	t.Run("GetWalletRole", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		id, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "first", Currency: "USD", Owner: "user-1"})
+		require.NoError(t, err)
+		createdAt := time.Now().UTC().Truncate(time.Millisecond)
+		require.NoError(t, walletRepository.GrantWalletAccess(ctx, model.WalletGrant{
+			WalletId: id, Subject: "user-2", Role: model.ViewerRole, CreatedAt: createdAt,
+		}))
+		require.NoError(t, walletRepository.GrantWalletAccess(ctx, model.WalletGrant{
+			WalletId: id, Subject: "user-2", Role: model.SpenderRole, CreatedAt: createdAt.Add(time.Second),
+		}))
+
+		// when
+		ownerRole, ownerErr := walletRepository.GetWalletRole(ctx, id, "user-1")
+		spenderRole, spenderErr := walletRepository.GetWalletRole(ctx, id, "user-2")
+		noRole, noRoleErr := walletRepository.GetWalletRole(ctx, id, "user-3")
+		_, notFoundErr := walletRepository.GetWalletRole(ctx, unknownId, "user-1")
+
+		// then
+		require.NoError(t, ownerErr)
+		require.NoError(t, spenderErr)
+		require.NoError(t, noRoleErr)
+		assert.Equal(t, model.OwnerRole, ownerRole)
+		assert.Equal(t, model.SpenderRole, spenderRole)
+		assert.Equal(t, model.UnknownWalletRole, noRole)
+		assert.ErrorIs(t, notFoundErr, model.ErrWalletNotFound)
+		wallet, err := walletRepository.GetWallet(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", wallet.Owner)
+		grants, err := walletRepository.GetWalletGrants(ctx, id)
+		require.NoError(t, err)
+		if assert.Len(t, grants, 1) {
+			assert.Equal(t, "user-2", grants[0].Subject)
+			assert.Equal(t, model.SpenderRole, grants[0].Role)
+			assert.WithinDuration(t, createdAt, grants[0].CreatedAt, time.Millisecond)
+		}
+	})
+
+	t.Run("GrantWalletAccessNotFound", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+
+		// when
+		err := walletRepository.GrantWalletAccess(ctx, model.WalletGrant{
+			WalletId: unknownId, Subject: "user-2", Role: model.ViewerRole, CreatedAt: time.Now().UTC(),
+		})
+
+		// then
+		assert.ErrorIs(t, err, model.ErrWalletNotFound)
+	})
+
+	t.Run("RevokeWalletAccess", func(t *testing.T) {
+		// given
+		walletRepository := newRepository(t)
+		id := createWallet(t, walletRepository, "first", 0)
+		require.NoError(t, walletRepository.GrantWalletAccess(ctx, model.WalletGrant{
+			WalletId: id, Subject: "user-2", Role: model.ViewerRole, CreatedAt: time.Now().UTC(),
+		}))
+
+		// when
+		err := walletRepository.RevokeWalletAccess(ctx, id, "user-2")
+		otherErr := walletRepository.RevokeWalletAccess(ctx, id, "user-2")
+
+		// then
+		assert.NoError(t, err)
+		assert.ErrorIs(t, otherErr, model.ErrWalletGrantNotFound)
+		role, err := walletRepository.GetWalletRole(ctx, id, "user-2")
+		require.NoError(t, err)
+		assert.Equal(t, model.UnknownWalletRole, role)
+	})
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+// A wallet entry is the side of a transaction seen by one wallet: the amount,
+// negative for the sender, and the balance after the transaction. The history of
+// a wallet is a range of the wallet_entries_history_idx index.
+
+// walletEntriesSql records the wallet entries of the transactions returned by the
+// inserted CTE of a statement.
+const walletEntriesSql = ", entries AS (INSERT INTO wallet_entries(wallet_id, transaction_id, operation_type, amount, balance, processed_at) " +
+	"SELECT recipient_wallet_id, id, operation_type, amount, recipient_wallet_balance, processed_at FROM inserted UNION ALL " +
+	"SELECT sender_wallet_id, id, operation_type, -amount, sender_wallet_balance, processed_at FROM inserted WHERE sender_wallet_id IS NOT NULL)"
+
+// FirstTransactionId precedes the ids of all transactions, backfilling starts after it.
+const FirstTransactionId = "00000000-0000-0000-0000-000000000000"
+
+// BackfillWalletEntries records the missing wallet entries of up to limit
+// transactions following the after one in the order of ids. It returns the last
+// transaction backfilled and the number of transactions, 0 once all are done.
+func (walletRepository *walletRepository) BackfillWalletEntries(ctx context.Context, after string, limit int) (string, int, error) {
+	var last string
+	var backfilled int
+	if err := walletRepository.db.QueryRow(
+		ctx,
+		"WITH batch AS (SELECT id, operation_type, amount, sender_wallet_id, sender_wallet_balance, recipient_wallet_id, recipient_wallet_balance, processed_at "+
+			"FROM transactions WHERE id > $1 ORDER BY id LIMIT $2), "+
+			"entries AS (INSERT INTO wallet_entries(wallet_id, transaction_id, operation_type, amount, balance, processed_at) "+
+			"SELECT recipient_wallet_id, id, operation_type, amount, recipient_wallet_balance, processed_at FROM batch UNION ALL "+
+			"SELECT sender_wallet_id, id, operation_type, -amount, sender_wallet_balance, processed_at FROM batch WHERE sender_wallet_id IS NOT NULL "+
+			"ON CONFLICT (wallet_id, transaction_id) DO NOTHING) "+
+			"SELECT COALESCE((SELECT id::TEXT FROM batch ORDER BY id DESC LIMIT 1), ''), (SELECT COUNT(*) FROM batch)",
+		after,
+		limit,
+	).Scan(&last, &backfilled); err != nil {
+		return "", 0, fmt.Errorf("WalletRepository - BackfillWalletEntries - walletRepository.db.QueryRow: %w", err)
+	}
+	return last, backfilled, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+)
+
+// Deposits to a hot wallet serialize on the lock of its wallets row. A sharded
+// wallet, one with balance_shards > 0, is credited to a random one of its
+// wallet_balance_shards rows instead, and is debited from the consolidated
+// balance of its wallets row. Its balance is the sum of both, the shards are
+// merged into the consolidated balance by the consolidation job or when a
+// debit needs them.
+
+// WalletShardRepository consolidates the balance shards of sharded wallets.
+type WalletShardRepository interface {
+	ConsolidateWalletShards(ctx context.Context, limit int) (int, error)
+}
+
+// shardsBalanceSql sums the balance shards of the wallet.
+func shardsBalanceSql(walletId string) string {
+	return "(SELECT COALESCE(SUM(balance), 0) FROM wallet_balance_shards WHERE wallet_id = " + walletId + ")"
+}
+
+// creditSql credits the amount to the wallet, provided that the guard holds, in the
+// CTEs of a statement. The credited CTE returns the balance of the credited wallet,
+// no row if the wallet does not exist or is frozen. The balance of a sharded wallet
+// includes the other shards as of the start of the statement.
+func creditSql(walletId string, amount string, guard string) string {
+	return "recipient_wallet AS (SELECT balance, balance_shards FROM wallets " +
+		"WHERE id = " + walletId + " AND NOT frozen AND balance_shards > 0 AND " + guard + "), " +
+		"recipient AS (UPDATE wallets SET balance = balance + " + amount + " " +
+		"WHERE id = " + walletId + " AND NOT frozen AND balance_shards = 0 AND " + guard + " RETURNING balance), " +
+		"recipient_shard AS (INSERT INTO wallet_balance_shards(wallet_id, shard, balance) " +
+		"SELECT " + walletId + ", floor(random() * balance_shards)::INT, " + amount + " FROM recipient_wallet " +
+		"ON CONFLICT (wallet_id, shard) DO UPDATE SET balance = wallet_balance_shards.balance + EXCLUDED.balance RETURNING shard, balance), " +
+		"credited AS (SELECT balance FROM recipient UNION ALL " +
+		"SELECT s.balance + w.balance + (SELECT COALESCE(SUM(balance), 0) FROM wallet_balance_shards " +
+		"WHERE wallet_id = " + walletId + " AND shard <> s.shard) FROM recipient_shard s, recipient_wallet w)"
+}
+
+// SetWalletShards shards the balance of the wallet into the number of shards,
+// 0 stops sharding. Shards left over are merged by the consolidation.
+func (walletRepository *walletRepository) SetWalletShards(ctx context.Context, id string, shards int) error {
+	affected, err := walletRepository.db.Exec(
+		ctx,
+		"UPDATE wallets SET balance_shards = $1 WHERE id = $2",
+		shards,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("WalletRepository - SetWalletShards - walletRepository.db.Exec: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("WalletRepository - SetWalletShards - walletRepository.db.Exec: %w", model.ErrWalletNotFound)
+	}
+	return nil
+}
+
+// ConsolidateWalletShards merges the balance shards of up to limit wallets into
+// their consolidated balances and returns the number of wallets merged.
+func (walletRepository *walletRepository) ConsolidateWalletShards(ctx context.Context, limit int) (int, error) {
+	rows, err := walletRepository.db.Query(
+		ctx,
+		"SELECT DISTINCT wallet_id FROM wallet_balance_shards WHERE balance > 0 LIMIT $1",
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("WalletRepository - ConsolidateWalletShards - walletRepository.db.Query: %w", err)
+	}
+	var walletIds []string
+	for rows.Next() {
+		var walletId string
+		if err = rows.Scan(&walletId); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("WalletRepository - ConsolidateWalletShards - rows.Scan: %w", err)
+		}
+		walletIds = append(walletIds, walletId)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("WalletRepository - ConsolidateWalletShards - rows.Err: %w", err)
+	}
+
+	consolidated := 0
+	for _, walletId := range walletIds {
+		merged, err := consolidateWalletShards(ctx, walletRepository.db, walletId)
+		if err != nil {
+			return consolidated, fmt.Errorf("WalletRepository - ConsolidateWalletShards - consolidateWalletShards: %w", err)
+		}
+		if merged {
+			consolidated++
+		}
+	}
+	return consolidated, nil
+}
+
+// consolidateWalletShards merges the balance shards of the wallet into its
+// consolidated balance in a single statement. It locks the shards before the
+// wallet, a wallet at a time, so it does not deadlock with transfers that lock
+// the sender before the shard of the recipient.
+func consolidateWalletShards(ctx context.Context, q pgQuerier, walletId string) (bool, error) {
+	affected, err := q.Exec(
+		ctx,
+		"WITH pending AS (SELECT shard, balance FROM wallet_balance_shards WHERE wallet_id = $1 AND balance > 0 FOR UPDATE), "+
+			"drained AS (UPDATE wallet_balance_shards s SET balance = 0 FROM pending p "+
+			"WHERE s.wallet_id = $1 AND s.shard = p.shard RETURNING p.balance) "+
+			"UPDATE wallets SET balance = balance + (SELECT SUM(balance) FROM drained) "+
+			"WHERE id = $1 AND EXISTS (SELECT 1 FROM drained)",
+		walletId,
+	)
+	if err != nil {
+		return false, fmt.Errorf("consolidateWalletShards - q.Exec: %w", err)
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/database/migrations"
+	"github.com/SergeyChupin/wallets-api/internal/database/postgres"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPostgresUrlEnv names the database the Postgres tests run against,
+// every test truncates its tables.
+const testPostgresUrlEnv = "WALLETS_TEST_POSTGRES_URL"
+
+func openTestPostgres(t testing.TB) *sql.DB {
+	url := os.Getenv(testPostgresUrlEnv)
+	if url == "" {
+		t.Skip(testPostgresUrlEnv + " is not set")
+	}
+	logger := logging.Discard()
+	config := postgres.NewConfig()
+	config.Url = url
+	db, err := postgres.Open(logger, config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+	require.NoError(t, migrations.NewMigrator(logger, db, migrations.Postgres).Up(context.Background()))
+	return db
+}
+
+// truncateTestPostgres empties the tables of the test database.
+func truncateTestPostgres(t testing.TB, db *sql.DB) {
+	_, err := db.Exec("TRUNCATE wallets, wallet_balance_shards, wallet_entries, transactions, deposit_imports, deposit_import_rows, deposit_references, " +
+		"outbox_events, webhook_subscriptions, webhook_deliveries, api_keys, wallet_grants, owners, audit_log")
+	require.NoError(t, err)
+}
+
+func TestWalletRepository(t *testing.T) {
+	db := openTestPostgres(t)
+	testWalletRepository(t, func(t *testing.T) WalletRepository {
+		truncateTestPostgres(t, db)
+		return NewWalletRepository(db)
+	})
+}
+
+func TestPgxWalletRepository(t *testing.T) {
+	db := openTestPostgres(t)
+	config := postgres.NewConfig()
+	config.Url = os.Getenv(testPostgresUrlEnv)
+	pool, err := postgres.OpenPool(logging.Discard(), config)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+	testWalletRepository(t, func(t *testing.T) WalletRepository {
+		truncateTestPostgres(t, db)
+		return NewPgxWalletRepository(pool)
+	})
+}
+
+func TestApiKeyRepository(t *testing.T) {
+	db := openTestPostgres(t)
+	testApiKeyRepository(t, func(t *testing.T) (ApiKeyRepository, WalletRepository) {
+		truncateTestPostgres(t, db)
+		return NewApiKeyRepository(db), NewWalletRepository(db)
+	})
+}
+
+func TestOwnerRepository(t *testing.T) {
+	db := openTestPostgres(t)
+	testOwnerRepository(t, func(t *testing.T) (OwnerRepository, WalletRepository) {
+		truncateTestPostgres(t, db)
+		return NewOwnerRepository(db), NewWalletRepository(db)
+	})
+}
+
+func TestAuditRepository(t *testing.T) {
+	db := openTestPostgres(t)
+	testAuditRepository(t, func(t *testing.T) (AuditRepository, WalletRepository) {
+		truncateTestPostgres(t, db)
+		return NewAuditRepository(db), NewWalletRepository(db)
+	})
+}
+
+func TestWalletRepositoryShards(t *testing.T) {
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	walletRepository := NewWalletRepository(db)
+	ctx := context.Background()
+	collectorId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "collector", Currency: "USD"})
+	require.NoError(t, err)
+	merchantId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "merchant", Currency: "USD"})
+	require.NoError(t, err)
+	require.NoError(t, walletRepository.SetWalletShards(ctx, collectorId, 4))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := walletRepository.Deposit(ctx, collectorId, 10)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	collector, err := walletRepository.GetWallet(ctx, collectorId)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(200), collector.Balance)
+	assert.Equal(t, 4, collector.Shards)
+
+	// The consolidated balance is empty, the transfer merges the shards first.
+	transaction, err := walletRepository.Transfer(ctx, collectorId, merchantId, 150)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50), transaction.SenderWallet.Balance)
+
+	_, err = walletRepository.Transfer(ctx, collectorId, merchantId, 100)
+	assert.ErrorIs(t, err, model.ErrInsufficientFunds)
+
+	_, err = walletRepository.Deposit(ctx, collectorId, 25)
+	require.NoError(t, err)
+	consolidated, err := walletRepository.ConsolidateWalletShards(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, consolidated)
+
+	collector, err = walletRepository.GetWallet(ctx, collectorId)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(75), collector.Balance)
+	reconciliations, err := walletRepository.Reconcile(ctx)
+	require.NoError(t, err)
+	for _, reconciliation := range reconciliations {
+		assert.True(t, reconciliation.Balanced(), reconciliation.WalletName)
+	}
+}
+
+func TestWalletEntriesMigrationBackfills(t *testing.T) {
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	walletRepository := NewWalletRepository(db)
+	ctx := context.Background()
+	firstId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "first", Currency: "USD"})
+	require.NoError(t, err)
+	secondId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "second", Currency: "USD"})
+	require.NoError(t, err)
+	_, err = walletRepository.Deposit(ctx, firstId, 100)
+	require.NoError(t, err)
+	_, err = walletRepository.Transfer(ctx, firstId, secondId, 30)
+	require.NoError(t, err)
+	expected, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: firstId})
+	require.NoError(t, err)
+
+	migrator := migrations.NewMigrator(logging.Discard(), db, migrations.Postgres)
+	latest, err := migrations.LatestVersion(migrations.Postgres)
+	require.NoError(t, err)
+	require.NoError(t, migrator.Down(ctx, latest-5))
+	require.NoError(t, migrator.Up(ctx))
+
+	var entries int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM wallet_entries").Scan(&entries))
+	assert.Equal(t, 3, entries)
+	transactions, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: firstId})
+	require.NoError(t, err)
+	assert.Equal(t, expected, transactions)
+}
+
+func TestBackfillWalletEntries(t *testing.T) {
+	db := openTestPostgres(t)
+	truncateTestPostgres(t, db)
+	walletRepository := NewWalletRepository(db)
+	ctx := context.Background()
+	firstId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "first", Currency: "USD"})
+	require.NoError(t, err)
+	secondId, err := walletRepository.CreateWallet(ctx, model.Wallet{Name: "second", Currency: "USD"})
+	require.NoError(t, err)
+	_, err = walletRepository.Deposit(ctx, firstId, 100)
+	require.NoError(t, err)
+	_, err = walletRepository.Transfer(ctx, firstId, secondId, 30)
+	require.NoError(t, err)
+	_, err = walletRepository.Transfer(ctx, secondId, firstId, 10)
+	require.NoError(t, err)
+	expected, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: firstId})
+	require.NoError(t, err)
+	_, err = db.Exec("DELETE FROM wallet_entries")
+	require.NoError(t, err)
+
+	after, batches := FirstTransactionId, 0
+	for {
+		last, backfilled, err := walletRepository.BackfillWalletEntries(ctx, after, 2)
+		require.NoError(t, err)
+		if backfilled == 0 {
+			break
+		}
+		after = last
+		batches++
+	}
+
+	assert.Equal(t, 2, batches)
+	var entries int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM wallet_entries").Scan(&entries))
+	assert.Equal(t, 5, entries)
+	transactions, err := walletRepository.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: firstId})
+	require.NoError(t, err)
+	assert.Equal(t, expected, transactions)
+}
@@ -0,0 +1,350 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/jackc/pgtype"
+)
+
+type WebhookRepository interface {
+	CreateSubscription(subscription model.WebhookSubscription) (string, error)
+	GetSubscriptions() ([]*model.WebhookSubscription, error)
+	DeactivateSubscription(id string) error
+	GetDeliveries(subscriptionId string, status model.WebhookDeliveryStatus, limit int, offset int) ([]*model.WebhookDelivery, error)
+	ReplayDelivery(subscriptionId string, id string) error
+	ReplayDeadDeliveries(subscriptionId string) (int, error)
+	FanOutEvents(limit int) (int, error)
+	ClaimDueDeliveries(limit int, lease time.Duration) ([]*model.WebhookDelivery, error)
+	UpdateDelivery(delivery *model.WebhookDelivery) error
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *webhookRepository {
+	return &webhookRepository{
+		db: db,
+	}
+}
+
+func (webhookRepository *webhookRepository) CreateSubscription(subscription model.WebhookSubscription) (string, error) {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, eventType.String())
+	}
+	var eventTypesArray pgtype.TextArray
+	if err := eventTypesArray.Set(eventTypes); err != nil {
+		return "", fmt.Errorf("WebhookRepository - CreateSubscription - eventTypesArray.Set: %w", err)
+	}
+	var id string
+	if err := webhookRepository.db.QueryRow(
+		"INSERT INTO webhook_subscriptions(url, secret, event_types, created_at) VALUES($1, $2, $3, $4) RETURNING id",
+		subscription.Url,
+		subscription.Secret,
+		&eventTypesArray,
+		subscription.CreatedAt,
+	).Scan(&id); err != nil {
+		return "", fmt.Errorf("WebhookRepository - CreateSubscription - webhookRepository.db.QueryRow: %w", err)
+	}
+	return id, nil
+}
+
+func (webhookRepository *webhookRepository) GetSubscriptions() ([]*model.WebhookSubscription, error) {
+	rows, err := webhookRepository.db.Query(
+		"SELECT id, url, secret, event_types, active, created_at FROM webhook_subscriptions WHERE active ORDER BY created_at",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepository - GetSubscriptions - webhookRepository.db.Query: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var subscriptions []*model.WebhookSubscription
+	for rows.Next() {
+		subscription := new(model.WebhookSubscription)
+		var eventTypesArray pgtype.TextArray
+		if err = rows.Scan(
+			&subscription.ID,
+			&subscription.Url,
+			&subscription.Secret,
+			&eventTypesArray,
+			&subscription.Active,
+			&subscription.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("WebhookRepository - GetSubscriptions - rows.Scan: %w", err)
+		}
+		for _, element := range eventTypesArray.Elements {
+			eventType, err := model.EventTypeFromString(element.String)
+			if err != nil {
+				return nil, fmt.Errorf("WebhookRepository - GetSubscriptions - model.EventTypeFromString: %w", err)
+			}
+			subscription.EventTypes = append(subscription.EventTypes, eventType)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("WebhookRepository - GetSubscriptions - rows.Err: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (webhookRepository *webhookRepository) DeactivateSubscription(id string) error {
+	result, err := webhookRepository.db.Exec(
+		"UPDATE webhook_subscriptions SET active = FALSE WHERE id = $1 AND active",
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("WebhookRepository - DeactivateSubscription - webhookRepository.db.Exec: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("WebhookRepository - DeactivateSubscription - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("WebhookRepository - DeactivateSubscription - webhookRepository.db.Exec: %w", model.ErrWebhookNotFound)
+	}
+	return nil
+}
+
+func (webhookRepository *webhookRepository) GetDeliveries(
+	subscriptionId string, status model.WebhookDeliveryStatus, limit int, offset int,
+) ([]*model.WebhookDelivery, error) {
+	query := "SELECT d.id, d.subscription_id, s.url, d.status, d.attempts, d.next_attempt_at, d.last_error, d.delivered_at, e.id, e.event_type, e.payload, e.created_at " +
+		"FROM webhook_deliveries d JOIN webhook_subscriptions s ON s.id = d.subscription_id JOIN outbox_events e ON e.id = d.event_id " +
+		"WHERE d.subscription_id = $1 AND ($2 = '' OR d.status = $2) ORDER BY e.created_at DESC"
+	values := []interface{}{subscriptionId, status.String()}
+	if limit > -1 {
+		values = append(values, limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(values))
+	}
+	if offset > -1 {
+		values = append(values, offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(values))
+	}
+	rows, err := webhookRepository.db.Query(query, values...)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepository - GetDeliveries - webhookRepository.db.Query: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery := new(model.WebhookDelivery)
+		var deliveryStatus, eventType string
+		var lastError sql.NullString
+		var deliveredAt sql.NullTime
+		if err = rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionId,
+			&delivery.Url,
+			&deliveryStatus,
+			&delivery.Attempts,
+			&delivery.NextAttemptAt,
+			&lastError,
+			&deliveredAt,
+			&delivery.Event.ID,
+			&eventType,
+			&delivery.Event.Payload,
+			&delivery.Event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("WebhookRepository - GetDeliveries - rows.Scan: %w", err)
+		}
+		if delivery.Status, err = model.WebhookDeliveryStatusFromString(deliveryStatus); err != nil {
+			return nil, fmt.Errorf("WebhookRepository - GetDeliveries - model.WebhookDeliveryStatusFromString: %w", err)
+		}
+		if delivery.Event.Type, err = model.EventTypeFromString(eventType); err != nil {
+			return nil, fmt.Errorf("WebhookRepository - GetDeliveries - model.EventTypeFromString: %w", err)
+		}
+		delivery.LastError = lastError.String
+		if deliveredAt.Valid {
+			delivery.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("WebhookRepository - GetDeliveries - rows.Err: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (webhookRepository *webhookRepository) ReplayDelivery(subscriptionId string, id string) error {
+	result, err := webhookRepository.db.Exec(
+		"UPDATE webhook_deliveries SET status = $1, attempts = 0, next_attempt_at = $2, last_error = NULL, delivered_at = NULL WHERE id = $3 AND subscription_id = $4",
+		model.WebhookDeliveryPending,
+		time.Now().UTC(),
+		id,
+		subscriptionId,
+	)
+	if err != nil {
+		return fmt.Errorf("WebhookRepository - ReplayDelivery - webhookRepository.db.Exec: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("WebhookRepository - ReplayDelivery - result.RowsAffected: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("WebhookRepository - ReplayDelivery - webhookRepository.db.Exec: %w", model.ErrDeliveryNotFound)
+	}
+	return nil
+}
+
+func (webhookRepository *webhookRepository) ReplayDeadDeliveries(subscriptionId string) (int, error) {
+	result, err := webhookRepository.db.Exec(
+		"UPDATE webhook_deliveries SET status = $1, attempts = 0, next_attempt_at = $2, last_error = NULL WHERE subscription_id = $3 AND status = $4",
+		model.WebhookDeliveryPending,
+		time.Now().UTC(),
+		subscriptionId,
+		model.WebhookDeliveryDead,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookRepository - ReplayDeadDeliveries - webhookRepository.db.Exec: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("WebhookRepository - ReplayDeadDeliveries - result.RowsAffected: %w", err)
+	}
+	return int(affected), nil
+}
+
+// FanOutEvents turns the oldest undispatched outbox events into deliveries for
+// every active subscription of the event type. Events are locked with SKIP LOCKED,
+// so several dispatchers can run against the same database.
+func (webhookRepository *webhookRepository) FanOutEvents(limit int) (int, error) {
+	tx, err := webhookRepository.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("WebhookRepository - FanOutEvents - webhookRepository.db.Begin: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	now := time.Now().UTC()
+	rows, err := tx.Query(
+		"SELECT id, event_type FROM outbox_events WHERE dispatched_at IS NULL ORDER BY created_at LIMIT $1 FOR UPDATE SKIP LOCKED",
+		limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookRepository - FanOutEvents - tx.Query: %w", err)
+	}
+	type outboxEvent struct {
+		id        string
+		eventType string
+	}
+	var events []outboxEvent
+	for rows.Next() {
+		var event outboxEvent
+		if err = rows.Scan(&event.id, &event.eventType); err != nil {
+			_ = rows.Close()
+			return 0, fmt.Errorf("WebhookRepository - FanOutEvents - rows.Scan: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err = rows.Err(); err != nil {
+		return 0, fmt.Errorf("WebhookRepository - FanOutEvents - rows.Err: %w", err)
+	}
+
+	for _, event := range events {
+		if _, err = tx.Exec(
+			"INSERT INTO webhook_deliveries(subscription_id, event_id, status, next_attempt_at) "+
+				"SELECT id, $1, $2, $3 FROM webhook_subscriptions WHERE active AND $4 = ANY(event_types) "+
+				"ON CONFLICT (subscription_id, event_id) DO NOTHING",
+			event.id,
+			model.WebhookDeliveryPending,
+			now,
+			event.eventType,
+		); err != nil {
+			return 0, fmt.Errorf("WebhookRepository - FanOutEvents - tx.Exec: %w", err)
+		}
+		if _, err = tx.Exec(
+			"UPDATE outbox_events SET dispatched_at = $1 WHERE id = $2",
+			now,
+			event.id,
+		); err != nil {
+			return 0, fmt.Errorf("WebhookRepository - FanOutEvents - tx.Exec: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("WebhookRepository - FanOutEvents - tx.Commit: %w", err)
+	}
+	return len(events), nil
+}
+
+// ClaimDueDeliveries returns pending deliveries whose next attempt is due and
+// postpones them by the lease, so no other dispatcher picks them up while they
+// are being sent.
+func (webhookRepository *webhookRepository) ClaimDueDeliveries(limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	now := time.Now().UTC()
+	rows, err := webhookRepository.db.Query(
+		"UPDATE webhook_deliveries d SET next_attempt_at = $1 "+
+			"FROM (SELECT id FROM webhook_deliveries WHERE status = $2 AND next_attempt_at <= $3 ORDER BY next_attempt_at LIMIT $4 FOR UPDATE SKIP LOCKED) due, "+
+			"webhook_subscriptions s, outbox_events e "+
+			"WHERE d.id = due.id AND s.id = d.subscription_id AND e.id = d.event_id "+
+			"RETURNING d.id, d.subscription_id, s.url, s.secret, d.attempts, e.id, e.event_type, e.payload, e.created_at",
+		now.Add(lease),
+		model.WebhookDeliveryPending,
+		now,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookRepository - ClaimDueDeliveries - webhookRepository.db.Query: %w", err)
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery := &model.WebhookDelivery{
+			Status:        model.WebhookDeliveryPending,
+			NextAttemptAt: now.Add(lease),
+		}
+		var eventType string
+		if err = rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionId,
+			&delivery.Url,
+			&delivery.Secret,
+			&delivery.Attempts,
+			&delivery.Event.ID,
+			&eventType,
+			&delivery.Event.Payload,
+			&delivery.Event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("WebhookRepository - ClaimDueDeliveries - rows.Scan: %w", err)
+		}
+		if delivery.Event.Type, err = model.EventTypeFromString(eventType); err != nil {
+			return nil, fmt.Errorf("WebhookRepository - ClaimDueDeliveries - model.EventTypeFromString: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("WebhookRepository - ClaimDueDeliveries - rows.Err: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (webhookRepository *webhookRepository) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	if _, err := webhookRepository.db.Exec(
+		"UPDATE webhook_deliveries SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, delivered_at = $5 WHERE id = $6",
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		sql.NullString{String: delivery.LastError, Valid: delivery.LastError != ""},
+		delivery.DeliveredAt,
+		delivery.ID,
+	); err != nil {
+		return fmt.Errorf("WebhookRepository - UpdateDelivery - webhookRepository.db.Exec: %w", err)
+	}
+	return nil
+}
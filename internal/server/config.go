@@ -7,13 +7,27 @@ type Config struct {
 	ReadTimeout         time.Duration `yaml:"read-timeout" env:"SERVER_READ_TIMEOUT"`
 	WriteTimeout        time.Duration `yaml:"write-timeout" env:"SERVER_WRITE_TIMEOUT"`
 	ShutdownGracePeriod time.Duration `yaml:"shutdown-grace-period" env:"SERVER_SHUTDOWN_GRACE_PERIOD"`
+	// ShutdownDrainDelay is how long the server keeps accepting connections
+	// once it reports not ready on shutdown, so that load balancers stop
+	// routing to it before it closes the listener.
+	ShutdownDrainDelay time.Duration `yaml:"shutdown-drain-delay" env:"SERVER_SHUTDOWN_DRAIN_DELAY"`
+	// MaxConcurrentRequests bounds the requests served at once, the requests
+	// over the bound are rejected with 503. Open event streams count as served
+	// requests. 0 is unbounded.
+	MaxConcurrentRequests int `yaml:"max-concurrent-requests" env:"SERVER_MAX_CONCURRENT_REQUESTS"`
+	// ShedOnPoolSaturation rejects requests with 503 while every connection of
+	// a database pool is in use, rather than queueing them for a connection.
+	ShedOnPoolSaturation bool `yaml:"shed-on-pool-saturation" env:"SERVER_SHED_ON_POOL_SATURATION"`
 }
 
 func NewConfig() Config {
 	return Config{
-		Port:                "8080",
-		ReadTimeout:         time.Second * 5,
-		WriteTimeout:        time.Second * 15,
-		ShutdownGracePeriod: time.Second * 30,
+		Port:                  "8080",
+		ReadTimeout:           time.Second * 5,
+		WriteTimeout:          time.Second * 15,
+		ShutdownGracePeriod:   time.Second * 30,
+		ShutdownDrainDelay:    time.Second * 5,
+		MaxConcurrentRequests: 1024,
+		ShedOnPoolSaturation:  true,
 	}
 }
@@ -2,27 +2,33 @@ package server
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
 )
 
 type server struct {
-	logger     *log.Logger
-	httpServer *http.Server
-	config     Config
+	logger      *logging.Logger
+	httpServer  *http.Server
+	loadShedder *loadShedder
+	onDrain     []func()
+	config      Config
 }
 
-func NewServer(logger *log.Logger, config Config, handler http.Handler) *server {
+func NewServer(logger *logging.Logger, config Config, handler http.Handler) *server {
+	loadShedder := newLoadShedder(handler, config.MaxConcurrentRequests)
 	server := &server{
-		logger: logger,
-		config: config,
+		logger:      logger,
+		loadShedder: loadShedder,
+		config:      config,
 		httpServer: &http.Server{
 			Addr:         ":" + config.Port,
-			Handler:      handler,
-			ErrorLog:     logger,
+			Handler:      loadShedder,
+			ErrorLog:     logger.StdLogger(logging.LevelWarn),
 			ReadTimeout:  config.ReadTimeout,
 			WriteTimeout: config.WriteTimeout,
 		},
@@ -31,15 +37,51 @@ func NewServer(logger *log.Logger, config Config, handler http.Handler) *server
 }
 
 func (server *server) Start() error {
-	server.logger.Println("Starting server on port " + server.config.Port)
+	server.logger.Info(context.Background(), "Starting server", logging.String("port", server.config.Port))
 	return server.httpServer.ListenAndServe()
 }
 
+// RegisterOnShutdown registers a function to call when the graceful shutdown
+// starts, e.g. to end long-lived streams the shutdown would wait for.
+func (server *server) RegisterOnShutdown(f func()) {
+	server.httpServer.RegisterOnShutdown(f)
+}
+
+// RegisterOnDrain registers a function to call when the server starts draining
+// on shutdown, e.g. to report it not ready.
+func (server *server) RegisterOnDrain(f func()) {
+	server.onDrain = append(server.onDrain, f)
+}
+
+// ShedLoad rejects requests with 503 while overloaded returns true, it is
+// called before Start.
+func (server *server) ShedLoad(overloaded func() bool) {
+	server.loadShedder.overloaded = overloaded
+}
+
+// ExemptFromShedding serves the requests to the paths even while load is shed,
+// it is called before Start.
+func (server *server) ExemptFromShedding(paths ...string) {
+	if server.loadShedder.exempt == nil {
+		server.loadShedder.exempt = make(map[string]bool, len(paths))
+	}
+	for _, path := range paths {
+		server.loadShedder.exempt[path] = true
+	}
+}
+
+// GracefulShutdown waits for SIGINT or SIGTERM, drains the server for the drain
+// delay and then shuts it down, waiting for the requests in flight.
 func (server *server) GracefulShutdown() error {
-	sigChannel := make(chan os.Signal)
+	sigChannel := make(chan os.Signal, 1)
 	signal.Notify(sigChannel, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChannel
-	server.logger.Println("Shutdown server")
+	server.logger.Info(context.Background(), "Draining server", logging.Duration("delay", server.config.ShutdownDrainDelay))
+	for _, f := range server.onDrain {
+		f()
+	}
+	time.Sleep(server.config.ShutdownDrainDelay)
+	server.logger.Info(context.Background(), "Shutdown server")
 	ctx, cancel := context.WithTimeout(context.Background(), server.config.ShutdownGracePeriod)
 	defer cancel()
 	return server.httpServer.Shutdown(ctx)
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+)
+
+// loadShedder rejects requests with 503 while the server serves as many
+// requests as it is allowed to, or while it is overloaded. Requests to exempt
+// paths, such as the probes of the orchestrator, are always served.
+type loadShedder struct {
+	next       http.Handler
+	slots      chan struct{}
+	overloaded func() bool
+	exempt     map[string]bool
+}
+
+func newLoadShedder(next http.Handler, maxConcurrentRequests int) *loadShedder {
+	loadShedder := &loadShedder{
+		next: next,
+	}
+	if maxConcurrentRequests > 0 {
+		loadShedder.slots = make(chan struct{}, maxConcurrentRequests)
+	}
+	return loadShedder
+}
+
+func (loadShedder *loadShedder) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if loadShedder.exempt[req.URL.Path] {
+		loadShedder.next.ServeHTTP(rw, req)
+		return
+	}
+	if loadShedder.overloaded != nil && loadShedder.overloaded() {
+		writeServiceUnavailable(rw)
+		return
+	}
+	if loadShedder.slots != nil {
+		select {
+		case loadShedder.slots <- struct{}{}:
+			defer func() {
+				<-loadShedder.slots
+			}()
+		default:
+			writeServiceUnavailable(rw)
+			return
+		}
+	}
+	loadShedder.next.ServeHTTP(rw, req)
+}
+
+func writeServiceUnavailable(rw http.ResponseWriter) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Retry-After", "1")
+	rw.WriteHeader(http.StatusServiceUnavailable)
+	_, _ = rw.Write([]byte(`{"message":"server is overloaded"}` + "\n"))
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadShedderMaxConcurrentRequests(t *testing.T) {
+	// given
+	started, release := make(chan struct{}), make(chan struct{})
+	loadShedder := newLoadShedder(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		started <- struct{}{}
+		<-release
+	}), 1)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		loadShedder.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	<-started
+	recorder := httptest.NewRecorder()
+
+	// when
+	loadShedder.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// then
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	close(release)
+	wg.Wait()
+	assert.Len(t, loadShedder.slots, 0)
+}
+
+func TestLoadShedderOverloaded(t *testing.T) {
+	// given
+	loadShedder := newLoadShedder(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}), 0)
+	overloaded := true
+	loadShedder.overloaded = func() bool {
+		return overloaded
+	}
+
+	// when
+	shed := httptest.NewRecorder()
+	loadShedder.ServeHTTP(shed, httptest.NewRequest(http.MethodGet, "/", nil))
+	overloaded = false
+	served := httptest.NewRecorder()
+	loadShedder.ServeHTTP(served, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// then
+	assert.Equal(t, http.StatusServiceUnavailable, shed.Code)
+	assert.Equal(t, http.StatusNoContent, served.Code)
+}
+
+func TestLoadShedderExemptPath(t *testing.T) {
+	// given
+	loadShedder := newLoadShedder(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusNoContent)
+	}), 0)
+	loadShedder.overloaded = func() bool {
+		return true
+	}
+	loadShedder.exempt = map[string]bool{"/healthz": true}
+
+	// when
+	probe := httptest.NewRecorder()
+	loadShedder.ServeHTTP(probe, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	shed := httptest.NewRecorder()
+	loadShedder.ServeHTTP(shed, httptest.NewRequest(http.MethodGet, "/api/v1/wallets", nil))
+
+	// then
+	assert.Equal(t, http.StatusNoContent, probe.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, shed.Code)
+}
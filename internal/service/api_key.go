@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+type ApiKeyService interface {
+	// IssueApiKey stores a new key and returns it with the key itself, which is
+	// not stored and cannot be recovered.
+	IssueApiKey(ctx context.Context, name string, scopes []model.Scope) (*model.ApiKey, string, error)
+	GetApiKeys(ctx context.Context) ([]*model.ApiKey, error)
+	// RotateApiKey replaces the key, the previous one stops working right away.
+	RotateApiKey(ctx context.Context, id string) (*model.ApiKey, string, error)
+	RevokeApiKey(ctx context.Context, id string) error
+	// Authenticate returns the API key of the key, ErrApiKeyNotFound if it is
+	// unknown or revoked.
+	Authenticate(ctx context.Context, key string) (*model.ApiKey, error)
+}
+
+type apiKeyService struct {
+	apiKeyRepository repository.ApiKeyRepository
+}
+
+func NewApiKeyService(apiKeyRepository repository.ApiKeyRepository) *apiKeyService {
+	return &apiKeyService{
+		apiKeyRepository: apiKeyRepository,
+	}
+}
+
+func (apiKeyService *apiKeyService) IssueApiKey(ctx context.Context, name string, scopes []model.Scope) (*model.ApiKey, string, error) {
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("ApiKeyService - IssueApiKey: %w", model.ErrInvalidScope)
+	}
+	key, prefix, err := auth.GenerateKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("ApiKeyService - IssueApiKey - auth.GenerateKey: %w", err)
+	}
+	apiKey := model.ApiKey{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      auth.HashKey(key),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if apiKey.ID, err = apiKeyService.apiKeyRepository.CreateApiKey(ctx, apiKey); err != nil {
+		return nil, "", fmt.Errorf("ApiKeyService - IssueApiKey - apiKeyService.apiKeyRepository.CreateApiKey: %w", err)
+	}
+	return &apiKey, key, nil
+}
+
+func (apiKeyService *apiKeyService) GetApiKeys(ctx context.Context) ([]*model.ApiKey, error) {
+	apiKeys, err := apiKeyService.apiKeyRepository.GetApiKeys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("ApiKeyService - GetApiKeys - apiKeyService.apiKeyRepository.GetApiKeys: %w", err)
+	}
+	return apiKeys, nil
+}
+
+func (apiKeyService *apiKeyService) RotateApiKey(ctx context.Context, id string) (*model.ApiKey, string, error) {
+	key, prefix, err := auth.GenerateKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("ApiKeyService - RotateApiKey - auth.GenerateKey: %w", err)
+	}
+	apiKey, err := apiKeyService.apiKeyRepository.RotateApiKey(ctx, id, prefix, auth.HashKey(key), time.Now().UTC())
+	if err != nil {
+		return nil, "", fmt.Errorf("ApiKeyService - RotateApiKey - apiKeyService.apiKeyRepository.RotateApiKey: %w", err)
+	}
+	return apiKey, key, nil
+}
+
+func (apiKeyService *apiKeyService) RevokeApiKey(ctx context.Context, id string) error {
+	if err := apiKeyService.apiKeyRepository.RevokeApiKey(ctx, id, time.Now().UTC()); err != nil {
+		return fmt.Errorf("ApiKeyService - RevokeApiKey - apiKeyService.apiKeyRepository.RevokeApiKey: %w", err)
+	}
+	return nil
+}
+
+func (apiKeyService *apiKeyService) Authenticate(ctx context.Context, key string) (*model.ApiKey, error) {
+	apiKey, err := apiKeyService.apiKeyRepository.GetApiKeyByHash(ctx, auth.HashKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("ApiKeyService - Authenticate - apiKeyService.apiKeyRepository.GetApiKeyByHash: %w", err)
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+type AuditService interface {
+	// RecordAuditEntry records the entry unless it was already recorded with
+	// the operation, which set its id.
+	RecordAuditEntry(ctx context.Context, entry *model.AuditEntry) error
+	GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error)
+}
+
+type auditService struct {
+	auditRepository repository.AuditRepository
+}
+
+func NewAuditService(auditRepository repository.AuditRepository) *auditService {
+	return &auditService{
+		auditRepository: auditRepository,
+	}
+}
+
+func (auditService *auditService) RecordAuditEntry(ctx context.Context, entry *model.AuditEntry) error {
+	if entry.ID != "" {
+		return nil
+	}
+	entry.CreatedAt = time.Now().UTC()
+	id, err := auditService.auditRepository.CreateAuditEntry(ctx, *entry)
+	if err != nil {
+		return fmt.Errorf("AuditService - RecordAuditEntry - auditService.auditRepository.CreateAuditEntry: %w", err)
+	}
+	entry.ID = id
+	return nil
+}
+
+func (auditService *auditService) GetAuditEntries(ctx context.Context, limit int, offset int, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	entries, err := auditService.auditRepository.GetAuditEntries(ctx, limit, offset, filter)
+	if err != nil {
+		return nil, fmt.Errorf("AuditService - GetAuditEntries - auditService.auditRepository.GetAuditEntries: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,19 @@
+package service
+
+import "time"
+
+type DepositImportConfig struct {
+	ChunkSize int `yaml:"chunk-size" env:"DEPOSIT_IMPORT_CHUNK_SIZE"`
+	// Lease is how long an import stays claimed by the instance processing it
+	// without being renewed, after that another instance resumes it.
+	Lease          time.Duration `yaml:"lease" env:"DEPOSIT_IMPORT_LEASE"`
+	ResumeInterval time.Duration `yaml:"resume-interval" env:"DEPOSIT_IMPORT_RESUME_INTERVAL"`
+}
+
+func NewDepositImportConfig() DepositImportConfig {
+	return DepositImportConfig{
+		ChunkSize:      500,
+		Lease:          time.Minute,
+		ResumeInterval: time.Minute,
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/audit"
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+const maxReferenceLength = 255
+
+// depositImportAuditAction is the audit log action of the deposits of an import.
+const depositImportAuditAction = "deposits.import.deposit"
+
+// resumeBatchSize is the number of interrupted imports claimed per resume round.
+const resumeBatchSize = 10
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type DepositImportService interface {
+	ImportDeposits(ctx context.Context, rows []*model.DepositImportRow, dryRun bool, atomic bool) (*model.DepositImport, error)
+	GetDepositImport(ctx context.Context, id string) (*model.DepositImport, error)
+	Start()
+	Shutdown()
+}
+
+type depositImportService struct {
+	logger                  *logging.Logger
+	depositImportRepository repository.DepositImportRepository
+	config                  DepositImportConfig
+	wg                      sync.WaitGroup
+	stop                    chan struct{}
+	done                    sync.WaitGroup
+}
+
+func NewDepositImportService(
+	logger *logging.Logger, depositImportRepository repository.DepositImportRepository, config DepositImportConfig,
+) *depositImportService {
+	return &depositImportService{
+		logger:                  logger,
+		depositImportRepository: depositImportRepository,
+		config:                  config,
+		stop:                    make(chan struct{}),
+	}
+}
+
+// ImportDeposits validates the rows and stores the import. A dry run is processed
+// right away, otherwise valid rows are applied in the background and the progress
+// is available through GetDepositImport. The import records the API key of ctx,
+// its deposits are audited as made by the principal of ctx.
+func (depositImportService *depositImportService) ImportDeposits(
+	ctx context.Context, rows []*model.DepositImportRow, dryRun bool, atomic bool,
+) (*model.DepositImport, error) {
+	validateDepositImportRows(rows)
+	depositImport := &model.DepositImport{
+		Status:    model.DepositImportPending,
+		DryRun:    dryRun,
+		Atomic:    atomic,
+		CreatedAt: time.Now().UTC(),
+		Rows:      rows,
+		ApiKeyId:  auth.ApiKeyId(ctx),
+	}
+	if principal := auth.PrincipalFromContext(ctx); principal != nil {
+		depositImport.Actor = principal.Subject
+	}
+	id, err := depositImportService.depositImportRepository.CreateDepositImport(ctx, depositImport, depositImportService.config.Lease)
+	if err != nil {
+		return nil, fmt.Errorf("DepositImportService - ImportDeposits - depositImportService.depositImportRepository.CreateDepositImport: %w", err)
+	}
+	depositImport.ID = id
+	requestId := logging.RequestIdFromContext(ctx)
+	if dryRun {
+		depositImportService.process(depositImport, requestId)
+		return depositImport, nil
+	}
+	processedImport := cloneDepositImport(depositImport)
+	depositImportService.wg.Add(1)
+	go func() {
+		defer depositImportService.wg.Done()
+		depositImportService.process(processedImport, requestId)
+	}()
+	return depositImport, nil
+}
+
+func (depositImportService *depositImportService) GetDepositImport(ctx context.Context, id string) (*model.DepositImport, error) {
+	depositImport, err := depositImportService.depositImportRepository.GetDepositImport(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("DepositImportService - GetDepositImport - depositImportService.depositImportRepository.GetDepositImport: %w", err)
+	}
+	return depositImport, nil
+}
+
+// resume claims the imports left unfinished by a stopped process
+// and applies their pending rows in the background. Rows applied before the
+// interruption are not deposited twice, their references are already claimed.
+func (depositImportService *depositImportService) resume(ctx context.Context) error {
+	ids, err := depositImportService.depositImportRepository.ClaimStaleDepositImports(ctx, resumeBatchSize, depositImportService.config.Lease)
+	if err != nil {
+		return fmt.Errorf("DepositImportService - resume - depositImportService.depositImportRepository.ClaimStaleDepositImports: %w", err)
+	}
+	for _, id := range ids {
+		depositImport, err := depositImportService.depositImportRepository.GetDepositImport(ctx, id)
+		if err != nil {
+			return fmt.Errorf("DepositImportService - resume - depositImportService.depositImportRepository.GetDepositImport: %w", err)
+		}
+		requestId := logging.NewRequestId()
+		depositImportService.logger.Info(
+			logging.WithRequestId(context.Background(), requestId), "Resuming deposit import", logging.String("import_id", id),
+		)
+		depositImportService.wg.Add(1)
+		go func() {
+			defer depositImportService.wg.Done()
+			depositImportService.process(depositImport, requestId)
+		}()
+	}
+	return nil
+}
+
+// Start resumes interrupted imports right away and then every resume interval
+// until Shutdown is called.
+func (depositImportService *depositImportService) Start() {
+	depositImportService.done.Add(1)
+	go func() {
+		defer depositImportService.done.Done()
+		ticker := time.NewTicker(depositImportService.config.ResumeInterval)
+		defer ticker.Stop()
+		for {
+			if err := depositImportService.resume(context.Background()); err != nil {
+				depositImportService.logger.Error(context.Background(), "DepositImportService - Start - depositImportService.resume", logging.Err(err))
+			}
+			select {
+			case <-depositImportService.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops resuming imports and waits for the imports being processed
+// in the background.
+func (depositImportService *depositImportService) Shutdown() {
+	close(depositImportService.stop)
+	depositImportService.done.Wait()
+	depositImportService.wg.Wait()
+}
+
+func (depositImportService *depositImportService) process(depositImport *model.DepositImport, requestId string) {
+	// The import outlives the request, its transactions record the API key it
+	// was started with, its audit entries the principal and its lines the id of
+	// the request.
+	ctx := auth.WithPrincipal(context.Background(), &model.Principal{Subject: depositImport.Actor, ApiKeyId: depositImport.ApiKeyId})
+	ctx = logging.WithRequestId(ctx, requestId)
+	ctx = audit.WithEntry(ctx, &model.AuditEntry{Actor: depositImport.Actor, Action: depositImportAuditAction, RequestId: requestId})
+	stopRenewing := depositImportService.renewLease(ctx, depositImport.ID)
+	defer stopRenewing()
+	depositImport.Status = model.DepositImportProcessing
+	if err := depositImportService.depositImportRepository.UpdateDepositImport(ctx, depositImport, nil); err != nil {
+		depositImportService.logger.Error(ctx, "DepositImportService - process - depositImportService.depositImportRepository.UpdateDepositImport", logging.Err(err))
+	}
+
+	var pendingRows []*model.DepositImportRow
+	for _, row := range depositImport.Rows {
+		if row.Status == model.DepositImportRowPending {
+			pendingRows = append(pendingRows, row)
+		}
+	}
+	chunkSize := depositImportService.config.ChunkSize
+	if depositImport.Atomic || chunkSize <= 0 {
+		chunkSize = len(pendingRows)
+	}
+
+	depositImport.Status = model.DepositImportCompleted
+	for start := 0; start < len(pendingRows); start += chunkSize {
+		end := start + chunkSize
+		if end > len(pendingRows) {
+			end = len(pendingRows)
+		}
+		chunk := pendingRows[start:end]
+		committed, err := depositImportService.depositImportRepository.ApplyDepositImportRows(
+			ctx, depositImport.ID, chunk, depositImport.DryRun, depositImport.Atomic,
+		)
+		if err != nil {
+			depositImportService.logger.Error(ctx, "DepositImportService - process - depositImportService.depositImportRepository.ApplyDepositImportRows", logging.Err(err))
+			for _, row := range chunk {
+				row.Status = model.DepositImportRowFailed
+				row.Error = "unable to apply row"
+				row.TransactionId = ""
+			}
+		} else if !committed {
+			for _, row := range chunk {
+				if row.Status != model.DepositImportRowApplied {
+					continue
+				}
+				row.Status = model.DepositImportRowRolledBack
+				if depositImport.DryRun {
+					row.Status = model.DepositImportRowValid
+				}
+				row.TransactionId = ""
+			}
+		}
+		if depositImport.Atomic && (err != nil || !committed) && !depositImport.DryRun {
+			depositImport.Status = model.DepositImportFailed
+		}
+		// The outcomes of the rows of a committed chunk are stored with their deposits.
+		updatedRows := chunk
+		if err == nil && committed {
+			updatedRows = nil
+		}
+		if err := depositImportService.depositImportRepository.UpdateDepositImport(ctx, depositImport, updatedRows); err != nil {
+			depositImportService.logger.Error(ctx, "DepositImportService - process - depositImportService.depositImportRepository.UpdateDepositImport", logging.Err(err))
+		}
+	}
+
+	finishedAt := time.Now().UTC()
+	depositImport.FinishedAt = &finishedAt
+	if err := depositImportService.depositImportRepository.UpdateDepositImport(ctx, depositImport, nil); err != nil {
+		depositImportService.logger.Error(ctx, "DepositImportService - process - depositImportService.depositImportRepository.UpdateDepositImport", logging.Err(err))
+	}
+}
+
+// renewLease keeps the import leased until the returned function is called, so
+// that other instances do not resume it while it is processed.
+func (depositImportService *depositImportService) renewLease(ctx context.Context, id string) func() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(depositImportService.config.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := depositImportService.depositImportRepository.RenewDepositImportLease(ctx, id, depositImportService.config.Lease); err != nil {
+					depositImportService.logger.Error(ctx, "DepositImportService - renewLease - depositImportService.depositImportRepository.RenewDepositImportLease", logging.Err(err))
+				}
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-done
+	}
+}
+
+// validateDepositImportRows marks every row either pending or invalid. Rows
+// already marked invalid, e.g. because they could not be parsed, are kept.
+func validateDepositImportRows(rows []*model.DepositImportRow) {
+	references := make(map[string]int, len(rows))
+	for _, row := range rows {
+		if row.Status == model.DepositImportRowInvalid {
+			continue
+		}
+		row.Status = model.DepositImportRowInvalid
+		switch {
+		case !uuidPattern.MatchString(row.WalletId):
+			row.Error = "invalid wallet id"
+		case row.Amount == 0:
+			row.Error = "amount should be greater than 0"
+		case row.Reference == "":
+			row.Error = "reference is required"
+		case len(row.Reference) > maxReferenceLength:
+			row.Error = fmt.Sprintf("reference should be at most %d characters", maxReferenceLength)
+		case references[row.Reference] != 0:
+			row.Status = model.DepositImportRowDuplicate
+			row.Error = fmt.Sprintf("duplicate reference of line %d", references[row.Reference])
+		default:
+			row.Status = model.DepositImportRowPending
+			references[row.Reference] = row.Line
+		}
+	}
+}
+
+func cloneDepositImport(depositImport *model.DepositImport) *model.DepositImport {
+	clone := *depositImport
+	clone.Rows = make([]*model.DepositImportRow, 0, len(depositImport.Rows))
+	for _, row := range depositImport.Rows {
+		rowClone := *row
+		clone.Rows = append(clone.Rows, &rowClone)
+	}
+	return &clone
+}
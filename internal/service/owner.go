@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+type OwnerService interface {
+	CreateOwner(ctx context.Context, owner model.Owner) (*model.Owner, error)
+	GetOwner(ctx context.Context, id string) (*model.Owner, error)
+	GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, error)
+	UpdateOwner(ctx context.Context, owner model.Owner) (*model.Owner, error)
+	DeleteOwner(ctx context.Context, id string) error
+	CreateOwnerWallet(ctx context.Context, ownerId string, wallet model.Wallet) (string, error)
+	GetOwnerWallets(ctx context.Context, ownerId string) ([]*model.Wallet, error)
+	GetOwnerTransactions(ctx context.Context, ownerId string, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error)
+}
+
+type ownerService struct {
+	ownerRepository  repository.OwnerRepository
+	walletRepository repository.WalletRepository
+	walletService    WalletService
+}
+
+func NewOwnerService(
+	ownerRepository repository.OwnerRepository, walletRepository repository.WalletRepository, walletService WalletService,
+) *ownerService {
+	return &ownerService{
+		ownerRepository:  ownerRepository,
+		walletRepository: walletRepository,
+		walletService:    walletService,
+	}
+}
+
+func (ownerService *ownerService) CreateOwner(ctx context.Context, owner model.Owner) (*model.Owner, error) {
+	owner.CreatedAt = time.Now().UTC()
+	owner.UpdatedAt = owner.CreatedAt
+	id, err := ownerService.ownerRepository.CreateOwner(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerService - CreateOwner - ownerService.ownerRepository.CreateOwner: %w", err)
+	}
+	owner.ID = id
+	return &owner, nil
+}
+
+func (ownerService *ownerService) GetOwner(ctx context.Context, id string) (*model.Owner, error) {
+	owner, err := ownerService.ownerRepository.GetOwner(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerService - GetOwner - ownerService.ownerRepository.GetOwner: %w", err)
+	}
+	return owner, nil
+}
+
+func (ownerService *ownerService) GetOwners(ctx context.Context, limit int, offset int) ([]*model.Owner, error) {
+	owners, err := ownerService.ownerRepository.GetOwners(ctx, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerService - GetOwners - ownerService.ownerRepository.GetOwners: %w", err)
+	}
+	return owners, nil
+}
+
+// UpdateOwner replaces the details of the owner and returns the owner as stored.
+// The subject owns the wallets of the owner, it can be omitted but not changed.
+func (ownerService *ownerService) UpdateOwner(ctx context.Context, owner model.Owner) (*model.Owner, error) {
+	current, err := ownerService.ownerRepository.GetOwner(ctx, owner.ID)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerService - UpdateOwner - ownerService.ownerRepository.GetOwner: %w", err)
+	}
+	if owner.Subject != "" && owner.Subject != current.Subject {
+		return nil, fmt.Errorf("OwnerService - UpdateOwner: %w", model.ErrOwnerSubjectChanged)
+	}
+	owner.UpdatedAt = time.Now().UTC()
+	if err := ownerService.ownerRepository.UpdateOwner(ctx, owner); err != nil {
+		return nil, fmt.Errorf("OwnerService - UpdateOwner - ownerService.ownerRepository.UpdateOwner: %w", err)
+	}
+	updated, err := ownerService.ownerRepository.GetOwner(ctx, owner.ID)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerService - UpdateOwner - ownerService.ownerRepository.GetOwner: %w", err)
+	}
+	return updated, nil
+}
+
+// DeleteOwner deletes an owner that holds no wallets, wallets are never
+// deleted and keep their transactions.
+func (ownerService *ownerService) DeleteOwner(ctx context.Context, id string) error {
+	wallets, err := ownerService.walletRepository.GetOwnerWallets(ctx, id)
+	if err != nil {
+		return fmt.Errorf("OwnerService - DeleteOwner - ownerService.walletRepository.GetOwnerWallets: %w", err)
+	}
+	if len(wallets) > 0 {
+		return fmt.Errorf("OwnerService - DeleteOwner: %w", model.ErrOwnerHasWallets)
+	}
+	if err = ownerService.ownerRepository.DeleteOwner(ctx, id); err != nil {
+		return fmt.Errorf("OwnerService - DeleteOwner - ownerService.ownerRepository.DeleteOwner: %w", err)
+	}
+	return nil
+}
+
+// CreateOwnerWallet creates a wallet held by the owner and owned by the subject of
+// the owner. An owner holds at most one wallet per currency, another one is
+// reported as model.ErrWalletAlreadyExists.
+func (ownerService *ownerService) CreateOwnerWallet(ctx context.Context, ownerId string, wallet model.Wallet) (string, error) {
+	owner, err := ownerService.ownerRepository.GetOwner(ctx, ownerId)
+	if err != nil {
+		return "", fmt.Errorf("OwnerService - CreateOwnerWallet - ownerService.ownerRepository.GetOwner: %w", err)
+	}
+	wallet.OwnerId = ownerId
+	wallet.Owner = owner.Subject
+	id, err := ownerService.walletService.CreateWallet(ctx, wallet)
+	if err != nil {
+		return "", fmt.Errorf("OwnerService - CreateOwnerWallet - ownerService.walletService.CreateWallet: %w", err)
+	}
+	return id, nil
+}
+
+func (ownerService *ownerService) GetOwnerWallets(ctx context.Context, ownerId string) ([]*model.Wallet, error) {
+	if _, err := ownerService.ownerRepository.GetOwner(ctx, ownerId); err != nil {
+		return nil, fmt.Errorf("OwnerService - GetOwnerWallets - ownerService.ownerRepository.GetOwner: %w", err)
+	}
+	wallets, err := ownerService.walletRepository.GetOwnerWallets(ctx, ownerId)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerService - GetOwnerWallets - ownerService.walletRepository.GetOwnerWallets: %w", err)
+	}
+	return wallets, nil
+}
+
+// GetOwnerTransactions returns the transactions of all wallets of the owner,
+// the newest first, as a single feed. The page is taken from the first
+// limit+offset transactions of every wallet, a transfer between two wallets
+// of the owner appears once.
+func (ownerService *ownerService) GetOwnerTransactions(
+	ctx context.Context, ownerId string, limit int, offset int, filter model.TransactionFilter,
+) ([]*model.Transaction, error) {
+	wallets, err := ownerService.GetOwnerWallets(ctx, ownerId)
+	if err != nil {
+		return nil, fmt.Errorf("OwnerService - GetOwnerTransactions - ownerService.GetOwnerWallets: %w", err)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	walletLimit := -1
+	if limit > -1 {
+		walletLimit = limit + offset
+	}
+	seen := make(map[string]bool)
+	var transactions []*model.Transaction
+	for _, wallet := range wallets {
+		filter.WalletId = wallet.ID
+		walletTransactions, err := ownerService.walletService.GetTransactions(ctx, walletLimit, -1, filter)
+		if err != nil {
+			return nil, fmt.Errorf("OwnerService - GetOwnerTransactions - ownerService.walletService.GetTransactions: %w", err)
+		}
+		for _, transaction := range walletTransactions {
+			if !seen[transaction.ID] {
+				seen[transaction.ID] = true
+				transactions = append(transactions, transaction)
+			}
+		}
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].ProcessedAt.After(transactions[j].ProcessedAt)
+	})
+	if offset >= len(transactions) {
+		return nil, nil
+	}
+	transactions = transactions[offset:]
+	if limit > -1 && limit < len(transactions) {
+		transactions = transactions[:limit]
+	}
+	return transactions, nil
+}
@@ -1,22 +1,39 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/SergeyChupin/wallets-api/internal/auth"
 	"github.com/SergeyChupin/wallets-api/internal/model"
 	"github.com/SergeyChupin/wallets-api/internal/repository"
 )
 
 type WalletService interface {
-	CreateWallet(wallet model.Wallet) (string, error)
-	Deposit(recipientWalletId string, amount uint64) (*model.Transaction, error)
-	Transfer(senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error)
-	GetTransactions(limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error)
+	CreateWallet(ctx context.Context, wallet model.Wallet) (string, error)
+	GetWallet(ctx context.Context, id string) (*model.Wallet, error)
+	Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error)
+	Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error)
+	GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error)
+	GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error)
+	FreezeWallet(ctx context.Context, id string) error
+	UnfreezeWallet(ctx context.Context, id string) error
+	ShardWallet(ctx context.Context, id string, shards int) error
+	Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error)
+	GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error)
+	GrantWalletAccess(ctx context.Context, walletId string, subject string, role model.WalletRole) error
+	RevokeWalletAccess(ctx context.Context, walletId string, subject string) error
 }
 
+// maxTransactionAttempts bounds the attempts of a deposit or a transfer
+// conflicting with concurrent transactions.
+const maxTransactionAttempts = 3
+
 type walletService struct {
 	walletRepository repository.WalletRepository
+	retryObserver    func(operation model.OperationType)
 }
 
 func NewWalletService(walletRepository repository.WalletRepository) *walletService {
@@ -25,37 +42,191 @@ func NewWalletService(walletRepository repository.WalletRepository) *walletServi
 	}
 }
 
-func (walletService *walletService) CreateWallet(wallet model.Wallet) (string, error) {
-	id, err := walletService.walletRepository.CreateWallet(wallet)
+// WithRetryObserver makes the service call observe on each retry of a deposit
+// or a transfer.
+func (walletService *walletService) WithRetryObserver(observe func(operation model.OperationType)) *walletService {
+	walletService.retryObserver = observe
+	return walletService
+}
+
+// CreateWallet creates the wallet owned by the principal of ctx. The wallet of
+// an owner record keeps the subject of the owner instead.
+func (walletService *walletService) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
+	if principal := auth.PrincipalFromContext(ctx); principal != nil && wallet.OwnerId == "" {
+		wallet.Owner = principal.Subject
+	}
+	id, err := walletService.walletRepository.CreateWallet(ctx, wallet)
 	if err != nil {
 		return "", fmt.Errorf("WalletService - CreateWallet - walletService.walletRepository.CreateWallet: %w", err)
 	}
 	return id, nil
 }
 
-func (walletService *walletService) Deposit(recipientWalletId string, amount uint64) (*model.Transaction, error) {
-	transaction, err := walletService.walletRepository.Deposit(recipientWalletId, amount)
+func (walletService *walletService) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	if err := walletService.authorize(ctx, id, model.ViewerRole); err != nil {
+		return nil, fmt.Errorf("WalletService - GetWallet - walletService.authorize: %w", err)
+	}
+	wallet, err := walletService.walletRepository.GetWallet(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("WalletService - GetWallet - walletService.walletRepository.GetWallet: %w", err)
+	}
+	return wallet, nil
+}
+
+// Deposit is open to every principal, only the movement of money out of a
+// wallet is restricted.
+func (walletService *walletService) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	transaction, err := walletService.retryConflicts(model.Deposit, func() (*model.Transaction, error) {
+		return walletService.walletRepository.Deposit(ctx, recipientWalletId, amount)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("WalletService - Deposit - walletService.walletRepository.Deposit: %w", err)
 	}
 	return transaction, nil
 }
 
-func (walletService *walletService) Transfer(senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+func (walletService *walletService) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
 	if senderWalletId == recipientWalletId {
-		return nil, errors.New("WalletService - Transfer - sender wallet should be different than recipient wallet")
+		return nil, fmt.Errorf("WalletService - Transfer: %w", model.ErrSameWallet)
+	}
+	if err := walletService.authorize(ctx, senderWalletId, model.SpenderRole); err != nil {
+		return nil, fmt.Errorf("WalletService - Transfer - walletService.authorize: %w", err)
 	}
-	transaction, err := walletService.walletRepository.Transfer(senderWalletId, recipientWalletId, amount)
+	transaction, err := walletService.retryConflicts(model.Transfer, func() (*model.Transaction, error) {
+		return walletService.walletRepository.Transfer(ctx, senderWalletId, recipientWalletId, amount)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("WalletService - Transfer - walletService.walletRepository.Transfer: %w", err)
 	}
 	return transaction, nil
 }
 
-func (walletService *walletService) GetTransactions(limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
-	transactions, err := walletService.walletRepository.GetTransactions(limit, offset, filter)
+func (walletService *walletService) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+	if err := walletService.authorize(ctx, filter.WalletId, model.ViewerRole); err != nil {
+		return nil, fmt.Errorf("WalletService - GetTransactions - walletService.authorize: %w", err)
+	}
+	transactions, err := walletService.walletRepository.GetTransactions(ctx, limit, offset, filter)
 	if err != nil {
 		return nil, fmt.Errorf("WalletService - GetTransactions - walletService.walletRepository.GetTransactions: %w", err)
 	}
 	return transactions, nil
 }
+
+func (walletService *walletService) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	if err := walletService.authorize(ctx, walletId, model.ViewerRole); err != nil {
+		return nil, fmt.Errorf("WalletService - GetTransactionsAfter - walletService.authorize: %w", err)
+	}
+	transactions, err := walletService.walletRepository.GetTransactionsAfter(ctx, walletId, transactionId)
+	if err != nil {
+		return nil, fmt.Errorf("WalletService - GetTransactionsAfter - walletService.walletRepository.GetTransactionsAfter: %w", err)
+	}
+	return transactions, nil
+}
+
+// FreezeWallet blocks deposits to and transfers from or to the wallet.
+func (walletService *walletService) FreezeWallet(ctx context.Context, id string) error {
+	if err := walletService.walletRepository.SetWalletFrozen(ctx, id, true); err != nil {
+		return fmt.Errorf("WalletService - FreezeWallet - walletService.walletRepository.SetWalletFrozen: %w", err)
+	}
+	return nil
+}
+
+// ShardWallet spreads the deposits to the wallet over the number of balance shards,
+// 0 stops sharding.
+func (walletService *walletService) ShardWallet(ctx context.Context, id string, shards int) error {
+	if shards < 0 || shards > model.MaxWalletShards {
+		return fmt.Errorf("WalletService - ShardWallet: %w", model.ErrInvalidWalletShards)
+	}
+	if err := walletService.walletRepository.SetWalletShards(ctx, id, shards); err != nil {
+		return fmt.Errorf("WalletService - ShardWallet - walletService.walletRepository.SetWalletShards: %w", err)
+	}
+	return nil
+}
+
+func (walletService *walletService) UnfreezeWallet(ctx context.Context, id string) error {
+	if err := walletService.walletRepository.SetWalletFrozen(ctx, id, false); err != nil {
+		return fmt.Errorf("WalletService - UnfreezeWallet - walletService.walletRepository.SetWalletFrozen: %w", err)
+	}
+	return nil
+}
+
+func (walletService *walletService) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	reconciliations, err := walletService.walletRepository.Reconcile(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("WalletService - Reconcile - walletService.walletRepository.Reconcile: %w", err)
+	}
+	return reconciliations, nil
+}
+
+func (walletService *walletService) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	if err := walletService.authorize(ctx, walletId, model.OwnerRole); err != nil {
+		return nil, fmt.Errorf("WalletService - GetWalletGrants - walletService.authorize: %w", err)
+	}
+	grants, err := walletService.walletRepository.GetWalletGrants(ctx, walletId)
+	if err != nil {
+		return nil, fmt.Errorf("WalletService - GetWalletGrants - walletService.walletRepository.GetWalletGrants: %w", err)
+	}
+	return grants, nil
+}
+
+// GrantWalletAccess grants the role on the wallet to the subject, replacing
+// the role it was granted before.
+func (walletService *walletService) GrantWalletAccess(ctx context.Context, walletId string, subject string, role model.WalletRole) error {
+	if err := walletService.authorize(ctx, walletId, model.OwnerRole); err != nil {
+		return fmt.Errorf("WalletService - GrantWalletAccess - walletService.authorize: %w", err)
+	}
+	if err := walletService.walletRepository.GrantWalletAccess(ctx, model.WalletGrant{
+		WalletId:  walletId,
+		Subject:   subject,
+		Role:      role,
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		return fmt.Errorf("WalletService - GrantWalletAccess - walletService.walletRepository.GrantWalletAccess: %w", err)
+	}
+	return nil
+}
+
+func (walletService *walletService) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	if err := walletService.authorize(ctx, walletId, model.OwnerRole); err != nil {
+		return fmt.Errorf("WalletService - RevokeWalletAccess - walletService.authorize: %w", err)
+	}
+	if err := walletService.walletRepository.RevokeWalletAccess(ctx, walletId, subject); err != nil {
+		return fmt.Errorf("WalletService - RevokeWalletAccess - walletService.walletRepository.RevokeWalletAccess: %w", err)
+	}
+	return nil
+}
+
+// retryConflicts runs the operation again while it conflicts with concurrent
+// transactions, such as opposite transfers between the same wallets that
+// deadlock, up to maxTransactionAttempts times.
+func (walletService *walletService) retryConflicts(operation model.OperationType, run func() (*model.Transaction, error)) (*model.Transaction, error) {
+	for attempt := 1; ; attempt++ {
+		transaction, err := run()
+		if !errors.Is(err, model.ErrTransactionConflict) || attempt == maxTransactionAttempts {
+			return transaction, err
+		}
+		if walletService.retryObserver != nil {
+			walletService.retryObserver(operation)
+		}
+	}
+}
+
+// authorize checks that the principal of ctx has the role on the wallet.
+// Principals granted the admin scope have every role on every wallet, and so
+// have callers without a principal, such as walletctl and background jobs. An
+// unknown wallet is denied rather than reported, so that its existence is
+// not revealed.
+func (walletService *walletService) authorize(ctx context.Context, walletId string, role model.WalletRole) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil || principal.HasScope(model.AdminScope) {
+		return nil
+	}
+	granted, err := walletService.walletRepository.GetWalletRole(ctx, walletId, principal.Subject)
+	if err != nil && !errors.Is(err, model.ErrWalletNotFound) {
+		return fmt.Errorf("WalletService - authorize - walletService.walletRepository.GetWalletRole: %w", err)
+	}
+	if !granted.Includes(role) {
+		return fmt.Errorf("WalletService - authorize: %w", model.ErrWalletAccessDenied)
+	}
+	return nil
+}
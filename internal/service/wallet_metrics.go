@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/SergeyChupin/wallets-api/internal/metrics"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+// maxCachedCurrencies bounds the wallet currencies kept by the currency
+// source, the cache is emptied once it is full.
+const maxCachedCurrencies = 100000
+
+// WalletCurrencySource tells the currency of a wallet, the deposits and
+// transfers of the instrumented wallet service are counted by it.
+type WalletCurrencySource interface {
+	WalletCurrency(ctx context.Context, walletId string) (string, error)
+}
+
+// walletCurrencySource reads the currencies of wallets. The currency of a
+// wallet never changes, so it is read once per wallet.
+type walletCurrencySource struct {
+	walletRepository repository.WalletRepository
+	mu               sync.RWMutex
+	currencies       map[string]string
+}
+
+func NewWalletCurrencySource(walletRepository repository.WalletRepository) *walletCurrencySource {
+	return &walletCurrencySource{
+		walletRepository: walletRepository,
+		currencies:       make(map[string]string),
+	}
+}
+
+func (currencySource *walletCurrencySource) WalletCurrency(ctx context.Context, walletId string) (string, error) {
+	currencySource.mu.RLock()
+	currency, ok := currencySource.currencies[walletId]
+	currencySource.mu.RUnlock()
+	if ok {
+		return currency, nil
+	}
+	wallet, err := currencySource.walletRepository.GetWallet(ctx, walletId)
+	if err != nil {
+		return "", err
+	}
+	currencySource.mu.Lock()
+	defer currencySource.mu.Unlock()
+	if len(currencySource.currencies) >= maxCachedCurrencies {
+		currencySource.currencies = make(map[string]string)
+	}
+	currencySource.currencies[walletId] = wallet.Currency
+	return wallet.Currency, nil
+}
+
+// instrumentedWalletService counts the deposits and transfers of the wallet
+// service it wraps, the other calls are passed through.
+type instrumentedWalletService struct {
+	WalletService
+	currencySource WalletCurrencySource
+	walletMetrics  *metrics.WalletMetrics
+}
+
+func NewInstrumentedWalletService(
+	walletService WalletService,
+	currencySource WalletCurrencySource,
+	walletMetrics *metrics.WalletMetrics,
+) *instrumentedWalletService {
+	return &instrumentedWalletService{
+		WalletService:  walletService,
+		currencySource: currencySource,
+		walletMetrics:  walletMetrics,
+	}
+}
+
+func (walletService *instrumentedWalletService) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	transaction, err := walletService.WalletService.Deposit(ctx, recipientWalletId, amount)
+	walletService.walletMetrics.ObserveOperation(model.Deposit, walletService.currency(ctx, recipientWalletId), amount, err)
+	return transaction, err
+}
+
+func (walletService *instrumentedWalletService) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	transaction, err := walletService.WalletService.Transfer(ctx, senderWalletId, recipientWalletId, amount)
+	walletService.walletMetrics.ObserveOperation(model.Transfer, walletService.currency(ctx, senderWalletId), amount, err)
+	return transaction, err
+}
+
+// currency returns the currency of the wallet, or an empty string if the
+// source cannot tell it.
+func (walletService *instrumentedWalletService) currency(ctx context.Context, walletId string) string {
+	currency, err := walletService.currencySource.WalletCurrency(ctx, walletId)
+	if err != nil {
+		return ""
+	}
+	return currency
+}
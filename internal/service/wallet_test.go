@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SergeyChupin/wallets-api/internal/auth"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const unknownWalletId = "6f1c2b7a-0d4e-4b8a-9c3f-5e2d1a0b9c87"
+
+// walletOperation calls the wallet service on the wallet, recipientId is a
+// wallet the operation may move money to.
+type walletOperation func(ctx context.Context, walletService WalletService, walletId string, recipientId string) error
+
+var walletOperations = map[string]walletOperation{
+	"GetWallet": func(ctx context.Context, walletService WalletService, walletId string, recipientId string) error {
+		_, err := walletService.GetWallet(ctx, walletId)
+		return err
+	},
+	"GetTransactions": func(ctx context.Context, walletService WalletService, walletId string, recipientId string) error {
+		_, err := walletService.GetTransactions(ctx, -1, -1, model.TransactionFilter{WalletId: walletId})
+		return err
+	},
+	"Deposit": func(ctx context.Context, walletService WalletService, walletId string, recipientId string) error {
+		_, err := walletService.Deposit(ctx, walletId, 10)
+		return err
+	},
+	"Transfer": func(ctx context.Context, walletService WalletService, walletId string, recipientId string) error {
+		_, err := walletService.Transfer(ctx, walletId, recipientId, 10)
+		return err
+	},
+	"GetWalletGrants": func(ctx context.Context, walletService WalletService, walletId string, recipientId string) error {
+		_, err := walletService.GetWalletGrants(ctx, walletId)
+		return err
+	},
+	"GrantWalletAccess": func(ctx context.Context, walletService WalletService, walletId string, recipientId string) error {
+		return walletService.GrantWalletAccess(ctx, walletId, "erin", model.ViewerRole)
+	},
+}
+
+// newAuthorizedWalletService returns the service of a wallet owned by alice,
+// with bob a viewer, carol a spender and dave an owner of it, and of a wallet
+// of zoe to transfer to.
+func newAuthorizedWalletService(t *testing.T) (WalletService, string, string) {
+	walletService := NewWalletService(repository.NewMemoryWalletRepository())
+	ctx := context.Background()
+	walletId, err := walletService.CreateWallet(auth.WithPrincipal(ctx, &model.Principal{Subject: "alice"}), model.Wallet{Name: "alice", Currency: "USD"})
+	require.NoError(t, err)
+	recipientId, err := walletService.CreateWallet(auth.WithPrincipal(ctx, &model.Principal{Subject: "zoe"}), model.Wallet{Name: "zoe", Currency: "USD"})
+	require.NoError(t, err)
+	_, err = walletService.Deposit(ctx, walletId, 1000)
+	require.NoError(t, err)
+	require.NoError(t, walletService.GrantWalletAccess(ctx, walletId, "bob", model.ViewerRole))
+	require.NoError(t, walletService.GrantWalletAccess(ctx, walletId, "carol", model.SpenderRole))
+	require.NoError(t, walletService.GrantWalletAccess(ctx, walletId, "dave", model.OwnerRole))
+	return walletService, walletId, recipientId
+}
+
+func TestWalletServiceAuthorize(t *testing.T) {
+	principals := map[string]*model.Principal{
+		"owner":         {Subject: "alice"},
+		"granted owner": {Subject: "dave"},
+		"spender":       {Subject: "carol"},
+		"viewer":        {Subject: "bob"},
+		"stranger":      {Subject: "mallory"},
+		"admin":         {Subject: "root", Scopes: []model.Scope{model.AdminScope}},
+		"no principal":  nil,
+	}
+	// allowed are the operations each principal may call on the wallet of alice.
+	allowed := map[string][]string{
+		"owner":         {"GetWallet", "GetTransactions", "Deposit", "Transfer", "GetWalletGrants", "GrantWalletAccess"},
+		"granted owner": {"GetWallet", "GetTransactions", "Deposit", "Transfer", "GetWalletGrants", "GrantWalletAccess"},
+		"spender":       {"GetWallet", "GetTransactions", "Deposit", "Transfer"},
+		"viewer":        {"GetWallet", "GetTransactions", "Deposit"},
+		"stranger":      {"Deposit"},
+		"admin":         {"GetWallet", "GetTransactions", "Deposit", "Transfer", "GetWalletGrants", "GrantWalletAccess"},
+		"no principal":  {"GetWallet", "GetTransactions", "Deposit", "Transfer", "GetWalletGrants", "GrantWalletAccess"},
+	}
+	for name, principal := range principals {
+		for operationName, operation := range walletOperations {
+			name, principal, operationName, operation := name, principal, operationName, operation
+			t.Run(name+"/"+operationName, func(t *testing.T) {
+				// given
+				walletService, walletId, recipientId := newAuthorizedWalletService(t)
+				ctx := context.Background()
+				if principal != nil {
+					ctx = auth.WithPrincipal(ctx, principal)
+				}
+
+				// when
+				err := operation(ctx, walletService, walletId, recipientId)
+
+				// then
+				if contains(allowed[name], operationName) {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorIs(t, err, model.ErrWalletAccessDenied)
+				}
+			})
+		}
+	}
+}
+
+func TestWalletServiceAuthorizeUnknownWallet(t *testing.T) {
+	principals := map[string]*model.Principal{
+		"owner of another wallet": {Subject: "alice"},
+		"stranger":                {Subject: "mallory"},
+		"admin":                   {Subject: "root", Scopes: []model.Scope{model.AdminScope}},
+	}
+	for name, principal := range principals {
+		for operationName, operation := range walletOperations {
+			name, principal, operationName, operation := name, principal, operationName, operation
+			t.Run(name+"/"+operationName, func(t *testing.T) {
+				// given
+				walletService, _, recipientId := newAuthorizedWalletService(t)
+				ctx := auth.WithPrincipal(context.Background(), principal)
+
+				// when
+				err := operation(ctx, walletService, unknownWalletId, recipientId)
+
+				// then
+				switch {
+				case principal.HasScope(model.AdminScope):
+					assert.NotErrorIs(t, err, model.ErrWalletAccessDenied)
+				case operationName == "Deposit":
+					assert.ErrorIs(t, err, model.ErrWalletNotFound)
+				default:
+					assert.ErrorIs(t, err, model.ErrWalletAccessDenied)
+					assert.NotErrorIs(t, err, model.ErrWalletNotFound)
+				}
+			})
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
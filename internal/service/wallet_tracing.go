@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/SergeyChupin/wallets-api/internal/service")
+
+// tracedWalletService starts a span for each call of the wallet service it
+// wraps, the spans of the queries run by the call are its children.
+type tracedWalletService struct {
+	walletService WalletService
+}
+
+func NewTracedWalletService(walletService WalletService) *tracedWalletService {
+	return &tracedWalletService{
+		walletService: walletService,
+	}
+}
+
+func (walletService *tracedWalletService) CreateWallet(ctx context.Context, wallet model.Wallet) (string, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.CreateWallet", trace.WithAttributes(
+		attribute.String("wallet.currency", wallet.Currency),
+	))
+	id, err := walletService.walletService.CreateWallet(ctx, wallet)
+	span.SetAttributes(attribute.String("wallet.id", id))
+	endSpan(span, err)
+	return id, err
+}
+
+func (walletService *tracedWalletService) GetWallet(ctx context.Context, id string) (*model.Wallet, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.GetWallet", trace.WithAttributes(
+		attribute.String("wallet.id", id),
+	))
+	wallet, err := walletService.walletService.GetWallet(ctx, id)
+	endSpan(span, err)
+	return wallet, err
+}
+
+func (walletService *tracedWalletService) Deposit(ctx context.Context, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.Deposit", trace.WithAttributes(
+		attribute.String("wallet.recipient_id", recipientWalletId),
+		attribute.Int64("transaction.amount", int64(amount)),
+	))
+	transaction, err := walletService.walletService.Deposit(ctx, recipientWalletId, amount)
+	if transaction != nil {
+		span.SetAttributes(attribute.String("transaction.id", transaction.ID))
+	}
+	endSpan(span, err)
+	return transaction, err
+}
+
+func (walletService *tracedWalletService) Transfer(ctx context.Context, senderWalletId string, recipientWalletId string, amount uint64) (*model.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.Transfer", trace.WithAttributes(
+		attribute.String("wallet.sender_id", senderWalletId),
+		attribute.String("wallet.recipient_id", recipientWalletId),
+		attribute.Int64("transaction.amount", int64(amount)),
+	))
+	transaction, err := walletService.walletService.Transfer(ctx, senderWalletId, recipientWalletId, amount)
+	if transaction != nil {
+		span.SetAttributes(attribute.String("transaction.id", transaction.ID))
+	}
+	endSpan(span, err)
+	return transaction, err
+}
+
+func (walletService *tracedWalletService) GetTransactions(ctx context.Context, limit int, offset int, filter model.TransactionFilter) ([]*model.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.GetTransactions", trace.WithAttributes(
+		attribute.String("wallet.id", filter.WalletId),
+		attribute.Int("limit", limit),
+		attribute.Int("offset", offset),
+	))
+	transactions, err := walletService.walletService.GetTransactions(ctx, limit, offset, filter)
+	span.SetAttributes(attribute.Int("transactions", len(transactions)))
+	endSpan(span, err)
+	return transactions, err
+}
+
+func (walletService *tracedWalletService) GetTransactionsAfter(ctx context.Context, walletId string, transactionId string) ([]*model.Transaction, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.GetTransactionsAfter", trace.WithAttributes(
+		attribute.String("wallet.id", walletId),
+		attribute.String("transaction.id", transactionId),
+	))
+	transactions, err := walletService.walletService.GetTransactionsAfter(ctx, walletId, transactionId)
+	span.SetAttributes(attribute.Int("transactions", len(transactions)))
+	endSpan(span, err)
+	return transactions, err
+}
+
+func (walletService *tracedWalletService) FreezeWallet(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "WalletService.FreezeWallet", trace.WithAttributes(
+		attribute.String("wallet.id", id),
+	))
+	err := walletService.walletService.FreezeWallet(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (walletService *tracedWalletService) UnfreezeWallet(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "WalletService.UnfreezeWallet", trace.WithAttributes(
+		attribute.String("wallet.id", id),
+	))
+	err := walletService.walletService.UnfreezeWallet(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (walletService *tracedWalletService) ShardWallet(ctx context.Context, id string, shards int) error {
+	ctx, span := tracer.Start(ctx, "WalletService.ShardWallet", trace.WithAttributes(
+		attribute.String("wallet.id", id),
+		attribute.Int("wallet.shards", shards),
+	))
+	err := walletService.walletService.ShardWallet(ctx, id, shards)
+	endSpan(span, err)
+	return err
+}
+
+func (walletService *tracedWalletService) Reconcile(ctx context.Context) ([]*model.WalletReconciliation, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.Reconcile")
+	reconciliations, err := walletService.walletService.Reconcile(ctx)
+	endSpan(span, err)
+	return reconciliations, err
+}
+
+func (walletService *tracedWalletService) GetWalletGrants(ctx context.Context, walletId string) ([]*model.WalletGrant, error) {
+	ctx, span := tracer.Start(ctx, "WalletService.GetWalletGrants", trace.WithAttributes(
+		attribute.String("wallet.id", walletId),
+	))
+	grants, err := walletService.walletService.GetWalletGrants(ctx, walletId)
+	endSpan(span, err)
+	return grants, err
+}
+
+func (walletService *tracedWalletService) GrantWalletAccess(ctx context.Context, walletId string, subject string, role model.WalletRole) error {
+	ctx, span := tracer.Start(ctx, "WalletService.GrantWalletAccess", trace.WithAttributes(
+		attribute.String("wallet.id", walletId),
+		attribute.String("wallet.role", role.String()),
+	))
+	err := walletService.walletService.GrantWalletAccess(ctx, walletId, subject, role)
+	endSpan(span, err)
+	return err
+}
+
+func (walletService *tracedWalletService) RevokeWalletAccess(ctx context.Context, walletId string, subject string) error {
+	ctx, span := tracer.Start(ctx, "WalletService.RevokeWalletAccess", trace.WithAttributes(
+		attribute.String("wallet.id", walletId),
+	))
+	err := walletService.walletService.RevokeWalletAccess(ctx, walletId, subject)
+	endSpan(span, err)
+	return err
+}
+
+// endSpan records the error of the call, if any, and ends its span.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+type WebhookService interface {
+	CreateSubscription(subscription model.WebhookSubscription) (*model.WebhookSubscription, error)
+	GetSubscriptions() ([]*model.WebhookSubscription, error)
+	DeleteSubscription(id string) error
+	GetDeliveries(subscriptionId string, status model.WebhookDeliveryStatus, limit int, offset int) ([]*model.WebhookDelivery, error)
+	ReplayDelivery(subscriptionId string, id string) error
+	ReplayDeadDeliveries(subscriptionId string) (int, error)
+}
+
+type webhookService struct {
+	webhookRepository repository.WebhookRepository
+}
+
+func NewWebhookService(webhookRepository repository.WebhookRepository) *webhookService {
+	return &webhookService{
+		webhookRepository: webhookRepository,
+	}
+}
+
+// CreateSubscription stores the subscription, generating a signing secret unless one is given.
+func (webhookService *webhookService) CreateSubscription(subscription model.WebhookSubscription) (*model.WebhookSubscription, error) {
+	if subscription.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("WebhookService - CreateSubscription - rand.Read: %w", err)
+		}
+		subscription.Secret = hex.EncodeToString(secret)
+	}
+	subscription.Active = true
+	subscription.CreatedAt = time.Now().UTC()
+	id, err := webhookService.webhookRepository.CreateSubscription(subscription)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService - CreateSubscription - webhookService.webhookRepository.CreateSubscription: %w", err)
+	}
+	subscription.ID = id
+	return &subscription, nil
+}
+
+func (webhookService *webhookService) GetSubscriptions() ([]*model.WebhookSubscription, error) {
+	subscriptions, err := webhookService.webhookRepository.GetSubscriptions()
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService - GetSubscriptions - webhookService.webhookRepository.GetSubscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+func (webhookService *webhookService) DeleteSubscription(id string) error {
+	if err := webhookService.webhookRepository.DeactivateSubscription(id); err != nil {
+		return fmt.Errorf("WebhookService - DeleteSubscription - webhookService.webhookRepository.DeactivateSubscription: %w", err)
+	}
+	return nil
+}
+
+func (webhookService *webhookService) GetDeliveries(
+	subscriptionId string, status model.WebhookDeliveryStatus, limit int, offset int,
+) ([]*model.WebhookDelivery, error) {
+	deliveries, err := webhookService.webhookRepository.GetDeliveries(subscriptionId, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("WebhookService - GetDeliveries - webhookService.webhookRepository.GetDeliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (webhookService *webhookService) ReplayDelivery(subscriptionId string, id string) error {
+	if err := webhookService.webhookRepository.ReplayDelivery(subscriptionId, id); err != nil {
+		return fmt.Errorf("WebhookService - ReplayDelivery - webhookService.webhookRepository.ReplayDelivery: %w", err)
+	}
+	return nil
+}
+
+func (webhookService *webhookService) ReplayDeadDeliveries(subscriptionId string) (int, error) {
+	replayed, err := webhookService.webhookRepository.ReplayDeadDeliveries(subscriptionId)
+	if err != nil {
+		return 0, fmt.Errorf("WebhookService - ReplayDeadDeliveries - webhookService.webhookRepository.ReplayDeadDeliveries: %w", err)
+	}
+	return replayed, nil
+}
@@ -0,0 +1,40 @@
+package tracing
+
+import "time"
+
+const (
+	OtlpExporter = "otlp"
+	FileExporter = "file"
+)
+
+// Stdout is the file the file exporter writes to stdout for.
+const Stdout = "stdout"
+
+type Config struct {
+	Enabled     bool       `yaml:"enabled" env:"TRACING_ENABLED"`
+	ServiceName string     `yaml:"service-name" env:"TRACING_SERVICE_NAME"`
+	SampleRatio float64    `yaml:"sample-ratio" env:"TRACING_SAMPLE_RATIO"`
+	Exporter    string     `yaml:"exporter" env:"TRACING_EXPORTER"`
+	Otlp        OtlpConfig `yaml:"otlp"`
+	File        string     `yaml:"file" env:"TRACING_FILE"`
+}
+
+type OtlpConfig struct {
+	Endpoint string            `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	Headers  map[string]string `yaml:"headers"`
+	Timeout  time.Duration     `yaml:"timeout" env:"TRACING_OTLP_TIMEOUT"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled:     false,
+		ServiceName: "wallets-api",
+		SampleRatio: 1,
+		Exporter:    OtlpExporter,
+		Otlp: OtlpConfig{
+			Endpoint: "http://localhost:4318/v1/traces",
+			Timeout:  time.Second * 10,
+		},
+		File: Stdout,
+	}
+}
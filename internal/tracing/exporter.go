@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// otlpHttpExporter posts the spans to an OTLP/HTTP endpoint, such as the one
+// of an OpenTelemetry collector, in the JSON encoding.
+type otlpHttpExporter struct {
+	client *http.Client
+	config OtlpConfig
+}
+
+func newOtlpHttpExporter(config OtlpConfig) *otlpHttpExporter {
+	return &otlpHttpExporter{
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+	}
+}
+
+func (exporter *otlpHttpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	body, err := json.Marshal(toOtlp(spans))
+	if err != nil {
+		return fmt.Errorf("otlpHttpExporter - ExportSpans - json.Marshal: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, exporter.config.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("otlpHttpExporter - ExportSpans - http.NewRequestWithContext: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range exporter.config.Headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := exporter.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlpHttpExporter - ExportSpans - exporter.client.Do: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("otlpHttpExporter - ExportSpans: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (exporter *otlpHttpExporter) Shutdown(ctx context.Context) error {
+	exporter.client.CloseIdleConnections()
+	return nil
+}
+
+// fileExporter writes each batch of spans as a line of OTLP JSON, the format
+// of the file exporter of the OpenTelemetry collector.
+type fileExporter struct {
+	mu   sync.Mutex
+	out  io.Writer
+	file *os.File
+}
+
+// openFileExporter appends the spans to the file at path, or writes them to
+// stdout.
+func openFileExporter(path string) (*fileExporter, error) {
+	if path == Stdout {
+		return &fileExporter{out: os.Stdout}, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("openFileExporter - os.OpenFile: %w", err)
+	}
+	return &fileExporter{out: file, file: file}, nil
+}
+
+func (exporter *fileExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	line, err := json.Marshal(toOtlp(spans))
+	if err != nil {
+		return fmt.Errorf("fileExporter - ExportSpans - json.Marshal: %w", err)
+	}
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	if _, err = exporter.out.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("fileExporter - ExportSpans - exporter.out.Write: %w", err)
+	}
+	return nil
+}
+
+// Shutdown closes the file the spans are written to, stdout is left open.
+func (exporter *fileExporter) Shutdown(ctx context.Context) error {
+	exporter.mu.Lock()
+	defer exporter.mu.Unlock()
+	if exporter.file != nil {
+		if err := exporter.file.Close(); err != nil {
+			return fmt.Errorf("fileExporter - Shutdown - exporter.file.Close: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans returns a server span and its failed child.
+func recordSpans() []sdktrace.ReadOnlySpan {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	ctx, parent := tracer.Start(context.Background(), "POST /api/v1/wallets/{id}/transfer", trace.WithSpanKind(trace.SpanKindServer))
+	_, child := tracer.Start(ctx, "WalletService.Transfer", trace.WithAttributes(
+		attribute.String("wallet.sender_id", "1001"),
+		attribute.Int64("transaction.amount", 500),
+	))
+	child.SetStatus(codes.Error, "insufficient funds")
+	child.End()
+	parent.End()
+	return recorder.Ended()
+}
+
+func TestOtlpHttpExporter(t *testing.T) {
+	// given
+	var body map[string]interface{}
+	var contentType, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		contentType = req.Header.Get("Content-Type")
+		authorization = req.Header.Get("Authorization")
+		data, _ := ioutil.ReadAll(req.Body)
+		_ = json.Unmarshal(data, &body)
+	}))
+	defer server.Close()
+	exporter := newOtlpHttpExporter(OtlpConfig{
+		Endpoint: server.URL + "/v1/traces",
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		Timeout:  NewConfig().Otlp.Timeout,
+	})
+	spans := recordSpans()
+
+	// when
+	err := exporter.ExportSpans(context.Background(), spans)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, "Bearer token", authorization)
+	resourceSpans := body["resourceSpans"].([]interface{})
+	assert.Len(t, resourceSpans, 1)
+	scopeSpans := resourceSpans[0].(map[string]interface{})["scopeSpans"].([]interface{})
+	assert.Equal(t, "test", scopeSpans[0].(map[string]interface{})["scope"].(map[string]interface{})["name"])
+	otlpSpans := scopeSpans[0].(map[string]interface{})["spans"].([]interface{})
+	assert.Len(t, otlpSpans, 2)
+	child, parent := otlpSpans[0].(map[string]interface{}), otlpSpans[1].(map[string]interface{})
+	assert.Equal(t, "WalletService.Transfer", child["name"])
+	assert.Equal(t, spans[0].SpanContext().TraceID().String(), child["traceId"])
+	assert.Equal(t, parent["spanId"], child["parentSpanId"])
+	assert.Equal(t, map[string]interface{}{"code": float64(2), "message": "insufficient funds"}, child["status"])
+	assert.Contains(t, child["attributes"], map[string]interface{}{
+		"key":   "transaction.amount",
+		"value": map[string]interface{}{"intValue": "500"},
+	})
+	assert.Equal(t, float64(trace.SpanKindServer), parent["kind"])
+	assert.NotContains(t, parent, "parentSpanId")
+}
+
+func TestOtlpHttpExporterError(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	exporter := newOtlpHttpExporter(OtlpConfig{Endpoint: server.URL, Timeout: NewConfig().Otlp.Timeout})
+
+	// when
+	err := exporter.ExportSpans(context.Background(), recordSpans())
+
+	// then
+	assert.Error(t, err)
+}
+
+func TestFileExporter(t *testing.T) {
+	// given
+	var buf bytes.Buffer
+	exporter := &fileExporter{out: &buf}
+
+	// when
+	err := exporter.ExportSpans(context.Background(), recordSpans())
+
+	// then
+	assert.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSuffix(buf.Bytes(), []byte("\n")), []byte("\n"))
+	assert.Len(t, lines, 1)
+	var traces otlpTraces
+	assert.NoError(t, json.Unmarshal(lines[0], &traces))
+	assert.Len(t, traces.ResourceSpans[0].ScopeSpans[0].Spans, 2)
+}
+
+func TestNewTracerProviderUnknownExporter(t *testing.T) {
+	// given
+	config := NewConfig()
+	config.Exporter = "zipkin"
+
+	// when
+	_, err := NewTracerProvider(config)
+
+	// then
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// The spans are exported in the JSON encoding of OTLP, accepted by the OTLP/HTTP
+// receivers of collectors and backends, and written as is by the file exporter.
+
+type otlpTraces struct {
+	ResourceSpans []*otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource      `json:"resource"`
+	ScopeSpans []*otlpScopeSpans `json:"scopeSpans"`
+	SchemaUrl  string            `json:"schemaUrl,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope     otlpScope  `json:"scope"`
+	Spans     []otlpSpan `json:"spans"`
+	SchemaUrl string     `json:"schemaUrl,omitempty"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceId           string         `json:"traceId"`
+	SpanId            string         `json:"spanId"`
+	TraceState        string         `json:"traceState,omitempty"`
+	ParentSpanId      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+// otlpStatus codes differ from the ones of the API: 1 is ok and 2 is error.
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+// otlpAnyValue holds one of the values, 64-bit integers are encoded as strings.
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+// toOtlp groups the spans by resource and instrumentation library.
+func toOtlp(spans []sdktrace.ReadOnlySpan) *otlpTraces {
+	traces := &otlpTraces{}
+	resources := make(map[attribute.Distinct]*otlpResourceSpans)
+	scopes := make(map[attribute.Distinct]map[instrumentation.Library]*otlpScopeSpans)
+	for _, span := range spans {
+		resourceKey := span.Resource().Equivalent()
+		resourceSpans, ok := resources[resourceKey]
+		if !ok {
+			resourceSpans = &otlpResourceSpans{
+				Resource:  otlpResource{Attributes: toOtlpAttributes(span.Resource().Attributes())},
+				SchemaUrl: span.Resource().SchemaURL(),
+			}
+			resources[resourceKey] = resourceSpans
+			scopes[resourceKey] = make(map[instrumentation.Library]*otlpScopeSpans)
+			traces.ResourceSpans = append(traces.ResourceSpans, resourceSpans)
+		}
+		library := span.InstrumentationLibrary()
+		scopeSpans, ok := scopes[resourceKey][library]
+		if !ok {
+			scopeSpans = &otlpScopeSpans{
+				Scope:     otlpScope{Name: library.Name, Version: library.Version},
+				SchemaUrl: library.SchemaURL,
+			}
+			scopes[resourceKey][library] = scopeSpans
+			resourceSpans.ScopeSpans = append(resourceSpans.ScopeSpans, scopeSpans)
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, toOtlpSpan(span))
+	}
+	return traces
+}
+
+func toOtlpSpan(span sdktrace.ReadOnlySpan) otlpSpan {
+	otlpSpan := otlpSpan{
+		TraceId:           span.SpanContext().TraceID().String(),
+		SpanId:            span.SpanContext().SpanID().String(),
+		TraceState:        span.SpanContext().TraceState().String(),
+		Name:              span.Name(),
+		Kind:              int(span.SpanKind()),
+		StartTimeUnixNano: strconv.FormatInt(span.StartTime().UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(span.EndTime().UnixNano(), 10),
+		Attributes:        toOtlpAttributes(span.Attributes()),
+		Status:            toOtlpStatus(span.Status()),
+	}
+	if span.Parent().HasSpanID() {
+		otlpSpan.ParentSpanId = span.Parent().SpanID().String()
+	}
+	for _, event := range span.Events() {
+		otlpSpan.Events = append(otlpSpan.Events, otlpEvent{
+			TimeUnixNano: strconv.FormatInt(event.Time.UnixNano(), 10),
+			Name:         event.Name,
+			Attributes:   toOtlpAttributes(event.Attributes),
+		})
+	}
+	return otlpSpan
+}
+
+func toOtlpStatus(status sdktrace.Status) otlpStatus {
+	switch status.Code {
+	case codes.Ok:
+		return otlpStatus{Code: 1}
+	case codes.Error:
+		return otlpStatus{Code: 2, Message: status.Description}
+	}
+	return otlpStatus{}
+}
+
+func toOtlpAttributes(attributes []attribute.KeyValue) []otlpKeyValue {
+	keyValues := make([]otlpKeyValue, 0, len(attributes))
+	for _, keyValue := range attributes {
+		keyValues = append(keyValues, otlpKeyValue{
+			Key:   string(keyValue.Key),
+			Value: toOtlpValue(keyValue.Value),
+		})
+	}
+	return keyValues
+}
+
+func toOtlpValue(value attribute.Value) otlpAnyValue {
+	switch value.Type() {
+	case attribute.BOOL:
+		boolValue := value.AsBool()
+		return otlpAnyValue{BoolValue: &boolValue}
+	case attribute.INT64:
+		intValue := strconv.FormatInt(value.AsInt64(), 10)
+		return otlpAnyValue{IntValue: &intValue}
+	case attribute.FLOAT64:
+		doubleValue := value.AsFloat64()
+		return otlpAnyValue{DoubleValue: &doubleValue}
+	case attribute.BOOLSLICE:
+		values := make([]otlpAnyValue, 0, len(value.AsBoolSlice()))
+		for _, boolValue := range value.AsBoolSlice() {
+			values = append(values, toOtlpValue(attribute.BoolValue(boolValue)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		values := make([]otlpAnyValue, 0, len(value.AsInt64Slice()))
+		for _, intValue := range value.AsInt64Slice() {
+			values = append(values, toOtlpValue(attribute.Int64Value(intValue)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		values := make([]otlpAnyValue, 0, len(value.AsFloat64Slice()))
+		for _, doubleValue := range value.AsFloat64Slice() {
+			values = append(values, toOtlpValue(attribute.Float64Value(doubleValue)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		values := make([]otlpAnyValue, 0, len(value.AsStringSlice()))
+		for _, stringValue := range value.AsStringSlice() {
+			values = append(values, toOtlpValue(attribute.StringValue(stringValue)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	}
+	stringValue := value.Emit()
+	return otlpAnyValue{StringValue: &stringValue}
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+)
+
+// NewTracerProvider returns the provider of the tracers of the service, it
+// samples the share of traces of config, keeps the sampling decision of the
+// caller and exports the spans in batches.
+func NewTracerProvider(config Config) (*sdktrace.TracerProvider, error) {
+	exporter, err := newExporter(config)
+	if err != nil {
+		return nil, fmt.Errorf("NewTracerProvider - newExporter: %w", err)
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(config.ServiceName),
+		)),
+	), nil
+}
+
+func newExporter(config Config) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case OtlpExporter:
+		return newOtlpHttpExporter(config.Otlp), nil
+	case FileExporter:
+		exporter, err := openFileExporter(config.File)
+		if err != nil {
+			return nil, fmt.Errorf("newExporter - openFileExporter: %w", err)
+		}
+		return exporter, nil
+	}
+	return nil, fmt.Errorf("newExporter: unknown exporter %q", config.Exporter)
+}
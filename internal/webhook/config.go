@@ -0,0 +1,25 @@
+package webhook
+
+import "time"
+
+type Config struct {
+	Enabled        bool          `yaml:"enabled" env:"WEBHOOK_ENABLED"`
+	PollInterval   time.Duration `yaml:"poll-interval" env:"WEBHOOK_POLL_INTERVAL"`
+	BatchSize      int           `yaml:"batch-size" env:"WEBHOOK_BATCH_SIZE"`
+	RequestTimeout time.Duration `yaml:"request-timeout" env:"WEBHOOK_REQUEST_TIMEOUT"`
+	MaxAttempts    int           `yaml:"max-attempts" env:"WEBHOOK_MAX_ATTEMPTS"`
+	BackoffBase    time.Duration `yaml:"backoff-base" env:"WEBHOOK_BACKOFF_BASE"`
+	BackoffMax     time.Duration `yaml:"backoff-max" env:"WEBHOOK_BACKOFF_MAX"`
+}
+
+func NewConfig() Config {
+	return Config{
+		Enabled:        true,
+		PollInterval:   time.Second,
+		BatchSize:      100,
+		RequestTimeout: time.Second * 10,
+		MaxAttempts:    10,
+		BackoffBase:    time.Second * 10,
+		BackoffMax:     time.Hour,
+	}
+}
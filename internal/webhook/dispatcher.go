@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/SergeyChupin/wallets-api/internal/repository"
+)
+
+const maxErrorBodySize = 1024
+
+type eventEnvelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+type Dispatcher interface {
+	Start()
+	Stop()
+	Dispatch() error
+}
+
+type dispatcher struct {
+	logger            *logging.Logger
+	webhookRepository repository.WebhookRepository
+	client            *http.Client
+	config            Config
+	stop              chan struct{}
+	done              sync.WaitGroup
+}
+
+func NewDispatcher(logger *logging.Logger, webhookRepository repository.WebhookRepository, client *http.Client, config Config) *dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: config.RequestTimeout}
+	}
+	return &dispatcher{
+		logger:            logger,
+		webhookRepository: webhookRepository,
+		client:            client,
+		config:            config,
+		stop:              make(chan struct{}),
+	}
+}
+
+// Start polls the outbox and delivers due webhooks in the background until Stop is called.
+func (dispatcher *dispatcher) Start() {
+	dispatcher.logger.Info(context.Background(), "Starting webhook dispatcher")
+	dispatcher.done.Add(1)
+	go func() {
+		defer dispatcher.done.Done()
+		ticker := time.NewTicker(dispatcher.config.PollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-dispatcher.stop:
+				return
+			case <-ticker.C:
+				if err := dispatcher.Dispatch(); err != nil {
+					dispatcher.logger.Error(context.Background(), "dispatcher - Start - dispatcher.Dispatch", logging.Err(err))
+				}
+			}
+		}
+	}()
+}
+
+// Stop waits for the current dispatch round to finish and stops the dispatcher.
+func (dispatcher *dispatcher) Stop() {
+	dispatcher.logger.Info(context.Background(), "Stopping webhook dispatcher")
+	close(dispatcher.stop)
+	dispatcher.done.Wait()
+}
+
+// Dispatch runs a single round: outbox events are fanned out to subscriptions
+// and due deliveries are sent.
+func (dispatcher *dispatcher) Dispatch() error {
+	if _, err := dispatcher.webhookRepository.FanOutEvents(dispatcher.config.BatchSize); err != nil {
+		return fmt.Errorf("dispatcher - Dispatch - dispatcher.webhookRepository.FanOutEvents: %w", err)
+	}
+	deliveries, err := dispatcher.webhookRepository.ClaimDueDeliveries(dispatcher.config.BatchSize, dispatcher.lease())
+	if err != nil {
+		return fmt.Errorf("dispatcher - Dispatch - dispatcher.webhookRepository.ClaimDueDeliveries: %w", err)
+	}
+	for _, delivery := range deliveries {
+		dispatcher.deliver(delivery)
+		if err = dispatcher.webhookRepository.UpdateDelivery(delivery); err != nil {
+			dispatcher.logger.Error(
+				context.Background(), "dispatcher - Dispatch - dispatcher.webhookRepository.UpdateDelivery",
+				logging.String("delivery_id", delivery.ID), logging.Err(err),
+			)
+		}
+	}
+	return nil
+}
+
+// lease is how long claimed deliveries stay locked to this dispatcher. The batch
+// is sent one delivery after another, each taking up to the request timeout, one
+// more timeout covers updating the deliveries.
+func (dispatcher *dispatcher) lease() time.Duration {
+	return dispatcher.config.RequestTimeout * time.Duration(dispatcher.config.BatchSize+1)
+}
+
+// deliver sends the delivery and records the outcome: delivered on a 2xx
+// response, otherwise retried with exponential backoff until it is dead.
+func (dispatcher *dispatcher) deliver(delivery *model.WebhookDelivery) {
+	delivery.Attempts++
+	err := dispatcher.send(delivery)
+	now := time.Now().UTC()
+	if err == nil {
+		delivery.Status = model.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		return
+	}
+	dispatcher.logger.Warn(context.Background(), "dispatcher - deliver - dispatcher.send", logging.String("delivery_id", delivery.ID), logging.Err(err))
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= dispatcher.config.MaxAttempts {
+		delivery.Status = model.WebhookDeliveryDead
+		return
+	}
+	delivery.NextAttemptAt = now.Add(dispatcher.backoff(delivery.Attempts))
+}
+
+func (dispatcher *dispatcher) backoff(attempts int) time.Duration {
+	backoff := dispatcher.config.BackoffBase
+	for i := 1; i < attempts && backoff < dispatcher.config.BackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > dispatcher.config.BackoffMax {
+		backoff = dispatcher.config.BackoffMax
+	}
+	return backoff
+}
+
+func (dispatcher *dispatcher) send(delivery *model.WebhookDelivery) error {
+	body, err := json.Marshal(eventEnvelope{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.Type.String(),
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      delivery.Event.Payload,
+	})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, delivery.Url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("http.NewRequest: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, time.Now(), body))
+	req.Header.Set(EventIdHeader, delivery.Event.ID)
+	req.Header.Set(EventTypeHeader, delivery.Event.Type.String())
+	resp, err := dispatcher.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("dispatcher.client.Do: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
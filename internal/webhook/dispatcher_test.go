@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SergeyChupin/wallets-api/internal/logging"
+	"github.com/SergeyChupin/wallets-api/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	logger, _ = logging.New(os.Stdout, logging.Config{Level: logging.LevelDebug.String(), Format: logging.TextFormat})
+)
+
+type webhookRepositoryMock struct {
+	mock.Mock
+}
+
+func (webhookRepository *webhookRepositoryMock) CreateSubscription(subscription model.WebhookSubscription) (string, error) {
+	args := webhookRepository.Called(subscription)
+	return args.String(0), args.Error(1)
+}
+
+func (webhookRepository *webhookRepositoryMock) GetSubscriptions() ([]*model.WebhookSubscription, error) {
+	args := webhookRepository.Called()
+	return args.Get(0).([]*model.WebhookSubscription), args.Error(1)
+}
+
+func (webhookRepository *webhookRepositoryMock) DeactivateSubscription(id string) error {
+	args := webhookRepository.Called(id)
+	return args.Error(0)
+}
+
+func (webhookRepository *webhookRepositoryMock) GetDeliveries(
+	subscriptionId string, status model.WebhookDeliveryStatus, limit int, offset int,
+) ([]*model.WebhookDelivery, error) {
+	args := webhookRepository.Called(subscriptionId, status, limit, offset)
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (webhookRepository *webhookRepositoryMock) ReplayDelivery(subscriptionId string, id string) error {
+	args := webhookRepository.Called(subscriptionId, id)
+	return args.Error(0)
+}
+
+func (webhookRepository *webhookRepositoryMock) ReplayDeadDeliveries(subscriptionId string) (int, error) {
+	args := webhookRepository.Called(subscriptionId)
+	return args.Int(0), args.Error(1)
+}
+
+func (webhookRepository *webhookRepositoryMock) FanOutEvents(limit int) (int, error) {
+	args := webhookRepository.Called(limit)
+	return args.Int(0), args.Error(1)
+}
+
+func (webhookRepository *webhookRepositoryMock) ClaimDueDeliveries(limit int, lease time.Duration) ([]*model.WebhookDelivery, error) {
+	args := webhookRepository.Called(limit, lease)
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (webhookRepository *webhookRepositoryMock) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	args := webhookRepository.Called(delivery)
+	return args.Error(0)
+}
+
+func newDelivery(url string, attempts int) *model.WebhookDelivery {
+	return &model.WebhookDelivery{
+		ID:             "3001",
+		SubscriptionId: "4001",
+		Url:            url,
+		Secret:         "secret",
+		Event: model.Event{
+			ID:        "5001",
+			Type:      model.DepositEvent,
+			Payload:   []byte(`{"transaction_id":"6001","amount":10000}`),
+			CreatedAt: time.Now().UTC(),
+		},
+		Status:   model.WebhookDeliveryPending,
+		Attempts: attempts,
+	}
+}
+
+func TestDispatchDelivered(t *testing.T) {
+	// given
+	var received eventEnvelope
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = Verify("secret", req.Header.Get(SignatureHeader), body, time.Minute); err != nil {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "5001", req.Header.Get(EventIdHeader))
+		assert.Equal(t, "transaction.deposit", req.Header.Get(EventTypeHeader))
+		if err = json.Unmarshal(body, &received); err != nil {
+			t.Fatal(err)
+		}
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	config := NewConfig()
+	webhookRepository := new(webhookRepositoryMock)
+	delivery := newDelivery(receiver.URL, 0)
+	webhookRepository.On("FanOutEvents", config.BatchSize).Return(1, nil)
+	webhookRepository.On("ClaimDueDeliveries", config.BatchSize, config.RequestTimeout*time.Duration(config.BatchSize+1)).Return(
+		[]*model.WebhookDelivery{delivery}, nil,
+	)
+	webhookRepository.On("UpdateDelivery", delivery).Return(nil)
+	dispatcher := NewDispatcher(logger, webhookRepository, receiver.Client(), config)
+
+	// when
+	err := dispatcher.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryDelivered, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.NotNil(t, delivery.DeliveredAt)
+	assert.Equal(t, "5001", received.ID)
+	assert.Equal(t, "transaction.deposit", received.Type)
+	assert.JSONEq(t, `{"transaction_id":"6001","amount":10000}`, string(received.Data))
+
+	webhookRepository.AssertExpectations(t)
+}
+
+func TestDispatchRetriedWithBackoff(t *testing.T) {
+	// given
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	config := NewConfig()
+	webhookRepository := new(webhookRepositoryMock)
+	delivery := newDelivery(receiver.URL, 2)
+	webhookRepository.On("FanOutEvents", config.BatchSize).Return(0, nil)
+	webhookRepository.On("ClaimDueDeliveries", config.BatchSize, config.RequestTimeout*time.Duration(config.BatchSize+1)).Return(
+		[]*model.WebhookDelivery{delivery}, nil,
+	)
+	webhookRepository.On("UpdateDelivery", delivery).Return(nil)
+	dispatcher := NewDispatcher(logger, webhookRepository, receiver.Client(), config)
+
+	// when
+	before := time.Now().UTC()
+	err := dispatcher.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, 3, delivery.Attempts)
+	assert.Contains(t, delivery.LastError, "unexpected status code 503")
+	assert.WithinDuration(t, before.Add(config.BackoffBase*4), delivery.NextAttemptAt, time.Second)
+	assert.Nil(t, delivery.DeliveredAt)
+
+	webhookRepository.AssertExpectations(t)
+}
+
+func TestDispatchDeadLetter(t *testing.T) {
+	// given
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	config := NewConfig()
+	webhookRepository := new(webhookRepositoryMock)
+	delivery := newDelivery(receiver.URL, config.MaxAttempts-1)
+	webhookRepository.On("FanOutEvents", config.BatchSize).Return(0, nil)
+	webhookRepository.On("ClaimDueDeliveries", config.BatchSize, config.RequestTimeout*time.Duration(config.BatchSize+1)).Return(
+		[]*model.WebhookDelivery{delivery}, nil,
+	)
+	webhookRepository.On("UpdateDelivery", delivery).Return(nil)
+	dispatcher := NewDispatcher(logger, webhookRepository, receiver.Client(), config)
+
+	// when
+	err := dispatcher.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, model.WebhookDeliveryDead, delivery.Status)
+	assert.Equal(t, config.MaxAttempts, delivery.Attempts)
+
+	webhookRepository.AssertExpectations(t)
+}
+
+func TestDispatchLeaseCoversBatch(t *testing.T) {
+	// given
+	config := NewConfig()
+	config.BatchSize = 3
+	config.RequestTimeout = time.Millisecond * 100
+	receiver := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(config.RequestTimeout * 4 / 5)
+		rw.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	webhookRepository := new(webhookRepositoryMock)
+	deliveries := make([]*model.WebhookDelivery, config.BatchSize)
+	for i := range deliveries {
+		deliveries[i] = newDelivery(receiver.URL, 0)
+	}
+	var leasedUntil time.Time
+	webhookRepository.On("FanOutEvents", config.BatchSize).Return(0, nil)
+	webhookRepository.On("ClaimDueDeliveries", config.BatchSize, mock.Anything).Run(func(args mock.Arguments) {
+		leasedUntil = time.Now().Add(args.Get(1).(time.Duration))
+	}).Return(deliveries, nil)
+	var updatedAt []time.Time
+	webhookRepository.On("UpdateDelivery", mock.Anything).Run(func(args mock.Arguments) {
+		updatedAt = append(updatedAt, time.Now())
+	}).Return(nil)
+	dispatcher := NewDispatcher(logger, webhookRepository, &http.Client{Timeout: config.RequestTimeout}, config)
+
+	// when
+	err := dispatcher.Dispatch()
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, updatedAt, config.BatchSize)
+	for _, at := range updatedAt {
+		assert.True(t, at.Before(leasedUntil), "delivery updated after its lease expired")
+	}
+	for _, delivery := range deliveries {
+		assert.Equal(t, model.WebhookDeliveryDelivered, delivery.Status)
+	}
+
+	webhookRepository.AssertExpectations(t)
+}
@@ -0,0 +1,57 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventIdHeader   = "X-Webhook-Event-Id"
+	EventTypeHeader = "X-Webhook-Event-Type"
+)
+
+// Sign returns the signature header value of a webhook body in the form
+// "t=<unix timestamp>,v1=<hex HMAC-SHA256 of "<timestamp>.<body>">".
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + computeSignature(secret, unix, body)
+}
+
+// Verify checks the signature header value against the body, rejecting
+// signatures older than tolerance. Receivers can use it to authenticate webhooks.
+func Verify(secret string, signature string, body []byte, tolerance time.Duration) error {
+	var unix, expected string
+	for _, part := range strings.Split(signature, ",") {
+		switch {
+		case strings.HasPrefix(part, "t="):
+			unix = strings.TrimPrefix(part, "t=")
+		case strings.HasPrefix(part, "v1="):
+			expected = strings.TrimPrefix(part, "v1=")
+		}
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || expected == "" {
+		return errors.New("malformed webhook signature")
+	}
+	if tolerance > 0 && time.Since(time.Unix(seconds, 0)) > tolerance {
+		return errors.New("webhook signature expired")
+	}
+	if !hmac.Equal([]byte(expected), []byte(computeSignature(secret, unix, body))) {
+		return errors.New("invalid webhook signature")
+	}
+	return nil
+}
+
+func computeSignature(secret string, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}